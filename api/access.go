@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"server-monitor/config"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RestrictNetworks 只允许受信任网段访问，用于管理接口、运行时设置和WebSocket；未配置允许网段时不限制
+func RestrictNetworks() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		checkNetwork(c)
+	}
+}
+
+// publicWrites 不受 restrictWrites 限制的非GET接口（方法 + 路由）：聊天消息中的告警按钮回调自带签名校验，
+// 即席查询只读取数据
+var publicWrites = map[string]bool{
+	"POST /api/v1/alerts/:id/actions/:action": true,
+	"POST /api/v1/query":                      true,
+}
+
+// restrictWrites 修改操作（GET、HEAD、OPTIONS以外的请求）只允许受信任网段访问，publicWrites 中的接口除外；
+// 在API路由组上统一设置，新增的修改接口无需逐个添加 RestrictNetworks
+func restrictWrites() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if publicWrites[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+		checkNetwork(c)
+	}
+}
+
+// restrictAll access.restrict_all 为true时所有页面和接口都只允许受信任网段访问
+func restrictAll() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.AppConfig.Access.RestrictAll {
+			c.Next()
+			return
+		}
+		checkNetwork(c)
+	}
+}
+
+// checkNetwork 客户端不在允许网段内时返回403
+func checkNetwork(c *gin.Context) {
+	if allowedNetwork(c.ClientIP(), config.AppConfig.Access.AllowedNetworks) {
+		c.Next()
+		return
+	}
+
+	abortError(c, http.StatusForbidden, tr(c, "不允许从该地址访问"), nil)
+}
+
+// allowedNetwork 判断IP是否在允许的网段（CIDR或单个IP）内；本机地址始终允许
+func allowedNetwork(clientIP string, networks []string) bool {
+	if len(networks) == 0 {
+		return true
+	}
+
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+
+	for _, network := range networks {
+		if !strings.Contains(network, "/") {
+			if allowed := net.ParseIP(network); allowed != nil && allowed.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if _, cidr, err := net.ParseCIDR(network); err == nil && cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"log"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/models"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 等待写入的访问记录上限，写入跟不上时丢弃新记录，不阻塞请求
+	accessLogBuffer = 4096
+	// 缓冲的记录达到该数量时立即写入
+	accessLogBatch = 500
+	// 每写入该数量的记录检查一次是否需要删除最早的记录
+	accessLogRotateEvery = 1000
+)
+
+var accessLogs = &accessLogger{
+	entries: make(chan models.AccessLog, accessLogBuffer),
+	flush:   make(chan chan struct{}),
+}
+
+// accessLogger 在后台批量写入访问记录，避免每个请求都写数据库
+type accessLogger struct {
+	once     sync.Once
+	started  atomic.Bool
+	entries  chan models.AccessLog
+	flush    chan chan struct{}
+	dropped  atomic.Int64
+	inserted int
+}
+
+// accessLog 记录每个请求的路径、状态码、耗时和客户端IP；低资源占用模式下不记录
+func accessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.AppConfig.AccessLog.Enabled || config.AppConfig.Minimal.Enabled {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0 // 没有响应体
+		}
+		accessLogs.add(models.AccessLog{
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Route:     c.FullPath(),
+			Status:    c.Writer.Status(),
+			Latency:   float64(time.Since(start).Microseconds()) / 1000,
+			Size:      size,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: c.GetString("request_id"),
+			Timestamp: start,
+		})
+	}
+}
+
+// FlushAccessLog 立即写入缓冲的访问记录，用于关闭服务器前
+func FlushAccessLog() {
+	// 后台写入尚未启动时没有需要写入的记录
+	if !accessLogs.started.Load() {
+		return
+	}
+	done := make(chan struct{})
+	accessLogs.flush <- done
+	<-done
+}
+
+// add 缓冲一条访问记录，缓冲已满时丢弃
+func (l *accessLogger) add(entry models.AccessLog) {
+	l.once.Do(func() {
+		l.started.Store(true)
+		go l.run()
+	})
+	select {
+	case l.entries <- entry:
+	default:
+		l.dropped.Add(1)
+	}
+}
+
+// run 每隔 flush_interval 秒或缓冲达到 accessLogBatch 条时批量写入
+func (l *accessLogger) run() {
+	batch := make([]models.AccessLog, 0, accessLogBatch)
+	timer := time.NewTimer(flushInterval())
+	for {
+		select {
+		case entry := <-l.entries:
+			batch = append(batch, entry)
+			if len(batch) < accessLogBatch {
+				continue
+			}
+		case <-timer.C:
+			timer.Reset(flushInterval())
+		case done := <-l.flush:
+			batch = l.drain(batch)
+			l.write(batch)
+			batch = batch[:0]
+			close(done)
+			continue
+		}
+		l.write(batch)
+		batch = batch[:0]
+	}
+}
+
+// drain 取出缓冲中所有等待写入的记录
+func (l *accessLogger) drain(batch []models.AccessLog) []models.AccessLog {
+	for {
+		select {
+		case entry := <-l.entries:
+			batch = append(batch, entry)
+		default:
+			return batch
+		}
+	}
+}
+
+// write 写入一批记录，累计写入足够多后删除超出 max_rows 的最早记录
+func (l *accessLogger) write(batch []models.AccessLog) {
+	if dropped := l.dropped.Swap(0); dropped > 0 {
+		log.Printf("Access log buffer full, dropped %d entries", dropped)
+	}
+	if len(batch) == 0 {
+		return
+	}
+	if err := database.DB.CreateInBatches(batch, 100).Error; err != nil {
+		log.Printf("Error writing access log: %v", err)
+		return
+	}
+
+	l.inserted += len(batch)
+	if l.inserted < accessLogRotateEvery {
+		return
+	}
+	l.inserted = 0
+
+	var maxID int64
+	if err := database.DB.Model(&models.AccessLog{}).Select("COALESCE(MAX(id), 0)").Scan(&maxID).Error; err != nil {
+		log.Printf("Error rotating access log: %v", err)
+		return
+	}
+	if cutoff := maxID - int64(config.AppConfig.AccessLog.MaxRows); cutoff > 0 {
+		if err := database.DB.Where("id <= ?", cutoff).Delete(&models.AccessLog{}).Error; err != nil {
+			log.Printf("Error rotating access log: %v", err)
+		}
+	}
+}
+
+// flushInterval 当前配置的写入间隔，配置重新加载后下一轮生效
+func flushInterval() time.Duration {
+	if seconds := config.AppConfig.AccessLog.FlushInterval; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 5 * time.Second
+}
+
+// GetAccessLog 查询访问记录，可按路径、路由、方法、IP、状态码（如 404 或 5xx）、最小耗时、请求ID和时间范围过滤；
+// sort=latency 时按耗时降序，用于找出慢接口
+func GetAccessLog(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		limit = 100
+	}
+
+	order := "timestamp desc"
+	if c.Query("sort") == "latency" {
+		order = "latency desc"
+	}
+	query := database.DB.Order(order).Limit(limit)
+	if path := c.Query("path"); path != "" {
+		query = query.Where("path LIKE ?", "%"+path+"%")
+	}
+	if route := c.Query("route"); route != "" {
+		query = query.Where("route = ?", route)
+	}
+	if method := c.Query("method"); method != "" {
+		query = query.Where("method = ?", strings.ToUpper(method))
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	if id := c.Query("request_id"); id != "" {
+		query = query.Where("request_id = ?", id)
+	}
+	if status := strings.ToLower(c.Query("status")); status != "" {
+		if class, ok := strings.CutSuffix(status, "xx"); ok {
+			if digit, err := strconv.Atoi(class); err == nil {
+				query = query.Where("status >= ? AND status < ?", digit*100, digit*100+100)
+			}
+		} else if code, err := strconv.Atoi(status); err == nil {
+			query = query.Where("status = ?", code)
+		}
+	}
+	if minLatency, err := strconv.ParseFloat(c.Query("min_latency"), 64); err == nil {
+		query = query.Where("latency >= ?", minLatency)
+	}
+	if from, err := time.Parse(time.RFC3339, c.Query("from")); err == nil {
+		query = query.Where("timestamp >= ?", from)
+	}
+	if to, err := time.Parse(time.RFC3339, c.Query("to")); err == nil {
+		query = query.Where("timestamp <= ?", to)
+	}
+
+	streamJSON[models.AccessLog](c, query, "获取访问日志失败")
+}
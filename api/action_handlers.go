@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetActions 获取维护操作列表
+func GetActions(c *gin.Context) {
+	var actions []models.Action
+	if err := database.DB.Order("id asc").Find(&actions).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取维护操作失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    actions,
+	})
+}
+
+// CreateAction 创建维护操作
+func CreateAction(c *gin.Context) {
+	var action models.Action
+	if err := c.ShouldBindJSON(&action); err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
+		return
+	}
+
+	action.ID = 0
+	action.LastRun, action.LastStatus = time.Time{}, ""
+	if err := monitor.ValidateAction(&action); err != nil {
+		abortError(c, http.StatusBadRequest, trErr(c, err), err)
+		return
+	}
+
+	if err := database.DB.Create(&action).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "创建维护操作失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "维护操作已创建"),
+		Data:    action,
+	})
+}
+
+// UpdateAction 更新维护操作，按新的cron表达式重新计算下一次执行时间
+func UpdateAction(c *gin.Context) {
+	var action models.Action
+	if err := database.DB.First(&action, c.Param("id")).Error; err != nil {
+		abortError(c, http.StatusNotFound, tr(c, "维护操作不存在"), err)
+		return
+	}
+
+	id, createdAt := action.ID, action.CreatedAt
+	lastRun, lastStatus := action.LastRun, action.LastStatus
+	if err := c.ShouldBindJSON(&action); err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
+		return
+	}
+	action.ID, action.CreatedAt = id, createdAt
+	action.LastRun, action.LastStatus = lastRun, lastStatus
+
+	if err := monitor.ValidateAction(&action); err != nil {
+		abortError(c, http.StatusBadRequest, trErr(c, err), err)
+		return
+	}
+
+	if err := database.DB.Save(&action).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "更新维护操作失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "维护操作已更新"),
+		Data:    action,
+	})
+}
+
+// DeleteAction 删除维护操作，执行记录保留到过期清理
+func DeleteAction(c *gin.Context) {
+	result := database.DB.Delete(&models.Action{}, c.Param("id"))
+	if result.Error != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "删除维护操作失败"), result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		abortError(c, http.StatusNotFound, tr(c, "维护操作不存在"), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "维护操作已删除"),
+		Data:    nil,
+	})
+}
+
+// RunActionNow 立即执行维护操作并返回执行记录，不影响下一次计划执行的时间
+func RunActionNow(c *gin.Context) {
+	var action models.Action
+	if err := database.DB.First(&action, c.Param("id")).Error; err != nil {
+		abortError(c, http.StatusNotFound, tr(c, "维护操作不存在"), err)
+		return
+	}
+
+	run, err := monitor.RunAction(action, "manual")
+	if errors.Is(err, monitor.ErrActionRunning) {
+		abortError(c, http.StatusConflict, trErr(c, err), err)
+		return
+	}
+	if err != nil {
+		abortError(c, http.StatusBadRequest, trErr(c, err), err)
+		return
+	}
+
+	message := tr(c, "执行成功")
+	if !run.Success {
+		message = tr(c, "执行失败: %s", run.Error)
+	}
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: message,
+		Data:    run,
+	})
+}
+
+// GetActionRuns 获取维护操作执行记录，支持按操作、执行结果和时间范围过滤
+func GetActionRuns(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		limit = 100
+	}
+
+	query := database.DB.Order("started_at desc").Limit(limit)
+	if id := c.Query("action_id"); id != "" {
+		query = query.Where("action_id = ?", id)
+	}
+	switch c.Query("status") {
+	case "success":
+		query = query.Where("success = ?", true)
+	case "failed":
+		query = query.Where("success = ?", false)
+	}
+	if hours, err := strconv.Atoi(c.Query("hours")); err == nil && hours > 0 {
+		query = query.Where("started_at >= ?", time.Now().Add(-time.Duration(hours)*time.Hour))
+	}
+
+	streamJSON[models.ActionRun](c, query, "获取执行记录失败")
+}
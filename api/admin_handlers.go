@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/config"
+	"server-monitor/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCleanupStats 获取最近一次数据清理的统计（每张表删除的行数、耗时）
+func GetCleanupStats(c *gin.Context) {
+	stats := database.GetLastCleanupStats()
+	if stats == nil {
+		c.JSON(http.StatusOK, Response{
+			Code:    200,
+			Message: tr(c, "尚未执行过数据清理"),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    stats,
+	})
+}
+
+// ReloadConfig 重新加载配置文件，阈值、采集间隔和监控服务无需重启即可生效
+func ReloadConfig(c *gin.Context) {
+	if err := config.Reload(); err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "配置重新加载失败: %s", trErr(c, err)), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "配置已重新加载"),
+		Data:    nil,
+	})
+}
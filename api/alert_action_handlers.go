@@ -0,0 +1,297 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"server-monitor/notify"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AcknowledgeAlert 确认告警
+func AcknowledgeAlert(c *gin.Context) {
+	alertID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondAlertError(c, gorm.ErrRecordNotFound)
+		return
+	}
+
+	alert, err := monitor.AcknowledgeAlert(uint(alertID))
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "告警已确认"),
+		Data:    alert,
+	})
+}
+
+// SilenceAlert 静默告警，请求体可指定静默时长 {"duration": 3600}（秒）
+func SilenceAlert(c *gin.Context) {
+	alertID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondAlertError(c, gorm.ErrRecordNotFound)
+		return
+	}
+
+	var req struct {
+		Duration int `json:"duration"`
+	}
+	c.ShouldBindJSON(&req)
+	if req.Duration <= 0 {
+		req.Duration = config.AppConfig.Notify.SilenceDuration
+	}
+
+	alert, err := monitor.SilenceAlert(uint(alertID), time.Duration(req.Duration)*time.Second)
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "告警已静默"),
+		Data:    alert,
+	})
+}
+
+// 告警操作确认页的按钮文字
+var alertActionLabels = map[string]string{
+	"ack":     "确认告警",
+	"resolve": "解决告警",
+	"silence": "静默告警",
+}
+
+// alertActionTemplate 告警操作确认页：样式内联、不依赖外部资源，表单提交到同一个签名链接
+var alertActionTemplate = template.Must(template.New("action").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Label}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI",sans-serif;max-width:480px;margin:40px auto;padding:0 16px;color:#222}
+.alert{border-left:4px solid #c0392b;background:#f8f8f8;padding:12px 16px;margin:16px 0;word-break:break-word}
+.meta{color:#888;font-size:13px}
+button{font-size:16px;padding:10px 24px;border:0;border-radius:4px;background:#2c7be5;color:#fff;cursor:pointer}
+</style>
+</head>
+<body>
+<h2>{{.Label}}</h2>
+<div class="alert">{{.Message}}<p class="meta">{{.Type}} · {{.Level}} · {{.Status}}</p></div>
+<form method="post" action="{{.Action}}">
+<button type="submit">{{.Label}}</button>
+</form>
+</body>
+</html>
+`))
+
+// verifyAlertAction 解析告警ID并校验回调链接的签名和有效期，失败时已写入错误响应
+func verifyAlertAction(c *gin.Context) (uint, string, bool) {
+	alertID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondAlertError(c, gorm.ErrRecordNotFound)
+		return 0, "", false
+	}
+	action := c.Param("action")
+	expires, _ := strconv.ParseInt(c.Query("expires"), 10, 64)
+
+	if err := notify.VerifyAction(uint(alertID), action, expires, c.Query("sig")); err != nil {
+		abortError(c, http.StatusForbidden, trErr(c, err), err)
+		return 0, "", false
+	}
+	if _, ok := alertActionLabels[action]; !ok {
+		abortError(c, http.StatusBadRequest, tr(c, "不支持的操作: %s", action), nil)
+		return 0, "", false
+	}
+	return uint(alertID), action, true
+}
+
+// ConfirmAlertAction 聊天机器人按钮打开的签名链接：只显示确认页，由页面上的表单以POST提交到同一链接后才执行操作，
+// 避免聊天工具的链接预览或安全扫描访问链接时误操作
+func ConfirmAlertAction(c *gin.Context) {
+	alertID, action, ok := verifyAlertAction(c)
+	if !ok {
+		return
+	}
+
+	var alert models.Alert
+	if err := database.DB.First(&alert, alertID).Error; err != nil {
+		respondAlertError(c, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	err := alertActionTemplate.Execute(&buf, map[string]string{
+		"Label":   tr(c, alertActionLabels[action]),
+		"Message": alert.Message,
+		"Type":    alert.Type,
+		"Level":   alert.Level,
+		"Status":  alert.Status,
+		"Action":  c.Request.URL.RequestURI(),
+	})
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "告警操作失败"), err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// AlertAction 确认页提交的签名回调：ack、resolve、silence
+func AlertAction(c *gin.Context) {
+	alertID, action, ok := verifyAlertAction(c)
+	if !ok {
+		return
+	}
+
+	var alert *models.Alert
+	var err error
+	var message string
+	switch action {
+	case "ack":
+		alert, err = monitor.AcknowledgeAlert(alertID)
+		message = "告警已确认"
+	case "resolve":
+		alert, err = monitor.ResolveAlertByID(alertID)
+		message = "告警已解决"
+	case "silence":
+		duration := time.Duration(config.AppConfig.Notify.SilenceDuration) * time.Second
+		alert, err = monitor.SilenceAlert(alertID, duration)
+		message = "告警已静默"
+	}
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, message),
+		Data:    alert,
+	})
+}
+
+// GetAlertTimeline 获取告警的状态变化时间线和处理备注，/timeline 和 /history 共用
+func GetAlertTimeline(c *gin.Context) {
+	alertID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondAlertError(c, gorm.ErrRecordNotFound)
+		return
+	}
+
+	alert, events, err := monitor.GetAlertTimeline(uint(alertID))
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+	comments, err := monitor.GetAlertComments(uint(alertID))
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data: gin.H{
+			"alert":    alert,
+			"events":   events,
+			"comments": comments,
+		},
+	})
+}
+
+// 告警备注的最大长度（字符）
+const maxCommentLength = 4000
+
+// AddAlertComment 为告警添加备注，请求体 {"author": "张三", "content": "磁盘被日志占满，已清理"}，未填写作者时使用认证用户
+func AddAlertComment(c *gin.Context) {
+	alertID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondAlertError(c, gorm.ErrRecordNotFound)
+		return
+	}
+
+	var req struct {
+		Author  string `json:"author"`
+		Content string `json:"content"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
+		return
+	}
+
+	content := strings.TrimSpace(req.Content)
+	if content == "" || utf8.RuneCountInString(content) > maxCommentLength {
+		abortError(c, http.StatusBadRequest, tr(c, "备注内容不能为空，且不能超过%d个字符", maxCommentLength), nil)
+		return
+	}
+	author := strings.TrimSpace(req.Author)
+	if author == "" {
+		author = c.GetString(AuditUserKey)
+	}
+	if author == "" {
+		author = "匿名"
+	}
+
+	comment, err := monitor.AddAlertComment(uint(alertID), author, content)
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "备注已添加"),
+		Data:    comment,
+	})
+}
+
+// GetAlertComments 获取告警的备注
+func GetAlertComments(c *gin.Context) {
+	alertID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondAlertError(c, gorm.ErrRecordNotFound)
+		return
+	}
+
+	comments, err := monitor.GetAlertComments(uint(alertID))
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    comments,
+	})
+}
+
+// respondAlertError 将告警操作错误转换为对应的HTTP响应
+func respondAlertError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		abortError(c, http.StatusNotFound, tr(c, "告警不存在"), err)
+	case errors.Is(err, monitor.ErrAlertResolved):
+		abortError(c, http.StatusConflict, trErr(c, err), err)
+	default:
+		abortError(c, http.StatusInternalServerError, tr(c, "告警操作失败"), err)
+	}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"server-monitor/monitor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAlertRules 获取告警规则列表
+func GetAlertRules(c *gin.Context) {
+	query := database.DB.Order("id asc")
+	if ruleType := c.Query("type"); ruleType != "" {
+		query = query.Where("type = ?", ruleType)
+	}
+
+	var rules []models.AlertRule
+	err := query.Find(&rules).Error
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取告警规则失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    rules,
+	})
+}
+
+// CreateAlertRule 创建告警规则
+func CreateAlertRule(c *gin.Context) {
+	var rule models.AlertRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
+		return
+	}
+
+	rule.ID = 0
+	if err := validateAlertRule(&rule); err != nil {
+		abortError(c, http.StatusBadRequest, trErr(c, err), err)
+		return
+	}
+
+	err := database.DB.Create(&rule).Error
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "创建告警规则失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "告警规则已创建"),
+		Data:    rule,
+	})
+}
+
+// UpdateAlertRule 更新告警规则
+func UpdateAlertRule(c *gin.Context) {
+	var rule models.AlertRule
+	err := database.DB.First(&rule, c.Param("id")).Error
+	if err != nil {
+		abortError(c, http.StatusNotFound, tr(c, "告警规则不存在"), err)
+		return
+	}
+
+	id, createdAt := rule.ID, rule.CreatedAt
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
+		return
+	}
+	rule.ID, rule.CreatedAt = id, createdAt
+
+	if err := validateAlertRule(&rule); err != nil {
+		abortError(c, http.StatusBadRequest, trErr(c, err), err)
+		return
+	}
+
+	err = database.DB.Save(&rule).Error
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "更新告警规则失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "告警规则已更新"),
+		Data:    rule,
+	})
+}
+
+// DeleteAlertRule 删除告警规则
+func DeleteAlertRule(c *gin.Context) {
+	result := database.DB.Delete(&models.AlertRule{}, c.Param("id"))
+	if result.Error != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "删除告警规则失败"), result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		abortError(c, http.StatusNotFound, tr(c, "告警规则不存在"), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "告警规则已删除"),
+		Data:    nil,
+	})
+}
+
+// validateAlertRule 校验告警规则参数
+func validateAlertRule(rule *models.AlertRule) error {
+	if rule.Name == "" {
+		return i18n.Errorf("规则名称不能为空")
+	}
+
+	switch rule.Level {
+	case "":
+		rule.Level = "warning"
+	case "info", "warning", "error":
+	default:
+		return i18n.Errorf("不支持的告警级别: %s", rule.Level)
+	}
+
+	switch rule.Type {
+	case "no_data":
+		if !monitor.IsNoDataMetric(rule.Metric) {
+			return i18n.Errorf("不支持的数据源: %s", rule.Metric)
+		}
+		if rule.Intervals < 1 {
+			return i18n.Errorf("采集周期数必须大于0")
+		}
+	case "comparison":
+		return monitor.ValidateComparisonRule(rule)
+	case "api":
+		return monitor.ValidateAPIRule(rule)
+	default:
+		return i18n.Errorf("不支持的规则类型: %s", rule.Type)
+	}
+
+	return nil
+}
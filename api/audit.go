@@ -0,0 +1,191 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 审计记录中请求体摘要的最大长度
+	auditPayloadLimit = 1024
+	// 读取请求体的上限，超过时只记录前面部分
+	auditBodyLimit = 64 << 10
+)
+
+// AuditUserKey 认证中间件在请求上下文中设置的操作者（用户名或API Key名称），审计日志据此记录操作者
+const AuditUserKey = "audit_user"
+
+// 不记录审计日志的接口：使用POST但不修改数据的查询，以及远程主机按采集频率推送的数据接入
+var auditSkip = map[string]bool{
+	"/api/v1/query":          true,
+	"/api/v1/ingest/metrics": true,
+	"/api/v1/alerts/ingest":  true,
+}
+
+// audit 记录所有修改操作（POST、PUT、DELETE等非只读请求）
+func audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case "GET", "HEAD", "OPTIONS":
+			c.Next()
+			return
+		}
+		if auditSkip[c.FullPath()] {
+			c.Next()
+			return
+		}
+		recordAudit(c)
+	}
+}
+
+// recordAudit 执行请求并记录操作者、来源IP、请求摘要和结果
+func recordAudit(c *gin.Context) {
+	payload := readAuditPayload(c)
+	writer := &auditWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	start := time.Now()
+
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	params := make([]string, 0, len(c.Params))
+	for _, param := range c.Params {
+		params = append(params, param.Key+"="+param.Value)
+	}
+	record := models.AuditLog{
+		Action:    c.Request.Method + " " + route,
+		Target:    strings.Join(params, ","),
+		User:      c.GetString(AuditUserKey),
+		IP:        c.ClientIP(),
+		Payload:   payload,
+		Status:    writer.Status(),
+		Result:    writer.message(),
+		Duration:  time.Since(start).Milliseconds(),
+		Timestamp: start,
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		log.Printf("Error recording audit log for %s: %v", record.Action, err)
+	}
+}
+
+// readAuditPayload 读取请求体生成摘要（敏感字段脱敏），并恢复请求体供处理器读取
+func readAuditPayload(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, auditBodyLimit))
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	if len(body) == 0 {
+		return ""
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return truncateAudit("<" + strconv.Itoa(len(body)) + " bytes>")
+	}
+	redacted, err := json.Marshal(config.Redact("", value))
+	if err != nil {
+		return ""
+	}
+	return truncateAudit(string(redacted))
+}
+
+// truncateAudit 截断过长的摘要
+func truncateAudit(s string) string {
+	if len(s) <= auditPayloadLimit {
+		return s
+	}
+	// 按UTF-8字符边界截断
+	cut := auditPayloadLimit
+	for cut > 0 && s[cut]&0xC0 == 0x80 {
+		cut--
+	}
+	return s[:cut] + "..."
+}
+
+// auditWriter 保留响应体的开头部分，用于从Response中提取结果消息
+type auditWriter struct {
+	gin.ResponseWriter
+	head []byte
+}
+
+func (w *auditWriter) Write(data []byte) (int, error) {
+	if remaining := auditPayloadLimit - len(w.head); remaining > 0 {
+		if len(data) < remaining {
+			remaining = len(data)
+		}
+		w.head = append(w.head, data[:remaining]...)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// message 从响应开头逐个读取字段找到Response的message，响应被截断或不是Response格式时为空
+func (w *auditWriter) message() string {
+	decoder := json.NewDecoder(bytes.NewReader(w.head))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return ""
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		if key == "message" {
+			var message string
+			decoder.Decode(&message)
+			return message
+		}
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return ""
+		}
+	}
+	return ""
+}
+
+// GetAuditLogs 查询审计日志，可按操作、操作者、IP和时间范围过滤
+func GetAuditLogs(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		limit = 100
+	}
+
+	query := database.DB.Order("timestamp desc").Limit(limit)
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action LIKE ?", "%"+action+"%")
+	}
+	if user := c.Query("user"); user != "" {
+		query = query.Where("user = ?", user)
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	if from, err := time.Parse(time.RFC3339, c.Query("from")); err == nil {
+		query = query.Where("timestamp >= ?", from)
+	}
+	if to, err := time.Parse(time.RFC3339, c.Query("to")); err == nil {
+		query = query.Where("timestamp <= ?", to)
+	}
+
+	streamJSON[models.AuditLog](c, query, "获取审计日志失败")
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 徽章的颜色，与 shields.io 一致
+const (
+	badgeGreen  = "#4c1"
+	badgeLime   = "#97ca00"
+	badgeYellow = "#dfb317"
+	badgeRed    = "#e05d44"
+	badgeGrey   = "#9f9f9f"
+)
+
+// 服务状态对应的徽章颜色
+var badgeStatusColors = map[string]string{
+	"running": badgeGreen,
+	"warning": badgeYellow,
+	"error":   badgeRed,
+}
+
+// GetServiceStatusBadge 服务当前状态的SVG徽章，可嵌入README、Wiki等页面；只提供设为公开的服务，?label= 自定义左侧文字
+func GetServiceStatusBadge(c *gin.Context) {
+	service, ok := publicBadgeService(c)
+	if !ok {
+		return
+	}
+
+	color, ok := badgeStatusColors[service.Status]
+	if !ok {
+		color = badgeGrey
+	}
+	writeBadge(c, http.StatusOK, c.DefaultQuery("label", service.Name), statusLabel(service.Status), color)
+}
+
+// GetServiceUptimeBadge 服务最近24h、7d或30d可用率的SVG徽章，只提供设为公开的服务
+func GetServiceUptimeBadge(c *gin.Context) {
+	service, ok := publicBadgeService(c)
+	if !ok {
+		return
+	}
+
+	period := c.Param("period")
+	uptime, err := monitor.GetServiceAvailability(service, period)
+	if errors.Is(err, monitor.ErrUnknownUptimePeriod) {
+		writeBadge(c, http.StatusBadRequest, "uptime", "无效周期", badgeGrey)
+		return
+	}
+	if err != nil {
+		writeBadge(c, http.StatusInternalServerError, "uptime", "查询失败", badgeGrey)
+		return
+	}
+
+	label := c.DefaultQuery("label", service.Name+" "+period)
+	if uptime.Availability == nil {
+		writeBadge(c, http.StatusOK, label, "无数据", badgeGrey)
+		return
+	}
+	availability := *uptime.Availability
+	color := badgeRed
+	switch {
+	case availability >= 99.9:
+		color = badgeGreen
+	case availability >= 99:
+		color = badgeLime
+	case availability >= 95:
+		color = badgeYellow
+	}
+	writeBadge(c, http.StatusOK, label, fmt.Sprintf("%.2f%%", availability), color)
+}
+
+// publicBadgeService 查找徽章对应的服务，服务不存在或未公开时输出"未找到"徽章，不暴露服务是否存在
+func publicBadgeService(c *gin.Context) (models.ServiceStatus, bool) {
+	var service models.ServiceStatus
+	if err := database.DB.Where("public = ?", true).First(&service, c.Param("id")).Error; err != nil {
+		writeBadge(c, http.StatusNotFound, "service", "未找到", badgeGrey)
+		return service, false
+	}
+	return service, true
+}
+
+// writeBadge 输出 shields.io flat 风格的徽章；嵌入徽章的页面通常经过图片代理缓存，缓存时间设为一分钟
+func writeBadge(c *gin.Context, status int, label, message, color string) {
+	labelWidth, messageWidth := badgeTextWidth(label)+10, badgeTextWidth(message)+10
+	width := labelWidth + messageWidth
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">`+
+		`<title>%[4]s: %[5]s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[7]d" y="14">%[4]s</text>`+
+		`<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text><text x="%[8]d" y="14">%[5]s</text>`+
+		`</g></svg>`,
+		width, labelWidth, messageWidth, label, message, color, labelWidth/2, labelWidth+messageWidth/2)
+
+	c.Header("Cache-Control", "max-age=60")
+	c.Data(status, "image/svg+xml; charset=utf-8", []byte(svg))
+}
+
+// badgeTextWidth 估算文字在11px Verdana下的宽度：ASCII字符约7px，中文等全角字符约12px
+func badgeTextWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		switch {
+		case r >= 0x1100:
+			width += 12
+		case r == 'i' || r == 'l' || r == '.' || r == ' ' || r == ':':
+			width += 4
+		default:
+			width += 7
+		}
+	}
+	return width
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// basicAuth 管理员接口的访问控制：enabled 返回false时返回404（disabledMsg），否则要求与 creds 一致的HTTP Basic认证，
+// 未设置密码时拒绝所有请求；每次请求读取当前配置，重新加载配置后立即生效
+func basicAuth(enabled func() bool, creds func() (user, pass string), realm, disabledMsg string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled() {
+			abortError(c, http.StatusNotFound, tr(c, disabledMsg), nil)
+			return
+		}
+
+		expectedUser, expectedPass := creds()
+		username, password, ok := c.Request.BasicAuth()
+		if !ok || expectedPass == "" ||
+			subtle.ConstantTimeCompare([]byte(username), []byte(expectedUser)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(password), []byte(expectedPass)) != 1 {
+			c.Header("WWW-Authenticate", `Basic realm="`+realm+`"`)
+			abortError(c, http.StatusUnauthorized, tr(c, "需要管理员认证"), nil)
+			return
+		}
+
+		c.Set(AuditUserKey, username)
+		c.Next()
+	}
+}
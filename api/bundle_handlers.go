@@ -0,0 +1,231 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"server-monitor/bundles"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"server-monitor/monitor"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 导入的检查包大小上限
+const maxBundleSize = 1 << 20
+
+// BundleImportResult 检查包导入结果，已存在的同名项目会被跳过
+type BundleImportResult struct {
+	Bundle     string   `json:"bundle"`
+	Services   int      `json:"services"`
+	Processes  int      `json:"processes"`
+	AlertRules int      `json:"alert_rules"`
+	Skipped    []string `json:"skipped"`
+}
+
+// GetBundles 获取内置检查包列表
+func GetBundles(c *gin.Context) {
+	list, err := bundles.List()
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取检查包失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    list,
+	})
+}
+
+// ImportBundle 导入检查包：?name= 指定内置检查包，否则从请求体读取JSON或YAML格式的检查包
+func ImportBundle(c *gin.Context) {
+	var bundle *bundles.Bundle
+	var err error
+	if name := c.Query("name"); name != "" {
+		bundle, err = bundles.Get(name)
+	} else {
+		var data []byte
+		data, err = io.ReadAll(io.LimitReader(c.Request.Body, maxBundleSize+1))
+		if err == nil && len(data) > maxBundleSize {
+			err = i18n.Errorf("检查包不能超过1MB")
+		}
+		if err == nil {
+			bundle, err = bundles.Parse(data)
+		}
+	}
+	if err != nil {
+		abortError(c, http.StatusBadRequest, trErr(c, err), err)
+		return
+	}
+
+	// 先校验全部项目，任一项目无效时不导入
+	if err := validateBundle(bundle); err != nil {
+		abortError(c, http.StatusBadRequest, trErr(c, err), err)
+		return
+	}
+
+	result := BundleImportResult{Bundle: bundle.Name, Skipped: []string{}}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, check := range bundle.Services {
+			created, err := createIfAbsent(tx, &check, "name = ?", check.Name)
+			if err != nil {
+				return err
+			}
+			if !created {
+				result.Skipped = append(result.Skipped, "服务: "+check.Name)
+				continue
+			}
+			result.Services++
+		}
+		for _, watch := range bundle.Processes {
+			created, err := createIfAbsent(tx, &watch, "name = ?", watch.Name)
+			if err != nil {
+				return err
+			}
+			if !created {
+				result.Skipped = append(result.Skipped, "进程: "+watch.Name)
+				continue
+			}
+			result.Processes++
+		}
+		for _, rule := range bundle.AlertRules {
+			created, err := createIfAbsent(tx, &rule, "name = ? AND type = ?", rule.Name, rule.Type)
+			if err != nil {
+				return err
+			}
+			if !created {
+				result.Skipped = append(result.Skipped, "告警规则: "+rule.Name)
+				continue
+			}
+			result.AlertRules++
+		}
+		return nil
+	})
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "导入检查包失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code: 200,
+		Message: tr(c, "已导入检查包 %s: %d个服务检查，%d个进程监视，%d条告警规则",
+			bundle.Name, result.Services, result.Processes, result.AlertRules),
+		Data: result,
+	})
+}
+
+// validateBundle 校验检查包中的所有项目并填充默认值，导入的项目记录来源检查包
+func validateBundle(bundle *bundles.Bundle) error {
+	for i := range bundle.Services {
+		check := &bundle.Services[i]
+		check.ID, check.Bundle = 0, bundle.Name
+		if err := monitor.ValidateServiceCheck(check); err != nil {
+			return err
+		}
+	}
+	for i := range bundle.Processes {
+		watch := &bundle.Processes[i]
+		watch.ID, watch.Bundle = 0, bundle.Name
+		if err := monitor.ValidateProcessWatch(watch); err != nil {
+			return err
+		}
+	}
+	for i := range bundle.AlertRules {
+		rule := &bundle.AlertRules[i]
+		rule.ID = 0
+		if err := validateAlertRule(rule); err != nil {
+			return i18n.Errorf("告警规则 %s: %v", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+// createIfAbsent 不存在满足条件的记录时创建，返回是否已创建
+func createIfAbsent(tx *gorm.DB, value interface{}, query string, args ...interface{}) (bool, error) {
+	var count int64
+	if err := tx.Model(value).Where(query, args...).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+	return true, tx.Create(value).Error
+}
+
+// GetServiceChecks 获取自定义服务检查列表
+func GetServiceChecks(c *gin.Context) {
+	var checks []models.ServiceCheck
+	if err := database.DB.Order("id asc").Find(&checks).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取服务检查失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    checks,
+	})
+}
+
+// DeleteServiceCheck 删除自定义服务检查及其服务状态
+func DeleteServiceCheck(c *gin.Context) {
+	var check models.ServiceCheck
+	if err := database.DB.First(&check, c.Param("id")).Error; err != nil {
+		abortError(c, http.StatusNotFound, tr(c, "服务检查不存在"), err)
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&check).Error; err != nil {
+			return err
+		}
+		return tx.Where("name = ?", check.Name).Delete(&models.ServiceStatus{}).Error
+	})
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "删除服务检查失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "服务检查已删除"),
+		Data:    nil,
+	})
+}
+
+// GetProcessWatches 获取进程监视列表
+func GetProcessWatches(c *gin.Context) {
+	var watches []models.ProcessWatch
+	if err := database.DB.Order("id asc").Find(&watches).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取进程监视失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    watches,
+	})
+}
+
+// DeleteProcessWatch 删除进程监视
+func DeleteProcessWatch(c *gin.Context) {
+	result := database.DB.Delete(&models.ProcessWatch{}, c.Param("id"))
+	if result.Error != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "删除进程监视失败"), result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		abortError(c, http.StatusNotFound, tr(c, "进程监视不存在"), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "进程监视已删除"),
+		Data:    nil,
+	})
+}
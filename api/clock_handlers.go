@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetClock 获取最近一次时钟偏差检查的结果，未检查过时 data 为null
+func GetClock(c *gin.Context) {
+	check, err := monitor.GetLatestClockCheck()
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取时钟偏差检查结果失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    check,
+	})
+}
+
+// GetClockHistory 获取最近 hours 小时（默认168）的时钟偏差检查结果，按时间正序，便于绘制偏差曲线
+func GetClockHistory(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "168"))
+	if err != nil || hours <= 0 {
+		hours = 168
+	}
+
+	var checks []models.ClockCheck
+	err = database.DB.Where("timestamp >= ?", time.Now().Add(-time.Duration(hours)*time.Hour)).
+		Order("timestamp asc").Find(&checks).Error
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取时钟偏差检查结果失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    checks,
+	})
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"server-monitor/config"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// 压缩器按级别复用，避免每个请求重新分配压缩缓冲区
+var (
+	gzipPools   sync.Map // level -> *sync.Pool
+	brotliPools sync.Map // level -> *sync.Pool
+)
+
+// compress 按客户端Accept-Encoding压缩响应（优先brotli，其次gzip），用于返回大量JSON的接口；
+// 小于 min_size 的响应不压缩
+func compress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.AppConfig.Server.Compression
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+
+		c.Header("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), cfg.Brotli)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			cfg:            cfg,
+		}
+		c.Writer = w
+		defer w.finish()
+		c.Next()
+	}
+}
+
+// negotiateEncoding 按q值选择压缩方式，q值相同时优先brotli；不接受任何压缩时返回空字符串
+func negotiateEncoding(header string, allowBrotli bool) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, q := strings.TrimSpace(part), 1.0
+		if i := strings.Index(name, ";"); i >= 0 {
+			param := strings.TrimSpace(name[i+1:])
+			name = strings.TrimSpace(name[:i])
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		name = strings.ToLower(name)
+		if name == "*" {
+			name = "gzip"
+			if allowBrotli {
+				name = "br"
+			}
+		}
+		if name == "br" && !allowBrotli || name != "br" && name != "gzip" {
+			continue
+		}
+		if q > bestQ || q == bestQ && q > 0 && name == "br" {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressWriter 先缓冲响应，超过 min_size 后才开始压缩输出
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	cfg      config.CompressionConfig
+
+	buf     []byte
+	encoder io.WriteCloser
+	release func()
+	decided bool
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	if w.decided {
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf = append(w.buf, data...)
+	if len(w.buf) >= w.cfg.MinSize {
+		if err := w.start(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 流式输出时刷新已压缩的数据；仍在缓冲时不输出，避免小响应被提前发出
+func (w *compressWriter) Flush() {
+	if w.encoder != nil {
+		if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+			flusher.Flush()
+		}
+	}
+	if w.decided {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// start 确定压缩响应：设置响应头并输出已缓冲的数据；已编码的响应或无响应体的状态码不压缩
+func (w *compressWriter) start() error {
+	w.decided = true
+	header := w.Header()
+	status := w.Status()
+	if header.Get("Content-Encoding") != "" || status == http.StatusNoContent || status == http.StatusNotModified {
+		return w.flushBuffer()
+	}
+
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	w.encoder, w.release = w.newEncoder()
+	_, err := w.encoder.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// flushBuffer 原样输出缓冲的数据
+func (w *compressWriter) flushBuffer() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// finish 请求处理完成后输出剩余数据并归还压缩器
+func (w *compressWriter) finish() {
+	if w.encoder == nil {
+		if !w.decided {
+			w.decided = true
+			w.flushBuffer()
+		}
+		return
+	}
+	w.encoder.Close()
+	w.release()
+}
+
+// newEncoder 从对应级别的池中取出压缩器，返回的release用于归还
+func (w *compressWriter) newEncoder() (io.WriteCloser, func()) {
+	if w.encoding == "br" {
+		level := w.cfg.BrotliLevel
+		pool, _ := brotliPools.LoadOrStore(level, &sync.Pool{New: func() interface{} {
+			return brotli.NewWriterLevel(nil, level)
+		}})
+		encoder := pool.(*sync.Pool).Get().(*brotli.Writer)
+		encoder.Reset(w.ResponseWriter)
+		return encoder, func() { pool.(*sync.Pool).Put(encoder) }
+	}
+
+	level := w.cfg.GzipLevel
+	pool, _ := gzipPools.LoadOrStore(level, &sync.Pool{New: func() interface{} {
+		encoder, _ := gzip.NewWriterLevel(nil, level)
+		return encoder
+	}})
+	encoder := pool.(*sync.Pool).Get().(*gzip.Writer)
+	encoder.Reset(w.ResponseWriter)
+	return encoder, func() { pool.(*sync.Pool).Put(encoder) }
+}
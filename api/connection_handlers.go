@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetConnections 获取本机当前的TCP/UDP连接及所属进程，可按 protocol（tcp、udp）、state（可重复或逗号分隔）、
+// port（本地或远端端口）、process（进程名包含）过滤，listening=true 只返回监听中的端口
+func GetConnections(c *gin.Context) {
+	filter := monitor.ConnectionFilter{
+		Protocol:  c.Query("protocol"),
+		States:    queryValues(c, "state"),
+		Process:   c.Query("process"),
+		Listening: c.Query("listening") == "true",
+	}
+	if filter.Protocol != "" && filter.Protocol != "tcp" && filter.Protocol != "udp" {
+		abortError(c, http.StatusBadRequest, tr(c, "protocol 只能是 tcp 或 udp"), nil)
+		return
+	}
+	if value := c.Query("port"); value != "" {
+		port, err := strconv.ParseUint(value, 10, 16)
+		if err != nil || port == 0 {
+			abortError(c, http.StatusBadRequest, tr(c, "port 必须在1到65535之间"), err)
+			return
+		}
+		filter.Port = uint32(port)
+	}
+
+	connections, err := monitor.GetConnections(filter)
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取网络连接失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    connections,
+	})
+}
+
+// GetListeningPorts 获取监听端口清单：端口变化检测记录的当前在监听的端口，timestamp 为开始监听的时间
+func GetListeningPorts(c *gin.Context) {
+	ports, err := monitor.GetListeningPorts()
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取监听端口失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    ports,
+	})
+}
+
+// GetPortChanges 获取监听端口变化历史，可按 port 过滤
+func GetPortChanges(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "720"))
+	if err != nil || hours <= 0 {
+		hours = 720
+	}
+
+	query := database.DB.Where("timestamp >= ?", time.Now().Add(-time.Duration(hours)*time.Hour))
+	if port := c.Query("port"); port != "" {
+		query = query.Where("port = ?", port)
+	}
+
+	var changes []models.PortChange
+	if err := query.Order("timestamp desc, id desc").Find(&changes).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取端口变化记录失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    changes,
+	})
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"server-monitor/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 错误类型，写在响应的 error 字段中，客户端按类型处理而不必解析消息文字
+const (
+	ErrBadRequest   = "bad_request"
+	ErrUnauthorized = "unauthorized"
+	ErrForbidden    = "forbidden"
+	ErrNotFound     = "not_found"
+	ErrConflict     = "conflict"
+	ErrRateLimited  = "rate_limited"
+	ErrUnavailable  = "unavailable"
+	ErrInternal     = "internal"
+)
+
+// HTTP状态码对应的错误类型
+var errorCodes = map[int]string{
+	http.StatusBadRequest:            ErrBadRequest,
+	http.StatusUnauthorized:          ErrUnauthorized,
+	http.StatusForbidden:             ErrForbidden,
+	http.StatusNotFound:              ErrNotFound,
+	http.StatusConflict:              ErrConflict,
+	http.StatusRequestEntityTooLarge: ErrBadRequest,
+	http.StatusTooManyRequests:       ErrRateLimited,
+	http.StatusServiceUnavailable:    ErrUnavailable,
+}
+
+// 请求头中的请求ID，客户端或反向代理已生成时沿用
+const requestIDHeader = "X-Request-ID"
+
+// 沿用的请求ID的格式，防止日志注入
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// APIError 接口错误：HTTP状态码、面向用户的消息（已翻译）和导致错误的内部错误
+type APIError struct {
+	Status  int
+	Message string
+	Err     error
+}
+
+func (e *APIError) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %v", e.Message, e.Err)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Code 错误类型，未列出的4xx为 bad_request，5xx为 internal
+func (e *APIError) Code() string {
+	if code, ok := errorCodes[e.Status]; ok {
+		return code
+	}
+	if e.Status < http.StatusInternalServerError {
+		return ErrBadRequest
+	}
+	return ErrInternal
+}
+
+// abortError 以统一的响应格式返回错误并中止后续处理；err 为内部错误，记录到服务端日志，
+// 开启 debug.error_detail 时同时返回给客户端
+func abortError(c *gin.Context, status int, message string, err error) {
+	apiErr := &APIError{Status: status, Message: message, Err: err}
+	c.Error(apiErr)
+	c.Abort()
+	writeError(c, apiErr)
+}
+
+// writeError 输出错误响应，响应已写出时只记录日志
+func writeError(c *gin.Context, err *APIError) {
+	if c.Writer.Written() {
+		return
+	}
+	response := Response{
+		Code:      err.Status,
+		Message:   err.Message,
+		Data:      nil,
+		Error:     err.Code(),
+		RequestID: c.GetString("request_id"),
+	}
+	if err.Err != nil && config.AppConfig.Debug.ErrorDetail {
+		response.Detail = err.Err.Error()
+	}
+	c.JSON(err.Status, response)
+}
+
+// requestID 为每个请求分配ID，写入响应头 X-Request-ID 和错误响应，服务端日志中的错误带有同一ID
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			buf := make([]byte, 8)
+			rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		c.Set("request_id", id)
+		c.Header(requestIDHeader, id)
+		c.Next()
+	}
+}
+
+// handleErrors 统一处理错误：处理器通过 c.Error 记录但未输出的错误按统一格式返回，
+// 服务器内部错误连同请求ID写入日志，不再只返回笼统的消息而丢失真实原因
+func handleErrors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 {
+			return
+		}
+
+		var apiErr *APIError
+		if !errors.As(c.Errors.Last().Err, &apiErr) {
+			apiErr = &APIError{Status: http.StatusInternalServerError, Message: tr(c, "服务器内部错误"), Err: c.Errors.Last().Err}
+		}
+		if apiErr.Status >= http.StatusInternalServerError {
+			log.Printf("[%s] %s %s: %v", c.GetString("request_id"), c.Request.Method, c.Request.URL.Path, apiErr)
+		}
+		writeError(c, apiErr)
+	}
+}
+
+// recoverPanic 处理器发生panic时返回统一格式的500错误，并记录请求ID和panic内容
+func recoverPanic() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		err := &APIError{Status: http.StatusInternalServerError, Message: tr(c, "服务器内部错误"), Err: fmt.Errorf("panic: %v", recovered)}
+		log.Printf("[%s] %s %s: %v", c.GetString("request_id"), c.Request.Method, c.Request.URL.Path, err)
+		c.Abort()
+		writeError(c, err)
+	})
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"server-monitor/config"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 记录Last-Modified的地址数上限，超过后清空重新记录
+const maxETagEntries = 1024
+
+// etagEntry 地址最近一次响应的ETag和内容变化的时间
+type etagEntry struct {
+	etag     string
+	modified time.Time
+}
+
+var (
+	etagMu      sync.Mutex
+	etagEntries = make(map[string]etagEntry)
+)
+
+// conditional 为响应计算ETag并设置Last-Modified，请求带有匹配的 If-None-Match 或 If-Modified-Since 时返回304，
+// 浏览器轮询仪表板和历史数据时内容未变化就不再重复下载；需要放在 compress 之后，ETag按压缩前的内容计算。
+// 只处理200响应，超过 server.etag.max_size 的响应不计算ETag，直接流式输出
+func conditional() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.AppConfig.Server.ETag
+		if !cfg.Enabled || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		w := &etagWriter{ResponseWriter: original, maxSize: cfg.MaxSize}
+		c.Writer = w
+		c.Next()
+		c.Writer = original
+
+		if w.streaming {
+			return
+		}
+		// 出错中断的响应不能被缓存
+		if w.Status() != http.StatusOK || len(c.Errors) > 0 {
+			original.Write(w.buf)
+			return
+		}
+
+		hash := fnv.New128a()
+		hash.Write(w.buf)
+		// 同一内容可能以不同的压缩方式返回，使用弱ETag
+		etag := fmt.Sprintf(`W/"%x"`, hash.Sum(nil))
+		modified := lastModified(c.Request.URL.RequestURI(), etag)
+
+		header := original.Header()
+		header.Set("ETag", etag)
+		header.Set("Last-Modified", modified.Format(http.TimeFormat))
+		// 允许浏览器缓存，但每次使用前都要重新验证
+		header.Set("Cache-Control", "no-cache")
+
+		if notModified(c.Request, etag, modified) {
+			header.Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			return
+		}
+		original.Write(w.buf)
+	}
+}
+
+// lastModified 地址的响应内容最近一次变化的时间（精确到秒），ETag与上次不同时为当前时间
+func lastModified(uri, etag string) time.Time {
+	etagMu.Lock()
+	defer etagMu.Unlock()
+
+	if entry, ok := etagEntries[uri]; ok && entry.etag == etag {
+		return entry.modified
+	}
+	if len(etagEntries) >= maxETagEntries {
+		etagEntries = make(map[string]etagEntry)
+	}
+	modified := time.Now().UTC().Truncate(time.Second)
+	etagEntries[uri] = etagEntry{etag: etag, modified: modified}
+	return modified
+}
+
+// notModified 按条件请求头判断内容是否未变化；同时带有两者时只按 If-None-Match 判断
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !modified.After(t)
+		}
+	}
+	return false
+}
+
+// etagWriter 缓冲响应以计算ETag，超过 max_size 后改为直接输出
+type etagWriter struct {
+	gin.ResponseWriter
+	maxSize int
+
+	buf       []byte
+	streaming bool
+}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf = append(w.buf, data...)
+	if len(w.buf) > w.maxSize {
+		w.streaming = true
+		buf := w.buf
+		w.buf = nil
+		if _, err := w.ResponseWriter.Write(buf); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 缓冲时不输出，改为直接输出后才刷新
+func (w *etagWriter) Flush() {
+	if w.streaming {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// Written 缓冲中的响应视为已写出，错误处理不再覆盖
+func (w *etagWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fail2banEnabled 未启用fail2ban集成时返回404
+func fail2banEnabled(c *gin.Context) bool {
+	if !config.AppConfig.Fail2ban.Enabled {
+		abortError(c, http.StatusNotFound, tr(c, "fail2ban集成未启用"), nil)
+		return false
+	}
+	return true
+}
+
+// GetFail2banStatus 获取各jail当前封禁的地址和失败、封禁计数
+func GetFail2banStatus(c *gin.Context) {
+	if !fail2banEnabled(c) {
+		return
+	}
+	jails, err := monitor.GetFail2banStatus()
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "读取fail2ban状态失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    jails,
+	})
+}
+
+// GetFail2banHistory 获取封禁历史，按封禁时间倒序；jail、ip 筛选，默认返回最近720小时（hours）内的封禁，active=true 只返回仍在封禁中的记录
+func GetFail2banHistory(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "720"))
+	if err != nil || hours <= 0 {
+		hours = 720
+	}
+
+	query := database.DB.Where("banned_at >= ?", time.Now().Add(-time.Duration(hours)*time.Hour))
+	if jail := c.Query("jail"); jail != "" {
+		query = query.Where("jail = ?", jail)
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	if c.Query("active") == "true" {
+		query = query.Where("unbanned_at IS NULL")
+	}
+
+	var bans []models.Fail2banBan
+	if err := query.Order("banned_at desc, id desc").Find(&bans).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取fail2ban封禁历史失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    bans,
+	})
+}
+
+// UnbanFail2ban 在指定jail中解封地址
+func UnbanFail2ban(c *gin.Context) {
+	if !fail2banEnabled(c) {
+		return
+	}
+	var req struct {
+		Jail string `json:"jail" binding:"required"`
+		IP   string `json:"ip" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
+		return
+	}
+	if net.ParseIP(req.IP) == nil {
+		abortError(c, http.StatusBadRequest, tr(c, "无效的IP地址: %s", req.IP), nil)
+		return
+	}
+
+	if err := monitor.UnbanFail2ban(req.Jail, req.IP); err != nil {
+		if errors.Is(err, monitor.ErrFail2banJailNotFound) {
+			abortError(c, http.StatusNotFound, trErr(c, err), err)
+			return
+		}
+		abortError(c, http.StatusInternalServerError, tr(c, "解封失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "已解封 %s", req.IP),
+		Data:    nil,
+	})
+}
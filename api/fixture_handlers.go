@@ -0,0 +1,151 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FixtureRequest 录制夹具的请求：指定 from、to，或以 hours 录制最近几小时
+type FixtureRequest struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Hours       int       `json:"hours"`
+}
+
+// FixtureRunRequest 运行夹具的请求，rules 为空时运行已保存的所有启用规则
+type FixtureRunRequest struct {
+	Rules []models.AlertRule `json:"rules"`
+}
+
+// FixtureRunResult 夹具运行结果
+type FixtureRunResult struct {
+	Fixture string               `json:"fixture"`
+	At      time.Time            `json:"at"`    // 规则判定的时刻，即录制结束时间
+	Fired   int                  `json:"fired"` // 触发的规则数
+	Results []monitor.RuleResult `json:"results"`
+}
+
+// GetAlertFixtures 获取告警规则夹具列表，不包含录制的数据
+func GetAlertFixtures(c *gin.Context) {
+	var fixtures []models.AlertFixture
+	if err := database.DB.Omit("data").Order("id asc").Find(&fixtures).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取夹具失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    fixtures,
+	})
+}
+
+// CreateAlertFixture 将一段时间内的指标数据录制为命名的夹具
+func CreateAlertFixture(c *gin.Context) {
+	var req FixtureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
+		return
+	}
+	if req.Hours > 0 {
+		req.To = time.Now()
+		req.From = req.To.Add(-time.Duration(req.Hours) * time.Hour)
+	}
+
+	var count int64
+	database.DB.Model(&models.AlertFixture{}).Where("name = ?", req.Name).Count(&count)
+	if count > 0 {
+		abortError(c, http.StatusBadRequest, tr(c, "夹具 %s 已存在", req.Name), nil)
+		return
+	}
+
+	fixture, err := monitor.RecordFixture(req.Name, req.Description, req.From, req.To)
+	if err != nil {
+		abortError(c, http.StatusBadRequest, trErr(c, err), err)
+		return
+	}
+	fixture.Data = ""
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "已录制夹具 %s: %d条记录", fixture.Name, fixture.Rows),
+		Data:    fixture,
+	})
+}
+
+// DeleteAlertFixture 删除夹具
+func DeleteAlertFixture(c *gin.Context) {
+	result := database.DB.Delete(&models.AlertFixture{}, c.Param("id"))
+	if result.Error != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "删除夹具失败"), result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		abortError(c, http.StatusNotFound, tr(c, "夹具不存在"), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "夹具已删除"),
+		Data:    nil,
+	})
+}
+
+// RunAlertFixture 在夹具上运行告警规则，返回每条规则是否会触发，用作告警配置的回归测试；
+// 请求体中的规则只用于本次运行，不保存
+func RunAlertFixture(c *gin.Context) {
+	var fixture models.AlertFixture
+	if err := database.DB.First(&fixture, c.Param("id")).Error; err != nil {
+		abortError(c, http.StatusNotFound, tr(c, "夹具不存在"), err)
+		return
+	}
+
+	var req FixtureRunRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
+			return
+		}
+	}
+
+	rules := req.Rules
+	if len(rules) == 0 {
+		if err := database.DB.Where("enabled = ?", true).Order("id asc").Find(&rules).Error; err != nil {
+			abortError(c, http.StatusInternalServerError, tr(c, "获取告警规则失败"), err)
+			return
+		}
+	} else {
+		for i := range rules {
+			if err := validateAlertRule(&rules[i]); err != nil {
+				abortError(c, http.StatusBadRequest, tr(c, "告警规则 %s: %v", rules[i].Name, err), err)
+				return
+			}
+		}
+	}
+
+	results, err := monitor.RunFixture(&fixture, rules)
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "运行夹具失败: %s", trErr(c, err)), err)
+		return
+	}
+
+	result := FixtureRunResult{Fixture: fixture.Name, At: fixture.To, Results: results}
+	for _, r := range results {
+		if r.Fired {
+			result.Fired++
+		}
+	}
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "%d条规则中%d条会触发", len(results), result.Fired),
+		Data:    result,
+	})
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/monitor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDiskForecast 获取各挂载点的写满预测，host 指定远程主机，默认本机
+func GetDiskForecast(c *gin.Context) {
+	forecasts, err := monitor.ForecastDisks(c.Query("host"))
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取磁盘写满预测失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    forecasts,
+	})
+}
@@ -6,20 +6,35 @@ import (
 	"server-monitor/models"
 	"server-monitor/monitor"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// Response 统一响应结构
+// Response 统一响应结构；出错时 code 为HTTP状态码，error 为错误类型，request_id 对应服务端日志中的记录
 type Response struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	Data      interface{} `json:"data"`
+	Error     string      `json:"error,omitempty"`      // 错误类型，如 not_found、internal
+	RequestID string      `json:"request_id,omitempty"` // 请求ID，与响应头 X-Request-ID 相同
+	Detail    string      `json:"detail,omitempty"`     // 内部错误详情，只在开启 debug.error_detail 时返回
 }
 
-// GetSystemMetrics 获取系统指标数据
+// GetSystemMetrics 获取系统指标数据，resolution=day 时返回每日汇总；host 为远程主机标识，为空时返回本机数据。
+// 时间范围可用 from、to（RFC3339）指定，优先于 hours、days
 func GetSystemMetrics(c *gin.Context) {
+	if c.Query("resolution") == "day" {
+		getDailySummaries(c, "system_metrics", nil)
+		return
+	}
+	rangeScope, ok := timeRangeScope(c)
+	if !ok {
+		return
+	}
+
 	// 获取查询参数
 	limitStr := c.DefaultQuery("limit", "100")
 	hoursStr := c.Query("hours")
@@ -30,10 +45,12 @@ func GetSystemMetrics(c *gin.Context) {
 		limit = 100
 	}
 
-	query := database.DB.Order("timestamp desc")
+	query := database.DB.Scopes(database.HostScope(c.Query("host"))).Order("timestamp desc")
 	
 	// 处理时间范围查询
-	if hoursStr != "" {
+	if rangeScope != nil {
+		query = query.Scopes(rangeScope)
+	} else if hoursStr != "" {
 		if hours, err := strconv.Atoi(hoursStr); err == nil {
 			startTime := time.Now().Add(-time.Duration(hours) * time.Hour)
 			query = query.Where("timestamp >= ?", startTime)
@@ -48,34 +65,16 @@ func GetSystemMetrics(c *gin.Context) {
 		query = query.Limit(limit)
 	}
 
-	var metrics []models.SystemMetrics
-	err = query.Find(&metrics).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "获取系统指标失败",
-			Data:    nil,
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, Response{
-		Code:    200,
-		Message: "success",
-		Data:    metrics,
-	})
+	// 按天查询时结果可能很大，流式输出避免整体加载到内存
+	streamJSON[models.SystemMetrics](c, query, "获取系统指标失败")
 }
 
-// GetCurrentMetrics 获取当前系统指标
+// GetCurrentMetrics 获取当前系统指标，host 为远程主机标识，为空时返回本机数据
 func GetCurrentMetrics(c *gin.Context) {
 	var metric models.SystemMetrics
-	err := database.DB.Order("timestamp desc").First(&metric).Error
+	err := database.DB.Scopes(database.HostScope(c.Query("host"))).Order("timestamp desc").First(&metric).Error
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "获取当前指标失败",
-			Data:    nil,
-		})
+		abortError(c, http.StatusInternalServerError, tr(c, "获取当前指标失败"), err)
 		return
 	}
 
@@ -86,16 +85,12 @@ func GetCurrentMetrics(c *gin.Context) {
 	})
 }
 
-// GetServiceStatus 获取服务状态
+// GetServiceStatus 获取服务状态，指定 host 时只返回该主机的服务
 func GetServiceStatus(c *gin.Context) {
 	var services []models.ServiceStatus
-	err := database.DB.Find(&services).Error
+	err := database.DB.Scopes(hostFilter(c)).Find(&services).Error
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "获取服务状态失败",
-			Data:    nil,
-		})
+		abortError(c, http.StatusInternalServerError, tr(c, "获取服务状态失败"), err)
 		return
 	}
 
@@ -106,73 +101,202 @@ func GetServiceStatus(c *gin.Context) {
 	})
 }
 
-// GetSystemLogs 获取系统日志
-func GetSystemLogs(c *gin.Context) {
-	// 获取查询参数
-	limitStr := c.DefaultQuery("limit", "50")
-	level := c.DefaultQuery("level", "")
-	category := c.DefaultQuery("category", "")
-	
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 50
+// ServiceWeightRequest 修改服务权重、关键性和是否公开的请求，未提供的字段保持不变
+type ServiceWeightRequest struct {
+	Weight   *int  `json:"weight"`
+	Critical *bool `json:"critical"`
+	Public   *bool `json:"public"`
+}
+
+// UpdateService 修改服务对主机健康度的权重、关键性，以及是否在公开状态页展示
+func UpdateService(c *gin.Context) {
+	var req ServiceWeightRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Weight != nil && (*req.Weight < 0 || *req.Weight > 100)) {
+		abortError(c, http.StatusBadRequest, tr(c, "请求参数错误，weight 必须在0到100之间"), err)
+		return
 	}
 
-	query := database.DB.Order("timestamp desc").Limit(limit)
-	
-	if level != "" {
-		query = query.Where("level = ?", level)
+	var service models.ServiceStatus
+	if err := database.DB.First(&service, c.Param("id")).Error; err != nil {
+		abortError(c, http.StatusNotFound, tr(c, "服务不存在"), err)
+		return
 	}
-	
-	if category != "" {
-		query = query.Where("category = ?", category)
+
+	if req.Weight != nil {
+		service.Weight = *req.Weight
+	}
+	if req.Critical != nil {
+		service.Critical = *req.Critical
+	}
+	if req.Public != nil {
+		service.Public = *req.Public
+	}
+	if err := database.DB.Model(&service).Select("weight", "critical", "public").Updates(&service).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "更新服务失败"), err)
+		return
 	}
 
-	var logs []models.SystemLog
-	err = query.Find(&logs).Error
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    service,
+	})
+}
+
+// GetServiceUptime 获取服务最近24小时、7天和30天的可用率和平均响应时间
+func GetServiceUptime(c *gin.Context) {
+	var service models.ServiceStatus
+	if err := database.DB.First(&service, c.Param("id")).Error; err != nil {
+		abortError(c, http.StatusNotFound, tr(c, "服务不存在"), err)
+		return
+	}
+
+	uptime, err := monitor.GetServiceUptime(service)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "获取系统日志失败",
-			Data:    nil,
-		})
+		abortError(c, http.StatusInternalServerError, tr(c, "计算服务可用率失败"), err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
-		Data:    logs,
+		Data:    uptime,
 	})
 }
 
-// GetDiskUsage 获取磁盘使用情况
-func GetDiskUsage(c *gin.Context) {
-	var diskUsages []models.DiskUsage
-	err := database.DB.Order("timestamp desc").Find(&diskUsages).Error
+// GetServiceCheckResults 获取服务的检查历史（状态、响应时间和错误），按时间升序，用于绘制响应时间曲线；
+// state_type 为 hard 时不含重试前的失败
+func GetServiceCheckResults(c *gin.Context) {
+	var service models.ServiceStatus
+	if err := database.DB.First(&service, c.Param("id")).Error; err != nil {
+		abortError(c, http.StatusNotFound, tr(c, "服务不存在"), err)
+		return
+	}
+
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "24"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+
+	query := database.DB.Where("service_id = ? AND timestamp >= ?", service.ID, time.Now().Add(-time.Duration(hours)*time.Hour))
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if stateType := c.Query("state_type"); stateType != "" {
+		query = query.Where("state_type = ?", stateType)
+	}
+	query = query.Order("timestamp asc")
+
+	streamJSON[models.ServiceCheckResult](c, query, "获取服务检查历史失败")
+}
+
+// GetServiceHealth 获取按服务权重计算的主机健康度，host 为远程主机标识，为空时计算本机
+func GetServiceHealth(c *gin.Context) {
+	health, err := monitor.GetHostHealth(c.Query("host"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "获取磁盘使用情况失败",
-			Data:    nil,
-		})
+		abortError(c, http.StatusInternalServerError, tr(c, "获取主机健康度失败"), err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
-		Data:    diskUsages,
+		Data:    health,
 	})
 }
 
-// GetAlerts 获取告警信息
+// GetSystemLogs 获取系统日志，指定 host 时只返回该主机的日志；指定 from、to 时返回时间范围内的全部日志，除非同时指定了 limit。
+// q 按关键词搜索消息，level、category 可指定多个值（重复参数或逗号分隔）
+func GetSystemLogs(c *gin.Context) {
+	rangeScope, ok := timeRangeScope(c)
+	if !ok {
+		return
+	}
+
+	// 获取查询参数
+	limitStr := c.DefaultQuery("limit", "50")
+	levels := queryValues(c, "level")
+	categories := queryValues(c, "category")
+	
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		limit = 50
+	}
+
+	query := database.DB.Scopes(hostFilter(c)).Order("timestamp desc")
+	if rangeScope != nil {
+		query = query.Scopes(rangeScope)
+	}
+	if rangeScope == nil || c.Query("limit") != "" {
+		query = query.Limit(limit)
+	}
+	
+	if len(levels) > 0 {
+		query = query.Where("level IN ?", levels)
+	}
+	
+	if len(categories) > 0 {
+		query = query.Where("category IN ?", categories)
+	}
+
+	// 空格分隔的每个关键词都须出现在消息中；LIKE 对英文字母不区分大小写
+	for _, word := range strings.Fields(c.Query("q")) {
+		query = query.Where("message LIKE ? ESCAPE '\\'", "%"+escapeLike(word)+"%")
+	}
+
+	streamJSON[models.SystemLog](c, query, "获取系统日志失败")
+}
+
+// queryValues 查询参数的所有值，支持重复参数（level=error&level=warning）和逗号分隔（level=error,warning）
+func queryValues(c *gin.Context, key string) []string {
+	var values []string
+	for _, param := range c.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// escapeLike 转义LIKE模式中的通配符，配合 ESCAPE '\' 使用，使 % 和 _ 按原字符匹配
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+// GetDiskUsage 获取磁盘使用情况，resolution=day 时返回每日汇总，from、to 指定时间范围
+func GetDiskUsage(c *gin.Context) {
+	if c.Query("resolution") == "day" {
+		getDailySummaries(c, "disk_usage", map[string]string{"path": c.Query("path")})
+		return
+	}
+	rangeScope, ok := timeRangeScope(c)
+	if !ok {
+		return
+	}
+	query := database.DB.Scopes(database.HostScope(c.Query("host"))).Order("timestamp desc")
+	if rangeScope != nil {
+		query = query.Scopes(rangeScope)
+	}
+	streamJSON[models.DiskUsage](c, query, "获取磁盘使用情况失败")
+}
+
+// GetAlerts 获取告警信息，指定 host 时只返回该主机的告警，from、to 按告警时间筛选
 func GetAlerts(c *gin.Context) {
+	rangeScope, ok := timeRangeScope(c)
+	if !ok {
+		return
+	}
+
 	// 获取查询参数
 	status := c.DefaultQuery("status", "")
 	level := c.DefaultQuery("level", "")
 	
-	query := database.DB.Order("timestamp desc")
+	query := database.DB.Scopes(hostFilter(c)).Order("timestamp desc")
+	if rangeScope != nil {
+		query = query.Scopes(rangeScope)
+	}
 	
 	if status != "" {
 		query = query.Where("status = ?", status)
@@ -182,26 +306,44 @@ func GetAlerts(c *gin.Context) {
 		query = query.Where("level = ?", level)
 	}
 
-	var alerts []models.Alert
-	err := query.Find(&alerts).Error
+	streamJSON[models.Alert](c, query, "获取告警信息失败")
+}
+
+// GetAlertStats 获取最近days天（默认7天）的告警统计：按类型/级别/日期计数、平均解决时间和最频繁的告警类型；
+// 指定 host 时只统计该主机的告警
+func GetAlertStats(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days <= 0 {
+		days = 7
+	}
+	if days > 366 {
+		days = 366
+	}
+
+	stats, err := monitor.GetAlertStats(days, hostFilter(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "获取告警信息失败",
-			Data:    nil,
-		})
+		abortError(c, http.StatusInternalServerError, tr(c, "获取告警统计失败"), err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "success",
-		Data:    alerts,
+		Data:    stats,
 	})
 }
 
-// GetNetworkTraffic 获取网络流量数据
+// GetNetworkTraffic 获取网络流量数据，resolution=day 时返回每日汇总；指定 from、to 时返回时间范围内的全部数据，除非同时指定了 limit
 func GetNetworkTraffic(c *gin.Context) {
+	if c.Query("resolution") == "day" {
+		getDailySummaries(c, "network_traffic", map[string]string{"interface": c.Query("interface")})
+		return
+	}
+	rangeScope, ok := timeRangeScope(c)
+	if !ok {
+		return
+	}
+
 	// 获取查询参数
 	limitStr := c.DefaultQuery("limit", "100")
 	interfaceName := c.DefaultQuery("interface", "")
@@ -211,52 +353,45 @@ func GetNetworkTraffic(c *gin.Context) {
 		limit = 100
 	}
 
-	query := database.DB.Order("timestamp desc").Limit(limit)
+	query := database.DB.Scopes(database.HostScope(c.Query("host"))).Order("timestamp desc")
+	if rangeScope != nil {
+		query = query.Scopes(rangeScope)
+	}
+	if rangeScope == nil || c.Query("limit") != "" {
+		query = query.Limit(limit)
+	}
 	
 	if interfaceName != "" {
 		query = query.Where("interface = ?", interfaceName)
 	}
 
-	var traffic []models.NetworkTraffic
-	err = query.Find(&traffic).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "获取网络流量数据失败",
-			Data:    nil,
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, Response{
-		Code:    200,
-		Message: "success",
-		Data:    traffic,
-	})
+	streamJSON[models.NetworkTraffic](c, query, "获取网络流量数据失败")
 }
 
-// GetDashboardData 获取仪表板数据
+// GetDashboardData 获取仪表板数据，host 为远程主机标识，为空时返回本机的仪表板
 func GetDashboardData(c *gin.Context) {
+	host := database.HostScope(c.Query("host"))
+
 	// 获取当前系统指标
 	var currentMetric models.SystemMetrics
-	database.DB.Order("timestamp desc").First(&currentMetric)
+	database.DB.Scopes(host).Order("timestamp desc").First(&currentMetric)
 
 	// 获取服务状态
 	var services []models.ServiceStatus
-	database.DB.Find(&services)
+	database.DB.Scopes(host).Find(&services)
 
 	// 获取最近的系统日志
 	var recentLogs []models.SystemLog
-	database.DB.Order("timestamp desc").Limit(10).Find(&recentLogs)
+	database.DB.Scopes(host).Order("timestamp desc").Limit(10).Find(&recentLogs)
 
 	// 获取活跃告警
 	var activeAlerts []models.Alert
-	database.DB.Where("status = ?", "active").Order("timestamp desc").Limit(10).Find(&activeAlerts)
+	database.DB.Scopes(host).Where("status = ?", "active").Order("timestamp desc").Limit(10).Find(&activeAlerts)
 
 	// 获取历史数据（最近24小时，每小时一个数据点）
 	var historicalData []models.SystemMetrics
 	startTime := time.Now().Add(-24 * time.Hour)
-	database.DB.Where("timestamp >= ?", startTime).Order("timestamp asc").Find(&historicalData)
+	database.DB.Scopes(host).Where("timestamp >= ?", startTime).Order("timestamp asc").Find(&historicalData)
 
 	dashboardData := map[string]interface{}{
 		"current_metrics":   currentMetric,
@@ -264,6 +399,13 @@ func GetDashboardData(c *gin.Context) {
 		"recent_logs":       recentLogs,
 		"active_alerts":     activeAlerts,
 		"historical_data":   historicalData,
+		"health":            monitor.ComputeHealth(services),
+	}
+
+	// 软件包更新只检查本机
+	if c.Query("host") == "" {
+		updates, _ := monitor.GetLatestUpdateCheck()
+		dashboardData["updates"] = updates
 	}
 
 	c.JSON(http.StatusOK, Response{
@@ -275,35 +417,21 @@ func GetDashboardData(c *gin.Context) {
 
 // ResolveAlert 解决告警
 func ResolveAlert(c *gin.Context) {
-	alertID := c.Param("id")
-	
-	var alert models.Alert
-	err := database.DB.First(&alert, alertID).Error
+	alertID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusNotFound, Response{
-			Code:    404,
-			Message: "告警不存在",
-			Data:    nil,
-		})
+		respondAlertError(c, gorm.ErrRecordNotFound)
 		return
 	}
 
-	alert.Status = "resolved"
-	alert.UpdatedAt = time.Now()
-	
-	err = database.DB.Save(&alert).Error
+	alert, err := monitor.ResolveAlertByID(uint(alertID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "更新告警状态失败",
-			Data:    nil,
-		})
+		respondAlertError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "告警已解决",
+		Message: tr(c, "告警已解决"),
 		Data:    alert,
 	})
 }
@@ -312,11 +440,7 @@ func ResolveAlert(c *gin.Context) {
 func AddSystemLog(c *gin.Context) {
 	var log models.SystemLog
 	if err := c.ShouldBindJSON(&log); err != nil {
-		c.JSON(http.StatusBadRequest, Response{
-			Code:    400,
-			Message: "请求参数错误",
-			Data:    nil,
-		})
+		abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
 		return
 	}
 
@@ -324,30 +448,172 @@ func AddSystemLog(c *gin.Context) {
 	
 	err := database.DB.Create(&log).Error
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "添加系统日志失败",
-			Data:    nil,
-		})
+		abortError(c, http.StatusInternalServerError, tr(c, "添加系统日志失败"), err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "日志添加成功",
+		Message: tr(c, "日志添加成功"),
 		Data:    log,
 	})
 }
 
+// GetIPReputation 获取最近一次公网IP黑名单检查结果
+func GetIPReputation(c *gin.Context) {
+	var latest models.IPReputation
+	err := database.DB.Order("timestamp desc").First(&latest).Error
+	if err != nil {
+		c.JSON(http.StatusOK, Response{
+			Code:    200,
+			Message: "success",
+			Data:    []models.IPReputation{},
+		})
+		return
+	}
+
+	var results []models.IPReputation
+	err = database.DB.Where("timestamp = ?", latest.Timestamp).Order("blocklist asc").Find(&results).Error
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取IP信誉信息失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    results,
+	})
+}
+
+// GetAddresses 获取各网卡及公网IP最近一次记录的地址
+func GetAddresses(c *gin.Context) {
+	addresses, err := monitor.GetCurrentAddresses()
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取IP地址失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    addresses,
+	})
+}
+
+// GetHostInfo 获取主机标识：主机名、公网IPv4/IPv6及其地理位置和各网卡地址
+func GetHostInfo(c *gin.Context) {
+	info, err := monitor.GetHostInfo()
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取主机信息失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    info,
+	})
+}
+
+// GetAddressChanges 获取IP地址变化历史
+func GetAddressChanges(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "720"))
+	if err != nil || hours <= 0 {
+		hours = 720
+	}
+
+	query := database.DB.Where("timestamp >= ?", time.Now().Add(-time.Duration(hours)*time.Hour))
+	if iface := c.Query("interface"); iface != "" {
+		query = query.Where("interface = ?", iface)
+	}
+
+	var changes []models.IPAddressChange
+	if err := query.Order("timestamp desc").Find(&changes).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取IP地址变化记录失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    changes,
+	})
+}
+
+// GetCertificates 获取已监控的TLS证书列表
+func GetCertificates(c *gin.Context) {
+	var certs []models.Certificate
+	err := database.DB.Order("not_after asc").Find(&certs).Error
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取证书列表失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    certs,
+	})
+}
+
+// AddCertificate 手动登记需要监控的证书（host:port 或证书文件路径）
+func AddCertificate(c *gin.Context) {
+	var req struct {
+		Endpoint string `json:"endpoint" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
+		return
+	}
+
+	cert, err := monitor.NewCertificateMonitor().Register(req.Endpoint)
+	if err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "登记证书失败: %s", trErr(c, err)), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "证书已登记"),
+		Data:    cert,
+	})
+}
+
+// GetAnnotations 获取图表注释（如调度中断导致的数据空洞）
+func GetAnnotations(c *gin.Context) {
+	hoursStr := c.DefaultQuery("hours", "24")
+	hours, err := strconv.Atoi(hoursStr)
+	if err != nil {
+		hours = 24
+	}
+
+	query := database.DB.Order("start_time asc").
+		Where("end_time >= ?", time.Now().Add(-time.Duration(hours)*time.Hour))
+
+	if annotationType := c.Query("type"); annotationType != "" {
+		query = query.Where("type = ?", annotationType)
+	}
+
+	var annotations []models.Annotation
+	err = query.Find(&annotations).Error
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取注释失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    annotations,
+	})
+}
+
 // GetHardwareInfo 获取硬件信息
 func GetHardwareInfoHandler(c *gin.Context) {
 	info, err := monitor.GetHardwareInfo()
 	if err != nil {
-		c.JSON(500, Response{
-			Code: 500,
-			Message: "获取硬件信息失败",
-			Data: nil,
-		})
+		abortError(c, http.StatusInternalServerError, tr(c, "获取硬件信息失败"), err)
 		return
 	}
 	c.JSON(200, Response{
@@ -357,6 +623,30 @@ func GetHardwareInfoHandler(c *gin.Context) {
 	})
 }
 
+// GetResourceGroups 获取按容器、systemd服务汇总的CPU和内存占用，sort=cpu时采样1秒
+func GetResourceGroups(c *gin.Context) {
+	sortBy := c.DefaultQuery("sort", "cpu")
+	if sortBy != "cpu" && sortBy != "memory" {
+		abortError(c, http.StatusBadRequest, tr(c, "sort 只能是 cpu 或 memory"), nil)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 0 {
+		limit = 10
+	}
+
+	groups, err := monitor.GetResourceGroups(sortBy, limit)
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取资源占用失败"), err)
+		return
+	}
+	c.JSON(200, Response{
+		Code:    200,
+		Message: "success",
+		Data:    groups,
+	})
+}
+
 // GetCssboardData 处理 /api/v1/css 路由，返回css静态文件
 func GetCssboardData(c *gin.Context) {
 	c.File("css/remixicon.min.css")
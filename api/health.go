@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/monitor"
+	"server-monitor/scheduler"
+	"server-monitor/websocket"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 检查数据库连接的超时时间
+const healthPingTimeout = 2 * time.Second
+
+// 系统指标采集超过该倍数的采集间隔未执行时视为停滞
+const healthStaleIntervals = 3
+
+// ComponentHealth 单个组件的健康状态，status为 ok 或 degraded
+type ComponentHealth struct {
+	Status  string                 `json:"status"`
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Health 健康检查：报告数据库、调度器、WebSocket和进程的状态，任一组件异常时返回503，
+// 供外部可用性监控检查监控程序自身
+func Health(hub *websocket.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		components := map[string]ComponentHealth{
+			"database":  databaseHealth(c.Request.Context()),
+			"scheduler": schedulerHealth(),
+			"websocket": {Status: "ok", Details: map[string]interface{}{"clients": hub.ClientCount()}},
+		}
+
+		status, message, code := "ok", "Server is running", http.StatusOK
+		for _, component := range components {
+			if component.Status != "ok" {
+				status, message, code = "degraded", "Server is degraded", http.StatusServiceUnavailable
+				break
+			}
+		}
+
+		c.JSON(code, gin.H{
+			"status":     status,
+			"message":    message,
+			"uptime":     int64(monitor.Uptime().Seconds()),
+			"components": components,
+		})
+	}
+}
+
+// databaseHealth 检查数据库连接并报告数据库文件大小
+func databaseHealth(ctx context.Context) ComponentHealth {
+	health := ComponentHealth{Status: "ok", Details: map[string]interface{}{}}
+
+	sqlDB, err := database.DB.DB()
+	if err == nil {
+		ctx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+		defer cancel()
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		health.Status, health.Message = "degraded", i18n.Sprintf("数据库连接失败: %v", err)
+	}
+
+	if config.AppConfig.Minimal.Enabled {
+		health.Details["file"] = "memory"
+		return health
+	}
+	path := config.AppConfig.Database.Database
+	var size int64
+	for _, suffix := range []string{"", "-wal"} {
+		if info, err := os.Stat(path + suffix); err == nil {
+			size += info.Size()
+		}
+	}
+	health.Details["file"] = path
+	health.Details["size"] = size
+	return health
+}
+
+// schedulerHealth 检查调度器是否在运行、系统指标采集是否停滞，并列出各任务最近一次执行的情况
+func schedulerHealth() ComponentHealth {
+	running, jobs := scheduler.Status()
+	health := ComponentHealth{
+		Status:  "ok",
+		Details: map[string]interface{}{"running": running, "jobs": jobs},
+	}
+	if !running {
+		health.Status, health.Message = "degraded", i18n.Sprintf("调度器未运行")
+		return health
+	}
+
+	// 刚启动时任务可能尚未执行，以启动时间为准
+	last := time.Now().Add(-monitor.Uptime())
+	if run, ok := jobs["system_metrics"]; ok {
+		last = run.LastRun
+	}
+	stale := time.Duration(config.AppConfig.Monitor.Interval*healthStaleIntervals) * time.Second
+	if since := time.Since(last); since > stale {
+		health.Status, health.Message = "degraded", i18n.Sprintf("系统指标采集已停滞 %v", since.Round(time.Second))
+	}
+	return health
+}
+
+// Healthz 存活检查：进程能处理请求即返回200，不检查依赖，供容器编排判断是否需要重启
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz 就绪检查：配置已加载、数据库已完成迁移且可连接、调度器已启动时返回200，否则返回503，
+// 避免容器编排把流量转发给尚未初始化完成或正在关闭的实例
+func Readyz(c *gin.Context) {
+	checks := map[string]string{
+		"config":    "ok",
+		"database":  "ok",
+		"scheduler": "ok",
+	}
+	if !config.Loaded() {
+		checks["config"] = tr(c, "配置未加载")
+	}
+	if !database.Migrated() {
+		checks["database"] = tr(c, "数据库未完成迁移")
+	} else if sqlDB, err := database.DB.DB(); err != nil {
+		checks["database"] = tr(c, "数据库连接失败: %v", err)
+	} else {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), healthPingTimeout)
+		defer cancel()
+		if err := sqlDB.PingContext(ctx); err != nil {
+			checks["database"] = tr(c, "数据库连接失败: %v", err)
+		}
+	}
+	if running, _ := scheduler.Status(); !running {
+		checks["scheduler"] = tr(c, "调度器未运行")
+	}
+
+	status, code := "ok", http.StatusOK
+	for _, check := range checks {
+		if check != "ok" {
+			status, code = "unavailable", http.StatusServiceUnavailable
+			break
+		}
+	}
+	c.JSON(code, gin.H{"status": status, "checks": checks})
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"server-monitor/config"
+	"server-monitor/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// locale 请求使用的语言：Accept-Language 中支持的语言优先，没有时使用 i18n.locale
+func locale(c *gin.Context) string {
+	if l := i18n.Match(c.GetHeader("Accept-Language")); l != "" {
+		return l
+	}
+	return config.AppConfig.I18n.Locale
+}
+
+// tr 将API消息翻译为请求使用的语言
+func tr(c *gin.Context, msgid string, args ...interface{}) string {
+	return i18n.T(locale(c), msgid, args...)
+}
+
+// trErr 将错误消息翻译为请求使用的语言，i18n.Errorf 创建的错误按参数重新翻译
+func trErr(c *gin.Context, err error) string {
+	if m, ok := err.(*i18n.Message); ok {
+		return m.In(locale(c))
+	}
+	return tr(c, err.Error())
+}
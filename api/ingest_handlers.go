@@ -0,0 +1,103 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+	"server-monitor/config"
+	"server-monitor/monitor"
+	"server-monitor/websocket"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 外部告警消息体的大小上限
+const maxIngestBody = 1 << 20
+
+// ingestAuth 告警和指标接入的访问控制：未启用 ingest.enabled 时返回404，配置了 ingest.token 时校验
+// Authorization: Bearer <token> 或 ?token= 参数；每次请求读取当前配置，重新加载配置后立即生效
+func ingestAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ingest := config.AppConfig.Ingest
+		if !ingest.Enabled {
+			abortError(c, http.StatusNotFound, tr(c, "数据接入未启用"), nil)
+			return
+		}
+
+		if ingest.Token != "" {
+			token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if token == "" {
+				token = c.Query("token")
+			}
+			if subtle.ConstantTimeCompare([]byte(token), []byte(ingest.Token)) != 1 {
+				abortError(c, http.StatusUnauthorized, tr(c, "接入令牌无效"), nil)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// IngestAlerts 接收Alertmanager、Grafana、Uptime Kuma的Webhook，转换为告警并写入告警表
+func IngestAlerts(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIngestBody))
+	if err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "读取请求失败"), err)
+		return
+	}
+
+	alerts, err := monitor.ParseExternalAlerts(body)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, monitor.ErrUnknownAlertFormat) {
+			status = http.StatusUnprocessableEntity
+		}
+		abortError(c, status, trErr(c, err), err)
+		return
+	}
+
+	fired, resolved := monitor.IngestExternalAlerts(alerts)
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data: gin.H{
+			"fired":    fired,
+			"resolved": resolved,
+			"alerts":   alerts,
+		},
+	})
+}
+
+// IngestMetrics 接收远程主机推送的系统指标、磁盘和网卡数据，按主机的 server_id 区分保存，
+// 系统指标同时推送给订阅了该主机的WebSocket客户端
+func IngestMetrics(hub *websocket.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var payload monitor.RemoteMetrics
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
+			return
+		}
+		if err := payload.Validate(); err != nil {
+			abortError(c, http.StatusBadRequest, trErr(c, err), err)
+			return
+		}
+
+		rows, err := monitor.SaveRemoteMetrics(&payload)
+		if err != nil {
+			abortError(c, http.StatusInternalServerError, tr(c, "保存指标失败"), err)
+			return
+		}
+		// 补发的历史数据不作为主机的当前状态推送
+		if payload.Realtime() {
+			hub.BroadcastSystemMetrics(payload.Metrics)
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Code:    200,
+			Message: "success",
+			Data:    gin.H{"host": payload.Host, "rows": rows, "late": payload.Late(), "dropped": payload.Dropped()},
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobSummary 定时任务执行情况汇总
+type JobSummary struct {
+	Job         string         `json:"job"`
+	Runs        int64          `json:"runs"`         // 统计时间范围内的执行次数
+	Failures    int64          `json:"failures"`     // 统计时间范围内的失败次数
+	AvgDuration float64        `json:"avg_duration"` // 平均耗时（毫秒）
+	LastRun     *models.JobRun `json:"last_run"`
+	LastFailure *models.JobRun `json:"last_failure"`
+}
+
+// GetJobSummary 获取各定时任务的执行汇总，用于排查图表数据空洞是否由采集失败引起
+func GetJobSummary(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "24"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+
+	var stats []struct {
+		Job         string
+		Runs        int64
+		Failures    int64
+		AvgDuration float64
+	}
+	err = database.DB.Model(&models.JobRun{}).
+		Select("job, COUNT(*) AS runs, SUM(CASE WHEN success THEN 0 ELSE 1 END) AS failures, AVG(duration) AS avg_duration").
+		Where("started_at >= ?", since).
+		Group("job").
+		Order("job asc").
+		Scan(&stats).Error
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取任务执行汇总失败"), err)
+		return
+	}
+
+	summaries := make([]JobSummary, 0, len(stats))
+	for _, stat := range stats {
+		summary := JobSummary{
+			Job:         stat.Job,
+			Runs:        stat.Runs,
+			Failures:    stat.Failures,
+			AvgDuration: stat.AvgDuration,
+		}
+
+		var lastRun models.JobRun
+		if database.DB.Where("job = ?", stat.Job).Order("started_at desc").First(&lastRun).Error == nil {
+			summary.LastRun = &lastRun
+		}
+		var lastFailure models.JobRun
+		if database.DB.Where("job = ? AND success = ?", stat.Job, false).Order("started_at desc").First(&lastFailure).Error == nil {
+			summary.LastFailure = &lastFailure
+		}
+		summaries = append(summaries, summary)
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    summaries,
+	})
+}
+
+// GetJobRuns 获取定时任务执行记录，支持按任务名、执行结果和时间范围过滤
+func GetJobRuns(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		limit = 100
+	}
+
+	query := database.DB.Order("started_at desc").Limit(limit)
+	if job := c.Query("job"); job != "" {
+		query = query.Where("job = ?", job)
+	}
+	switch c.Query("status") {
+	case "success":
+		query = query.Where("success = ?", true)
+	case "failed":
+		query = query.Where("success = ?", false)
+	}
+	if hours, err := strconv.Atoi(c.Query("hours")); err == nil && hours > 0 {
+		query = query.Where("started_at >= ?", time.Now().Add(-time.Duration(hours)*time.Hour))
+	}
+
+	streamJSON[models.JobRun](c, query, "获取任务执行记录失败")
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http/pprof"
+	"server-monitor/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pprofRoutes 注册 net/http/pprof 的各个性能分析接口
+func pprofRoutes(g *gin.RouterGroup) {
+	g.GET("/", gin.WrapF(pprof.Index))
+	g.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	g.GET("/profile", gin.WrapF(pprof.Profile))
+	g.POST("/symbol", gin.WrapF(pprof.Symbol))
+	g.GET("/symbol", gin.WrapF(pprof.Symbol))
+	g.GET("/trace", gin.WrapF(pprof.Trace))
+	for _, name := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		g.GET("/"+name, gin.WrapH(pprof.Handler(name)))
+	}
+}
+
+// debugAuth 诊断接口的访问控制：未启用 debug.pprof 时返回404，启用时要求 debug 中配置的管理员认证
+func debugAuth() gin.HandlerFunc {
+	return basicAuth(
+		func() bool { return config.AppConfig.Debug.Pprof },
+		func() (string, string) { return config.AppConfig.Debug.Username, config.AppConfig.Debug.Password },
+		"server-monitor debug", "诊断接口未启用")
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// querySource 查询构建器允许访问的数据表及字段白名单
+type querySource struct {
+	table   string
+	columns map[string]bool // 可聚合的数值字段
+	filters map[string]bool // 可过滤/分组的维度字段
+}
+
+var querySources = map[string]querySource{
+	"system_metrics": {
+		table:   "system_metrics",
+		columns: map[string]bool{"cpu": true, "cpu_user": true, "cpu_system": true, "cpu_iowait": true, "cpu_steal": true, "memory": true, "mem_total": true, "mem_available": true, "mem_used": true, "mem_cached": true, "mem_buffers": true, "disk": true, "upload": true, "download": true},
+		filters: map[string]bool{"server_id": true},
+	},
+	"network_traffic": {
+		table:   "network_traffics",
+		columns: map[string]bool{"upload": true, "download": true, "upload_speed": true, "download_speed": true},
+		filters: map[string]bool{"server_id": true, "interface": true},
+	},
+	"disk_usage": {
+		table:   "disk_usages",
+		columns: map[string]bool{"total": true, "used": true, "free": true, "usage": true},
+		filters: map[string]bool{"server_id": true, "path": true, "name": true},
+	},
+}
+
+var queryAggregates = map[string]string{
+	"avg":   "AVG",
+	"min":   "MIN",
+	"max":   "MAX",
+	"sum":   "SUM",
+	"count": "COUNT",
+}
+
+// 单次查询最多返回的数据点
+const maxQueryPoints = 10000
+
+// QueryRequest 查询构建器请求
+type QueryRequest struct {
+	Source    string            `json:"source" binding:"required"` // 数据源: system_metrics, network_traffic, disk_usage
+	Select    []string          `json:"select" binding:"required"` // 聚合的字段
+	Aggregate string            `json:"aggregate"`                 // 聚合函数: avg, min, max, sum, count
+	Bucket    string            `json:"bucket"`                    // 时间分桶，如 1m、5m、1h
+	From      time.Time         `json:"from"`                      // 起始时间（RFC3339）
+	To        time.Time         `json:"to"`                        // 结束时间（RFC3339）
+	Filters   map[string]string `json:"filters"`                   // 维度过滤，如 {"interface": "eth0"}，未按 server_id 过滤或分组时只查询本机数据
+	GroupBy   string            `json:"group_by"`                  // 额外的分组维度，如 interface
+}
+
+// compileQuery 将查询请求编译为参数化SQL，字段名只能来自白名单
+func compileQuery(req *QueryRequest) (string, []interface{}, error) {
+	source, ok := querySources[req.Source]
+	if !ok {
+		return "", nil, i18n.Errorf("不支持的数据源: %s", req.Source)
+	}
+
+	if req.Aggregate == "" {
+		req.Aggregate = "avg"
+	}
+	aggregate, ok := queryAggregates[req.Aggregate]
+	if !ok {
+		return "", nil, i18n.Errorf("不支持的聚合函数: %s", req.Aggregate)
+	}
+
+	if req.Bucket == "" {
+		req.Bucket = "5m"
+	}
+	bucket, err := time.ParseDuration(req.Bucket)
+	if err != nil || bucket < time.Second {
+		return "", nil, i18n.Errorf("无效的时间分桶: %s", req.Bucket)
+	}
+
+	if req.To.IsZero() {
+		req.To = time.Now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-24 * time.Hour)
+	}
+	if !req.From.Before(req.To) {
+		return "", nil, i18n.Errorf("起始时间必须早于结束时间")
+	}
+	if req.To.Sub(req.From)/bucket > maxQueryPoints {
+		return "", nil, i18n.Errorf("时间范围内的分桶数超过上限 %d，请增大分桶", maxQueryPoints)
+	}
+
+	if len(req.Select) == 0 {
+		return "", nil, i18n.Errorf("至少选择一个字段")
+	}
+	bucketSeconds := int64(bucket.Seconds())
+	selects := []string{"(CAST(strftime('%s', timestamp) AS INTEGER) / ?) * ? AS bucket"}
+	args := []interface{}{bucketSeconds, bucketSeconds}
+	for _, column := range req.Select {
+		if !source.columns[column] {
+			return "", nil, i18n.Errorf("不支持的字段: %s", column)
+		}
+		selects = append(selects, fmt.Sprintf("%s(%s) AS %s", aggregate, column, column))
+	}
+
+	groupBy := "bucket"
+	if req.GroupBy != "" {
+		if !source.filters[req.GroupBy] {
+			return "", nil, i18n.Errorf("不支持的分组维度: %s", req.GroupBy)
+		}
+		selects = append(selects, req.GroupBy)
+		groupBy += ", " + req.GroupBy
+	}
+
+	conditions := []string{"timestamp >= ?", "timestamp <= ?"}
+	args = append(args, req.From, req.To)
+	for column, value := range req.Filters {
+		if !source.filters[column] {
+			return "", nil, i18n.Errorf("不支持的过滤字段: %s", column)
+		}
+		conditions = append(conditions, column+" = ?")
+		args = append(args, value)
+	}
+	if _, ok := req.Filters["server_id"]; !ok && req.GroupBy != "server_id" {
+		conditions = append(conditions, "server_id = 0")
+	}
+
+	sql := fmt.Sprintf("SELECT %s FROM %s WHERE %s GROUP BY %s ORDER BY %s LIMIT %d",
+		strings.Join(selects, ", "), source.table, strings.Join(conditions, " AND "), groupBy, groupBy, maxQueryPoints)
+	return sql, args, nil
+}
+
+// runQuery 执行编译后的查询，每行转换为 字段名 -> 值，分桶起始时间输出为time字段
+func runQuery(sql string, args []interface{}) ([]map[string]interface{}, error) {
+	rows, err := database.DB.Raw(sql, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	points := []map[string]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		point := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if column == "bucket" {
+				if bucket, ok := values[i].(int64); ok {
+					point["time"] = time.Unix(bucket, 0)
+				}
+				continue
+			}
+			point[column] = values[i]
+		}
+		points = append(points, point)
+	}
+
+	return points, rows.Err()
+}
+
+// QueryMetrics 受限的即席聚合查询：选择指标字段、按时间分桶聚合并按时间/维度过滤
+func QueryMetrics(c *gin.Context) {
+	var req QueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
+		return
+	}
+
+	sql, args, err := compileQuery(&req)
+	if err != nil {
+		abortError(c, http.StatusBadRequest, trErr(c, err), err)
+		return
+	}
+
+	points, err := runQuery(sql, args)
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "查询失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data: gin.H{
+			"source":    req.Source,
+			"aggregate": req.Aggregate,
+			"bucket":    req.Bucket,
+			"from":      req.From,
+			"to":        req.To,
+			"points":    points,
+		},
+	})
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRAID 获取最近一次检查时各mdadm阵列的状态，没有阵列时为空列表
+func GetRAID(c *gin.Context) {
+	arrays, err := monitor.GetLatestRAIDStatuses()
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取RAID阵列状态失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    arrays,
+	})
+}
+
+// GetRAIDHistory 获取最近 hours 小时（默认24）的阵列状态，可按 device 过滤，按时间正序
+func GetRAIDHistory(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "24"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+
+	query := database.DB.Where("timestamp >= ?", time.Now().Add(-time.Duration(hours)*time.Hour))
+	if device := c.Query("device"); device != "" {
+		query = query.Where("device = ?", device)
+	}
+
+	var arrays []models.RAIDStatus
+	if err := query.Order("timestamp asc, device asc").Find(&arrays).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取RAID阵列状态失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    arrays,
+	})
+}
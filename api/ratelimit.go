@@ -0,0 +1,113 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"server-monitor/config"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// 超过该时间没有请求的客户端令牌桶会被回收
+const rateLimitIdle = 10 * time.Minute
+
+var (
+	apiLimiter       = newIPLimiter()
+	websocketLimiter = newIPLimiter()
+)
+
+// ipLimiter 按客户端IP分别维护令牌桶
+type ipLimiter struct {
+	mu        sync.Mutex
+	limit     rate.Limit
+	burst     int
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newIPLimiter() *ipLimiter {
+	return &ipLimiter{clients: make(map[string]*clientLimiter)}
+}
+
+// allow 消耗客户端的一个令牌，返回剩余令牌数；被限流时返回需要等待的时间
+func (l *ipLimiter) allow(ip string, limit rate.Limit, burst int) (bool, int, time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 配置重新加载后按新的速率重建令牌桶
+	if limit != l.limit || burst != l.burst {
+		l.limit, l.burst = limit, burst
+		l.clients = make(map[string]*clientLimiter)
+	}
+	if now.Sub(l.lastSweep) > time.Minute {
+		for key, client := range l.clients {
+			if now.Sub(client.lastSeen) > rateLimitIdle {
+				delete(l.clients, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	client, ok := l.clients[ip]
+	if !ok {
+		client = &clientLimiter{limiter: rate.NewLimiter(limit, burst)}
+		l.clients[ip] = client
+	}
+	client.lastSeen = now
+
+	reservation := client.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, 0, delay
+	}
+	return true, int(client.limiter.TokensAt(now)), 0
+}
+
+// rateLimit API按客户端IP限流，超出时返回429
+func rateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.AppConfig.RateLimit
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+		limited(c, apiLimiter, rate.Limit(cfg.RequestsPerSecond), cfg.Burst)
+	}
+}
+
+// LimitWebSocket WebSocket连接按客户端IP限流，防止客户端反复重连
+func LimitWebSocket() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.AppConfig.RateLimit
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+		limited(c, websocketLimiter, rate.Limit(float64(cfg.WebSocketPerMinute)/60), cfg.WebSocketBurst)
+	}
+}
+
+// limited 检查令牌桶并设置限流响应头
+func limited(c *gin.Context, limiter *ipLimiter, limit rate.Limit, burst int) {
+	ok, remaining, retryAfter := limiter.allow(c.ClientIP(), limit, burst)
+	c.Header("X-RateLimit-Limit", strconv.Itoa(burst))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	if ok {
+		c.Next()
+		return
+	}
+
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	abortError(c, http.StatusTooManyRequests, tr(c, "请求过于频繁，请稍后再试"), nil)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"server-monitor/config"
+	"server-monitor/monitor"
+	"server-monitor/notify"
+	"server-monitor/report"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// buildDigest 按 period 参数（默认 reports.period）生成截止到当前的摘要报告，参数无效时返回400
+func buildDigest(c *gin.Context) (*monitor.Report, bool) {
+	period := c.DefaultQuery("period", config.AppConfig.Reports.Period)
+	if !monitor.ValidReportPeriod(period) {
+		abortError(c, http.StatusBadRequest, tr(c, "period 无效，可选 daily、weekly"), nil)
+		return nil, false
+	}
+
+	report, err := monitor.BuildReport(period, time.Now())
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "生成摘要报告失败: %s", trErr(c, err)), err)
+		return nil, false
+	}
+	return report, true
+}
+
+// GetDigest 预览摘要报告：返回报告数据和按模板渲染后的文本，不发送
+func GetDigest(c *gin.Context) {
+	report, ok := buildDigest(c)
+	if !ok {
+		return
+	}
+
+	text, err := notify.RenderReport(report)
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, trErr(c, err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    gin.H{"report": report, "text": text},
+	})
+}
+
+// SendDigest 立即生成摘要报告并发送到订阅了 report 事件的Webhook
+func SendDigest(c *gin.Context) {
+	report, ok := buildDigest(c)
+	if !ok {
+		return
+	}
+
+	sent, err := notify.SendReport(report)
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "发送摘要报告失败: %s", trErr(c, err)), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    gin.H{"sent": sent},
+	})
+}
+
+// GetReport 生成独立的HTML或PDF报告（含趋势图），range 为时间范围（默认30d），format 为 html（默认）或 pdf
+func GetReport(c *gin.Context) {
+	format := c.DefaultQuery("format", "html")
+	if format != "html" && format != "pdf" {
+		abortError(c, http.StatusBadRequest, tr(c, "format 无效，可选 html、pdf"), nil)
+		return
+	}
+	rangeValue := c.DefaultQuery("range", "30d")
+	duration, err := report.ParseRange(rangeValue)
+	if err != nil {
+		abortError(c, http.StatusBadRequest, trErr(c, err), err)
+		return
+	}
+
+	doc, err := report.Generate(rangeValue, duration)
+	var data []byte
+	if err == nil {
+		if format == "pdf" {
+			data, err = report.RenderPDF(doc)
+		} else {
+			data, err = report.RenderHTML(doc)
+		}
+	}
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "生成报告失败: %s", trErr(c, err)), err)
+		return
+	}
+
+	contentType := "text/html; charset=utf-8"
+	if format == "pdf" {
+		contentType = "application/pdf"
+	}
+	filename := fmt.Sprintf("report-%s-%s.%s", doc.Report.Hostname, doc.Generated.In(config.AppConfig.I18n.Location()).Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// GetCapacityReport 获取容量规划报告：按最近 days 天（默认 capacity.window_days）的每日汇总预测磁盘、内存和带宽
+// 达到上限的时间并给出扩容建议，host 指定远程主机，默认本机
+func GetCapacityReport(c *gin.Context) {
+	days := config.AppConfig.Capacity.WindowDays
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 2 || parsed > 3650 {
+			abortError(c, http.StatusBadRequest, tr(c, "days 必须是2到3650之间的整数"), err)
+			return
+		}
+		days = parsed
+	}
+
+	capacity, err := monitor.BuildCapacityReport(c.Query("host"), days)
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "生成容量规划报告失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    capacity,
+	})
+}
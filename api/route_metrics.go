@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/monitor"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeMetrics 按路由记录API请求数、错误数和延迟，用于自身指标和API告警规则；未匹配路由的请求不记录
+func routeMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		if route := c.FullPath(); route != "" {
+			monitor.RecordAPIRequest(c.Request.Method+" "+route, c.Writer.Status(), time.Since(start))
+		}
+	}
+}
+
+// GetSelfMetrics 获取监控程序自身的运行状态及各API路由最近window秒（默认300，最大3600）的请求统计
+func GetSelfMetrics(c *gin.Context) {
+	window, err := strconv.Atoi(c.DefaultQuery("window", "300"))
+	if err != nil || window < 60 || window > 3600 {
+		abortError(c, http.StatusBadRequest, tr(c, "window 必须在60到3600秒之间"), nil)
+		return
+	}
+
+	c.JSON(200, Response{
+		Code:    200,
+		Message: "success",
+		Data:    monitor.GetSelfMetrics(time.Duration(window) * time.Second),
+	})
+}
@@ -1,13 +1,29 @@
 package api
 
 import (
+	"log"
+	"net/http"
+	appconfig "server-monitor/config"
+	"server-monitor/selftest"
+	"server-monitor/websocket"
+	"strings"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes 设置路由
-func SetupRoutes() *gin.Engine {
-	r := gin.Default()
+func SetupRoutes(hub *websocket.Hub) *gin.Engine {
+	r := gin.New()
+	// 请求ID在最外层，panic和错误响应都带有请求ID
+	r.Use(gin.Logger(), requestID(), recoverPanic())
+
+	// 只采用受信任代理转发的客户端地址，避免伪造X-Forwarded-For绕过访问控制和限流
+	if err := r.SetTrustedProxies(appconfig.AppConfig.Access.TrustedProxies); err != nil {
+		log.Printf("Error setting trusted proxies: %v", err)
+	}
+	// 访问日志在访问控制之前，被拒绝的请求同样记录
+	r.Use(accessLog(), handleErrors(), restrictAll())
 
 	// 配置CORS
 	config := cors.DefaultConfig()
@@ -16,46 +32,234 @@ func SetupRoutes() *gin.Engine {
 	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
 	r.Use(cors.New(config))
 
-	// API路由组
-	api := r.Group("/api/v1")
+	// API路由组，按路由统计请求延迟，按客户端IP限流并记录修改操作的审计日志，修改操作只允许受信任网段访问；
+	// 历史数据等大响应的接口按需压缩
+	api := r.Group("/api/v1", routeMetrics(), rateLimit(), audit(), restrictWrites())
 	{
 		// 系统指标相关
-		api.GET("/metrics", GetSystemMetrics)
+		api.GET("/metrics", compress(), conditional(), GetSystemMetrics)
 		api.GET("/metrics/current", GetCurrentMetrics)
 		
+		// 已登记的远程主机
+		api.GET("/servers", GetServers)
+		api.DELETE("/servers/:id", DeleteServer)
+		
 		// 服务状态相关
 		api.GET("/services", GetServiceStatus)
+		api.GET("/services/health", GetServiceHealth)
+		api.PUT("/services/:id", UpdateService)
+		api.GET("/services/:id/uptime", GetServiceUptime)
+		api.GET("/services/:id/checks", compress(), GetServiceCheckResults)
 		
 		// 系统日志相关
-		api.GET("/logs", GetSystemLogs)
+		api.GET("/logs", compress(), GetSystemLogs)
 		api.POST("/logs", AddSystemLog)
 		
 		// 磁盘使用情况
-		api.GET("/disk", GetDiskUsage)
+		api.GET("/disk", compress(), conditional(), GetDiskUsage)
+		api.GET("/disk/forecast", GetDiskForecast)
 		
 		// 告警相关
-		api.GET("/alerts", GetAlerts)
+		api.GET("/alerts", compress(), GetAlerts)
+		api.GET("/alerts/stats", GetAlertStats)
+		api.POST("/alerts/ingest", ingestAuth(), IngestAlerts)
+		api.POST("/ingest/metrics", ingestAuth(), IngestMetrics(hub))
 		api.PUT("/alerts/:id/resolve", ResolveAlert)
+		api.PUT("/alerts/:id/acknowledge", AcknowledgeAlert)
+		api.PUT("/alerts/:id/silence", SilenceAlert)
+		api.GET("/alerts/:id/timeline", GetAlertTimeline)
+		api.GET("/alerts/:id/history", GetAlertTimeline)
+		api.GET("/alerts/:id/comments", GetAlertComments)
+		api.POST("/alerts/:id/comments", AddAlertComment)
+		
+		// 聊天机器人按钮回调（签名校验）
+		api.GET("/alerts/:id/actions/:action", ConfirmAlertAction)
+		api.POST("/alerts/:id/actions/:action", AlertAction)
+		
+		// 告警规则
+		api.GET("/alert-rules", GetAlertRules)
+		api.POST("/alert-rules", CreateAlertRule)
+		api.PUT("/alert-rules/:id", UpdateAlertRule)
+		api.DELETE("/alert-rules/:id", DeleteAlertRule)
+		
+		// 告警规则夹具：录制指标数据并在其上运行规则
+		api.GET("/alert-fixtures", GetAlertFixtures)
+		api.POST("/alert-fixtures", CreateAlertFixture)
+		api.DELETE("/alert-fixtures/:id", DeleteAlertFixture)
+		api.POST("/alert-fixtures/:id/run", RunAlertFixture)
+		
+		// 检查包及其导入的服务检查、进程监视
+		api.GET("/bundles", GetBundles)
+		api.POST("/bundles/import", ImportBundle)
+		api.GET("/service-checks", GetServiceChecks)
+		api.DELETE("/service-checks/:id", DeleteServiceCheck)
+		api.GET("/process-watches", GetProcessWatches)
+		api.DELETE("/process-watches/:id", DeleteProcessWatch)
 		
 		// 网络流量
-		api.GET("/network", GetNetworkTraffic)
+		api.GET("/network", compress(), conditional(), GetNetworkTraffic)
+		
+		// 公网IP信誉
+		api.GET("/reputation", GetIPReputation)
+		
+		// IP地址变化
+		api.GET("/addresses", GetAddresses)
+		// 主机标识（公网IP及地理位置）
+		api.GET("/host", GetHostInfo)
+		api.GET("/addresses/changes", compress(), GetAddressChanges)
+		
+		// 即席聚合查询
+		api.POST("/query", compress(), QueryMetrics)
+		
+		// 指标序列发现
+		api.GET("/series", compress(), GetSeries)
+		api.GET("/series/metrics", GetSeriesMetrics)
+		api.GET("/series/tags", GetSeriesTagKeys)
+		api.GET("/series/tags/:key/values", GetSeriesTagValues)
+		
+		// 图表注释
+		api.GET("/annotations", compress(), GetAnnotations)
+		
+		// TLS证书
+		api.GET("/certificates", GetCertificates)
+		api.POST("/certificates", AddCertificate)
+		
+		// WebSocket客户端管理
+		api.GET("/ws/clients", RestrictNetworks(), GetWSClients(hub))
+		// 实时数据的SSE推送，与 /ws 共用连接数限制和客户端管理
+		api.GET("/stream", RestrictNetworks(), LimitWebSocket(), StreamEvents(hub))
+		api.DELETE("/ws/clients/:id", DisconnectWSClient(hub))
+		
+		// 维护操作：按计划执行白名单中的命令，只允许受信任网段访问
+		actions := api.Group("/actions", RestrictNetworks())
+		actions.GET("", GetActions)
+		actions.POST("", CreateAction)
+		actions.GET("/runs", compress(), GetActionRuns)
+		actions.PUT("/:id", UpdateAction)
+		actions.DELETE("/:id", DeleteAction)
+		actions.POST("/:id/run", RunActionNow)
+		
+		// 导出报告（HTML/PDF）
+		api.GET("/reports", GetReport)
+		
+		// 摘要报告：预览，或立即发送到订阅了 report 事件的Webhook
+		api.GET("/reports/digest", GetDigest)
+		api.POST("/reports/digest/send", SendDigest)
+		
+		// 容量规划报告：磁盘、内存、带宽的增长趋势和扩容建议
+		api.GET("/reports/capacity", GetCapacityReport)
+		
+		// 定时任务执行记录
+		api.GET("/jobs", GetJobSummary)
+		api.GET("/jobs/runs", compress(), GetJobRuns)
 		
 		// 硬件信息
 		api.GET("/hardware", GetHardwareInfoHandler)
 		
+		// 按容器、systemd服务汇总的资源占用
+		api.GET("/resource-groups", GetResourceGroups)
+
+		// 本机的TCP/UDP连接及所属进程，会暴露客户端地址，只允许受信任网段访问
+		api.GET("/connections", RestrictNetworks(), compress(), GetConnections)
+
+		// 监听端口清单及变化历史
+		api.GET("/ports", GetListeningPorts)
+		api.GET("/ports/changes", compress(), GetPortChanges)
+
+		// SSH登录记录，包含来源地址和用户名，只允许受信任网段访问
+		api.GET("/ssh/logins", RestrictNetworks(), compress(), GetSSHLogins)
+
+		// fail2ban各jail的封禁状态及封禁历史
+		api.GET("/fail2ban", RestrictNetworks(), GetFail2banStatus)
+		api.GET("/fail2ban/history", RestrictNetworks(), compress(), GetFail2banHistory)
+
+		// 软件包更新和是否需要重启
+		api.GET("/updates", GetUpdates)
+		api.GET("/updates/history", GetUpdateHistory)
+
+		// 本机时钟相对NTP服务器的偏差
+		api.GET("/clock", GetClock)
+		api.GET("/clock/history", GetClockHistory)
+
+		// 温度等硬件传感器的最新读数及历史
+		api.GET("/sensors", GetSensors)
+		api.GET("/sensors/history", compress(), GetSensorHistory)
+
+		// mdadm软RAID阵列的状态和重建进度
+		api.GET("/raid", GetRAID)
+		api.GET("/raid/history", compress(), GetRAIDHistory)
+
+		// ZFS存储池的健康状态、容量和scrub状态
+		api.GET("/zfs", GetZFS)
+		api.GET("/zfs/history", compress(), GetZFSHistory)
+		
+		// 监控程序自身的运行状态及API请求统计
+		api.GET("/self-metrics", GetSelfMetrics)
+		
 		// 仪表板数据
-		api.GET("/dashboard", GetDashboardData)
+		api.GET("/dashboard", compress(), conditional(), GetDashboardData)
+		
+		// 运行时设置
+		api.GET("/settings", RestrictNetworks(), GetSettings)
+		api.PUT("/settings", UpdateSettings)
+		
+		// 审计日志
+		api.GET("/audit-logs", RestrictNetworks(), auditLogAuth(), GetAuditLogs)
+		
+		// HTTP访问日志
+		api.GET("/access-log", RestrictNetworks(), compress(), GetAccessLog)
+		
+		// 管理接口，只允许受信任网段访问
+		admin := api.Group("/admin", RestrictNetworks())
+		admin.GET("/cleanup", GetCleanupStats)
+		admin.POST("/reload", ReloadConfig)
+		admin.POST("/fail2ban/unban", UnbanFail2ban)
 		r.Static("/css", "./css")
 		r.Static("/js", "./js")
 	}
 
-	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "ok",
-			"message": "Server is running",
+	// 健康检查，包含各组件状态，组件异常时返回503
+	r.GET("/health", Health(hub))
+	r.HEAD("/health", Health(hub))
+
+	// 公开状态页，无需认证，需启用 status_page.enabled
+	r.GET("/status", rateLimit(), GetStatusPage)
+
+	// 服务状态和可用率徽章（SVG），只提供设为公开的服务
+	r.GET("/badge/service/:id/status", rateLimit(), GetServiceStatusBadge)
+	r.GET("/badge/service/:id/uptime/:period", rateLimit(), GetServiceUptimeBadge)
+
+	// Web终端（WebSocket），需启用 terminal.enabled 并通过管理员认证
+	r.GET("/terminal", RestrictNetworks(), LimitWebSocket(), terminalAuth(), ServeTerminal)
+
+	// Go运行时性能分析，需启用 debug.pprof 并通过管理员认证
+	pprofRoutes(r.Group("/debug/pprof", RestrictNetworks(), debugAuth()))
+
+	// 存活检查与就绪检查，供Kubernetes等容器编排的探针使用
+	r.GET("/healthz", Healthz)
+	r.HEAD("/healthz", Healthz)
+	r.GET("/readyz", Readyz)
+	r.HEAD("/readyz", Readyz)
+
+	// 详细健康检查，包含启动自检结果
+	r.GET("/health/detail", func(c *gin.Context) {
+		report := selftest.Last()
+		status, code := "ok", 200
+		if report != nil && !report.Passed {
+			status, code = "degraded", 503
+		}
+		c.JSON(code, gin.H{
+			"status":   status,
+			"selftest": report,
 		})
 	})
 
+	// 不存在的API接口同样返回统一的错误格式，其他路径保持默认的404页面
+	r.NoRoute(func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/api/") {
+			abortError(c, http.StatusNotFound, tr(c, "接口不存在"), nil)
+		}
+	})
+
 	return r
 } 
\ No newline at end of file
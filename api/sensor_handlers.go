@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 超过该时间没有新读数的传感器不再出现在最新读数中
+const sensorStaleAfter = 24 * time.Hour
+
+// GetSensors 获取各传感器的最新读数，可按 source（thermal、rpi）过滤
+func GetSensors(c *gin.Context) {
+	readings, err := monitor.GetLatestSensorReadings(c.Query("source"), sensorStaleAfter)
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取传感器读数失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    readings,
+	})
+}
+
+// GetSensorHistory 获取最近 hours 小时（默认24）的传感器读数，可按 source、name 过滤，按时间正序
+func GetSensorHistory(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "24"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+
+	query := database.DB.Where("timestamp >= ?", time.Now().Add(-time.Duration(hours)*time.Hour))
+	if source := c.Query("source"); source != "" {
+		query = query.Where("source = ?", source)
+	}
+	if name := c.Query("name"); name != "" {
+		query = query.Where("name = ?", name)
+	}
+
+	var readings []models.SensorReading
+	if err := query.Order("timestamp asc, id asc").Find(&readings).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取传感器读数失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    readings,
+	})
+}
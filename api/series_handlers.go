@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// filterSeries 按指标名和标签（tag=key=value，可重复）过滤序列
+func filterSeries(c *gin.Context, query *gorm.DB) *gorm.DB {
+	if metric := c.Query("metric"); metric != "" {
+		query = query.Where("metric = ?", metric)
+	}
+	for _, tag := range c.QueryArray("tag") {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok {
+			continue
+		}
+		subQuery := database.DB.Model(&models.SeriesTag{}).Select("series_id").Where("key = ? AND value = ?", key, value)
+		query = query.Where("id IN (?)", subQuery)
+	}
+	return query
+}
+
+// GetSeries 获取指标序列列表，如 /series?metric=network_traffic.upload_speed&tag=interface=eth0
+func GetSeries(c *gin.Context) {
+	var series []models.Series
+	err := filterSeries(c, database.DB.Order("key asc")).Find(&series).Error
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取指标序列失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    series,
+	})
+}
+
+// GetSeriesMetrics 获取所有指标名及各自的序列数
+func GetSeriesMetrics(c *gin.Context) {
+	var metrics []struct {
+		Metric string `json:"metric"`
+		Series int64  `json:"series"`
+	}
+	err := database.DB.Model(&models.Series{}).
+		Select("metric, COUNT(*) AS series").
+		Group("metric").
+		Order("metric asc").
+		Scan(&metrics).Error
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取指标名失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    metrics,
+	})
+}
+
+// GetSeriesTagKeys 获取标签键列表，可按指标名过滤
+func GetSeriesTagKeys(c *gin.Context) {
+	query := database.DB.Model(&models.SeriesTag{}).Distinct("key").Order("key asc")
+	if metric := c.Query("metric"); metric != "" {
+		query = query.Where("metric = ?", metric)
+	}
+
+	var keys []string
+	if err := query.Pluck("key", &keys).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取标签失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    keys,
+	})
+}
+
+// GetSeriesTagValues 获取指定标签键的所有取值，可按指标名过滤
+func GetSeriesTagValues(c *gin.Context) {
+	query := database.DB.Model(&models.SeriesTag{}).Distinct("value").
+		Where("key = ?", c.Param("key")).
+		Order("value asc")
+	if metric := c.Query("metric"); metric != "" {
+		query = query.Where("metric = ?", metric)
+	}
+
+	var values []string
+	if err := query.Pluck("value", &values).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取标签值失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    values,
+	})
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// hostFilter 服务、告警和日志接口的主机筛选：指定了 host 参数时按主机筛选（host= 为空表示本机），
+// 未指定时返回所有主机的数据
+func hostFilter(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	host, ok := c.GetQuery("host")
+	if !ok {
+		return func(db *gorm.DB) *gorm.DB { return db }
+	}
+	return database.HostScope(host)
+}
+
+// GetServers 获取已登记的远程主机及最后推送时间，本机不在列表中，其数据的 server_id 为0
+func GetServers(c *gin.Context) {
+	var servers []models.Server
+	if err := database.DB.Order("name asc").Find(&servers).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取主机列表失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    servers,
+	})
+}
+
+// DeleteServer 注销已下线的远程主机，停止离线检测并解除其离线告警
+func DeleteServer(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		abortError(c, http.StatusBadRequest, tr(c, "主机ID无效"), err)
+		return
+	}
+
+	server, err := monitor.DeleteServer(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		abortError(c, http.StatusNotFound, tr(c, "主机不存在"), err)
+		return
+	}
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "注销主机失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "主机已注销"),
+		Data:    server,
+	})
+}
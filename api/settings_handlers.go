@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"server-monitor/settings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSettings 获取可在运行时修改的设置项及其当前生效的值
+func GetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    settings.Current(),
+	})
+}
+
+// UpdateSettings 修改设置并保存到数据库，立即生效且覆盖配置文件；值为null时恢复配置文件中的值
+func UpdateSettings(c *gin.Context) {
+	var changes map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&changes); err != nil || len(changes) == 0 {
+		abortError(c, http.StatusBadRequest, tr(c, "请求参数错误"), err)
+		return
+	}
+
+	if err := settings.Update(changes); err != nil {
+		if errors.Is(err, settings.ErrInvalidSetting) {
+			abortError(c, http.StatusBadRequest, trErr(c, err), err)
+			return
+		}
+		abortError(c, http.StatusInternalServerError, tr(c, "保存设置失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: tr(c, "设置已保存"),
+		Data:    settings.Current(),
+	})
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/websocket"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamEvents 以Server-Sent Events推送与WebSocket相同的实时数据，供WebSocket被代理拦截的客户端或curl使用。
+// host 选择主机，types 只推送指定类型的广播（可重复或逗号分隔），interval 为合并推送的间隔（秒）
+func StreamEvents(hub *websocket.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hub.ShuttingDown() {
+			abortError(c, http.StatusServiceUnavailable, tr(c, "服务正在重启"), nil)
+			return
+		}
+
+		opts := websocket.StreamOptions{Host: c.Query("host")}
+		if opts.Host != "" {
+			var server models.Server
+			if err := database.DB.Where("name = ?", opts.Host).First(&server).Error; err != nil {
+				abortError(c, http.StatusNotFound, tr(c, "主机不存在"), err)
+				return
+			}
+			opts.ServerID = server.ID
+		}
+		for _, t := range queryValues(c, "types") {
+			if !slices.Contains(websocket.BroadcastTypes, t) {
+				abortError(c, http.StatusBadRequest, tr(c, "types 无效，可选 %s", strings.Join(websocket.BroadcastTypes, "、")), nil)
+				return
+			}
+			opts.Types = append(opts.Types, t)
+		}
+		if value := c.Query("interval"); value != "" {
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 {
+				abortError(c, http.StatusBadRequest, tr(c, "interval 必须是非负的秒数"), err)
+				return
+			}
+			opts.Interval = time.Duration(seconds * float64(time.Second))
+		}
+
+		if status, ok := hub.ServeEvents(c, opts); !ok {
+			abortError(c, status, tr(c, "连接数已达上限"), nil)
+		}
+	}
+}
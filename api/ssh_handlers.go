@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSSHLogins 获取SSH登录记录，按时间倒序；success=true/false 只返回登录成功或失败的记录，ip、user 按来源地址和用户名筛选，
+// 默认返回最近24小时（hours）内的记录，指定 from、to 时按时间范围筛选；limit 默认100
+func GetSSHLogins(c *gin.Context) {
+	rangeScope, ok := timeRangeScope(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.SSHLogin{})
+	if rangeScope != nil {
+		query = query.Scopes(rangeScope)
+	} else {
+		hours, err := strconv.Atoi(c.DefaultQuery("hours", "24"))
+		if err != nil || hours <= 0 {
+			hours = 24
+		}
+		query = query.Where("timestamp >= ?", time.Now().Add(-time.Duration(hours)*time.Hour))
+	}
+	if success := c.Query("success"); success != "" {
+		value, err := strconv.ParseBool(success)
+		if err != nil {
+			abortError(c, http.StatusBadRequest, tr(c, "success 必须是 true 或 false"), err)
+			return
+		}
+		query = query.Where("success = ?", value)
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	if user := c.Query("user"); user != "" {
+		query = query.Where("user = ?", user)
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+
+	var logins []models.SSHLogin
+	if err := query.Order("timestamp desc, id desc").Limit(limit).Find(&logins).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取SSH登录记录失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    logins,
+	})
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"server-monitor/config"
+	"server-monitor/monitor"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 状态页的总体状态说明
+var statusPageSummaries = map[string]string{
+	"operational": "所有服务运行正常",
+	"degraded":    "部分服务性能下降",
+	"outage":      "部分服务不可用",
+}
+
+// 服务状态的显示名称
+var statusPageLabels = map[string]string{
+	"running": "正常",
+	"warning": "性能下降",
+	"error":   "不可用",
+}
+
+// statusLabel 服务状态的显示名称
+func statusLabel(status string) string {
+	if label, ok := statusPageLabels[status]; ok {
+		return label
+	}
+	return "未知"
+}
+
+// statusPageTemplate 公开状态页：样式内联、不依赖外部资源，每分钟自动刷新
+var statusPageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"summary": func(status string) string { return statusPageSummaries[status] },
+	"label":   statusLabel,
+	"percent": func(value *float64) string {
+		if value == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f%%", *value)
+	},
+	"dayClass": func(value *float64) string {
+		switch {
+		case value == nil:
+			return "none"
+		case *value >= 99.9:
+			return "running"
+		case *value >= 95:
+			return "warning"
+		default:
+			return "error"
+		}
+	},
+	"datetime": func(t time.Time) string { return t.In(config.AppConfig.I18n.Location()).Format("2006-01-02 15:04") },
+	"duration": func(seconds int64) string {
+		d := time.Duration(seconds) * time.Second
+		switch {
+		case d < time.Minute:
+			return fmt.Sprintf("%d秒", seconds)
+		case d < time.Hour:
+			return fmt.Sprintf("%d分钟", int(d.Minutes()))
+		default:
+			return fmt.Sprintf("%d小时%d分钟", int(d.Hours()), int(d.Minutes())%60)
+		}
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="60">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif; color: #1f2937; max-width: 760px; margin: 24px auto; padding: 0 16px; }
+h1 { font-size: 22px; }
+h2 { font-size: 16px; margin: 28px 0 8px; border-bottom: 1px solid #e5e7eb; padding-bottom: 4px; }
+.banner { border-radius: 6px; padding: 12px 16px; color: #fff; font-weight: 600; }
+.banner.operational { background: #16a34a; } .banner.degraded { background: #d97706; } .banner.outage { background: #dc2626; }
+.service { border: 1px solid #e5e7eb; border-radius: 6px; padding: 10px 12px; margin-bottom: 10px; }
+.service .head { display: flex; justify-content: space-between; }
+.service .uptime { color: #6b7280; font-size: 12px; margin-top: 4px; }
+.state.running { color: #16a34a; } .state.warning { color: #d97706; } .state.error { color: #dc2626; }
+.days { display: flex; gap: 2px; margin-top: 8px; }
+.days i { flex: 1; height: 24px; border-radius: 2px; }
+i.running { background: #16a34a; } i.warning { background: #f59e0b; } i.error { background: #dc2626; } i.none { background: #e5e7eb; }
+table { width: 100%; border-collapse: collapse; font-size: 13px; }
+th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid #f3f4f6; }
+th { color: #6b7280; font-weight: normal; }
+.meta, .empty { color: #9ca3af; font-size: 13px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="banner {{.Status}}">{{summary .Status}}</div>
+
+{{if .Incidents}}
+<h2>当前故障</h2>
+<table>
+<tr><th>服务</th><th>开始时间</th><th>已持续</th></tr>
+{{range .Incidents}}<tr><td>{{.Service}}</td><td>{{datetime .Start}}</td><td>{{duration .Duration}}</td></tr>
+{{end}}</table>
+{{end}}
+
+<h2>服务</h2>
+{{range .Services}}
+<div class="service">
+<div class="head"><span>{{.Name}}</span><span class="state {{.Status}}">{{label .Status}}</span></div>
+<div class="uptime">{{range $i, $p := .Uptime}}{{if $i}} · {{end}}{{$p.Period}} 可用率 {{percent $p.Availability}}{{end}}</div>
+<div class="days">{{range .Days}}<i class="{{dayClass .Availability}}" title="{{.Date}} {{percent .Availability}}"></i>{{end}}</div>
+</div>
+{{else}}
+<p class="empty">暂无公开的服务</p>
+{{end}}
+
+<h2>最近 {{.HistoryDays}} 天的故障记录</h2>
+{{if .History}}
+<table>
+<tr><th>服务</th><th>开始时间</th><th>恢复时间</th><th>持续时间</th></tr>
+{{range .History}}<tr><td>{{.Service}}</td><td>{{datetime .Start}}</td><td>{{datetime .End}}</td><td>{{duration .Duration}}</td></tr>
+{{end}}</table>
+{{else}}
+<p class="empty">没有故障记录</p>
+{{end}}
+
+<p class="meta">更新于 {{datetime .UpdatedAt}}</p>
+</body>
+</html>
+`))
+
+// GetStatusPage 公开状态页，无需认证：浏览器访问返回HTML页面，其他客户端返回JSON，可用 ?format=html|json 指定；
+// 只包含设为公开的服务，未启用 status_page.enabled 时返回404
+func GetStatusPage(c *gin.Context) {
+	if !config.AppConfig.StatusPage.Enabled {
+		abortError(c, http.StatusNotFound, tr(c, "状态页未启用"), nil)
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = "json"
+		if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+			format = "html"
+		}
+	}
+	if format != "html" && format != "json" {
+		abortError(c, http.StatusBadRequest, tr(c, "format 无效，可选 html、json"), nil)
+		return
+	}
+
+	page, err := monitor.GetStatusPage()
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取服务状态失败"), err)
+		return
+	}
+
+	if format == "html" {
+		var buf bytes.Buffer
+		if err := statusPageTemplate.Execute(&buf, page); err != nil {
+			abortError(c, http.StatusInternalServerError, tr(c, "生成状态页失败"), err)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=60")
+		c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    page,
+	})
+}
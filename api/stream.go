@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 流式输出时每编码多少行刷新一次响应缓冲
+const streamFlushRows = 200
+
+// streamJSON 以流式JSON数组输出查询结果：逐行扫描、逐行编码并定期刷新，
+// 内存占用与结果集大小无关。响应格式与Response一致，data为结果数组
+func streamJSON[T any](c *gin.Context, query *gorm.DB, errMessage string) {
+	rows, err := query.Model(new(T)).Rows()
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, errMessage), err)
+		return
+	}
+	defer rows.Close()
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	w.WriteString(`{"code":200,"message":"success","data":[`)
+
+	encoder := json.NewEncoder(w)
+	count := 0
+	for rows.Next() {
+		var item T
+		if err := query.ScanRows(rows, &item); err != nil {
+			// 响应头已发出，无法再返回错误码，中断输出让客户端解析失败；错误由 handleErrors 记录
+			c.Error(fmt.Errorf("scanning streamed row: %w", err))
+			return
+		}
+		if count > 0 {
+			w.WriteString(",")
+		}
+		if err := encoder.Encode(&item); err != nil {
+			return
+		}
+
+		count++
+		if count%streamFlushRows == 0 {
+			w.Flush()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		c.Error(fmt.Errorf("streaming rows: %w", err))
+		return
+	}
+
+	w.WriteString("]}")
+	w.Flush()
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getDailySummaries resolution=day 时返回数据源最近 days 天（默认365）的每日最小值、平均值、最大值，
+// 按日期升序；field 只返回单个字段，tags 中非空的标签用于过滤，host 为远程主机标识（为空时返回本机的汇总）。
+// 数据量与天数成正比，适合数月到数年的趋势图
+func getDailySummaries(c *gin.Context, source string, tags map[string]string) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "365"))
+	if err != nil || days <= 0 {
+		days = 365
+	}
+	since := time.Now().In(config.AppConfig.I18n.Location()).AddDate(0, 0, -days+1).Format("2006-01-02")
+
+	query := database.DB.Scopes(database.HostSummaryScope(c.Query("host"))).
+		Where("day >= ?", since).Order("day asc, metric asc, tags asc")
+	if field := c.Query("field"); field != "" {
+		query = query.Where("metric = ?", source+"."+field)
+	} else {
+		query = query.Where("metric LIKE ?", source+".%")
+	}
+	for key, value := range tags {
+		if value != "" {
+			// 标签按 k=v 逗号分隔存储，两端补逗号后按完整的 k=v 匹配
+			query = query.Where("',' || tags || ',' LIKE ?", "%,"+key+"="+value+",%")
+		}
+	}
+
+	streamJSON[models.DailySummary](c, query, "获取每日汇总失败")
+}
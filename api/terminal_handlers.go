@@ -0,0 +1,356 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/terminal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// 终端输出写入客户端的超时时间
+const terminalWriteTimeout = 10 * time.Second
+
+// 当前打开的终端会话数
+var terminalSessions atomic.Int32
+
+var terminalUpgrader = websocket.Upgrader{CheckOrigin: sameOrigin}
+
+// sameOrigin 只接受同源页面或非浏览器客户端发起的连接：终端使用Basic认证，浏览器会自动附带已保存的凭据，
+// 需防止其他网站的页面跨站打开终端
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// terminalAuth Web终端的访问控制：未启用 terminal.enabled 时返回404，启用时要求 terminal 中配置的管理员认证
+func terminalAuth() gin.HandlerFunc {
+	return basicAuth(
+		func() bool { return config.AppConfig.Terminal.Enabled },
+		terminalCredentials, "server-monitor terminal", "Web终端未启用")
+}
+
+// auditLogAuth 审计日志包含终端会话的记录：设置了 terminal.password 时要求与Web终端相同的管理员认证，
+// 停用终端后已有的记录仍受保护；未设置时不需要认证
+func auditLogAuth() gin.HandlerFunc {
+	auth := basicAuth(func() bool { return true }, terminalCredentials, "server-monitor terminal", "")
+	return func(c *gin.Context) {
+		if config.AppConfig.Terminal.Password == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
+// terminalCredentials Web终端的管理员用户名和密码
+func terminalCredentials() (string, string) {
+	return config.AppConfig.Terminal.Username, config.AppConfig.Terminal.Password
+}
+
+// terminalMessage 客户端发送的文本消息：input 为键盘输入，resize 调整窗口大小；二进制消息直接作为键盘输入
+type terminalMessage struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+	Cols uint16 `json:"cols"`
+	Rows uint16 `json:"rows"`
+}
+
+// ServeTerminal Web终端：将WebSocket连接桥接到本机PTY或SSH会话。终端输出以二进制消息发送，
+// 超过 terminal.idle_timeout 秒没有键盘输入时断开，会话的开始和结束记录在审计日志中，开启 terminal.record_input 时同时记录输入
+func ServeTerminal(c *gin.Context) {
+	cfg := config.AppConfig.Terminal
+	if n := terminalSessions.Add(1); int(n) > cfg.MaxSessions {
+		terminalSessions.Add(-1)
+		abortError(c, http.StatusTooManyRequests, tr(c, "终端会话数已达上限"), nil)
+		return
+	}
+	defer terminalSessions.Add(-1)
+
+	cols, rows := terminalSize(c.Query("cols"), 80), terminalSize(c.Query("rows"), 24)
+	conn, err := terminalUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("Terminal upgrade error: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	recorder := newTerminalRecorder(c, cols, rows, cfg.RecordInput)
+	session, err := terminal.Open(cfg, cols, rows)
+	if err != nil {
+		recorder.record("open", recorder.summary(false), err.Error())
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()),
+			time.Now().Add(terminalWriteTimeout))
+		return
+	}
+	if s, ok := session.(terminal.SecretInput); ok {
+		recorder.secretInput = s.SecretInput
+	}
+	recorder.record("open", recorder.summary(false), terminal.Target(cfg))
+
+	var writeMu sync.Mutex
+	done := make(chan string, 2)
+
+	// 终端输出 → 客户端
+	go func() {
+		buf := make([]byte, 32<<10)
+		for {
+			n, err := session.Read(buf)
+			if n > 0 {
+				recorder.output(buf[:n])
+				writeMu.Lock()
+				conn.SetWriteDeadline(time.Now().Add(terminalWriteTimeout))
+				werr := conn.WriteMessage(websocket.BinaryMessage, buf[:n])
+				writeMu.Unlock()
+				if werr != nil {
+					done <- "客户端断开"
+					return
+				}
+			}
+			if err != nil {
+				done <- "会话结束"
+				return
+			}
+		}
+	}()
+
+	// 客户端输入 → 终端，每条消息都重新计算空闲时间
+	idle := time.Duration(cfg.IdleTimeout) * time.Second
+	go func() {
+		for {
+			conn.SetReadDeadline(time.Now().Add(idle))
+			msgType, data, err := conn.ReadMessage()
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					done <- "空闲超时"
+				} else {
+					done <- "客户端断开"
+				}
+				return
+			}
+
+			if msgType == websocket.TextMessage {
+				var msg terminalMessage
+				if err := json.Unmarshal(data, &msg); err != nil {
+					continue
+				}
+				if msg.Type == "resize" {
+					if msg.Cols > 0 && msg.Rows > 0 {
+						session.Resize(msg.Cols, msg.Rows)
+						recorder.resize(msg.Cols, msg.Rows)
+					}
+					continue
+				}
+				if msg.Type != "input" {
+					continue
+				}
+				data = []byte(msg.Data)
+			}
+			recorder.input(data)
+			if _, err := session.Write(data); err != nil {
+				done <- "会话结束"
+				return
+			}
+		}
+	}()
+
+	reason := <-done
+	session.Close()
+	recorder.flush()
+	recorder.record("close", recorder.summary(true), reason)
+
+	writeMu.Lock()
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason),
+		time.Now().Add(terminalWriteTimeout))
+	writeMu.Unlock()
+}
+
+// terminalSize 解析窗口大小参数，无效时使用默认值
+func terminalSize(value string, fallback uint16) uint16 {
+	n, err := strconv.ParseUint(value, 10, 16)
+	if err != nil || n == 0 {
+		return fallback
+	}
+	return uint16(n)
+}
+
+// 识别密码提示时保留的终端输出长度
+const terminalPromptTail = 256
+
+// passwordPrompt 终端输出的最后一行是密码提示，用于无法读取终端属性的SSH会话
+var passwordPrompt = regexp.MustCompile(`(?i)(password|passphrase|passcode|密码|口令)[^\r\n]*[:：]\s*$`)
+
+// terminalRecorder 将终端会话记录到审计日志：开始、结束各一条，包含窗口大小、时长和输入输出的字节数；
+// 开启 terminal.record_input 时键盘输入按行记录（回车时写入一条），密码提示下的输入不记录
+type terminalRecorder struct {
+	session     string
+	user        string
+	ip          string
+	start       time.Time
+	recordInput bool
+	secretInput func() bool // 会话能判断是否在读取不回显的输入时非nil
+
+	bytesIn  atomic.Int64
+	bytesOut atomic.Int64
+
+	mu     sync.Mutex
+	cols   uint16
+	rows   uint16
+	line   []byte
+	secret bool   // 当前行有在密码提示下输入的内容，整行不记录
+	prompt []byte // 最近的终端输出，用于识别密码提示
+	escape int    // 转义序列的解析状态：0 普通字符，1 收到ESC，2 在CSI序列中
+}
+
+func newTerminalRecorder(c *gin.Context, cols, rows uint16, recordInput bool) *terminalRecorder {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return &terminalRecorder{
+		session:     hex.EncodeToString(id),
+		user:        c.GetString(AuditUserKey),
+		ip:          c.ClientIP(),
+		start:       time.Now(),
+		recordInput: recordInput,
+		cols:        cols,
+		rows:        rows,
+	}
+}
+
+// record 写入一条审计日志，action 为 open、input、close
+func (r *terminalRecorder) record(action, payload, result string) {
+	now := time.Now()
+	entry := models.AuditLog{
+		Action:    "TERMINAL " + action,
+		Target:    "session=" + r.session,
+		User:      r.user,
+		IP:        r.ip,
+		Payload:   truncateAudit(payload),
+		Status:    http.StatusSwitchingProtocols,
+		Result:    result,
+		Duration:  now.Sub(r.start).Milliseconds(),
+		Timestamp: now,
+	}
+	if err := database.DB.Create(&entry).Error; err != nil {
+		log.Printf("Error recording terminal audit log: %v", err)
+	}
+}
+
+// summary 会话的元数据：窗口大小，会话结束时加上输入和输出的字节数
+func (r *terminalRecorder) summary(closed bool) string {
+	r.mu.Lock()
+	meta := map[string]interface{}{"cols": r.cols, "rows": r.rows, "record_input": r.recordInput}
+	r.mu.Unlock()
+	if closed {
+		meta["bytes_in"] = r.bytesIn.Load()
+		meta["bytes_out"] = r.bytesOut.Load()
+	}
+	data, _ := json.Marshal(meta)
+	return string(data)
+}
+
+// resize 记录当前的窗口大小
+func (r *terminalRecorder) resize(cols, rows uint16) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cols, r.rows = cols, rows
+}
+
+// output 统计终端输出的字节数，记录输入时保留最后一部分用于识别密码提示
+func (r *terminalRecorder) output(data []byte) {
+	r.bytesOut.Add(int64(len(data)))
+	if !r.recordInput {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prompt = append(r.prompt, data...)
+	if over := len(r.prompt) - terminalPromptTail; over > 0 {
+		r.prompt = append(r.prompt[:0], r.prompt[over:]...)
+	}
+}
+
+// input 按键盘输入还原命令行：回车时记录一行，退格删除前一个字符，方向键等转义序列忽略，
+// 其他控制字符记为 ^C 的形式。终端正在读取不回显的输入或最近的输出是密码提示时，该行不记录
+func (r *terminalRecorder) input(data []byte) {
+	r.bytesIn.Add(int64(len(data)))
+	if !r.recordInput {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if (r.secretInput != nil && r.secretInput()) || passwordPrompt.Match(r.prompt) {
+		r.secret = true
+	}
+	for _, b := range data {
+		switch {
+		case r.escape == 1:
+			r.escape = 0
+			if b == '[' {
+				r.escape = 2
+			}
+		case r.escape == 2:
+			if b >= 0x40 && b <= 0x7e {
+				r.escape = 0
+			}
+		case b == 0x1b:
+			r.escape = 1
+		case b == '\r' || b == '\n':
+			r.flushLocked()
+		case b == 0x7f || b == 0x08:
+			if len(r.line) > 0 {
+				_, size := utf8.DecodeLastRune(r.line)
+				r.line = r.line[:len(r.line)-size]
+			}
+		case b < 0x20 && b != '\t':
+			r.line = append(r.line, '^', b+'@')
+		default:
+			r.line = append(r.line, b)
+		}
+	}
+}
+
+// flush 记录尚未回车的输入
+func (r *terminalRecorder) flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushLocked()
+}
+
+func (r *terminalRecorder) flushLocked() {
+	secret := r.secret
+	r.secret = false
+	if len(r.line) == 0 {
+		return
+	}
+	line := string(r.line)
+	r.line = r.line[:0]
+	if secret {
+		return
+	}
+	r.record("input", line, "")
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// timeRangeScope 按查询参数 from、to（RFC3339时间）筛选 timestamp 在[from, to]内的记录，只指定一端时另一端不限制，
+// 用于图表的缩放和平移；都未指定时返回nil。格式错误或 from 晚于 to 时返回400，ok 为false
+func timeRangeScope(c *gin.Context) (scope func(*gorm.DB) *gorm.DB, ok bool) {
+	var from, to time.Time
+	for _, param := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			abortError(c, http.StatusBadRequest, tr(c, "%s 必须是RFC3339时间，如 2024-05-01T08:00:00+08:00", param.name), err)
+			return nil, false
+		}
+		*param.target = t
+	}
+	if from.IsZero() && to.IsZero() {
+		return nil, true
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		abortError(c, http.StatusBadRequest, tr(c, "起始时间必须早于结束时间"), nil)
+		return nil, false
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if !from.IsZero() {
+			db = db.Where("timestamp >= ?", from)
+		}
+		if !to.IsZero() {
+			db = db.Where("timestamp <= ?", to)
+		}
+		return db
+	}, true
+}
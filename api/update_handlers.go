@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUpdates 获取最近一次软件包更新检查的结果（可更新和安全更新的软件包数、是否需要重启），未检查过时 data 为null
+func GetUpdates(c *gin.Context) {
+	check, err := monitor.GetLatestUpdateCheck()
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取软件包更新检查结果失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    check,
+	})
+}
+
+// GetUpdateHistory 获取最近 days 天（默认30）的软件包更新检查结果，按时间倒序
+func GetUpdateHistory(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days <= 0 {
+		days = 30
+	}
+
+	var checks []models.UpdateCheck
+	err = database.DB.Where("timestamp >= ?", time.Now().AddDate(0, 0, -days)).
+		Order("timestamp desc").Find(&checks).Error
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取软件包更新检查结果失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    checks,
+	})
+}
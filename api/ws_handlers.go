@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/websocket"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWSClients 获取当前连接的WebSocket客户端列表
+func GetWSClients(hub *websocket.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, Response{
+			Code:    200,
+			Message: "success",
+			Data:    hub.ClientInfos(),
+		})
+	}
+}
+
+// DisconnectWSClient 断开指定的WebSocket客户端
+func DisconnectWSClient(hub *websocket.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hub.Disconnect(c.Param("id")) {
+			abortError(c, http.StatusNotFound, tr(c, "客户端不存在"), nil)
+			return
+		}
+
+		c.JSON(http.StatusOK, Response{
+			Code:    200,
+			Message: tr(c, "客户端已断开"),
+			Data:    nil,
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetZFS 获取最近一次检查时各ZFS存储池的状态，没有存储池时为空列表
+func GetZFS(c *gin.Context) {
+	pools, err := monitor.GetLatestZFSPools()
+	if err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取ZFS存储池状态失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    pools,
+	})
+}
+
+// GetZFSHistory 获取最近 hours 小时（默认168）的存储池状态，可按 pool 过滤，按时间正序
+func GetZFSHistory(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "168"))
+	if err != nil || hours <= 0 {
+		hours = 168
+	}
+
+	query := database.DB.Where("timestamp >= ?", time.Now().Add(-time.Duration(hours)*time.Hour))
+	if pool := c.Query("pool"); pool != "" {
+		query = query.Where("name = ?", pool)
+	}
+
+	var pools []models.ZFSPool
+	if err := query.Order("timestamp asc, name asc").Find(&pools).Error; err != nil {
+		abortError(c, http.StatusInternalServerError, tr(c, "获取ZFS存储池状态失败"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    pools,
+	})
+}
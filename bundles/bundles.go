@@ -0,0 +1,96 @@
+package bundles
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed stacks/*.yaml
+var stacks embed.FS
+
+// Bundle 检查包：一次导入常见应用栈所需的服务检查、进程监视和告警规则
+type Bundle struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Services    []models.ServiceCheck `json:"services"`
+	Processes   []models.ProcessWatch `json:"processes"`
+	AlertRules  []models.AlertRule    `json:"alert_rules"`
+}
+
+// Parse 解析JSON或YAML格式的检查包（JSON是YAML的子集），字段名与API一致
+func Parse(data []byte) (*Bundle, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, i18n.Errorf("检查包格式错误: %v", err)
+	}
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, i18n.Errorf("检查包格式错误: 顶层必须是对象")
+	}
+
+	// 经JSON中转，复用模型的json标签
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, i18n.Errorf("检查包格式错误: %v", err)
+	}
+	var bundle Bundle
+	if err := json.Unmarshal(encoded, &bundle); err != nil {
+		return nil, i18n.Errorf("检查包格式错误: %v", err)
+	}
+	if bundle.Name == "" {
+		return nil, i18n.Errorf("检查包名称不能为空")
+	}
+	return &bundle, nil
+}
+
+// List 列出内置的检查包
+func List() ([]*Bundle, error) {
+	files, err := stacks.ReadDir("stacks")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Bundle, 0, len(files))
+	for _, file := range files {
+		bundle, err := load(file.Name())
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, bundle)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// Get 按名称获取内置的检查包
+func Get(name string) (*Bundle, error) {
+	bundles, err := List()
+	if err != nil {
+		return nil, err
+	}
+	for _, bundle := range bundles {
+		if bundle.Name == name {
+			return bundle, nil
+		}
+	}
+	return nil, i18n.Errorf("检查包不存在: %s", name)
+}
+
+// load 解析内置的检查包文件
+func load(file string) (*Bundle, error) {
+	data, err := stacks.ReadFile(path.Join("stacks", file))
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return bundle, nil
+}
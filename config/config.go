@@ -1,8 +1,13 @@
 package config
 
 import (
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Config struct {
@@ -10,12 +15,84 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	Monitor  MonitorConfig  `mapstructure:"monitor"`
 	Services ServicesConfig `mapstructure:"services"`
+	Reputation ReputationConfig `mapstructure:"reputation"`
+	Certificates CertificatesConfig `mapstructure:"certificates"`
+	Addresses AddressesConfig `mapstructure:"addresses"`
+	Ports     PortsConfig     `mapstructure:"ports"`
+	WebSocket WebSocketConfig `mapstructure:"websocket"`
+	Notify    NotifyConfig    `mapstructure:"notify"`
+	SelfTest  SelfTestConfig  `mapstructure:"selftest"`
+	Secrets   SecretsConfig   `mapstructure:"secrets"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Access    AccessConfig    `mapstructure:"access"`
+	AccessLog AccessLogConfig `mapstructure:"access_log"`
+	Minimal   MinimalConfig   `mapstructure:"minimal"`
+	Rollup    RollupConfig    `mapstructure:"rollup"`
+	Debug     DebugConfig     `mapstructure:"debug"`
+	Actions   ActionsConfig   `mapstructure:"actions"`
+	Reports   ReportsConfig   `mapstructure:"reports"`
+	Ingest    IngestConfig    `mapstructure:"ingest"`
+	Terminal  TerminalConfig  `mapstructure:"terminal"`
+	StatusPage StatusPageConfig `mapstructure:"status_page"`
+	I18n      I18nConfig      `mapstructure:"i18n"`
+	Journal   JournalConfig   `mapstructure:"journal"`
+	SSHAuth   SSHAuthConfig   `mapstructure:"ssh_auth"`
+	Fail2ban  Fail2banConfig  `mapstructure:"fail2ban"`
+	Updates   UpdatesConfig   `mapstructure:"updates"`
+	Clock     ClockConfig     `mapstructure:"clock"`
+	SoC       SoCConfig       `mapstructure:"soc"`
+	UPS       UPSConfig       `mapstructure:"ups"`
+	IPMI      IPMIConfig      `mapstructure:"ipmi"`
+	RAID      RAIDConfig      `mapstructure:"raid"`
+	ZFS       ZFSConfig       `mapstructure:"zfs"`
+	Forecast  ForecastConfig  `mapstructure:"forecast"`
+	Anomaly   AnomalyConfig   `mapstructure:"anomaly"`
+	Capacity  CapacityConfig  `mapstructure:"capacity"`
 }
 
 type ServerConfig struct {
 	Port    string `mapstructure:"port"`
 	Host    string `mapstructure:"host"`
 	LogLevel string `mapstructure:"log_level"`
+
+	WatchConfig bool   `mapstructure:"watch_config"` // 配置文件修改后是否自动重新加载
+	PIDFile     string `mapstructure:"pid_file"`     // PID文件路径，不停机升级后由新进程更新，为空时不写入
+
+	TLS         TLSConfig         `mapstructure:"tls"`
+	Compression CompressionConfig `mapstructure:"compression"`
+	ETag        ETagConfig        `mapstructure:"etag"`
+}
+
+// TLSConfig HTTPS配置，证书文件与自动申请（Let's Encrypt）二选一
+type TLSConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	CertFile     string   `mapstructure:"cert_file"`     // 证书文件（PEM，可包含证书链），文件更新后自动重新加载
+	KeyFile      string   `mapstructure:"key_file"`      // 私钥文件（PEM）
+	Domains      []string `mapstructure:"domains"`       // 自动申请证书的域名，未配置证书文件时使用
+	Email        string   `mapstructure:"email"`         // 证书到期提醒邮箱，可为空
+	CacheDir     string   `mapstructure:"cache_dir"`     // 自动申请的证书缓存目录
+	RedirectHTTP bool     `mapstructure:"redirect_http"` // 是否监听HTTP端口并重定向到HTTPS（自动申请证书的HTTP验证也通过该端口）
+	HTTPPort     string   `mapstructure:"http_port"`     // HTTP重定向端口
+}
+
+// CompressionConfig 响应压缩配置，作用于返回大量JSON的接口（历史数据、仪表板等）
+type CompressionConfig struct {
+	Enabled     bool `mapstructure:"enabled"`
+	MinSize     int  `mapstructure:"min_size"`     // 响应超过该字节数才压缩
+	Brotli      bool `mapstructure:"brotli"`       // 客户端支持时是否优先使用brotli
+	GzipLevel   int  `mapstructure:"gzip_level"`   // gzip压缩级别（1-9）
+	BrotliLevel int  `mapstructure:"brotli_level"` // brotli压缩级别（0-11）
+}
+
+// ETagConfig 条件请求配置，作用于仪表板和历史数据接口，内容未变化时返回304
+type ETagConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	MaxSize int  `mapstructure:"max_size"` // 响应超过该字节数时不计算ETag，直接流式输出
+}
+
+// Autocert 是否自动申请证书
+func (t *TLSConfig) Autocert() bool {
+	return t.CertFile == "" && len(t.Domains) > 0
 }
 
 type DatabaseConfig struct {
@@ -25,6 +102,9 @@ type DatabaseConfig struct {
 	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
 	Database string `mapstructure:"database"`
+
+	IntegrityInterval int  `mapstructure:"integrity_interval"` // 完整性检查间隔（秒），0表示不检查
+	AutoRecover       bool `mapstructure:"auto_recover"`       // 发现损坏时是否隔离数据库文件并重建
 }
 
 type MonitorConfig struct {
@@ -33,6 +113,35 @@ type MonitorConfig struct {
 	AlertCPU     int `mapstructure:"alert_cpu"`     // CPU告警阈值
 	AlertMemory  int `mapstructure:"alert_memory"`  // 内存告警阈值
 	AlertDisk    int `mapstructure:"alert_disk"`    // 磁盘告警阈值
+
+	AlertMemoryBasis string `mapstructure:"alert_memory_basis"` // 内存告警依据: used, available, with_cache
+
+	CatchUpPolicy string `mapstructure:"catch_up_policy"` // 调度中断后的补偿策略: run_once, skip
+	GapThreshold  int    `mapstructure:"gap_threshold"`   // 判定为调度中断的时间间隔（秒）
+
+	CleanupBatchSize  int `mapstructure:"cleanup_batch_size"`  // 数据清理每批删除的行数
+	CleanupBatchPause int `mapstructure:"cleanup_batch_pause"` // 数据清理批次之间的暂停时间（毫秒）
+
+	ServiceInterval    int    `mapstructure:"service_interval"`    // 服务检查间隔（秒）
+	DiskInterval       int    `mapstructure:"disk_interval"`       // 磁盘使用收集间隔（秒）
+	NetworkInterval    int    `mapstructure:"network_interval"`    // 网络流量收集间隔（秒）
+	NoDataInterval     int    `mapstructure:"no_data_interval"`    // 无数据告警检查间隔（秒）
+	ComparisonInterval int    `mapstructure:"comparison_interval"` // 同比告警检查间隔（秒）
+	APIRuleInterval    int    `mapstructure:"api_rule_interval"`   // API延迟、错误率告警检查间隔（秒）
+	CleanupSchedule    string `mapstructure:"cleanup_schedule"`    // 数据清理的cron表达式（含秒字段）
+
+	JobRunHours  int `mapstructure:"job_run_hours"`  // 任务执行记录保留小时数
+	AuditLogDays int `mapstructure:"audit_log_days"` // 审计日志保留天数
+	JobJitter    int `mapstructure:"job_jitter"`     // 任务执行前的最大随机延迟（毫秒），0表示不延迟
+
+	ServiceCheckDays int `mapstructure:"service_check_days"` // 服务检查历史保留天数
+
+	AttributionGroups int `mapstructure:"attribution_groups"` // CPU、内存告警中列出的占用最高的容器/服务数量，0表示不列出
+
+	IgnoreInterfaces []string `mapstructure:"ignore_interfaces"` // 不记录接入、移除事件的网卡名前缀
+
+	Filesystems FilterConfig `mapstructure:"filesystems"` // 按挂载点、设备或文件系统类型过滤采集的磁盘
+	Interfaces  FilterConfig `mapstructure:"interfaces"`  // 按网卡名过滤采集的网卡
 }
 
 type ServicesConfig struct {
@@ -42,18 +151,36 @@ type ServicesConfig struct {
 	Storage  StorageServiceConfig  `mapstructure:"storage"`
 }
 
+// ServiceCheckOptions 内置服务的检查选项，与自定义服务检查的同名字段含义相同
+type ServiceCheckOptions struct {
+	Retries         int `mapstructure:"retries"`          // 检查失败后的重试次数，连续失败 retries+1 次才判定为异常
+	RetryInterval   int `mapstructure:"retry_interval"`   // 重试间隔（秒）
+	Timeout         int `mapstructure:"timeout"`          // 连接或请求的超时时间（秒）
+	LatencyWarning  int `mapstructure:"latency_warning"`  // 响应时间达到该值（毫秒）时状态为warning
+	LatencyCritical int `mapstructure:"latency_critical"` // 响应时间达到该值（毫秒）时状态为error
+}
+
+// LongestCheck 每次尝试都超时并重试时一轮检查的最长时间（秒）
+func (o ServiceCheckOptions) LongestCheck() int {
+	return (o.Retries+1)*o.Timeout + o.Retries*o.RetryInterval
+}
+
 type DatabaseServiceConfig struct {
 	Host     string `mapstructure:"host"`
 	Port     string `mapstructure:"port"`
 	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
 	Database string `mapstructure:"database"`
+
+	ServiceCheckOptions `mapstructure:",squash"`
 }
 
 type WebServiceConfig struct {
 	URL      string `mapstructure:"url"`
 	Port     string `mapstructure:"port"`
 	Protocol string `mapstructure:"protocol"`
+
+	ServiceCheckOptions `mapstructure:",squash"`
 }
 
 type MailServiceConfig struct {
@@ -61,6 +188,8 @@ type MailServiceConfig struct {
 	Port     string `mapstructure:"port"`
 	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
+
+	ServiceCheckOptions `mapstructure:",squash"`
 }
 
 type StorageServiceConfig struct {
@@ -68,49 +197,811 @@ type StorageServiceConfig struct {
 	AccessKey string `mapstructure:"access_key"`
 	SecretKey string `mapstructure:"secret_key"`
 	Bucket   string `mapstructure:"bucket"`
+
+	ServiceCheckOptions `mapstructure:",squash"`
+}
+
+// ReputationConfig 公网IP信誉/黑名单检查配置
+type ReputationConfig struct {
+	Enabled     bool     `mapstructure:"enabled"`       // 是否启用
+	Interval    int      `mapstructure:"interval"`      // 检查间隔（秒）
+	PublicIPURL string   `mapstructure:"public_ip_url"` // 公网IP探测地址
+	Blocklists  []string `mapstructure:"blocklists"`    // DNSBL黑名单列表
+}
+
+// CertificatesConfig TLS证书到期监控配置
+type CertificatesConfig struct {
+	Enabled     bool     `mapstructure:"enabled"`      // 是否启用
+	Interval    int      `mapstructure:"interval"`     // 检查间隔（秒）
+	WarnDays    int      `mapstructure:"warn_days"`    // 到期前多少天告警
+	ScanHosts   []string `mapstructure:"scan_hosts"`   // 自动发现时扫描的主机
+	ScanPorts   []int    `mapstructure:"scan_ports"`   // 自动发现时扫描的端口
+	ConfigPaths []string `mapstructure:"config_paths"` // 本地Web服务器配置目录（nginx/apache）
+}
+
+// PortsConfig 监听端口变化检测配置
+type PortsConfig struct {
+	Enabled         bool     `mapstructure:"enabled"`          // 是否启用
+	Interval        int      `mapstructure:"interval"`         // 检查间隔（秒）
+	UDP             bool     `mapstructure:"udp"`              // 是否跟踪UDP端口
+	IncludeLoopback bool     `mapstructure:"include_loopback"` // 是否跟踪只监听回环地址的端口
+	AlertNew        bool     `mapstructure:"alert_new"`        // 新端口开始监听时告警
+	Expected        []string `mapstructure:"expected"`         // 应一直监听的端口（如 22、tcp/443、udp/53），停止监听时告警
+	Ignore          []string `mapstructure:"ignore"`           // 不跟踪的端口，格式与 expected 相同
+}
+
+// AddressesConfig IP地址变化检测配置
+type AddressesConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`     // 是否启用
+	Interval   int      `mapstructure:"interval"`    // 检查间隔（秒）
+	PublicIP   bool     `mapstructure:"public_ip"`   // 是否跟踪公网IPv4和IPv6，变化时告警
+	StaticIPs  []string `mapstructure:"static_ips"`  // 应固定存在的本机地址，丢失时告警
+	Interfaces []string `mapstructure:"interfaces"`  // 只跟踪这些网卡，为空时跟踪所有非回环网卡
+
+	PublicIPv4URLs []string `mapstructure:"public_ipv4_urls"` // 公网IPv4探测地址，依次尝试，为空时使用 reputation.public_ip_url
+	PublicIPv6URLs []string `mapstructure:"public_ipv6_urls"` // 公网IPv6探测地址，依次尝试，为空时不跟踪IPv6
+	GeoURL         string   `mapstructure:"geo_url"`          // 地理位置查询地址，{ip} 替换为公网IP，为空时不查询
+}
+
+// WebSocketConfig WebSocket推送配置
+type WebSocketConfig struct {
+	Compression      bool `mapstructure:"compression"`       // 是否协商permessage-deflate压缩
+	CompressionLevel int  `mapstructure:"compression_level"` // 压缩级别（1-9，1最快）
+	Batch            bool `mapstructure:"batch"`             // 是否将积压的多条更新合并为一帧发送
+	SnapshotPoints   int  `mapstructure:"snapshot_points"`   // 连接时初始快照包含的历史数据点数
+
+	MaxConnections int    `mapstructure:"max_connections"`  // 最大连接数，0表示不限制
+	MaxPerIP       int    `mapstructure:"max_per_ip"`       // 单个IP最大连接数，0表示不限制
+	SendQueueSize  int    `mapstructure:"send_queue_size"`  // 每个客户端发送队列长度
+	OverflowPolicy string `mapstructure:"overflow_policy"`  // 发送队列满时的策略: disconnect, drop_newest, drop_oldest
+	MaxMessageRate int    `mapstructure:"max_message_rate"` // 每个客户端每秒最多发送的消息数，0表示不限制
+
+	MinInterval float64 `mapstructure:"min_interval"` // 客户端可协商的最小更新间隔（秒），0表示允许实时推送
+	ReplaySize  int     `mapstructure:"replay_size"`  // 每个主题缓存的最近消息数，供客户端请求补发，0表示不缓存
+
+	UnchangedInterval int `mapstructure:"unchanged_interval"` // 内容未变化的主题每隔多少秒重新推送一次，0表示内容不变时不再推送
+}
+
+// NotifyConfig 告警通知配置
+type NotifyConfig struct {
+	PublicURL       string          `mapstructure:"public_url"`       // 对外访问地址，用于生成操作回调链接
+	SigningSecret   string          `mapstructure:"signing_secret"`   // 回调链接签名密钥，为空时通知中不带操作按钮
+	ActionTTL       int             `mapstructure:"action_ttl"`       // 回调链接有效期（秒）
+	SilenceDuration int             `mapstructure:"silence_duration"` // 通过回调链接静默告警的时长（秒）
+	Cooldown        int             `mapstructure:"cooldown"`         // 同类型告警的通知冷却时间（秒），冷却期内的重复事件合并为一条通知，0表示不限制
+	Cooldowns       map[string]int  `mapstructure:"cooldowns"`        // 按告警类型覆盖冷却时间
+	Webhooks        []WebhookConfig `mapstructure:"webhooks"`         // 告警事件Webhook列表
+}
+
+// WebhookConfig 告警事件Webhook
+type WebhookConfig struct {
+	Name   string   `mapstructure:"name"`
+	URL    string   `mapstructure:"url"`
+	Format string   `mapstructure:"format"`  // 消息格式: generic, slack, telegram
+	ChatID string   `mapstructure:"chat_id"` // Telegram会话ID
+	Events []string `mapstructure:"events"`  // 订阅的事件: fired, resolved, acknowledged, silenced，为空表示全部
+}
+
+// SelfTestConfig 启动自检配置
+type SelfTestConfig struct {
+	Enabled     bool `mapstructure:"enabled"`       // 启动时是否执行自检
+	FailOnError bool `mapstructure:"fail_on_error"` // 自检失败时是否拒绝启动
+}
+
+// RateLimitConfig API和WebSocket按客户端IP限流配置（令牌桶）
+type RateLimitConfig struct {
+	Enabled            bool    `mapstructure:"enabled"`
+	RequestsPerSecond  float64 `mapstructure:"requests_per_second"`  // API每秒补充的令牌数
+	Burst              int     `mapstructure:"burst"`                // API令牌桶容量，即允许的突发请求数
+	WebSocketPerMinute int     `mapstructure:"websocket_per_minute"` // 每分钟允许新建的WebSocket连接数
+	WebSocketBurst     int     `mapstructure:"websocket_burst"`      // WebSocket连接突发数
+}
+
+// AccessConfig 访问控制配置，限制管理接口和WebSocket只能从受信任网段访问
+type AccessConfig struct {
+	AllowedNetworks []string `mapstructure:"allowed_networks"` // 允许访问的网段（CIDR或单个IP），为空时不限制
+	RestrictAll     bool     `mapstructure:"restrict_all"`     // 是否同时限制所有页面和接口
+	TrustedProxies  []string `mapstructure:"trusted_proxies"`  // 受信任的反向代理，只有来自这些地址的X-Forwarded-For才会被采用
+}
+
+// AccessLogConfig HTTP访问日志配置
+type AccessLogConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	MaxRows       int  `mapstructure:"max_rows"`       // 最多保留的记录数，超过时删除最早的记录
+	FlushInterval int  `mapstructure:"flush_interval"` // 批量写入数据库的间隔（秒）
+}
+
+// RollupConfig 每日汇总配置：按天预先计算指标的最小值、平均值、最大值，原始数据清理后仍可查询长期趋势
+type RollupConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	Interval      int  `mapstructure:"interval"`       // 汇总间隔（秒），每次重新计算今天和昨天
+	RetentionDays int  `mapstructure:"retention_days"` // 每日汇总保留天数
+}
+
+// ReportsConfig 摘要报告配置：定期汇总CPU、内存、磁盘增长、流量、告警和服务可用率，通过通知Webhook发送
+type ReportsConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Period   string `mapstructure:"period"`   // 报告周期: daily（每天发送最近24小时）, weekly（每周发送最近7天）
+	Hour     int    `mapstructure:"hour"`     // 发送时间（0~23点）
+	Weekday  int    `mapstructure:"weekday"`  // 每周报告的发送日（0为周日）
+	Template string `mapstructure:"template"` // 自定义报告模板文件（Go text/template），为空时使用内置模板
+}
+
+// IngestConfig 数据接入配置：接收Alertmanager、Grafana、Uptime Kuma的告警Webhook，以及远程主机推送的指标
+type IngestConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	Token        string `mapstructure:"token"`         // 接入令牌，请求需带 Authorization: Bearer <token> 或 ?token=；为空时不校验
+	OfflineAfter int    `mapstructure:"offline_after"` // 已登记的远程主机超过多少秒未推送数据时触发主机离线告警，0表示不检测
+	MaxLateness  int    `mapstructure:"max_lateness"`  // 推送数据的时间戳最多允许早于接收时间多少秒，更早的记录被丢弃
+}
+
+// TerminalConfig Web终端配置：管理员通过WebSocket打开本机的终端或SSH到指定主机，会话记录在审计日志中
+type TerminalConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`
+	Username    string            `mapstructure:"username"`     // 管理员用户名
+	Password    string            `mapstructure:"password"`     // 管理员密码，建议用 enc: 加密
+	Mode        string            `mapstructure:"mode"`         // pty: 本机终端（仅Linux）；ssh: SSH到 ssh.address
+	Shell       string            `mapstructure:"shell"`        // pty模式启动的shell
+	IdleTimeout int               `mapstructure:"idle_timeout"` // 超过多少秒没有键盘输入时断开会话
+	MaxSessions int               `mapstructure:"max_sessions"` // 同时打开的终端会话数上限
+	RecordInput bool              `mapstructure:"record_input"` // 是否将键盘输入按行记录到审计日志，关闭回显的密码输入不记录
+	SSH         TerminalSSHConfig `mapstructure:"ssh"`
+}
+
+// TerminalSSHConfig Web终端的SSH目标
+type TerminalSSHConfig struct {
+	Address    string `mapstructure:"address"`     // 主机地址，如 10.0.0.5:22
+	User       string `mapstructure:"user"`        // 登录用户
+	Password   string `mapstructure:"password"`    // 登录密码，建议用 enc: 加密；与私钥至少配置一项
+	PrivateKey string `mapstructure:"private_key"` // 私钥文件路径
+	KnownHosts string `mapstructure:"known_hosts"` // known_hosts 文件，为空时不校验主机密钥
+}
+
+// StatusPageConfig 公开状态页配置：无需认证的只读页面 /status，展示设为公开的服务的可用率和故障记录
+type StatusPageConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	Title       string `mapstructure:"title"`        // 页面标题
+	HistoryDays int    `mapstructure:"history_days"` // 展示最近多少天的每日可用率和故障记录
+}
+
+// I18nConfig 多语言配置：告警、系统日志等服务端生成的消息使用 locale，API消息优先使用请求的 Accept-Language；
+// timezone 为显示时区，数据库中的时间统一以UTC保存，按天统计、报告和定时任务按显示时区计算
+type I18nConfig struct {
+	Locale   string `mapstructure:"locale"`   // 默认语言: zh-CN, en-US
+	Timezone string `mapstructure:"timezone"` // IANA时区名，如 Asia/Shanghai，为空时使用系统时区
+
+	location *time.Location // 校验时加载的显示时区
+}
+
+// Location 显示时区，未配置 timezone 时为系统时区
+func (i *I18nConfig) Location() *time.Location {
+	if i.location == nil {
+		return time.Local
+	}
+	return i.location
+}
+
+// JournalConfig systemd journal采集（仅Linux）：定期把OOM、磁盘错误、服务崩溃等系统事件写入系统日志
+type JournalConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`
+	Interval   int      `mapstructure:"interval"`    // 采集间隔（秒）
+	Priority   string   `mapstructure:"priority"`    // 采集该级别及更严重的记录: emerg, alert, crit, err, warning, notice, info, debug
+	Units      []string `mapstructure:"units"`       // 只采集这些systemd单元（如 nginx、docker.service），为空时采集全部
+	Kernel     bool     `mapstructure:"kernel"`      // 配置了 units 时是否仍采集内核消息（OOM、磁盘I/O错误等）
+	MaxEntries int      `mapstructure:"max_entries"` // 每次最多读取的记录数
+	Command    string   `mapstructure:"command"`     // journalctl 的路径
+}
+
+// SSHAuthConfig SSH登录监控：解析sshd的认证日志记录登录成功和失败，检测暴力破解和来自新地址的登录
+type SSHAuthConfig struct {
+	Enabled     bool     `mapstructure:"enabled"`
+	Interval    int      `mapstructure:"interval"`     // 读取间隔（秒）
+	Source      string   `mapstructure:"source"`       // auto, file, journal；auto 在日志文件存在时读取文件，否则读取journal
+	LogFiles    []string `mapstructure:"log_files"`    // 认证日志文件，使用第一个存在的文件
+	MaxFailures int      `mapstructure:"max_failures"` // 同一地址在 window 秒内登录失败达到该次数时告警，0表示不检测
+	Window      int      `mapstructure:"window"`       // 暴力破解检测的时间窗口（秒）
+	AlertNewIP  bool     `mapstructure:"alert_new_ip"` // 从未成功登录过的地址登录成功时告警
+}
+
+// Fail2banConfig fail2ban集成：通过 fail2ban-client 读取各jail的封禁状态，记录封禁历史
+type Fail2banConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Interval int    `mapstructure:"interval"` // 同步封禁状态的间隔（秒）
+	Command  string `mapstructure:"command"`  // fail2ban-client 的路径
+	Socket   string `mapstructure:"socket"`   // fail2ban服务的套接字，为空时使用 fail2ban-client 的默认值
+}
+
+// UpdatesConfig 软件包更新检查（仅Linux）：统计apt/dnf/yum可更新的软件包和其中的安全更新，检测是否需要重启
+type UpdatesConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	Interval      int    `mapstructure:"interval"`       // 检查间隔（秒）
+	Manager       string `mapstructure:"manager"`        // auto, apt, dnf, yum；auto 按 apt、dnf、yum 的顺序使用第一个存在的
+	AlertSecurity bool   `mapstructure:"alert_security"` // 有安全更新时告警
+	AlertReboot   bool   `mapstructure:"alert_reboot"`   // 需要重启时告警
+	AlertPending  int    `mapstructure:"alert_pending"`  // 可更新的软件包达到该数量时告警，0表示不告警
+}
+
+// ClockConfig 时钟偏差检测：定期向NTP服务器查询时间，本机时钟偏差过大时告警
+type ClockConfig struct {
+	Enabled   bool     `mapstructure:"enabled"`
+	Interval  int      `mapstructure:"interval"`  // 检查间隔（秒）
+	Servers   []string `mapstructure:"servers"`   // NTP服务器（主机名或 主机:端口），依次尝试直到成功
+	Timeout   int      `mapstructure:"timeout"`   // 单个服务器的超时（秒）
+	Threshold float64  `mapstructure:"threshold"` // 偏差超过该秒数时告警（warning）
+	Critical  float64  `mapstructure:"critical"`  // 偏差超过该秒数时告警升级为error，0表示不升级
+}
+
+// SoCConfig SoC温度和降频检测（仅Linux）：读取thermal zone温度，树莓派上通过 vcgencmd 或固件接口读取欠压和降频标志
+type SoCConfig struct {
+	Enabled          bool    `mapstructure:"enabled"`
+	Interval         int     `mapstructure:"interval"`          // 采集间隔（秒）
+	Vcgencmd         string  `mapstructure:"vcgencmd"`          // vcgencmd 的路径，不存在时改为读取固件接口
+	AlertTemperature float64 `mapstructure:"alert_temperature"` // 温度达到该值（°C）时告警，0表示不告警
+	AlertThrottling  bool    `mapstructure:"alert_throttling"`  // 当前处于欠压、降频或温度限制状态时告警
+}
+
+// UPSConfig UPS监控：通过NUT（upsd）或apcupsd的网络接口读取供电状态、电量、剩余续航和输入电压
+type UPSConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	Interval       int    `mapstructure:"interval"`         // 采集间隔（秒）
+	Driver         string `mapstructure:"driver"`           // nut 或 apcupsd
+	Address        string `mapstructure:"address"`          // upsd 或 apcupsd NIS 的地址，为空时使用 127.0.0.1 上的默认端口
+	Name           string `mapstructure:"name"`             // NUT中的UPS名称，为空时使用 upsd 列出的第一个
+	Timeout        int    `mapstructure:"timeout"`          // 连接和读取超时（秒）
+	AlertOnBattery bool   `mapstructure:"alert_on_battery"` // 切换到电池供电时告警
+	AlertRuntime   int    `mapstructure:"alert_runtime"`    // 电池供电且剩余续航低于该秒数时告警（error），0表示不告警
+}
+
+// IPMIConfig IPMI/BMC传感器：通过 ipmitool 或Redfish读取风扇转速、电源状态、机箱温度等
+type IPMIConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Interval  int    `mapstructure:"interval"`   // 采集间隔（秒）
+	Source    string `mapstructure:"source"`     // ipmitool 或 redfish
+	Command   string `mapstructure:"command"`    // ipmitool 的路径
+	Host      string `mapstructure:"host"`       // ipmitool 连接的远程BMC地址，为空时通过本机的 /dev/ipmi0 读取
+	Interface string `mapstructure:"interface"`  // 连接远程BMC的接口，通常为 lanplus
+	URL       string `mapstructure:"url"`        // Redfish服务的地址，如 https://10.0.0.5
+	Username  string `mapstructure:"username"`   // 远程BMC或Redfish的用户名
+	Password  string `mapstructure:"password"`   // 密码，建议用 enc: 加密
+	Insecure  bool   `mapstructure:"insecure"`   // 不校验Redfish的TLS证书（BMC通常使用自签名证书）
+	Timeout   int    `mapstructure:"timeout"`    // 单次采集的超时（秒）
+	AlertFans bool   `mapstructure:"alert_fans"` // 风扇故障时告警
+	AlertPSU  bool   `mapstructure:"alert_psu"`  // 电源故障（含断电、预测性故障）时告警
+}
+
+// RAIDConfig mdadm软RAID健康检测（仅Linux）：解析 /proc/mdstat，记录阵列状态和重建进度
+type RAIDConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	Interval      int  `mapstructure:"interval"`       // 检查间隔（秒）
+	AlertDegraded bool `mapstructure:"alert_degraded"` // 阵列降级、有故障成员或未激活时告警（error）
+}
+
+// ZFSConfig ZFS存储池检测：通过 zpool list/status 读取健康状态、容量、碎片率和scrub状态，未安装ZFS时跳过
+type ZFSConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	Interval      int    `mapstructure:"interval"`       // 检查间隔（秒）
+	Command       string `mapstructure:"command"`        // zpool 的路径
+	AlertHealth   bool   `mapstructure:"alert_health"`   // 存储池不是ONLINE（如DEGRADED、FAULTED）时告警
+	AlertCapacity int    `mapstructure:"alert_capacity"` // 已用容量达到该百分比时告警，0表示不告警
+}
+
+// ForecastConfig 磁盘写满预测：按每日汇总拟合各挂载点使用率的趋势，估算还有多少天写满
+type ForecastConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	Interval    int    `mapstructure:"interval"`     // 检查间隔（秒）
+	Method      string `mapstructure:"method"`       // linear（最小二乘直线）或 holt（Holt双指数平滑，更侧重近期变化）
+	WindowDays  int    `mapstructure:"window_days"`  // 参与拟合的最近天数
+	MinDays     int    `mapstructure:"min_days"`     // 至少有多少天的数据才预测
+	HorizonDays int    `mapstructure:"horizon_days"` // 预计在该天数内写满时告警，0表示不告警
+}
+
+// AnomalyConfig 异常检测：最近一个窗口的均值偏离基线均值超过若干倍标准差时记录info级告警，用于发现阈值以下的异常
+type AnomalyConfig struct {
+	Enabled       bool     `mapstructure:"enabled"`
+	Interval      int      `mapstructure:"interval"`       // 检查间隔（秒）
+	Metrics       []string `mapstructure:"metrics"`        // 检测的指标，格式与同比规则相同，可带标签，如 network_traffic.download_speed{interface=eth0}
+	Window        int      `mapstructure:"window"`         // 最近窗口（秒）
+	BaselineHours int      `mapstructure:"baseline_hours"` // 基线的小时数（不含最近窗口），不能超过 monitor.history_hours
+	Threshold     float64  `mapstructure:"threshold"`      // 偏离超过多少倍标准差时标记为异常
+	MinSamples    int      `mapstructure:"min_samples"`    // 基线至少需要的样本数
+}
+
+// CapacityConfig 容量规划报告：按每日汇总拟合磁盘、内存和带宽的增长趋势，预测达到上限的时间并给出扩容建议
+type CapacityConfig struct {
+	WindowDays  int     `mapstructure:"window_days"`  // 默认参与拟合的天数
+	HorizonDays int     `mapstructure:"horizon_days"` // 预计在该天数内达到上限时给出扩容建议
+	DiskLimit   float64 `mapstructure:"disk_limit"`   // 磁盘使用率上限（%）
+	MemoryLimit float64 `mapstructure:"memory_limit"` // 内存使用率上限（%）
+	Bandwidth   float64 `mapstructure:"bandwidth"`    // 网卡带宽（Mbps），与每日峰值速率比较；0表示不预测带宽何时用满
+}
+
+// DebugConfig 诊断配置：在 /debug/pprof 提供Go运行时性能分析，排查内存增长等问题时无需重新编译
+type DebugConfig struct {
+	Pprof    bool   `mapstructure:"pprof"`    // 是否启用 /debug/pprof
+	Username string `mapstructure:"username"` // 访问诊断接口的管理员用户名
+	Password string `mapstructure:"password"` // 管理员密码，建议用 enc: 加密
+
+	ErrorDetail bool `mapstructure:"error_detail"` // 错误响应中是否返回内部错误详情（如数据库错误），可能泄露内部信息，仅用于排查问题
+}
+
+// ActionsConfig 定时维护操作配置：只允许执行白名单中的命令
+type ActionsConfig struct {
+	Enabled          bool     `mapstructure:"enabled"`
+	AllowedCommands  []string `mapstructure:"allowed_commands"`   // 允许执行的完整命令行（可执行文件绝对路径和参数模式）
+	Timeout          int      `mapstructure:"timeout"`            // 默认超时时间（秒）
+	MaxOutput        int      `mapstructure:"max_output"`         // 每次执行保存的最大输出字节数
+	RunRetentionDays int      `mapstructure:"run_retention_days"` // 执行记录保留天数
+}
+
+// MinimalConfig 低资源占用模式：数据只保存在内存中，不写磁盘，只保留最近的数据
+type MinimalConfig struct {
+	Enabled        bool `mapstructure:"enabled"`
+	HistoryMinutes int  `mapstructure:"history_minutes"` // 内存中保留的数据时长（分钟）
 }
 
 var AppConfig Config
 
+var (
+	configFile string // 命令行指定的配置文件，无法读取时不回退到默认值
+
+	reloadMu    sync.Mutex
+	reloadHooks []func()
+	overrides   = make(map[string]interface{}) // 运行时设置覆盖的配置项
+
+	loaded atomic.Bool // 配置已成功加载并通过校验
+)
+
 func LoadConfig() error {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath("./config")
 	viper.AddConfigPath(".")
+	if configFile != "" {
+		viper.SetConfigFile(configFile)
+	}
 
 	// 设置默认值
-	setDefaults()
+	setDefaults(viper.GetViper())
+
+	// 环境变量覆盖配置文件，如 MONITOR_SERVER_PORT 对应 server.port
+	viper.SetEnvPrefix("MONITOR")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err != nil {
+		if configFile != "" {
+			return err
+		}
 		log.Printf("Warning: Could not read config file: %v", err)
 	}
 
 	if err := viper.Unmarshal(&AppConfig); err != nil {
 		return err
 	}
+	if err := AppConfig.decryptSecrets(); err != nil {
+		return err
+	}
+
+	if err := AppConfig.Validate(); err != nil {
+		return err
+	}
+
+	loaded.Store(true)
+	return nil
+}
+
+// Loaded 配置是否已成功加载，用于就绪检查
+func Loaded() bool {
+	return loaded.Load()
+}
+
+// SetConfigFile 指定配置文件路径，为空时按默认路径查找 config.yaml
+func SetConfigFile(path string) {
+	configFile = path
+}
+
+// FileUsed 当前使用的配置文件路径，没有找到配置文件时为空
+func FileUsed() string {
+	return viper.ConfigFileUsed()
+}
+
+// Override 以命令行参数覆盖配置项，优先级高于环境变量和配置文件，重新加载后依然有效；value为空时忽略
+func Override(key, value string) {
+	if value != "" {
+		viper.Set(key, value)
+	}
+}
+
+// OnReload 注册配置重新加载后的回调，如重新注册定时任务
+func OnReload(hook func()) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadHooks = append(reloadHooks, hook)
+}
+
+// Reload 重新读取配置文件，校验通过后替换当前配置并通知回调；校验失败时保留原配置
+func Reload() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+	return apply()
+}
+
+// ApplyOverrides 以运行时设置覆盖配置项，优先级高于配置文件，重新加载后依然有效；
+// 值为nil时取消覆盖，恢复配置文件中的值。校验失败时保留原配置
+func ApplyOverrides(values map[string]interface{}) error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	previous := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		previous[key] = overrides[key]
+		viper.Set(key, value)
+	}
+
+	if err := apply(); err != nil {
+		for key, value := range previous {
+			viper.Set(key, value)
+		}
+		return err
+	}
+
+	for key, value := range values {
+		if value == nil {
+			delete(overrides, key)
+		} else {
+			overrides[key] = value
+		}
+	}
+	return nil
+}
+
+// Get 获取配置项当前生效的值
+func Get(key string) interface{} {
+	return viper.Get(key)
+}
+
+// IsOverridden 判断配置项是否被运行时设置覆盖
+func IsOverridden(key string) bool {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	_, ok := overrides[key]
+	return ok
+}
+
+// apply 按viper中的当前配置重建AppConfig，校验通过后替换并通知回调，调用方需持有reloadMu
+func apply() error {
+	var newConfig Config
+	if err := viper.Unmarshal(&newConfig); err != nil {
+		return err
+	}
+	if err := newConfig.decryptSecrets(); err != nil {
+		return err
+	}
+	if err := newConfig.Validate(); err != nil {
+		return err
+	}
 
+	if newConfig.Server.Host != AppConfig.Server.Host || newConfig.Server.Port != AppConfig.Server.Port ||
+		newConfig.Database.Database != AppConfig.Database.Database {
+		log.Println("Warning: server address and database settings take effect after restart")
+	}
+
+	AppConfig = newConfig
+	for _, hook := range reloadHooks {
+		hook()
+	}
+
+	log.Println("Configuration reloaded")
 	return nil
 }
 
-func setDefaults() {
-	viper.SetDefault("server.port", "8080")
-	viper.SetDefault("server.host", "0.0.0.0")
-	viper.SetDefault("server.log_level", "info")
+// WatchConfig 监听配置文件变化并自动重新加载
+func WatchConfig() {
+	var timer *time.Timer
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		// 编辑器保存时可能连续触发多次事件，合并为一次重新加载
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(time.Second, func() {
+			log.Printf("Config file changed: %s", e.Name)
+			if err := Reload(); err != nil {
+				log.Printf("Error reloading config, keeping previous settings: %v", err)
+			}
+		})
+	})
+	viper.WatchConfig()
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.port", "8080")
+	v.SetDefault("server.host", "0.0.0.0")
+	v.SetDefault("server.log_level", "info")
+	v.SetDefault("server.watch_config", true)
+	v.SetDefault("server.pid_file", "")
+	v.SetDefault("server.tls.enabled", false)
+	v.SetDefault("server.tls.cert_file", "")
+	v.SetDefault("server.tls.key_file", "")
+	v.SetDefault("server.tls.domains", []string{})
+	v.SetDefault("server.tls.email", "")
+	v.SetDefault("server.tls.cache_dir", "certs")
+	v.SetDefault("server.tls.redirect_http", false)
+	v.SetDefault("server.tls.http_port", "80")
+	v.SetDefault("server.compression.enabled", true)
+	v.SetDefault("server.compression.min_size", 1024)
+	v.SetDefault("server.compression.brotli", true)
+	v.SetDefault("server.compression.gzip_level", 6)
+	v.SetDefault("server.compression.brotli_level", 4)
+	v.SetDefault("server.etag.enabled", true)
+	v.SetDefault("server.etag.max_size", 4<<20)
 	
-	viper.SetDefault("database.driver", "sqlite")
-	viper.SetDefault("database.database", "monitor.db")
+	v.SetDefault("database.driver", "sqlite")
+	v.SetDefault("database.database", "monitor.db")
+	v.SetDefault("database.integrity_interval", 86400)
+	v.SetDefault("database.auto_recover", true)
 	
-	viper.SetDefault("monitor.interval", 5)
-	viper.SetDefault("monitor.history_hours", 24)
-	viper.SetDefault("monitor.alert_cpu", 80)
-	viper.SetDefault("monitor.alert_memory", 80)
-	viper.SetDefault("monitor.alert_disk", 90)
+	v.SetDefault("monitor.interval", 5)
+	v.SetDefault("monitor.history_hours", 24)
+	v.SetDefault("monitor.alert_cpu", 80)
+	v.SetDefault("monitor.alert_memory", 80)
+	v.SetDefault("monitor.alert_memory_basis", "used")
+	v.SetDefault("monitor.alert_disk", 90)
+	v.SetDefault("monitor.catch_up_policy", "run_once")
+	v.SetDefault("monitor.gap_threshold", 60)
+	v.SetDefault("monitor.cleanup_batch_size", 1000)
+	v.SetDefault("monitor.cleanup_batch_pause", 100)
+	v.SetDefault("monitor.service_interval", 30)
+	v.SetDefault("monitor.disk_interval", 300)
+	v.SetDefault("monitor.network_interval", 30)
+	v.SetDefault("monitor.no_data_interval", 30)
+	v.SetDefault("monitor.comparison_interval", 300)
+	v.SetDefault("monitor.api_rule_interval", 60)
+	v.SetDefault("monitor.cleanup_schedule", "0 0 2 * * *")
+	v.SetDefault("monitor.job_run_hours", 72)
+	v.SetDefault("monitor.audit_log_days", 90)
+	v.SetDefault("monitor.service_check_days", 30)
+	v.SetDefault("monitor.job_jitter", 2000)
+	v.SetDefault("monitor.attribution_groups", 3)
+	v.SetDefault("monitor.ignore_interfaces", []string{"veth"})
+	v.SetDefault("monitor.filesystems.include", []string{})
+	v.SetDefault("monitor.filesystems.exclude", []string{"squashfs", "iso9660", "udf", "/dev/loop*", "/snap/*", "/var/lib/docker/*"})
+	v.SetDefault("monitor.interfaces.include", []string{})
+	v.SetDefault("monitor.interfaces.exclude", []string{"lo", "veth*", "br-*", "docker*", "virbr*"})
 	
-	viper.SetDefault("services.database.host", "localhost")
-	viper.SetDefault("services.database.port", "3306")
-	viper.SetDefault("services.web.url", "localhost")
-	viper.SetDefault("services.web.port", "80")
-	viper.SetDefault("services.web.protocol", "http")
-	viper.SetDefault("services.mail.host", "localhost")
-	viper.SetDefault("services.mail.port", "25")
+	v.SetDefault("services.database.host", "localhost")
+	v.SetDefault("services.database.port", "3306")
+	v.SetDefault("services.web.url", "localhost")
+	v.SetDefault("services.web.port", "80")
+	v.SetDefault("services.web.protocol", "http")
+	v.SetDefault("services.mail.host", "localhost")
+	v.SetDefault("services.mail.port", "25")
+	// TCP连接类检查与Web检查的默认超时和响应时间分级
+	for _, service := range []string{"database", "mail", "storage"} {
+		v.SetDefault("services."+service+".timeout", 5)
+		v.SetDefault("services."+service+".latency_warning", 100)
+		v.SetDefault("services."+service+".latency_critical", 500)
+	}
+	v.SetDefault("services.web.timeout", 10)
+	v.SetDefault("services.web.latency_warning", 200)
+	v.SetDefault("services.web.latency_critical", 1000)
+
+	v.SetDefault("reputation.enabled", false)
+	v.SetDefault("reputation.interval", 3600)
+	v.SetDefault("reputation.public_ip_url", "https://api.ipify.org")
+	v.SetDefault("reputation.blocklists", []string{
+		"zen.spamhaus.org",
+		"bl.spamcop.net",
+		"b.barracudacentral.org",
+		"dnsbl.sorbs.net",
+	})
+
+	v.SetDefault("certificates.enabled", false)
+	v.SetDefault("certificates.interval", 21600)
+	v.SetDefault("certificates.warn_days", 14)
+	v.SetDefault("certificates.scan_hosts", []string{"localhost"})
+	v.SetDefault("certificates.scan_ports", []int{443, 8443, 465, 993, 995})
+	v.SetDefault("certificates.config_paths", []string{"/etc/nginx", "/etc/apache2", "/etc/httpd"})
+
+	v.SetDefault("ports.enabled", true)
+	v.SetDefault("ports.interval", 60)
+	v.SetDefault("ports.udp", false)
+	v.SetDefault("ports.include_loopback", false)
+	v.SetDefault("ports.alert_new", true)
+	v.SetDefault("ports.expected", []string{})
+	v.SetDefault("ports.ignore", []string{})
+
+	v.SetDefault("ssh_auth.enabled", false)
+	v.SetDefault("ssh_auth.interval", 60)
+	v.SetDefault("ssh_auth.source", "auto")
+	v.SetDefault("ssh_auth.log_files", []string{"/var/log/auth.log", "/var/log/secure"})
+	v.SetDefault("ssh_auth.max_failures", 10)
+	v.SetDefault("ssh_auth.window", 300)
+	v.SetDefault("ssh_auth.alert_new_ip", true)
+
+	v.SetDefault("fail2ban.enabled", false)
+	v.SetDefault("fail2ban.interval", 60)
+	v.SetDefault("fail2ban.command", "fail2ban-client")
+	v.SetDefault("fail2ban.socket", "")
+
+	v.SetDefault("updates.enabled", false)
+	v.SetDefault("updates.interval", 86400)
+	v.SetDefault("updates.manager", "auto")
+	v.SetDefault("updates.alert_security", false)
+	v.SetDefault("updates.alert_reboot", false)
+	v.SetDefault("updates.alert_pending", 0)
+
+	v.SetDefault("clock.enabled", false)
+	v.SetDefault("clock.interval", 3600)
+	v.SetDefault("clock.servers", []string{"pool.ntp.org", "ntp.aliyun.com", "time.cloudflare.com"})
+	v.SetDefault("clock.timeout", 5)
+	v.SetDefault("clock.threshold", 1.0)
+	v.SetDefault("clock.critical", 30.0)
+
+	v.SetDefault("soc.enabled", true)
+	v.SetDefault("soc.interval", 60)
+	v.SetDefault("soc.vcgencmd", "vcgencmd")
+	v.SetDefault("soc.alert_temperature", 80.0)
+	v.SetDefault("soc.alert_throttling", true)
+
+	v.SetDefault("ups.enabled", false)
+	v.SetDefault("ups.interval", 30)
+	v.SetDefault("ups.driver", "nut")
+	v.SetDefault("ups.address", "")
+	v.SetDefault("ups.name", "")
+	v.SetDefault("ups.timeout", 5)
+	v.SetDefault("ups.alert_on_battery", true)
+	v.SetDefault("ups.alert_runtime", 300)
+
+	v.SetDefault("ipmi.enabled", false)
+	v.SetDefault("ipmi.interval", 60)
+	v.SetDefault("ipmi.source", "ipmitool")
+	v.SetDefault("ipmi.command", "ipmitool")
+	v.SetDefault("ipmi.host", "")
+	v.SetDefault("ipmi.interface", "lanplus")
+	v.SetDefault("ipmi.url", "")
+	v.SetDefault("ipmi.username", "")
+	v.SetDefault("ipmi.password", "")
+	v.SetDefault("ipmi.insecure", false)
+	v.SetDefault("ipmi.timeout", 30)
+	v.SetDefault("ipmi.alert_fans", true)
+	v.SetDefault("ipmi.alert_psu", true)
+
+	v.SetDefault("raid.enabled", true)
+	v.SetDefault("raid.interval", 60)
+	v.SetDefault("raid.alert_degraded", true)
+
+	v.SetDefault("zfs.enabled", true)
+	v.SetDefault("zfs.interval", 300)
+	v.SetDefault("zfs.command", "zpool")
+	v.SetDefault("zfs.alert_health", true)
+	v.SetDefault("zfs.alert_capacity", 80)
+
+	v.SetDefault("forecast.enabled", true)
+	v.SetDefault("forecast.interval", 3600)
+	v.SetDefault("forecast.method", "linear")
+	v.SetDefault("forecast.window_days", 30)
+	v.SetDefault("forecast.min_days", 3)
+	v.SetDefault("forecast.horizon_days", 14)
+
+	v.SetDefault("anomaly.enabled", false)
+	v.SetDefault("anomaly.interval", 300)
+	v.SetDefault("anomaly.metrics", []string{"system_metrics.cpu", "system_metrics.memory", "system_metrics.download", "system_metrics.upload"})
+	v.SetDefault("anomaly.window", 600)
+	v.SetDefault("anomaly.baseline_hours", 24)
+	v.SetDefault("anomaly.threshold", 3.0)
+	v.SetDefault("anomaly.min_samples", 30)
+
+	v.SetDefault("capacity.window_days", 90)
+	v.SetDefault("capacity.horizon_days", 90)
+	v.SetDefault("capacity.disk_limit", 90.0)
+	v.SetDefault("capacity.memory_limit", 90.0)
+	v.SetDefault("capacity.bandwidth", 0.0)
+	v.SetDefault("addresses.enabled", true)
+	v.SetDefault("addresses.interval", 300)
+	v.SetDefault("addresses.public_ip", false)
+	v.SetDefault("addresses.static_ips", []string{})
+	v.SetDefault("addresses.interfaces", []string{})
+	v.SetDefault("addresses.public_ipv4_urls", []string{})
+	v.SetDefault("addresses.public_ipv6_urls", []string{"https://api6.ipify.org", "https://ipv6.icanhazip.com"})
+	v.SetDefault("addresses.geo_url", "https://ipinfo.io/{ip}/json")
+
+	v.SetDefault("websocket.compression", true)
+	v.SetDefault("websocket.compression_level", 1)
+	v.SetDefault("websocket.unchanged_interval", 60)
+	v.SetDefault("websocket.batch", true)
+	v.SetDefault("websocket.snapshot_points", 50)
+	v.SetDefault("websocket.max_connections", 200)
+	v.SetDefault("websocket.max_per_ip", 10)
+	v.SetDefault("websocket.send_queue_size", 256)
+	v.SetDefault("websocket.overflow_policy", "disconnect")
+	v.SetDefault("websocket.max_message_rate", 10)
+	v.SetDefault("websocket.min_interval", 0)
+	v.SetDefault("websocket.replay_size", 100)
+
+	v.SetDefault("notify.action_ttl", 86400)
+	v.SetDefault("notify.silence_duration", 3600)
+	v.SetDefault("notify.cooldown", 300)
+
+	v.SetDefault("selftest.enabled", true)
+	v.SetDefault("selftest.fail_on_error", false)
+
+	v.SetDefault("secrets.key_file", "")
+	v.SetDefault("secrets.passphrase", "")
+
+	v.SetDefault("rate_limit.enabled", true)
+	v.SetDefault("rate_limit.requests_per_second", 20)
+	v.SetDefault("rate_limit.burst", 60)
+	v.SetDefault("rate_limit.websocket_per_minute", 10)
+	v.SetDefault("rate_limit.websocket_burst", 5)
+
+	v.SetDefault("access.allowed_networks", []string{})
+	v.SetDefault("access.restrict_all", false)
+	v.SetDefault("access.trusted_proxies", []string{})
+
+	v.SetDefault("access_log.enabled", true)
+	v.SetDefault("access_log.max_rows", 100000)
+	v.SetDefault("access_log.flush_interval", 5)
+
+	v.SetDefault("rollup.enabled", true)
+	v.SetDefault("rollup.interval", 3600)
+	v.SetDefault("rollup.retention_days", 730)
+
+	v.SetDefault("debug.pprof", false)
+	v.SetDefault("debug.username", "admin")
+	v.SetDefault("debug.password", "")
+	v.SetDefault("debug.error_detail", false)
+
+	v.SetDefault("actions.enabled", false)
+	v.SetDefault("actions.allowed_commands", []string{})
+	v.SetDefault("actions.timeout", 300)
+	v.SetDefault("actions.max_output", 65536)
+	v.SetDefault("actions.run_retention_days", 30)
+
+	// 摘要报告默认值
+	v.SetDefault("reports.enabled", false)
+	v.SetDefault("reports.period", "daily")
+	v.SetDefault("reports.hour", 8)
+	v.SetDefault("reports.weekday", 1)
+	v.SetDefault("reports.template", "")
+
+	v.SetDefault("ingest.enabled", false)
+	v.SetDefault("ingest.token", "")
+	v.SetDefault("ingest.offline_after", 300)
+	v.SetDefault("ingest.max_lateness", 3600)
+
+	v.SetDefault("terminal.enabled", false)
+	v.SetDefault("terminal.username", "admin")
+	v.SetDefault("terminal.password", "")
+	v.SetDefault("terminal.mode", "pty")
+	v.SetDefault("terminal.shell", "/bin/bash")
+	v.SetDefault("terminal.idle_timeout", 600)
+	v.SetDefault("terminal.max_sessions", 2)
+	v.SetDefault("terminal.record_input", false)
+	v.SetDefault("terminal.ssh.address", "127.0.0.1:22")
+	v.SetDefault("terminal.ssh.user", "")
+	v.SetDefault("terminal.ssh.password", "")
+	v.SetDefault("terminal.ssh.private_key", "")
+	v.SetDefault("terminal.ssh.known_hosts", "")
+
+	v.SetDefault("status_page.enabled", false)
+	v.SetDefault("status_page.title", "服务状态")
+	v.SetDefault("status_page.history_days", 30)
+
+	v.SetDefault("i18n.locale", "zh-CN")
+	v.SetDefault("i18n.timezone", "")
+
+	v.SetDefault("journal.enabled", false)
+	v.SetDefault("journal.interval", 60)
+	v.SetDefault("journal.priority", "warning")
+	v.SetDefault("journal.units", []string{})
+	v.SetDefault("journal.kernel", true)
+	v.SetDefault("journal.max_entries", 1000)
+	v.SetDefault("journal.command", "journalctl")
+
+	v.SetDefault("minimal.enabled", false)
+	v.SetDefault("minimal.history_minutes", 15)
 } 
\ No newline at end of file
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// FilterConfig 采集对象的包含/排除过滤：模式为通配符（* 匹配任意字符，包括 /；? 匹配单个字符），
+// 以 re: 开头时为正则表达式；include 不为空时只采集匹配其中任一模式的对象，再排除匹配 exclude 的对象
+type FilterConfig struct {
+	Include []string `mapstructure:"include"`
+	Exclude []string `mapstructure:"exclude"`
+}
+
+// 已编译的模式，配置重新加载后模式不变时无需重新编译
+var (
+	patternMu sync.Mutex
+	patterns  = make(map[string]*regexp.Regexp)
+)
+
+// compilePattern 将通配符或 re: 开头的正则表达式编译为正则
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patternMu.Lock()
+	defer patternMu.Unlock()
+
+	if re, ok := patterns[pattern]; ok {
+		return re, nil
+	}
+
+	expr := ""
+	if strings.HasPrefix(pattern, "re:") {
+		expr = strings.TrimPrefix(pattern, "re:")
+	} else {
+		expr = regexp.QuoteMeta(pattern)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		expr = "^" + expr + "$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	patterns[pattern] = re
+	return re, nil
+}
+
+// matchAny 判断任一值是否匹配任一模式，无效的模式不匹配（启动时已校验）
+func matchAny(patterns []string, values []string) bool {
+	for _, pattern := range patterns {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			continue
+		}
+		for _, value := range values {
+			if value != "" && re.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Allows 判断对象是否应被采集；values 为对象的各个属性（如文件系统的挂载点、设备和类型），任一属性匹配即视为匹配
+func (f FilterConfig) Allows(values ...string) bool {
+	if len(f.Include) > 0 && !matchAny(f.Include, values) {
+		return false
+	}
+	return !matchAny(f.Exclude, values)
+}
+
+// validate 校验所有模式能否编译
+func (f FilterConfig) validate(v *validator, key string) {
+	for _, list := range []struct {
+		name     string
+		patterns []string
+	}{{"include", f.Include}, {"exclude", f.Exclude}} {
+		for i, pattern := range list.patterns {
+			if _, err := compilePattern(pattern); err != nil {
+				v.fatalf("无效的 %s.%s[%d] %q: %v", key, list.name, i, pattern, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// 带注释的配置文件模板，即仓库中的 config/config.yaml
+//
+//go:embed config.yaml
+var template []byte
+
+// 不写入生成的配置文件的配置项
+var generateSkip = map[string]bool{
+	"secrets.passphrase": true, // 应通过环境变量设置
+}
+
+// Generate 以配置文件模板的结构和注释生成配置文件，配置项的值取默认值；
+// 模板中没有的配置项追加到对应的节点下，生成的内容经过校验
+func Generate() ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(template, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置模板失败: %v", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("配置模板格式错误")
+	}
+
+	defaults := viper.New()
+	setDefaults(defaults)
+	if err := fillDefaults(doc.Content[0], "", defaults.AllSettings()); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	encoder.Close()
+
+	data := separateSections(buf.Bytes())
+	if err := Validate(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// fillDefaults 将映射节点中的配置项替换为默认值，保留注释和列表的书写格式；
+// 有默认值但模板中没有的配置项按名称顺序追加
+func fillDefaults(node *yaml.Node, prefix string, defaults map[string]interface{}) error {
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		def, ok := defaults[key]
+		if !ok {
+			continue
+		}
+		seen[key] = true
+
+		if nested, ok := def.(map[string]interface{}); ok && value.Kind == yaml.MappingNode {
+			if err := fillDefaults(value, prefix+key+".", nested); err != nil {
+				return err
+			}
+			continue
+		}
+
+		replacement, err := encodeValue(def)
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		if replacement.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode {
+			replacement.Style = value.Style
+		}
+		replacement.HeadComment, replacement.LineComment, replacement.FootComment =
+			value.HeadComment, value.LineComment, value.FootComment
+		node.Content[i+1] = replacement
+	}
+
+	missing := make([]string, 0)
+	for key := range defaults {
+		if !seen[key] && !generateSkip[prefix+key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	for _, key := range missing {
+		value, err := encodeValue(defaults[key])
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+	return nil
+}
+
+// encodeValue 将默认值转换为YAML节点，字符串加双引号与模板一致，短列表写为单行
+func encodeValue(value interface{}) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	switch {
+	case node.Kind == yaml.ScalarNode && node.Tag == "!!str":
+		node.Style = yaml.DoubleQuotedStyle
+	case node.Kind == yaml.SequenceNode:
+		node.Style = yaml.FlowStyle
+		for _, item := range node.Content {
+			if item.Kind == yaml.ScalarNode && item.Tag == "!!str" {
+				item.Style = yaml.DoubleQuotedStyle
+			}
+		}
+		if len(node.Content) > 3 {
+			node.Style = 0
+		}
+	case node.Kind == yaml.MappingNode:
+		// 默认值为映射（如模板中没有的配置节）时逐项转换
+		for i := 1; i < len(node.Content); i += 2 {
+			if item := node.Content[i]; item.Kind == yaml.ScalarNode && item.Tag == "!!str" {
+				item.Style = yaml.DoubleQuotedStyle
+			}
+		}
+	}
+	return &node, nil
+}
+
+// separateSections 在顶层配置节之间插入空行，与模板的排版一致
+func separateSections(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	result := make([]string, 0, len(lines)+16)
+	for i, line := range lines {
+		topLevel := line != "" && line[0] != ' ' && line[0] != '-'
+		if topLevel && i > 0 && (lines[i-1] == "" || lines[i-1][0] == ' ' || lines[i-1][0] == '-') &&
+			len(result) > 0 && result[len(result)-1] != "" {
+			result = append(result, "")
+		}
+		result = append(result, line)
+	}
+	return []byte(strings.Join(result, "\n"))
+}
+
+// Validate 按配置结构解析并校验配置文件内容（包括解密加密值），不影响当前生效的配置
+func Validate(data []byte) error {
+	v := viper.New()
+	setDefaults(v)
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("配置文件格式错误: %v", err)
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("配置文件格式错误: %v", err)
+	}
+	if err := cfg.decryptSecrets(); err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
+
+// WriteFile 校验后原子地写入配置文件：先写入同目录的临时文件并同步到磁盘，再重命名替换，
+// 写入中断或校验失败时原文件保持不变；已存在的文件保留原权限，新文件权限为0600
+func WriteFile(path string, data []byte) error {
+	if err := Validate(data); err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// 同步目录，确保重命名在断电后依然有效
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedPrefix 配置文件中加密值的前缀
+const encryptedPrefix = "enc:"
+
+// RedactedValue 脱敏后显示的占位符
+const RedactedValue = "******"
+
+// 加密值格式: enc:base64(salt | nonce | ciphertext)
+const (
+	saltSize  = 16
+	nonceSize = 12
+)
+
+// SecretsConfig 加密配置值使用的密钥
+type SecretsConfig struct {
+	KeyFile    string `mapstructure:"key_file"`   // 密钥文件，文件内容作为口令
+	Passphrase string `mapstructure:"passphrase"` // 口令，建议通过环境变量 MONITOR_SECRETS_PASSPHRASE 设置，不要写入配置文件
+}
+
+// ErrNoSecretKey 配置中有加密值但未提供密钥
+var ErrNoSecretKey = errors.New("未设置解密口令，请配置 secrets.key_file 或环境变量 MONITOR_SECRETS_PASSPHRASE")
+
+// sensitiveKeys 敏感配置项（键名的最后一段），通过接口返回时脱敏
+var sensitiveKeys = map[string]bool{
+	"password":       true,
+	"access_key":     true,
+	"secret_key":     true,
+	"signing_secret": true,
+	"token":          true,
+	"passphrase":     true,
+}
+
+// passphrase 获取加密口令，优先使用口令配置，其次读取密钥文件
+func (s *SecretsConfig) passphrase() (string, error) {
+	if s.Passphrase != "" {
+		return s.Passphrase, nil
+	}
+	if s.KeyFile == "" {
+		return "", ErrNoSecretKey
+	}
+
+	data, err := os.ReadFile(s.KeyFile)
+	if err != nil {
+		return "", fmt.Errorf("读取密钥文件失败: %v", err)
+	}
+	passphrase := strings.TrimSpace(string(data))
+	if passphrase == "" {
+		return "", fmt.Errorf("密钥文件 %s 为空", s.KeyFile)
+	}
+	return passphrase, nil
+}
+
+// deriveKey 由口令和盐通过scrypt派生AES-256密钥
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+// EncryptSecret 使用AES-256-GCM加密配置值，返回可直接写入配置文件的 enc: 前缀字符串
+func EncryptSecret(plaintext string) (string, error) {
+	passphrase, err := AppConfig.Secrets.passphrase()
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, saltSize+nonceSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	salt, nonce := buf[:saltSize], buf[saltSize:]
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(buf, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密 enc: 前缀的配置值，未加密的值原样返回
+func Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	passphrase, err := AppConfig.Secrets.passphrase()
+	if err != nil {
+		return "", err
+	}
+	return decrypt(value, passphrase)
+}
+
+func decrypt(value, passphrase string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil || len(data) < saltSize+nonceSize {
+		return "", errors.New("加密值格式错误")
+	}
+	salt, nonce, ciphertext := data[:saltSize], data[saltSize:saltSize+nonceSize], data[saltSize+nonceSize:]
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("解密失败，口令错误或加密值已损坏")
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decryptSecrets 解密配置中所有 enc: 前缀的字符串值（含列表中的值，如Webhook地址）
+func (c *Config) decryptSecrets() error {
+	var passphrase string
+	return walkStrings(reflect.ValueOf(c).Elem(), "", func(key string, v reflect.Value) error {
+		if !strings.HasPrefix(v.String(), encryptedPrefix) {
+			return nil
+		}
+		if passphrase == "" {
+			var err error
+			if passphrase, err = c.Secrets.passphrase(); err != nil {
+				return fmt.Errorf("%s 是加密值: %w", key, err)
+			}
+		}
+
+		plaintext, err := decrypt(v.String(), passphrase)
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		v.SetString(plaintext)
+		return nil
+	})
+}
+
+// walkStrings 遍历结构体中的所有字符串字段（含切片元素），key为对应的配置项名称
+func walkStrings(v reflect.Value, key string, fn func(key string, v reflect.Value) error) error {
+	switch v.Kind() {
+	case reflect.String:
+		return fn(key, v)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			name := v.Type().Field(i).Tag.Get("mapstructure")
+			if key != "" {
+				name = key + "." + name
+			}
+			if err := walkStrings(v.Field(i), name, fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := walkStrings(v.Index(i), fmt.Sprintf("%s[%d]", key, i), fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Redact 脱敏配置值：敏感配置项和加密值替换为占位符，URL只保留协议和主机（路径和参数中常含令牌）
+func Redact(key string, value interface{}) interface{} {
+	name := key[strings.LastIndex(key, ".")+1:]
+
+	switch v := value.(type) {
+	case string:
+		if sensitiveKeys[name] || strings.HasPrefix(v, encryptedPrefix) {
+			if v == "" {
+				return v
+			}
+			return RedactedValue
+		}
+		if name == "url" {
+			return RedactURL(v)
+		}
+		return v
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = Redact(key, item)
+		}
+		return redacted
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			redacted[k] = Redact(key+"."+k, item)
+		}
+		return redacted
+	}
+	return value
+}
+
+// RedactURL 只保留URL的协议和主机
+func RedactURL(raw string) string {
+	if strings.HasPrefix(raw, encryptedPrefix) {
+		return RedactedValue
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return RedactedValue
+	}
+	if parsed.Path == "" && parsed.RawQuery == "" && parsed.User == nil {
+		return raw
+	}
+	return parsed.Scheme + "://" + parsed.Host + "/" + RedactedValue
+}
+
+// IsRedacted 判断值是否为脱敏后的占位值
+func IsRedacted(value string) bool {
+	return value == RedactedValue || strings.HasSuffix(value, "/"+RedactedValue)
+}
@@ -0,0 +1,796 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// 采集间隔允许的最大值（秒）
+const maxJobInterval = 86400
+
+// ValidationError 配置校验失败，列出所有致命问题
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("配置存在%d个问题:\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// validator 收集配置校验发现的问题：致命问题拒绝启动，其余问题只记录警告
+type validator struct {
+	problems []string
+	warnings []string
+}
+
+func (v *validator) fatalf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) warnf(format string, args ...interface{}) {
+	v.warnings = append(v.warnings, fmt.Sprintf(format, args...))
+}
+
+// intRange 校验整数配置项在[min, max]范围内
+func (v *validator) intRange(key string, value, min, max int) {
+	if value < min || value > max {
+		v.fatalf("%s 必须在%d到%d之间，当前为 %d", key, min, max, value)
+	}
+}
+
+// port 校验端口号，fatal为false时只记录警告
+func (v *validator) port(key, value string, fatal bool) {
+	if n, err := strconv.Atoi(value); err == nil && n >= 1 && n <= 65535 {
+		return
+	}
+	if fatal {
+		v.fatalf("%s 必须是1到65535之间的端口号，当前为 %q", key, value)
+	} else {
+		v.warnf("%s 不是有效的端口号（当前为 %q），相关检查将失败", key, value)
+	}
+}
+
+// network 校验CIDR网段或单个IP地址
+func (v *validator) network(key, value string) {
+	if strings.Contains(value, "/") {
+		if _, _, err := net.ParseCIDR(value); err == nil {
+			return
+		}
+	} else if net.ParseIP(value) != nil {
+		return
+	}
+	v.fatalf("%s 必须是CIDR网段或IP地址，当前为 %q", key, value)
+}
+
+// httpURL 校验HTTP(S)地址
+func (v *validator) httpURL(key, value string) {
+	parsed, err := url.ParseRequestURI(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		v.fatalf("%s 必须是http或https地址，当前为 %q", key, value)
+	}
+}
+
+// Validate 校验整个配置并一次性报告所有问题：致命问题以ValidationError返回，其余问题记录为警告
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	c.Server.validate(v)
+	c.Database.validate(v)
+	c.Monitor.validate(v)
+	c.Services.validate(v)
+	c.Reputation.validate(v)
+	c.Certificates.validate(v)
+	c.Addresses.validate(v)
+	c.Ports.validate(v)
+	c.WebSocket.validate(v)
+	c.Notify.validate(v)
+	c.RateLimit.validate(v)
+	c.Access.validate(v)
+	c.AccessLog.validate(v)
+	c.Minimal.validate(v)
+	c.Rollup.validate(v)
+	c.Debug.validate(v)
+	c.Actions.validate(v)
+	c.Reports.validate(v)
+	c.Ingest.validate(v)
+	c.Terminal.validate(v)
+	c.StatusPage.validate(v)
+	c.I18n.validate(v)
+	c.Journal.validate(v)
+	c.SSHAuth.validate(v)
+	c.Fail2ban.validate(v)
+	c.Updates.validate(v)
+	c.Clock.validate(v)
+	c.SoC.validate(v)
+	c.UPS.validate(v)
+	c.IPMI.validate(v)
+	c.RAID.validate(v)
+	c.ZFS.validate(v)
+	c.Forecast.validate(v)
+	c.Anomaly.validate(v)
+	c.Capacity.validate(v)
+
+	// 未单独配置IPv4探测地址时复用信誉检查的探测地址，信誉检查启用时已校验过
+	if c.Addresses.Enabled && c.Addresses.PublicIP && len(c.Addresses.PublicIPv4URLs) == 0 && !c.Reputation.Enabled {
+		v.httpURL("reputation.public_ip_url", c.Reputation.PublicIPURL)
+	}
+
+	// 数据接入既无令牌也不限制来源网段时，任何人都可以写入告警和指标
+	if c.Ingest.Enabled && c.Ingest.Token == "" && len(c.Access.AllowedNetworks) == 0 {
+		v.warnf("ingest.token 为空且未配置 access.allowed_networks，任何人都可以通过 /api/v1/alerts/ingest 和 /api/v1/ingest/metrics 写入数据")
+	}
+
+	// 一轮检查要等所有服务的重试都结束，最长检查时间达到检查间隔时其他服务的状态会停止更新
+	for _, service := range []struct {
+		key     string
+		options ServiceCheckOptions
+	}{
+		{"services.database", c.Services.Database.ServiceCheckOptions},
+		{"services.web", c.Services.Web.ServiceCheckOptions},
+		{"services.mail", c.Services.Mail.ServiceCheckOptions},
+		{"services.storage", c.Services.Storage.ServiceCheckOptions},
+	} {
+		if longest := service.options.LongestCheck(); longest >= c.Monitor.ServiceInterval {
+			v.fatalf("%s 的最长检查时间（%d秒）不小于 monitor.service_interval（%d秒）", service.key, longest, c.Monitor.ServiceInterval)
+		}
+	}
+
+	// 异常检测的基线来自原始数据，超过保留时间的部分已被清理
+	// 早于保留时间的数据写入后会在下一次清理时删除
+	if c.Ingest.Enabled && c.Ingest.MaxLateness > c.Monitor.HistoryHours*3600 {
+		v.fatalf("ingest.max_lateness（%d秒）不能超过 monitor.history_hours（%d小时）", c.Ingest.MaxLateness, c.Monitor.HistoryHours)
+	}
+	if c.Anomaly.Enabled && c.Anomaly.BaselineHours > c.Monitor.HistoryHours {
+		v.fatalf("anomaly.baseline_hours（%d）不能超过 monitor.history_hours（%d）", c.Anomaly.BaselineHours, c.Monitor.HistoryHours)
+	}
+
+	// 磁盘写满预测基于每日汇总
+	if c.Forecast.Enabled && !c.Rollup.Enabled {
+		v.warnf("forecast.enabled 已启用但 rollup.enabled 未启用，没有每日汇总时无法预测磁盘写满")
+	}
+
+	for _, warning := range v.warnings {
+		log.Printf("Config warning: %s", warning)
+	}
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+func (s *ServerConfig) validate(v *validator) {
+	v.port("server.port", s.Port, true)
+
+	switch s.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		v.warnf("server.log_level %q 无效，可选 debug、info、warn、error，将按 info 处理", s.LogLevel)
+	}
+
+	s.TLS.validate(v)
+
+	if s.Compression.Enabled {
+		v.intRange("server.compression.min_size", s.Compression.MinSize, 0, 1<<30)
+		v.intRange("server.compression.gzip_level", s.Compression.GzipLevel, 1, 9)
+		v.intRange("server.compression.brotli_level", s.Compression.BrotliLevel, 0, 11)
+	}
+	if s.ETag.Enabled {
+		v.intRange("server.etag.max_size", s.ETag.MaxSize, 1024, 1<<30)
+	}
+}
+
+func (t *TLSConfig) validate(v *validator) {
+	if !t.Enabled {
+		return
+	}
+
+	switch {
+	case t.CertFile != "" || t.KeyFile != "":
+		if t.CertFile == "" || t.KeyFile == "" {
+			v.fatalf("server.tls.cert_file 和 server.tls.key_file 必须同时配置")
+		}
+	case len(t.Domains) == 0:
+		v.fatalf("server.tls 已启用，需要配置证书文件（cert_file、key_file）或自动申请证书的域名（domains）")
+	case t.CacheDir == "":
+		v.fatalf("server.tls.cache_dir 不能为空，自动申请的证书需要缓存")
+	}
+
+	if t.RedirectHTTP {
+		v.port("server.tls.http_port", t.HTTPPort, true)
+	}
+}
+
+func (d *DatabaseConfig) validate(v *validator) {
+	if d.Driver != "" && d.Driver != "sqlite" {
+		v.warnf("database.driver %q 暂不支持，将使用 sqlite", d.Driver)
+	}
+	if d.Database == "" {
+		v.fatalf("database.database 不能为空，请指定SQLite数据库文件路径")
+	}
+	v.intRange("database.integrity_interval", d.IntegrityInterval, 0, 30*maxJobInterval)
+}
+
+func (m *MonitorConfig) validate(v *validator) {
+	v.intRange("monitor.interval", m.Interval, 1, maxJobInterval)
+	v.intRange("monitor.service_interval", m.ServiceInterval, 1, maxJobInterval)
+	v.intRange("monitor.disk_interval", m.DiskInterval, 1, maxJobInterval)
+	v.intRange("monitor.network_interval", m.NetworkInterval, 1, maxJobInterval)
+	v.intRange("monitor.no_data_interval", m.NoDataInterval, 1, maxJobInterval)
+	v.intRange("monitor.comparison_interval", m.ComparisonInterval, 1, maxJobInterval)
+	v.intRange("monitor.api_rule_interval", m.APIRuleInterval, 1, maxJobInterval)
+
+	v.intRange("monitor.alert_cpu", m.AlertCPU, 1, 100)
+	v.intRange("monitor.alert_memory", m.AlertMemory, 1, 100)
+	v.intRange("monitor.alert_disk", m.AlertDisk, 1, 100)
+
+	if m.HistoryHours < 1 {
+		v.fatalf("monitor.history_hours 必须大于0，当前为 %d", m.HistoryHours)
+	}
+	if m.JobRunHours < 1 {
+		v.fatalf("monitor.job_run_hours 必须大于0，当前为 %d", m.JobRunHours)
+	}
+	if m.AuditLogDays < 1 {
+		v.fatalf("monitor.audit_log_days 必须大于0，当前为 %d", m.AuditLogDays)
+	}
+	if m.ServiceCheckDays < 1 {
+		v.fatalf("monitor.service_check_days 必须大于0，当前为 %d", m.ServiceCheckDays)
+	} else if m.ServiceCheckDays < 30 {
+		v.warnf("monitor.service_check_days 小于30（当前为 %d），服务的30天可用率只统计保留的检查历史", m.ServiceCheckDays)
+	}
+	if m.GapThreshold < 1 {
+		v.fatalf("monitor.gap_threshold 必须大于0，当前为 %d", m.GapThreshold)
+	}
+	if m.CleanupBatchSize < 1 {
+		v.fatalf("monitor.cleanup_batch_size 必须大于0，当前为 %d", m.CleanupBatchSize)
+	}
+	if m.CleanupBatchPause < 0 {
+		v.fatalf("monitor.cleanup_batch_pause 不能为负数，当前为 %d", m.CleanupBatchPause)
+	}
+	v.intRange("monitor.job_jitter", m.JobJitter, 0, 60000)
+	v.intRange("monitor.attribution_groups", m.AttributionGroups, 0, 20)
+	m.Filesystems.validate(v, "monitor.filesystems")
+	m.Interfaces.validate(v, "monitor.interfaces")
+
+	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	if _, err := parser.Parse(m.CleanupSchedule); err != nil {
+		v.fatalf("无效的 monitor.cleanup_schedule %q（需要含秒字段的6段cron表达式）: %v", m.CleanupSchedule, err)
+	}
+
+	switch m.AlertMemoryBasis {
+	case "used", "available", "with_cache":
+	default:
+		v.fatalf("无效的 monitor.alert_memory_basis %q，可选 used、available、with_cache", m.AlertMemoryBasis)
+	}
+
+	switch m.CatchUpPolicy {
+	case "run_once", "skip":
+	default:
+		v.fatalf("无效的 monitor.catch_up_policy %q，可选 run_once 或 skip", m.CatchUpPolicy)
+	}
+}
+
+// validate 被监控服务的配置错误只影响对应服务的检查，记录为警告
+func (s *ServicesConfig) validate(v *validator) {
+	if s.Database.Host != "" {
+		v.port("services.database.port", s.Database.Port, false)
+	}
+	if s.Web.URL != "" {
+		v.port("services.web.port", s.Web.Port, false)
+		if s.Web.Protocol != "http" && s.Web.Protocol != "https" {
+			v.warnf("services.web.protocol %q 无效，可选 http 或 https", s.Web.Protocol)
+		}
+	}
+	if s.Mail.Host != "" {
+		v.port("services.mail.port", s.Mail.Port, false)
+	}
+	s.Database.ServiceCheckOptions.validate(v, "services.database")
+	s.Web.ServiceCheckOptions.validate(v, "services.web")
+	s.Mail.ServiceCheckOptions.validate(v, "services.mail")
+	s.Storage.ServiceCheckOptions.validate(v, "services.storage")
+}
+
+func (o *ServiceCheckOptions) validate(v *validator, prefix string) {
+	v.intRange(prefix+".retries", o.Retries, 0, 10)
+	v.intRange(prefix+".retry_interval", o.RetryInterval, 0, 300)
+	v.intRange(prefix+".timeout", o.Timeout, 1, 300)
+	v.intRange(prefix+".latency_warning", o.LatencyWarning, 1, 300000)
+	if o.LatencyCritical <= o.LatencyWarning {
+		v.fatalf("%s.latency_critical（%d）必须大于 latency_warning（%d）", prefix, o.LatencyCritical, o.LatencyWarning)
+	} else if o.LatencyCritical > o.Timeout*1000 {
+		v.warnf("%s.latency_critical（%dms）超过 timeout（%d秒），响应慢时会先超时", prefix, o.LatencyCritical, o.Timeout)
+	}
+}
+
+func (r *ReputationConfig) validate(v *validator) {
+	if !r.Enabled {
+		return
+	}
+	v.intRange("reputation.interval", r.Interval, 1, maxJobInterval)
+	v.httpURL("reputation.public_ip_url", r.PublicIPURL)
+	if len(r.Blocklists) == 0 {
+		v.warnf("reputation.blocklists 为空，黑名单检查不会产生结果")
+	}
+}
+
+func (c *CertificatesConfig) validate(v *validator) {
+	if !c.Enabled {
+		return
+	}
+	v.intRange("certificates.interval", c.Interval, 1, maxJobInterval)
+	if c.WarnDays < 1 {
+		v.fatalf("certificates.warn_days 必须大于0，当前为 %d", c.WarnDays)
+	}
+	for _, port := range c.ScanPorts {
+		v.intRange("certificates.scan_ports", port, 1, 65535)
+	}
+}
+
+func (p *PortsConfig) validate(v *validator) {
+	if !p.Enabled {
+		return
+	}
+	v.intRange("ports.interval", p.Interval, 1, maxJobInterval)
+	for name, specs := range map[string][]string{"ports.expected": p.Expected, "ports.ignore": p.Ignore} {
+		for _, spec := range specs {
+			if _, _, ok := ParsePortSpec(spec); !ok {
+				v.fatalf("%s 中的 %q 无效，应为端口号或 tcp/端口、udp/端口", name, spec)
+			}
+		}
+	}
+}
+
+// ParsePortSpec 解析 ports.expected 和 ports.ignore 中的端口，如 22、tcp/443、udp/53；未指定协议时 protocol 为空，匹配TCP和UDP
+func ParsePortSpec(spec string) (protocol string, port uint32, ok bool) {
+	if i := strings.Index(spec, "/"); i >= 0 {
+		protocol, spec = strings.ToLower(spec[:i]), spec[i+1:]
+		if protocol != "tcp" && protocol != "udp" {
+			return "", 0, false
+		}
+	}
+	n, err := strconv.ParseUint(spec, 10, 16)
+	if err != nil || n == 0 {
+		return "", 0, false
+	}
+	return protocol, uint32(n), true
+}
+
+func (a *AddressesConfig) validate(v *validator) {
+	if !a.Enabled {
+		return
+	}
+	v.intRange("addresses.interval", a.Interval, 1, maxJobInterval)
+	for _, ip := range a.StaticIPs {
+		if net.ParseIP(ip) == nil {
+			v.fatalf("addresses.static_ips 中的 %q 不是有效的IP地址", ip)
+		}
+	}
+	if !a.PublicIP {
+		return
+	}
+	for i, u := range a.PublicIPv4URLs {
+		v.httpURL(fmt.Sprintf("addresses.public_ipv4_urls[%d]", i), u)
+	}
+	for i, u := range a.PublicIPv6URLs {
+		v.httpURL(fmt.Sprintf("addresses.public_ipv6_urls[%d]", i), u)
+	}
+	if a.GeoURL != "" {
+		v.httpURL("addresses.geo_url", strings.ReplaceAll(a.GeoURL, "{ip}", "0.0.0.0"))
+	}
+}
+
+func (w *WebSocketConfig) validate(v *validator) {
+	if w.Compression {
+		v.intRange("websocket.compression_level", w.CompressionLevel, 1, 9)
+	}
+	switch w.OverflowPolicy {
+	case "", "disconnect", "drop_newest", "drop_oldest":
+	default:
+		v.fatalf("无效的 websocket.overflow_policy %q，可选 disconnect、drop_newest、drop_oldest", w.OverflowPolicy)
+	}
+	if w.SendQueueSize < 0 || w.MaxConnections < 0 || w.MaxPerIP < 0 || w.MaxMessageRate < 0 || w.SnapshotPoints < 0 {
+		v.fatalf("websocket.send_queue_size、max_connections、max_per_ip、max_message_rate、snapshot_points 不能为负数")
+	}
+	if w.MinInterval < 0 {
+		v.fatalf("websocket.min_interval 不能为负数，当前为 %v", w.MinInterval)
+	}
+	v.intRange("websocket.replay_size", w.ReplaySize, 0, 10000)
+	v.intRange("websocket.unchanged_interval", w.UnchangedInterval, 0, 86400)
+}
+
+func (n *NotifyConfig) validate(v *validator) {
+	if n.ActionTTL < 1 {
+		v.fatalf("notify.action_ttl 必须大于0，当前为 %d", n.ActionTTL)
+	}
+	if n.SilenceDuration < 1 {
+		v.fatalf("notify.silence_duration 必须大于0，当前为 %d", n.SilenceDuration)
+	}
+	if n.Cooldown < 0 {
+		v.fatalf("notify.cooldown 不能为负数，当前为 %d", n.Cooldown)
+	}
+	for alertType, cooldown := range n.Cooldowns {
+		if cooldown < 0 {
+			v.fatalf("notify.cooldowns.%s 不能为负数，当前为 %d", alertType, cooldown)
+		}
+	}
+	if n.PublicURL != "" {
+		v.httpURL("notify.public_url", n.PublicURL)
+	}
+
+	for i, webhook := range n.Webhooks {
+		key := fmt.Sprintf("notify.webhooks[%d]", i)
+		v.httpURL(key+".url", webhook.URL)
+		switch webhook.Format {
+		case "", "generic", "slack":
+		case "telegram":
+			if webhook.ChatID == "" {
+				v.fatalf("%s.chat_id 不能为空（telegram格式需要会话ID）", key)
+			}
+		default:
+			v.fatalf("无效的 %s.format %q，可选 generic、slack、telegram", key, webhook.Format)
+		}
+	}
+}
+
+func (r *RateLimitConfig) validate(v *validator) {
+	if !r.Enabled {
+		return
+	}
+	if r.RequestsPerSecond <= 0 {
+		v.fatalf("rate_limit.requests_per_second 必须大于0，当前为 %v", r.RequestsPerSecond)
+	}
+	v.intRange("rate_limit.burst", r.Burst, 1, 100000)
+	v.intRange("rate_limit.websocket_per_minute", r.WebSocketPerMinute, 1, 100000)
+	v.intRange("rate_limit.websocket_burst", r.WebSocketBurst, 1, 100000)
+}
+
+func (a *AccessConfig) validate(v *validator) {
+	for i, network := range a.AllowedNetworks {
+		v.network(fmt.Sprintf("access.allowed_networks[%d]", i), network)
+	}
+	for i, proxy := range a.TrustedProxies {
+		v.network(fmt.Sprintf("access.trusted_proxies[%d]", i), proxy)
+	}
+	if a.RestrictAll && len(a.AllowedNetworks) == 0 {
+		v.warnf("access.restrict_all 已启用但 access.allowed_networks 为空，不会限制访问")
+	}
+}
+
+func (a *AccessLogConfig) validate(v *validator) {
+	if !a.Enabled {
+		return
+	}
+	v.intRange("access_log.max_rows", a.MaxRows, 1000, 10000000)
+	v.intRange("access_log.flush_interval", a.FlushInterval, 1, 60)
+}
+
+func (r *RollupConfig) validate(v *validator) {
+	if !r.Enabled {
+		return
+	}
+	v.intRange("rollup.interval", r.Interval, 60, 86400)
+	v.intRange("rollup.retention_days", r.RetentionDays, 1, 3650)
+}
+
+func (d *DebugConfig) validate(v *validator) {
+	if !d.Pprof {
+		return
+	}
+	if d.Username == "" || d.Password == "" {
+		v.fatalf("debug.pprof 已启用，必须设置 debug.username 和 debug.password")
+	}
+}
+
+func (a *ActionsConfig) validate(v *validator) {
+	v.intRange("actions.run_retention_days", a.RunRetentionDays, 1, 3650)
+	if !a.Enabled {
+		return
+	}
+	v.intRange("actions.timeout", a.Timeout, 1, 86400)
+	v.intRange("actions.max_output", a.MaxOutput, 1024, 10*1024*1024)
+	for i, command := range a.AllowedCommands {
+		fields := strings.Fields(command)
+		if len(fields) == 0 || !filepath.IsAbs(fields[0]) {
+			v.fatalf("actions.allowed_commands[%d] 必须以可执行文件的绝对路径开头，当前为 %q", i, command)
+			continue
+		}
+		for _, pattern := range fields[1:] {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				v.fatalf("actions.allowed_commands[%d] 的参数模式 %q 无效", i, pattern)
+			}
+		}
+	}
+	if len(a.AllowedCommands) == 0 {
+		v.warnf("actions.enabled 已启用但 actions.allowed_commands 为空，所有维护操作都不会执行")
+	}
+}
+
+func (r *ReportsConfig) validate(v *validator) {
+	if !r.Enabled {
+		return
+	}
+	if r.Period != "daily" && r.Period != "weekly" {
+		v.fatalf("reports.period %q 无效，可选 daily、weekly", r.Period)
+	}
+	v.intRange("reports.hour", r.Hour, 0, 23)
+	v.intRange("reports.weekday", r.Weekday, 0, 6)
+	if r.Template != "" {
+		if _, err := os.Stat(r.Template); err != nil {
+			v.fatalf("reports.template 无法读取: %v", err)
+		}
+	}
+}
+
+func (i *IngestConfig) validate(v *validator) {
+	v.intRange("ingest.offline_after", i.OfflineAfter, 0, 7*86400)
+	v.intRange("ingest.max_lateness", i.MaxLateness, 60, 30*86400)
+}
+
+func (s *StatusPageConfig) validate(v *validator) {
+	v.intRange("status_page.history_days", s.HistoryDays, 1, 90)
+}
+
+func (i *I18nConfig) validate(v *validator) {
+	switch i.Locale {
+	case "zh-CN", "en-US":
+	default:
+		v.fatalf("无效的 i18n.locale %q，可选 zh-CN、en-US", i.Locale)
+	}
+	if i.Timezone != "" {
+		location, err := time.LoadLocation(i.Timezone)
+		if err != nil {
+			v.fatalf("无效的 i18n.timezone %q，应为IANA时区名，如 Asia/Shanghai", i.Timezone)
+			return
+		}
+		i.location = location
+	}
+}
+
+func (j *JournalConfig) validate(v *validator) {
+	if !j.Enabled {
+		return
+	}
+	if runtime.GOOS != "linux" {
+		v.fatalf("journal 只支持Linux")
+	}
+	v.intRange("journal.interval", j.Interval, 5, 86400)
+	v.intRange("journal.max_entries", j.MaxEntries, 1, 100000)
+	switch j.Priority {
+	case "emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+		"0", "1", "2", "3", "4", "5", "6", "7":
+	default:
+		v.fatalf("无效的 journal.priority %q，可选 emerg、alert、crit、err、warning、notice、info、debug", j.Priority)
+	}
+	if j.Command == "" {
+		v.fatalf("journal.enabled 已启用，必须设置 journal.command")
+	}
+}
+
+func (s *SSHAuthConfig) validate(v *validator) {
+	if !s.Enabled {
+		return
+	}
+	v.intRange("ssh_auth.interval", s.Interval, 5, maxJobInterval)
+	v.intRange("ssh_auth.max_failures", s.MaxFailures, 0, 100000)
+	if s.MaxFailures > 0 {
+		v.intRange("ssh_auth.window", s.Window, 10, 86400)
+	}
+	switch s.Source {
+	case "auto", "journal":
+	case "file":
+		if len(s.LogFiles) == 0 {
+			v.fatalf("ssh_auth.source 为 file 时必须设置 ssh_auth.log_files")
+		}
+	default:
+		v.fatalf("无效的 ssh_auth.source %q，可选 auto、file、journal", s.Source)
+	}
+}
+
+func (f *Fail2banConfig) validate(v *validator) {
+	if !f.Enabled {
+		return
+	}
+	v.intRange("fail2ban.interval", f.Interval, 5, maxJobInterval)
+	if f.Command == "" {
+		v.fatalf("fail2ban.enabled 已启用，必须设置 fail2ban.command")
+	}
+}
+
+func (u *UpdatesConfig) validate(v *validator) {
+	if !u.Enabled {
+		return
+	}
+	if runtime.GOOS != "linux" {
+		v.fatalf("updates 只支持Linux")
+	}
+	v.intRange("updates.interval", u.Interval, 300, maxJobInterval)
+	v.intRange("updates.alert_pending", u.AlertPending, 0, 100000)
+	switch u.Manager {
+	case "auto", "apt", "dnf", "yum":
+	default:
+		v.fatalf("无效的 updates.manager %q，可选 auto、apt、dnf、yum", u.Manager)
+	}
+}
+
+func (c *ClockConfig) validate(v *validator) {
+	if !c.Enabled {
+		return
+	}
+	v.intRange("clock.interval", c.Interval, 60, maxJobInterval)
+	v.intRange("clock.timeout", c.Timeout, 1, 60)
+	if len(c.Servers) == 0 {
+		v.fatalf("clock.enabled 已启用，必须设置 clock.servers")
+	}
+	if c.Threshold <= 0 {
+		v.fatalf("clock.threshold 必须大于0，当前为 %g", c.Threshold)
+	}
+	if c.Critical != 0 && c.Critical < c.Threshold {
+		v.fatalf("clock.critical 必须为0或不小于 clock.threshold，当前为 %g", c.Critical)
+	}
+}
+
+func (s *SoCConfig) validate(v *validator) {
+	// 其他系统上没有thermal zone，跳过采集即可，不阻止启动
+	if !s.Enabled || runtime.GOOS != "linux" {
+		return
+	}
+	v.intRange("soc.interval", s.Interval, 1, maxJobInterval)
+	if s.AlertTemperature < 0 || s.AlertTemperature > 150 {
+		v.fatalf("soc.alert_temperature 必须在0到150之间，当前为 %g", s.AlertTemperature)
+	}
+}
+
+func (u *UPSConfig) validate(v *validator) {
+	if !u.Enabled {
+		return
+	}
+	v.intRange("ups.interval", u.Interval, 5, maxJobInterval)
+	v.intRange("ups.timeout", u.Timeout, 1, 60)
+	v.intRange("ups.alert_runtime", u.AlertRuntime, 0, 86400)
+	if u.Driver != "nut" && u.Driver != "apcupsd" {
+		v.fatalf("ups.driver 必须为 nut 或 apcupsd，当前为 %q", u.Driver)
+	}
+	if u.Address != "" {
+		if _, _, err := net.SplitHostPort(u.Address); err != nil {
+			v.fatalf("ups.address 必须为 host:port，当前为 %q", u.Address)
+		}
+	}
+}
+
+func (i *IPMIConfig) validate(v *validator) {
+	if !i.Enabled {
+		return
+	}
+	v.intRange("ipmi.interval", i.Interval, 10, maxJobInterval)
+	v.intRange("ipmi.timeout", i.Timeout, 1, 300)
+	switch i.Source {
+	case "ipmitool":
+		if i.Host != "" && i.Username == "" {
+			v.fatalf("ipmi.host 已设置，必须设置 ipmi.username")
+		}
+	case "redfish":
+		u, err := url.Parse(i.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.fatalf("ipmi.source 为 redfish 时 ipmi.url 必须为 http(s) 地址，当前为 %q", i.URL)
+		}
+		if i.Username == "" {
+			v.fatalf("ipmi.source 为 redfish 时必须设置 ipmi.username")
+		}
+		if i.Insecure {
+			v.warnf("ipmi.insecure 已启用，不校验Redfish的TLS证书")
+		}
+	default:
+		v.fatalf("ipmi.source 必须为 ipmitool 或 redfish，当前为 %q", i.Source)
+	}
+}
+
+func (r *RAIDConfig) validate(v *validator) {
+	// 其他系统上没有 /proc/mdstat，跳过检查即可，不阻止启动
+	if !r.Enabled || runtime.GOOS != "linux" {
+		return
+	}
+	v.intRange("raid.interval", r.Interval, 10, maxJobInterval)
+}
+
+func (z *ZFSConfig) validate(v *validator) {
+	if !z.Enabled {
+		return
+	}
+	v.intRange("zfs.interval", z.Interval, 10, maxJobInterval)
+	v.intRange("zfs.alert_capacity", z.AlertCapacity, 0, 100)
+}
+
+func (f *ForecastConfig) validate(v *validator) {
+	if !f.Enabled {
+		return
+	}
+	v.intRange("forecast.interval", f.Interval, 60, maxJobInterval)
+	v.intRange("forecast.window_days", f.WindowDays, 2, 3650)
+	v.intRange("forecast.min_days", f.MinDays, 2, f.WindowDays)
+	v.intRange("forecast.horizon_days", f.HorizonDays, 0, 3650)
+	if f.Method != "linear" && f.Method != "holt" {
+		v.fatalf("forecast.method 必须为 linear 或 holt，当前为 %q", f.Method)
+	}
+}
+
+func (a *AnomalyConfig) validate(v *validator) {
+	if !a.Enabled {
+		return
+	}
+	v.intRange("anomaly.interval", a.Interval, 60, maxJobInterval)
+	v.intRange("anomaly.window", a.Window, 60, 86400)
+	v.intRange("anomaly.baseline_hours", a.BaselineHours, 1, 24*365)
+	v.intRange("anomaly.min_samples", a.MinSamples, 2, 1000000)
+	if a.Threshold <= 0 {
+		v.fatalf("anomaly.threshold 必须大于0，当前为 %g", a.Threshold)
+	}
+	if len(a.Metrics) == 0 {
+		v.fatalf("anomaly.enabled 已启用，必须配置 anomaly.metrics")
+	}
+}
+
+func (c *CapacityConfig) validate(v *validator) {
+	v.intRange("capacity.window_days", c.WindowDays, 2, 3650)
+	v.intRange("capacity.horizon_days", c.HorizonDays, 1, 3650)
+	if c.DiskLimit <= 0 || c.DiskLimit > 100 {
+		v.fatalf("capacity.disk_limit 必须大于0且不超过100，当前为 %g", c.DiskLimit)
+	}
+	if c.MemoryLimit <= 0 || c.MemoryLimit > 100 {
+		v.fatalf("capacity.memory_limit 必须大于0且不超过100，当前为 %g", c.MemoryLimit)
+	}
+	if c.Bandwidth < 0 {
+		v.fatalf("capacity.bandwidth 不能为负数，当前为 %g", c.Bandwidth)
+	}
+}
+
+func (t *TerminalConfig) validate(v *validator) {
+	if !t.Enabled {
+		return
+	}
+	if t.Username == "" || t.Password == "" {
+		v.fatalf("terminal.enabled 已启用，必须设置 terminal.username 和 terminal.password")
+	}
+	v.intRange("terminal.idle_timeout", t.IdleTimeout, 10, 86400)
+	v.intRange("terminal.max_sessions", t.MaxSessions, 1, 100)
+
+	switch t.Mode {
+	case "pty":
+		if runtime.GOOS != "linux" {
+			v.fatalf("terminal.mode 为 pty 时只支持Linux，当前平台请使用 ssh")
+		}
+		if t.Shell == "" {
+			v.fatalf("terminal.mode 为 pty 时必须设置 terminal.shell")
+		}
+	case "ssh":
+		if _, _, err := net.SplitHostPort(t.SSH.Address); err != nil {
+			v.fatalf("terminal.ssh.address 必须为 host:port，当前为 %q", t.SSH.Address)
+		}
+		if t.SSH.User == "" {
+			v.fatalf("terminal.mode 为 ssh 时必须设置 terminal.ssh.user")
+		}
+		if t.SSH.Password == "" && t.SSH.PrivateKey == "" {
+			v.fatalf("terminal.mode 为 ssh 时必须设置 terminal.ssh.password 或 terminal.ssh.private_key")
+		}
+		if t.SSH.KnownHosts == "" {
+			v.warnf("terminal.ssh.known_hosts 为空，不校验SSH主机密钥，可能遭受中间人攻击")
+		}
+	default:
+		v.fatalf("terminal.mode 必须为 pty 或 ssh，当前为 %q", t.Mode)
+	}
+}
+
+func (m *MinimalConfig) validate(v *validator) {
+	if !m.Enabled {
+		return
+	}
+	v.intRange("minimal.history_minutes", m.HistoryMinutes, 1, 1440)
+}
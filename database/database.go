@@ -1,20 +1,102 @@
 package database
 
 import (
+	"fmt"
 	"log"
 	"server-monitor/config"
 	"server-monitor/models"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 
-// InitDatabase 初始化数据库连接
+// 数据库已连接并完成表结构迁移
+var migrated atomic.Bool
+
+// InitDatabase 初始化数据库连接；数据库文件损坏且开启自动恢复时隔离损坏文件并重建
 func InitDatabase() error {
+	if err := open(); err != nil {
+		if !isCorruption(err) || !config.AppConfig.Database.AutoRecover {
+			return err
+		}
+		if err := recoverOnStartup(err); err != nil {
+			return err
+		}
+	}
+
+	migrated.Store(true)
+
+	if config.AppConfig.Minimal.Enabled {
+		log.Printf("Minimal mode: using in-memory database, keeping the last %d minutes of data", config.AppConfig.Minimal.HistoryMinutes)
+	}
+	log.Println("Database initialized successfully")
+	return nil
+}
+
+// recoverOnStartup 启动时发现数据库损坏，隔离损坏文件并重建，在新数据库中记录告警（下次完整性检查通过时自动解除）
+func recoverOnStartup(cause error) error {
+	log.Printf("Database is corrupted: %v", cause)
+	quarantined, err := Quarantine()
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("启动时发现数据库损坏（%v），已隔离到 %s 并重建数据库", cause, quarantined)
+	DB.Create(&models.Alert{
+		Type:      "database_corrupt",
+		Level:     "error",
+		Message:   message,
+		Status:    "active",
+		Timestamp: time.Now(),
+	})
+	DB.Create(&models.SystemLog{
+		Level:     "error",
+		Category:  "system",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+	return nil
+}
+
+// Migrated 数据库是否已连接并完成表结构迁移，用于就绪检查
+func Migrated() bool {
+	return migrated.Load()
+}
+
+// dsn 数据库连接地址，低资源占用模式下使用内存数据库，不写磁盘
+func dsn() string {
+	if config.AppConfig.Minimal.Enabled {
+		return ":memory:"
+	}
+	return config.AppConfig.Database.Database
+}
+
+// OpenMemory 打开一个独立的内存数据库并创建指定模型的表，用于在录制的数据上运行查询，不影响主数据库
+func OpenMemory(tables ...interface{}) (*gorm.DB, error) {
+	db, err := openSQLite(":memory:", &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	// 内存数据库属于单个连接
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(tables...); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// open 连接数据库并迁移表结构、初始化默认数据
+func open() error {
 	var err error
 	
 	// 配置GORM日志
@@ -23,7 +105,7 @@ func InitDatabase() error {
 	}
 	
 	// 连接SQLite数据库
-	DB, err = gorm.Open(sqlite.Open(config.AppConfig.Database.Database), gormConfig)
+	DB, err = openSQLite(dsn(), gormConfig)
 	if err != nil {
 		return err
 	}
@@ -35,37 +117,75 @@ func InitDatabase() error {
 	}
 	
 	// 设置连接池参数
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	if config.AppConfig.Minimal.Enabled {
+		// 内存数据库随连接关闭而消失，只使用一个永不关闭的连接
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+	} else {
+		sqlDB.SetMaxIdleConns(10)
+		sqlDB.SetMaxOpenConns(100)
+		sqlDB.SetConnMaxLifetime(time.Hour)
+	}
 	
 	// 自动迁移数据库表
 	err = autoMigrate()
 	if err != nil {
 		return err
 	}
-	
-	// 初始化默认数据
-	err = initDefaultData()
-	if err != nil {
+	if err := migrateToUTC(); err != nil {
 		return err
 	}
 	
-	log.Println("Database initialized successfully")
-	return nil
+	// 初始化默认数据
+	return initDefaultData()
 }
 
 // autoMigrate 自动迁移数据库表
 func autoMigrate() error {
-	return DB.AutoMigrate(
-		&models.SystemMetrics{},
-		&models.ServiceStatus{},
-		&models.SystemLog{},
-		&models.DiskUsage{},
-		&models.Alert{},
-		&models.NetworkTraffic{},
-		&models.ProcessInfo{},
-	)
+	return DB.AutoMigrate(tableModels...)
+}
+
+// tableModels 数据库中所有表的模型
+var tableModels = []interface{}{
+	&models.SystemMetrics{},
+	&models.ServiceStatus{},
+	&models.SystemLog{},
+	&models.DiskUsage{},
+	&models.Alert{},
+	&models.NetworkTraffic{},
+	&models.ProcessInfo{},
+	&models.IPReputation{},
+	&models.Certificate{},
+	&models.Annotation{},
+	&models.AlertRule{},
+	&models.JobRun{},
+	&models.AlertEvent{},
+	&models.AlertComment{},
+	&models.Series{},
+	&models.SeriesTag{},
+	&models.Server{},
+	&models.IPAddressChange{},
+	&models.PortChange{},
+	&models.SSHLogin{},
+	&models.Fail2banBan{},
+	&models.UpdateCheck{},
+	&models.ClockCheck{},
+	&models.SensorReading{},
+	&models.RAIDStatus{},
+	&models.ZFSPool{},
+	&models.IPGeolocation{},
+	&models.Setting{},
+	&models.AuditLog{},
+	&models.AccessLog{},
+	&models.ServiceCheck{},
+	&models.ServiceCheckResult{},
+	&models.ProcessWatch{},
+	&models.AlertFixture{},
+	&models.DailySummary{},
+	&models.RollupState{},
+	&models.Action{},
+	&models.ActionRun{},
 }
 
 // initDefaultData 初始化默认数据
@@ -118,6 +238,22 @@ func initDefaultData() error {
 		}
 	}
 	
+	// 插入默认的无数据告警规则
+	DB.Model(&models.AlertRule{}).Count(&count)
+	if count == 0 {
+		defaultRules := []models.AlertRule{
+			{Name: "系统指标无数据", Type: "no_data", Metric: "system_metrics", Intervals: 3, Level: "error", Enabled: true},
+			{Name: "网络流量无数据", Type: "no_data", Metric: "network_traffic", Intervals: 3, Level: "warning", Enabled: true},
+			{Name: "磁盘使用无数据", Type: "no_data", Metric: "disk_usage", Intervals: 3, Level: "warning", Enabled: true},
+		}
+
+		for _, rule := range defaultRules {
+			if err := DB.Create(&rule).Error; err != nil {
+				return err
+			}
+		}
+	}
+
 	// 插入初始系统日志
 	initialLogs := []models.SystemLog{
 		{
@@ -143,21 +279,141 @@ func initDefaultData() error {
 	return nil
 }
 
+// CleanupStats 数据清理统计
+type CleanupStats struct {
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+	Duration   string           `json:"duration"`
+	Removed    map[string]int64 `json:"removed"` // 每张表删除的行数
+	Errors     []string         `json:"errors"`
+}
+
+var (
+	lastCleanup   *CleanupStats
+	lastCleanupMu sync.RWMutex
+)
+
+// GetLastCleanupStats 获取最近一次数据清理的统计，尚未执行过时返回nil
+func GetLastCleanupStats() *CleanupStats {
+	lastCleanupMu.RLock()
+	defer lastCleanupMu.RUnlock()
+	return lastCleanup
+}
+
+// cutoff 保留期的起始时间；低资源占用模式下所有数据最多保留 minimal.history_minutes
+func cutoff(retention time.Duration) time.Time {
+	if minimal := config.AppConfig.Minimal; minimal.Enabled {
+		if window := time.Duration(minimal.HistoryMinutes) * time.Minute; window < retention {
+			retention = window
+		}
+	}
+	return time.Now().Add(-retention)
+}
+
 // CleanupOldData 清理旧数据
-func CleanupOldData() {
+func CleanupOldData() *CleanupStats {
+	stats := &CleanupStats{
+		StartedAt: time.Now(),
+		Removed:   make(map[string]int64),
+	}
+
 	// 清理超过保留时间的系统指标数据
 	retentionHours := config.AppConfig.Monitor.HistoryHours
-	cutoffTime := time.Now().Add(-time.Duration(retentionHours) * time.Hour)
-	
-	DB.Where("created_at < ?", cutoffTime).Delete(&models.SystemMetrics{})
-	DB.Where("created_at < ?", cutoffTime).Delete(&models.NetworkTraffic{})
-	DB.Where("created_at < ?", cutoffTime).Delete(&models.ProcessInfo{})
-	
+	cutoffTime := cutoff(time.Duration(retentionHours) * time.Hour)
+
+	stats.deleteInBatches(&models.SystemMetrics{}, "created_at < ?", cutoffTime)
+	stats.deleteInBatches(&models.NetworkTraffic{}, "created_at < ?", cutoffTime)
+	stats.deleteInBatches(&models.ProcessInfo{}, "created_at < ?", cutoffTime)
+	stats.deleteInBatches(&models.IPReputation{}, "created_at < ?", cutoffTime)
+	stats.deleteInBatches(&models.SensorReading{}, "created_at < ?", cutoffTime)
+	stats.deleteInBatches(&models.RAIDStatus{}, "created_at < ?", cutoffTime)
+	stats.deleteInBatches(&models.ZFSPool{}, "created_at < ?", cutoffTime)
+
+	// 清理保留时间内不再出现的指标序列及其标签索引
+	stats.deleteInBatches(&models.Series{}, "last_seen < ?", cutoffTime)
+	stats.deleteInBatches(&models.SeriesTag{}, "series_id NOT IN (SELECT id FROM series)")
+
 	// 清理已解决的告警（保留7天）
-	alertCutoffTime := time.Now().Add(-7 * 24 * time.Hour)
-	DB.Where("status = ? AND updated_at < ?", "resolved", alertCutoffTime).Delete(&models.Alert{})
-	
+	alertCutoffTime := cutoff(7 * 24 * time.Hour)
+	stats.deleteInBatches(&models.Alert{}, "status = ? AND updated_at < ?", "resolved", alertCutoffTime)
+	stats.deleteInBatches(&models.AlertEvent{}, "alert_id NOT IN (SELECT id FROM alerts)")
+	stats.deleteInBatches(&models.AlertComment{}, "alert_id NOT IN (SELECT id FROM alerts)")
+
 	// 清理旧日志（保留30天）
-	logCutoffTime := time.Now().Add(-30 * 24 * time.Hour)
-	DB.Where("created_at < ?", logCutoffTime).Delete(&models.SystemLog{})
-} 
\ No newline at end of file
+	logCutoffTime := cutoff(30 * 24 * time.Hour)
+	stats.deleteInBatches(&models.SystemLog{}, "created_at < ?", logCutoffTime)
+	stats.deleteInBatches(&models.Annotation{}, "created_at < ?", logCutoffTime)
+	// 登录成功的记录用于判断新地址，一直保留
+	stats.deleteInBatches(&models.SSHLogin{}, "success = ? AND created_at < ?", false, logCutoffTime)
+	stats.deleteInBatches(&models.Fail2banBan{}, "unbanned_at < ?", logCutoffTime)
+	stats.deleteInBatches(&models.UpdateCheck{}, "created_at < ?", logCutoffTime)
+	stats.deleteInBatches(&models.ClockCheck{}, "created_at < ?", logCutoffTime)
+
+	// 清理超过保留天数的每日汇总
+	summaryCutoffTime := cutoff(time.Duration(config.AppConfig.Rollup.RetentionDays) * 24 * time.Hour)
+	stats.deleteInBatches(&models.DailySummary{}, "day < ?", summaryCutoffTime.Format("2006-01-02"))
+
+	// 清理任务执行记录
+	jobRunCutoffTime := cutoff(time.Duration(config.AppConfig.Monitor.JobRunHours) * time.Hour)
+	stats.deleteInBatches(&models.JobRun{}, "created_at < ?", jobRunCutoffTime)
+
+	// 清理服务检查历史，以及已删除服务的检查历史
+	serviceCheckCutoffTime := cutoff(time.Duration(config.AppConfig.Monitor.ServiceCheckDays) * 24 * time.Hour)
+	stats.deleteInBatches(&models.ServiceCheckResult{}, "created_at < ?", serviceCheckCutoffTime)
+	stats.deleteInBatches(&models.ServiceCheckResult{}, "service_id NOT IN (SELECT id FROM service_statuses)")
+
+	// 清理维护操作执行记录
+	actionRunCutoffTime := cutoff(time.Duration(config.AppConfig.Actions.RunRetentionDays) * 24 * time.Hour)
+	stats.deleteInBatches(&models.ActionRun{}, "created_at < ?", actionRunCutoffTime)
+
+	// 清理审计日志
+	auditCutoffTime := time.Now().Add(-time.Duration(config.AppConfig.Monitor.AuditLogDays) * 24 * time.Hour)
+	stats.deleteInBatches(&models.AuditLog{}, "created_at < ?", auditCutoffTime)
+
+	stats.FinishedAt = time.Now()
+	stats.Duration = stats.FinishedAt.Sub(stats.StartedAt).String()
+
+	lastCleanupMu.Lock()
+	lastCleanup = stats
+	lastCleanupMu.Unlock()
+
+	return stats
+}
+
+// deleteInBatches 分批删除满足条件的记录，批次之间暂停，避免长时间锁表和WAL膨胀
+func (stats *CleanupStats) deleteInBatches(model interface{}, condition string, args ...interface{}) {
+	stmt := &gorm.Statement{DB: DB}
+	if err := stmt.Parse(model); err != nil {
+		stats.Errors = append(stats.Errors, err.Error())
+		return
+	}
+	table := stmt.Schema.Table
+
+	batchSize := config.AppConfig.Monitor.CleanupBatchSize
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	pause := time.Duration(config.AppConfig.Monitor.CleanupBatchPause) * time.Millisecond
+
+	for {
+		subQuery := DB.Model(model).Select("id").Where(condition, args...).Limit(batchSize)
+		result := DB.Where("id IN (?)", subQuery).Delete(model)
+		if result.Error != nil {
+			log.Printf("Error cleaning up %s: %v", table, result.Error)
+			stats.Errors = append(stats.Errors, fmt.Sprintf("%s: %v", table, result.Error))
+			return
+		}
+
+		stats.Removed[table] += result.RowsAffected
+		if result.RowsAffected < int64(batchSize) {
+			break
+		}
+
+		log.Printf("Cleanup progress: %s, %d rows removed so far", table, stats.Removed[table])
+		time.Sleep(pause)
+	}
+
+	if stats.Removed[table] > 0 {
+		log.Printf("Cleanup finished for %s: %d rows removed", table, stats.Removed[table])
+	}
+}
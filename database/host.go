@@ -0,0 +1,43 @@
+package database
+
+import (
+	"server-monitor/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HostScope 按主机筛选带 server_id 的数据（指标、磁盘、网络流量、服务状态、告警和系统日志），
+// host为空时只查询本机的数据，远程主机的数据需按主机标识查询，未登记的主机查询结果为空
+func HostScope(host string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if host == "" {
+			return db.Where("server_id = 0")
+		}
+		return db.Where("server_id = (SELECT id FROM servers WHERE name = ?)", host)
+	}
+}
+
+// HostSummaryScope 按主机筛选每日汇总：远程主机的汇总带 server_id 标签，本机的汇总不带
+func HostSummaryScope(host string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		// 标签按 k=v 逗号分隔存储，两端补逗号后按完整的 k=v 匹配；未登记的主机拼接结果为NULL，查询结果为空
+		if host == "" {
+			return db.Where("',' || tags || ',' NOT LIKE ?", "%,server_id=%")
+		}
+		return db.Where("',' || tags || ',' LIKE '%,server_id=' || (SELECT id FROM servers WHERE name = ?) || ',%'", host)
+	}
+}
+
+// TouchServer 登记远程主机并更新最后推送时间，返回主机记录；tx 可以是事务
+func TouchServer(tx *gorm.DB, name string) (*models.Server, error) {
+	server := models.Server{Name: name}
+	if err := tx.Where(models.Server{Name: name}).FirstOrCreate(&server).Error; err != nil {
+		return nil, err
+	}
+	server.LastSeen = time.Now()
+	if err := tx.Model(&server).Update("last_seen", server.LastSeen).Error; err != nil {
+		return nil, err
+	}
+	return &server, nil
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"server-monitor/config"
+	"strings"
+	"sync"
+	"time"
+)
+
+var quarantineMu sync.Mutex
+
+// CheckIntegrity 执行 PRAGMA integrity_check，返回发现的问题，数据库完好时返回空
+func CheckIntegrity() ([]string, error) {
+	var results []string
+	if err := DB.Raw("PRAGMA integrity_check").Scan(&results).Error; err != nil {
+		// 严重损坏时检查本身会失败
+		if isCorruption(err) {
+			return []string{err.Error()}, nil
+		}
+		return nil, err
+	}
+
+	if len(results) == 1 && results[0] == "ok" {
+		return nil, nil
+	}
+	return results, nil
+}
+
+// Quarantine 将损坏的数据库文件（含WAL/SHM文件）改名隔离，重新创建数据库和表结构，返回隔离后的文件路径
+func Quarantine() (string, error) {
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+
+	if DB != nil {
+		if sqlDB, err := DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+
+	path := config.AppConfig.Database.Database
+	quarantined := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(path, quarantined); err != nil {
+		return "", err
+	}
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if err := os.Rename(path+suffix, quarantined+suffix); err != nil && !os.IsNotExist(err) {
+			log.Printf("Error moving %s: %v", path+suffix, err)
+		}
+	}
+
+	if err := open(); err != nil {
+		return quarantined, err
+	}
+
+	log.Printf("Corrupted database moved to %s, created a new database", quarantined)
+	return quarantined, nil
+}
+
+// isCorruption 判断错误是否由数据库文件损坏引起
+func isCorruption(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "malformed") || strings.Contains(message, "not a database") ||
+		strings.Contains(message, "SQLITE_CORRUPT") || strings.Contains(message, "SQLITE_NOTADB")
+}
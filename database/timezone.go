@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"server-monitor/config"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// SQLite没有时间类型，时间按带时区偏移的字符串保存、按字符串比较，同一列混用不同的偏移时范围查询会出错。
+// 数据库中的时间统一以UTC保存：写入和查询参数中的时间在执行前转换为UTC
+
+// 已保存的时间转换为UTC后的数据库版本（PRAGMA user_version）
+const utcVersion = 1
+
+// openSQLite 打开SQLite数据库，SQL参数中的时间统一转换为UTC
+func openSQLite(dsn string, gormConfig *gorm.Config) (*gorm.DB, error) {
+	sqlDB, err := sql.Open(sqlite.DriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(&sqlite.Dialector{Conn: &utcConnPool{sqlDB}}, gormConfig)
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// utcConnPool 执行前把参数中的时间转换为UTC的连接池
+type utcConnPool struct {
+	*sql.DB
+}
+
+func (p *utcConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.DB.ExecContext(ctx, query, utcArgs(args)...)
+}
+
+func (p *utcConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.DB.QueryContext(ctx, query, utcArgs(args)...)
+}
+
+func (p *utcConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.DB.QueryRowContext(ctx, query, utcArgs(args)...)
+}
+
+// BeginTx 开始事务，事务中的参数同样转换为UTC
+func (p *utcConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := p.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &utcTx{tx}, nil
+}
+
+// GetDBConn 底层的sql.DB，供 gorm.DB.DB() 设置连接池参数
+func (p *utcConnPool) GetDBConn() (*sql.DB, error) {
+	return p.DB, nil
+}
+
+// utcTx 执行前把参数中的时间转换为UTC的事务
+type utcTx struct {
+	*sql.Tx
+}
+
+func (t *utcTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return t.Tx.ExecContext(ctx, query, utcArgs(args)...)
+}
+
+func (t *utcTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return t.Tx.QueryContext(ctx, query, utcArgs(args)...)
+}
+
+func (t *utcTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return t.Tx.QueryRowContext(ctx, query, utcArgs(args)...)
+}
+
+// utcArgs 把参数中的时间转换为UTC，不修改调用方的参数
+func utcArgs(args []interface{}) []interface{} {
+	var converted []interface{}
+	for i, arg := range args {
+		var t time.Time
+		switch v := arg.(type) {
+		case time.Time:
+			t = v
+		case *time.Time:
+			if v == nil {
+				continue
+			}
+			t = *v
+		default:
+			continue
+		}
+		if converted == nil {
+			converted = append([]interface{}(nil), args...)
+		}
+		converted[i] = t.UTC()
+	}
+	if converted == nil {
+		return args
+	}
+	return converted
+}
+
+// migrateToUTC 把旧版本按系统时区保存的时间转换为UTC（精确到毫秒），每个数据库只执行一次
+func migrateToUTC() error {
+	var version int
+	if err := DB.Raw("PRAGMA user_version").Scan(&version).Error; err != nil {
+		return err
+	}
+	if version >= utcVersion {
+		return nil
+	}
+
+	var converted int64
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		for _, model := range tableModels {
+			stmt := &gorm.Statement{DB: tx}
+			if err := stmt.Parse(model); err != nil {
+				return err
+			}
+			for _, field := range stmt.Schema.Fields {
+				if field.DBName == "" || field.DataType != schema.Time {
+					continue
+				}
+				table, column := stmt.Quote(stmt.Schema.Table), stmt.Quote(field.DBName)
+				utc := fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:%%M:%%f', %s)", column)
+				result := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = %s || '+00:00' WHERE %s NOT LIKE '%%+00:00' AND %s IS NOT NULL",
+					table, column, utc, column, utc))
+				if result.Error != nil {
+					return fmt.Errorf("%s.%s: %v", stmt.Schema.Table, field.DBName, result.Error)
+				}
+				converted += result.RowsAffected
+			}
+		}
+		return tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", utcVersion)).Error
+	})
+	if err != nil {
+		return err
+	}
+	if converted > 0 {
+		log.Printf("Converted %d timestamps to UTC", converted)
+	}
+	return nil
+}
+
+// LocalDate 时间列在显示时区（i18n.timezone）的日期，用于在SQL中按天分组；SQLite不支持时区名，
+// 按显示时区当前的偏移计算，夏令时切换之前的日期在零点附近可能相差一小时
+func LocalDate(column string) string {
+	_, offset := time.Now().In(config.AppConfig.I18n.Location()).Zone()
+	return fmt.Sprintf("date(%s, '%+d seconds')", column, offset)
+}
@@ -0,0 +1,180 @@
+package https
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"server-monitor/config"
+	"server-monitor/upgrade"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// manager 自动申请证书时的证书管理器，HTTP重定向端口同时用于HTTP-01验证
+var manager *autocert.Manager
+
+// redirectServer HTTP重定向服务器
+var redirectServer *http.Server
+
+// TLSConfig 根据配置创建HTTPS的TLS配置，未启用HTTPS时返回nil
+func TLSConfig() (*tls.Config, error) {
+	cfg := config.AppConfig.Server.TLS
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	if cfg.Autocert() {
+		manager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+			Cache:      autocert.DirCache(cfg.CacheDir),
+			Email:      cfg.Email,
+		}
+		tlsConfig := manager.TLSConfig()
+		tlsConfig.MinVersion = tls.VersionTLS12
+		return tlsConfig, nil
+	}
+
+	loader := &certLoader{certFile: cfg.CertFile, keyFile: cfg.KeyFile}
+	if _, err := loader.load(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: loader.getCertificate,
+	}, nil
+}
+
+// StartRedirect 启动HTTP重定向服务器，将请求重定向到HTTPS地址；自动申请证书时同时响应HTTP-01验证
+func StartRedirect() {
+	cfg := config.AppConfig.Server.TLS
+	if !cfg.Enabled || !cfg.RedirectHTTP {
+		return
+	}
+
+	var handler http.Handler = http.HandlerFunc(redirect)
+	if manager != nil {
+		handler = manager.HTTPHandler(handler)
+	}
+	redirectServer = &http.Server{
+		Addr:              net.JoinHostPort(config.AppConfig.Server.Host, cfg.HTTPPort),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		listener, err := listenRedirect(redirectServer.Addr)
+		if err != nil {
+			log.Printf("Error starting HTTP redirect on %s: %v", redirectServer.Addr, err)
+			return
+		}
+		log.Printf("Redirecting HTTP on %s to HTTPS", redirectServer.Addr)
+		if err := redirectServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("HTTP redirect server error: %v", err)
+		}
+	}()
+}
+
+// ShutdownRedirect 关闭HTTP重定向服务器
+func ShutdownRedirect(ctx context.Context) error {
+	if redirectServer == nil {
+		return nil
+	}
+	return redirectServer.Shutdown(ctx)
+}
+
+// listenRedirect 监听HTTP重定向端口；升级启动时旧进程仍占用该端口，等待旧进程退出后再监听
+func listenRedirect(addr string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err == nil || !upgrade.Inherited() {
+		return listener, err
+	}
+
+	for i := 0; i < 30; i++ {
+		time.Sleep(time.Second)
+		if listener, err = net.Listen("tcp", addr); err == nil {
+			return listener, nil
+		}
+	}
+	return nil, err
+}
+
+// redirect 将HTTP请求重定向到相同主机的HTTPS地址
+func redirect(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	if port := config.AppConfig.Server.Port; port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+}
+
+// certLoader 从文件加载证书，证书或私钥文件修改后自动重新加载，证书续期无需重启
+type certLoader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// getCertificate TLS握手时获取证书，重新加载失败时继续使用原证书
+func (l *certLoader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert, err := l.load()
+	if err != nil {
+		log.Printf("Error reloading TLS certificate, keeping previous one: %v", err)
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.cert, nil
+	}
+	return cert, nil
+}
+
+// load 证书或私钥文件比上次加载时新时重新加载
+func (l *certLoader) load() (*tls.Certificate, error) {
+	modTime, err := latestModTime(l.certFile, l.keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cert != nil && !modTime.After(l.modTime) {
+		return l.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载证书失败: %v", err)
+	}
+	if l.cert != nil {
+		log.Printf("TLS certificate %s reloaded", l.certFile)
+	}
+	l.cert = &cert
+	l.modTime = modTime
+	return l.cert, nil
+}
+
+// latestModTime 返回多个文件中最晚的修改时间
+func latestModTime(paths ...string) (time.Time, error) {
+	var latest time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
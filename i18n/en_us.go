@@ -0,0 +1,415 @@
+package i18n
+
+// enUS 英文消息目录，键为代码中的中文原文；格式化动词的数量和类型需与原文一致，顺序不同时使用 %[n] 指定参数
+var enUS = map[string]string{
+	"检查包格式错误: %v":      "invalid bundle format: %v",
+	"检查包格式错误: 顶层必须是对象": "invalid bundle format: top level must be an object",
+	"检查包名称不能为空":        "bundle name is required",
+	"检查包不存在: %s":       "bundle not found: %s",
+	"链接无效或已过期":         "link is invalid or has expired",
+	"自检示例告警":           "Self-test sample alert",
+	"确认":               "Acknowledge",
+	"解决":               "Resolve",
+	"静默%v":             "Silence %v",
+	"%s [%s] %s\n%s\n当前值: %.2f  阈值: %.2f  时间: %s": "%s [%s] %s\n%s\nValue: %.2f  Threshold: %.2f  Time: %s",
+	"%s %d 次": "%s ×%d",
+	"🔁 %s 起的冷却期内合并了 %s，以上为最新状态": "🔁 Merged %[2]s during the cooldown since %[1]s; the latest state is shown above",
+	"、": ", ",
+	"无效的时间范围: %q，格式如 24h、7d、30d":                  "invalid range %q, expected e.g. 24h, 7d, 30d",
+	"时间范围不能超过 %d 天":                               "range cannot exceed %d days",
+	"查询趋势数据失败: %v":                                "failed to query trend data: %v",
+	"诊断接口未启用":                                     "Diagnostics endpoint is not enabled",
+	"需要管理员认证":                                     "Administrator authentication required",
+	"获取夹具失败":                                      "Failed to get fixtures",
+	"请求参数错误":                                      "Invalid request parameters",
+	"夹具 %s 已存在":                                   "Fixture %s already exists",
+	"已录制夹具 %s: %d条记录":                             "Recorded fixture %s: %d rows",
+	"删除夹具失败":                                      "Failed to delete fixture",
+	"夹具不存在":                                       "Fixture not found",
+	"夹具已删除":                                       "Fixture deleted",
+	"获取告警规则失败":                                    "Failed to get alert rules",
+	"告警规则 %s: %v":                                 "alert rule %s: %v",
+	"运行夹具失败: %s":                                  "Failed to run fixture: %s",
+	"%d条规则中%d条会触发":                                "%[2]d of %[1]d rules would fire",
+	"尚未执行过数据清理":                                   "No cleanup has run yet",
+	"配置重新加载失败: %s":                                "Failed to reload configuration: %s",
+	"配置已重新加载":                                     "Configuration reloaded",
+	"请求过于频繁，请稍后再试":                                "Too many requests, please try again later",
+	"不支持的数据源: %s":                                 "unsupported source: %s",
+	"不支持的聚合函数: %s":                                "unsupported aggregate: %s",
+	"无效的时间分桶: %s":                                 "invalid bucket: %s",
+	"%s 必须是RFC3339时间，如 2024-05-01T08:00:00+08:00": "%s must be an RFC3339 time, e.g. 2024-05-01T08:00:00+08:00",
+	"起始时间必须早于结束时间":                                "from must be earlier than to",
+	"时间范围内的分桶数超过上限 %d，请增大分桶":                      "too many buckets in range (limit %d), use a larger bucket",
+	"至少选择一个字段":                                    "select at least one field",
+	"不支持的字段: %s":                                  "unsupported field: %s",
+	"不支持的分组维度: %s":                                "unsupported group_by: %s",
+	"不支持的过滤字段: %s":                                "unsupported filter: %s",
+	"查询失败":                                        "Query failed",
+	"数据库连接失败: %v":                                 "database connection failed: %v",
+	"调度器未运行":                                      "scheduler is not running",
+	"系统指标采集已停滞 %v":                                "system metrics collection stalled for %v",
+	"配置未加载":                                       "configuration not loaded",
+	"数据库未完成迁移":                                    "database migration not finished",
+	"获取当前指标失败":                                    "Failed to get current metrics",
+	"获取服务状态失败":                                    "Failed to get service status",
+	"请求参数错误，weight 必须在0到100之间":                    "Invalid request parameters, weight must be between 0 and 100",
+	"服务不存在":                                       "Service not found",
+	"更新服务失败":                                      "Failed to update service",
+	"计算服务可用率失败":                                   "Failed to calculate service uptime",
+	"获取主机健康度失败":                                   "Failed to get host health",
+	"获取告警统计失败":                                    "Failed to get alert statistics",
+	"告警已解决":                                       "Alert resolved",
+	"添加系统日志失败":                                    "Failed to add system log",
+	"日志添加成功":                                      "Log added",
+	"获取IP信誉信息失败":                                  "Failed to get IP reputation",
+	"获取IP地址失败":                                    "Failed to get IP addresses",
+	"获取主机信息失败":                                    "Failed to get host information",
+	"获取IP地址变化记录失败":                                "Failed to get IP address changes",
+	"获取证书列表失败":                                    "Failed to get certificates",
+	"登记证书失败: %s":                                  "Failed to register certificate: %s",
+	"证书已登记":                                       "Certificate registered",
+	"获取注释失败":                                      "Failed to get annotations",
+	"获取硬件信息失败":                                    "Failed to get hardware information",
+	"sort 只能是 cpu 或 memory":                       "sort must be cpu or memory",
+	"获取资源占用失败":                                    "Failed to get resource usage",
+	"获取系统指标失败":                                    "Failed to get system metrics",
+	"获取服务检查历史失败":                                  "Failed to get service check history",
+	"获取系统日志失败":                                    "Failed to get system logs",
+	"获取磁盘使用情况失败":                                  "Failed to get disk usage",
+	"获取告警信息失败":                                    "Failed to get alerts",
+	"获取网络流量数据失败":                                  "Failed to get network traffic",
+	"获取主机列表失败":                                    "Failed to get hosts",
+	"主机ID无效":                                      "Invalid host ID",
+	"主机不存在":                                       "Host not found",
+	"注销主机失败":                                      "Failed to deregister host",
+	"主机已注销":                                       "Host deregistered",
+	"不允许从该地址访问":                                   "Access from this address is not allowed",
+	"告警已确认":                                       "Alert acknowledged",
+	"告警已静默":                                       "Alert silenced",
+	"不支持的操作: %s":                                  "Unsupported action: %s",
+	"备注内容不能为空，且不能超过%d个字符":                         "Comment must not be empty or longer than %d characters",
+	"备注已添加":                                       "Comment added",
+	"告警不存在":                                       "Alert not found",
+	"告警操作失败":                                      "Alert operation failed",
+	"客户端不存在":                                      "Client not found",
+	"客户端已断开":                                      "Client disconnected",
+	"获取指标序列失败":                                    "Failed to get series",
+	"获取指标名失败":                                     "Failed to get metric names",
+	"获取标签失败":                                      "Failed to get tag keys",
+	"获取标签值失败":                                     "Failed to get tag values",
+	"window 必须在60到3600秒之间":                        "window must be between 60 and 3600 seconds",
+	"获取维护操作失败":                                    "Failed to get actions",
+	"创建维护操作失败":                                    "Failed to create action",
+	"维护操作已创建":                                     "Action created",
+	"维护操作不存在":                                     "Action not found",
+	"更新维护操作失败":                                    "Failed to update action",
+	"维护操作已更新":                                     "Action updated",
+	"删除维护操作失败":                                    "Failed to delete action",
+	"维护操作已删除":                                     "Action deleted",
+	"执行成功":                                        "Run succeeded",
+	"执行失败: %s":                                    "Run failed: %s",
+	"获取执行记录失败":                                    "Failed to get action runs",
+	"创建告警规则失败":                                    "Failed to create alert rule",
+	"告警规则已创建":                                     "Alert rule created",
+	"告警规则不存在":                                     "Alert rule not found",
+	"更新告警规则失败":                                    "Failed to update alert rule",
+	"告警规则已更新":                                     "Alert rule updated",
+	"删除告警规则失败":                                    "Failed to delete alert rule",
+	"告警规则已删除":                                     "Alert rule deleted",
+	"规则名称不能为空":                                    "rule name is required",
+	"不支持的告警级别: %s":                                "unsupported level: %s",
+	"采集周期数必须大于0":                                  "periods must be greater than 0",
+	"不支持的规则类型: %s":                                "unsupported rule type: %s",
+	"状态页未启用":                                      "Status page is not enabled",
+	"format 无效，可选 html、json":                      "Invalid format, expected html or json",
+	"生成状态页失败":                                     "Failed to render status page",
+	"获取任务执行汇总失败":                                  "Failed to get job summary",
+	"获取任务执行记录失败":                                  "Failed to get job runs",
+	"获取访问日志失败":                                    "Failed to get access log",
+	"数据接入未启用":                                     "Ingest is not enabled",
+	"接入令牌无效":                                      "Invalid ingest token",
+	"读取请求失败":                                      "Failed to read request",
+	"保存指标失败":                                      "Failed to save metrics",
+	"获取每日汇总失败":                                    "Failed to get daily summaries",
+	"period 无效，可选 daily、weekly":                   "Invalid period, expected daily or weekly",
+	"生成摘要报告失败: %s":                                "Failed to generate digest: %s",
+	"发送摘要报告失败: %s":                                "Failed to send digest: %s",
+	"format 无效，可选 html、pdf":                       "Invalid format, expected html or pdf",
+	"生成报告失败: %s":                                  "Failed to generate report: %s",
+	"获取审计日志失败":                                    "Failed to get audit logs",
+	"Web终端未启用":                                    "Web terminal is not enabled",
+	"终端会话数已达上限":                                   "Too many terminal sessions",
+	"保存设置失败":                                      "Failed to save settings",
+	"设置已保存":                                       "Settings saved",
+	"获取检查包失败":                                     "Failed to get bundles",
+	"检查包不能超过1MB":                                  "bundle must not exceed 1MB",
+	"导入检查包失败":                                     "Failed to import bundle",
+	"已导入检查包 %s: %d个服务检查，%d个进程监视，%d条告警规则": "Imported bundle %s: %d service checks, %d process watches, %d alert rules",
+	"获取服务检查失败": "Failed to get service checks",
+	"服务检查不存在":  "Service check not found",
+	"删除服务检查失败": "Failed to delete service check",
+	"服务检查已删除":  "Service check deleted",
+	"获取进程监视失败": "Failed to get process watches",
+	"删除进程监视失败": "Failed to delete process watch",
+	"进程监视不存在":  "Process watch not found",
+	"进程监视已删除":  "Process watch deleted",
+	"检测到调度中断 %v（进程挂起或系统休眠），期间监控数据缺失": "Scheduler gap of %v detected (process suspended or system asleep); monitoring data is missing for this period",
+	"无效的设置":        "invalid setting",
+	"%v: 不支持修改 %s": "%v: %s cannot be changed",
+	"必须是整数":        "must be an integer",
+	"必须是字符串":       "must be a string",
+	"必须是Webhook数组": "must be an array of webhooks",
+	"未知的设置类型":      "unknown setting type",
+	"webhook %s 的地址已脱敏，请填写完整地址":                              "the URL of webhook %s is masked, please provide the full URL",
+	"不支持的指标: %s":                                             "unsupported metric: %s",
+	"不支持的对比时段: %s，可选 day、week":                               "unsupported compare_to: %s, expected day or week",
+	"不支持的变化方向: %s，可选 drop、rise":                              "unsupported direction: %s, expected drop or rise",
+	"变化百分比必须大于0":                                             "percent must be greater than 0",
+	"聚合窗口必须在60秒到%d秒之间":                                       "window must be between 60 and %d seconds",
+	"历史数据只保留%d小时，对比%s至少需要%d小时，请调大 monitor.history_hours":     "history is kept for %d hours but comparing with %s needs at least %d hours, increase monitor.history_hours",
+	"指标格式应为 数据源.字段，如 network_traffic.download_speed":         "metric must be source.field, e.g. network_traffic.download_speed",
+	"标签格式应为 key=value，当前为 %q":                                "tag must be key=value, got %q",
+	"数据源 %s 不支持标签: %s":                                       "source %s does not support tag: %s",
+	"最近一个窗口没有数据":                                             "no data in the latest window",
+	"%s没有数据，无法计算变化率":                                         "no data for %s, cannot calculate change",
+	"下降":                                                     "dropped",
+	"上升":                                                     "rose",
+	"%s: %s较%s%s %.1f%%（当前 %.2f，%s %.2f）":                    "%[1]s: %[2]s %[4]s %[5].1f%% compared with %[3]s (current %[6].2f, %[7]s %[8].2f)",
+	"%s: %s已恢复到%s水平（当前 %.2f，%s %.2f）":                        "%[1]s: %[2]s is back to the level of %[3]s (current %[4].2f, %[5]s %[6].2f)",
+	"无法识别的告警格式，支持 Alertmanager、Grafana、Uptime Kuma 的Webhook": "unrecognized alert format, supported webhooks are Alertmanager, Grafana and Uptime Kuma",
+	"解析告警消息失败: %v":                                           "failed to parse alert payload: %v",
+	"%s 已恢复: %s":                                             "%s resolved: %s",
+	"%s 返回的地址 %s 不是%s地址":                                     "%s returned %s which is not an %s address",
+	"未配置探测地址":                                                "no probe URL configured",
+	"%s HTTP状态码错误: %d":                                       "%s returned HTTP status %d",
+	"%s 返回了无效的公网IP: %q":                                      "%s returned an invalid public IP: %q",
+	"HTTP状态码错误: %d":                                          "unexpected HTTP status: %d",
+	"响应格式错误: %v":                                             "invalid response: %v",
+	"统计周期无效，可选 24h、7d、30d":                                   "invalid period, expected 24h, 7d or 30d",
+	"服务检查失败: %v":                                             "Service check failed: %v",
+	"服务状态: %s, 响应时间: %dms":                                   "Service status: %s, response time: %dms",
+	"响应时间过长: %dms":                                           "response time too long: %dms",
+	"服务名称不能为空":                                               "service name is required",
+	"服务 %s 缺少地址（host）":                                       "service %s has no host",
+	"服务 %s 的端口无效: %q":                                        "service %s has an invalid port: %q",
+	"服务 %s 的地址必须是http或https地址: %q":                           "service %s must use an http or https URL: %q",
+	"服务 %s 的检查方式不支持: %q，可选 tcp、http":                         "service %s has an unsupported type: %q, expected tcp or http",
+	"服务 %s 的权重不能为负数":                                         "service %s must not have a negative weight",
+	"统计告警失败: %v":                                             "failed to count alerts: %v",
+	"不支持的报告周期: %s，可选 daily、weekly":                           "unsupported report period: %s, expected daily or weekly",
+	"统计服务可用率失败: %v":                                          "failed to calculate service uptime: %v",
+	"统计系统指标失败: %v":                                           "failed to aggregate system metrics: %v",
+	"统计磁盘增长失败: %v":                                           "failed to calculate disk growth: %v",
+	"统计网络流量失败: %v":                                           "failed to aggregate network traffic: %v",
+	"内存使用率":                                                  "Memory usage",
+	"内存使用率（按可用内存）":                                           "Memory usage (by available memory)",
+	"内存使用率（含缓存）":                                             "Memory usage (including cache)",
+	"CPU使用率过高: %.2f%%":                                       "CPU usage too high: %.2f%%",
+	"CPU使用率恢复正常: %.2f%%":                                     "CPU usage back to normal: %.2f%%",
+	"%s过高: %.2f%%":                                           "%s too high: %.2f%%",
+	"%s恢复正常: %.2f%%":                                         "%s back to normal: %.2f%%",
+	"磁盘使用率过高: %.2f%%":                                        "Disk usage too high: %.2f%%",
+	"磁盘使用率恢复正常: %.2f%%":                                      "Disk usage back to normal: %.2f%%",
+	"该操作正在执行":                                                "the action is already running",
+	"操作名称不能为空":                                               "action name is required",
+	"无效的cron表达式 %q: %v":                                      "invalid cron expression %q: %v",
+	"命令必须是可执行文件的绝对路径: %s":                                    "command must be an absolute path to an executable: %s",
+	"命令不在 actions.allowed_commands 白名单中: %s":                 "command is not in actions.allowed_commands: %s",
+	"超时时间必须在0~86400秒之间":                                      "timeout must be between 0 and 86400 seconds",
+	"维护操作未启用（actions.enabled）":                               "actions are not enabled (actions.enabled)",
+	"维护操作 %s 已恢复正常执行":                                        "Action %s is running normally again",
+	"维护操作 %s 执行失败: %s":                                       "Action %s failed: %s",
+	"执行超时（%d秒），已终止":                                          "timed out after %d seconds, killed",
+	"进程 %s 未运行: 匹配 %q 的进程有 %d 个，至少需要 %d 个":                   "Process %s is not running: %[3]d processes match %[2]q, at least %[4]d required",
+	"进程 %s 已恢复运行: 匹配的进程有 %d 个":                               "Process %s is running again: %d processes match",
+	"进程监视名称不能为空":                                             "process watch name is required",
+	"进程监视 %s 缺少匹配的进程名（pattern）":                              "process watch %s has no pattern",
+	"进程监视 %s 的进程数不能为负数":                                      "process watch %s must not have a negative min_count",
+	"%s: 已超过 %v 未收到数据":                                       "%s: no data received for more than %v",
+	"%s: 数据已恢复上报":                                            "%s: data is being reported again",
+	"主机 %s 已超过 %v 未推送数据":                                     "Host %s has not pushed data for more than %v",
+	"主机 %s 已恢复推送数据":                                          "Host %s is pushing data again",
+	"主机 %s 已注销":                                              "Host %s deregistered",
+	"不支持的指标: %s，可选 error_rate、p50、p95、p99":                   "unsupported metric: %s, expected error_rate, p50, p95 or p99",
+	"阈值必须大于0":                                                "threshold must be greater than 0",
+	"错误率阈值不能超过100":                                           "error rate threshold cannot exceed 100",
+	"统计窗口必须在60秒到%d秒之间":                                       "window must be between 60 and %d seconds",
+	"标签格式应为 route=方法 路径，如 route=GET /api/v1/metrics":         "tag must be route=METHOD path, e.g. route=GET /api/v1/metrics",
+	"%s: %s最近%d秒%s %.2f%s，超过阈值 %.2f%s（%d个请求）":                "%[1]s: %[2]s %[4]s over the last %[3]d seconds is %[5].2f%[6]s, above the threshold %[7].2f%[8]s (%[9]d requests)",
+	"%s: %s%s已恢复到 %.2f%s":                                    "%s: %s %s is back to %.2f%s",
+	"数据库完整性检查通过":                                             "Database integrity check passed",
+	"数据库完整性检查发现%d个问题: %s":                                    "Database integrity check found %d problems: %s",
+	"%s，自动恢复失败: %v":                                          "%s, automatic recovery failed: %v",
+	"%s，已隔离到 %s 并重建数据库":                                      "%s, quarantined to %s and rebuilt the database",
+	"host 无效：只能包含字母、数字、点、下划线和连字符，最长64个字符": "invalid host: only letters, digits, dots, underscores and hyphens are allowed, up to 64 characters",
+	"metrics、disks、network 至少需要一项":        "at least one of metrics, disks and network is required",
+	"单次最多推送%d条磁盘和网卡记录":                    "at most %d disk and network rows can be pushed at once",
+	"时间戳 %s 晚于当前时间，请检查远程主机的时钟":            "timestamp %s is in the future, check the clock of the remote host",
+	"无效的公网IP: %q":          "invalid public IP: %q",
+	"公网IP %s 被列入黑名单: %s":   "Public IP %s is listed on blocklists: %s",
+	"公网IP %s 已从所有黑名单中移除":   "Public IP %s has been removed from all blocklists",
+	"无效的IP: %s":            "invalid IP: %s",
+	"无效的证书地址: %s":          "invalid certificate endpoint: %s",
+	"%s(%d天)":              "%s (%d days)",
+	"TLS证书即将到期: %s":        "TLS certificates expiring soon: %s",
+	"所有TLS证书有效期正常":         "All TLS certificates are valid",
+	"服务端未返回证书":             "the server did not return a certificate",
+	"未找到PEM格式证书: %s":       "no PEM certificate found: %s",
+	"探测公网IP失败: %v":         "failed to detect public IP: %v",
+	"网卡 %s 地址变化: %s -> %s": "Interface %s address changed: %s -> %s",
+	"公网IP":                 "Public IP",
+	"公网IPv6":               "Public IPv6",
+	"%s发生变化: %s -> %s":     "%s changed: %s -> %s",
+	"（%s）":                 " (%s)",
+	"%s，请检查DNS记录":          "%s, please check DNS records",
+	"固定地址已从本机网卡上消失: %s":    "Static addresses are missing from local interfaces: %s",
+	"所有固定地址均已恢复":           "All static addresses are back",
+	"无":                    "none",
+	"夹具名称不能为空":             "fixture name is required",
+	"结束时间必须晚于开始时间":         "end must be later than start",
+	"时间范围内的记录超过%d条，请缩小录制范围":  "more than %d rows in range, please narrow the recording range",
+	"时间范围内没有数据":              "no data in range",
+	"夹具数据格式错误: %v":           "invalid fixture data: %v",
+	"%s规则不能在夹具上运行":           "%s rules cannot run on fixtures",
+	"%.1fMB（%.2f%%）":         "%.1fMB (%.2f%%)",
+	"%s（%s，%s）%s":            "%s (%s, %s) %s",
+	"；":                      "; ",
+	"网卡接入: %s":               "Interfaces added: %s",
+	"网卡移除: %s":               "Interfaces removed: %s",
+	"CPU占用最高: %s":            "Top CPU: %s",
+	"内存占用最高: %s":             "Top memory: %s",
+	"🔥 告警触发":                 "🔥 Alert fired",
+	"✅ 告警恢复":                 "✅ Alert resolved",
+	"👀 告警已确认":                "👀 Alert acknowledged",
+	"🔕 告警已静默":                "🔕 Alert silenced",
+	"⬆️ 告警升级":                "⬆️ Alert escalated",
+	"⬇️ 告警降级":                "⬇️ Alert de-escalated",
+	"触发":                     "fired",
+	"恢复":                     "resolved",
+	"升级":                     "escalated",
+	"降级":                     "de-escalated",
+	"昨天同期":                   "the same time yesterday",
+	"上周同期":                   "the same time last week",
+	"错误率":                    "error rate",
+	"P50延迟":                  "P50 latency",
+	"P95延迟":                  "P95 latency",
+	"P99延迟":                  "P99 latency",
+	"容器":                     "container",
+	"服务":                     "service",
+	"会话":                     "session",
+	"分组":                     "slice",
+	"进程":                     "process",
+	"服务器内部错误":                "Internal server error",
+	"接口不存在":                  "API endpoint not found",
+	"服务正在重启":                 "Server is restarting",
+	"连接数已达上限":                "Connection limit reached",
+	"types 无效，可选 %s":         "Invalid types, expected %s",
+	"interval 必须是非负的秒数":      "interval must be a non-negative number of seconds",
+	"protocol 只能是 tcp 或 udp": "protocol must be tcp or udp",
+	"port 必须在1到65535之间":      "port must be between 1 and 65535",
+	"获取网络连接失败":               "Failed to get network connections",
+	"获取监听端口失败":               "Failed to get listening ports",
+	"获取端口变化记录失败":             "Failed to get port changes",
+	"端口 %s 开始监听%s":           "Port %s started listening%s",
+	"端口 %s 停止监听%s":           "Port %s stopped listening%s",
+	"新端口开始监听: %s%s":          "New port listening: %s%s",
+	"应监听的端口已停止监听: %s":        "Expected ports are no longer listening: %s",
+	"所有应监听的端口均已恢复":           "All expected ports are listening again",
+	"认证日志文件不存在: %s":          "Auth log file not found: %s",
+	"SSH登录成功: %s@%s（%s）":     "SSH login: %s@%s (%s)",
+	"SSH登录失败 %d 次，来自 %s（用户: %s）": "%d failed SSH login(s) from %s (users: %s)",
+	"用户 %s 从新地址 %s 登录SSH（%s）":    "User %s logged in via SSH from new address %s (%s)",
+	"SSH暴力破解: %s 在%d秒内登录失败%d次":   "SSH brute force: %s failed to log in %[3]d times within %[2]d seconds",
+	"%s 已停止SSH登录尝试":              "%s stopped SSH login attempts",
+	"success 必须是 true 或 false":   "success must be true or false",
+	"获取SSH登录记录失败":                "Failed to get SSH logins",
+	"jail不存在":                    "Jail not found",
+	"fail2ban %s 封禁了 %d 个地址: %s": "fail2ban %s banned %d address(es): %s",
+	"无效的IP地址: %s":                "Invalid IP address: %s",
+	"fail2ban集成未启用":              "fail2ban integration is not enabled",
+	"读取fail2ban状态失败":             "Failed to read fail2ban status",
+	"获取fail2ban封禁历史失败":           "Failed to get fail2ban ban history",
+	"解封失败":                       "Failed to unban",
+	"已解封 %s":                     "Unbanned %s",
+	"有 %d 个安全更新待安装: %s":          "%d security update(s) pending: %s",
+	"安全更新已全部安装":                  "All security updates are installed",
+	"系统需要重启以完成更新":                "A reboot is required to complete updates",
+	"系统需要重启以完成更新: %s":            "A reboot is required to complete updates: %s",
+	"系统已重启，不再需要重启":               "The system has been rebooted; no reboot is required",
+	"有 %d 个软件包可更新":               "%d package update(s) available",
+	"待更新的软件包数已恢复正常":              "Pending package updates are back to normal",
+	"未找到支持的包管理器（apt、dnf、yum）":    "No supported package manager found (apt, dnf, yum)",
+	"未找到包管理器 %s":                 "Package manager %s not found",
+	"%s 等%d个":                    "%s and others (%d total)",
+	"获取软件包更新检查结果失败":              "Failed to get package update check results",
+	"所有NTP服务器均查询失败: %s":          "All NTP servers failed: %s",
+	"时钟偏差已恢复正常: %.3f秒":           "Clock drift is back to normal: %.3fs",
+	"本机时钟比 %s 快 %.3f 秒":          "Local clock is %[2].3f seconds ahead of %[1]s",
+	"本机时钟比 %s 慢 %.3f 秒":          "Local clock is %[2].3f seconds behind %[1]s",
+	"获取时钟偏差检查结果失败":               "Failed to get clock drift check results",
+	"欠压":                         "under-voltage",
+	"频率受限":                       "frequency capped",
+	"降频":                         "throttled",
+	"温度软限制":                      "soft temperature limit",
+	"SoC温度过高: %s %.1f°C":         "SoC temperature too high: %s %.1f°C",
+	"SoC温度恢复正常: %.1f°C":          "SoC temperature is back to normal: %.1f°C",
+	"SoC处于受限状态: %s":              "SoC is limited: %s",
+	"SoC已解除欠压和降频":                "SoC is no longer under-voltage or throttled",
+	"获取传感器读数失败":                  "Failed to get sensor readings",
+	"UPS已切换到电池供电":                "UPS switched to battery power",
+	"UPS已切换到电池供电，电量 %.0f%%":      "UPS switched to battery power, charge %.0f%%",
+	"UPS已恢复市电供电":                 "UPS is back on mains power",
+	"UPS电池电量低，即将耗尽":              "UPS battery is low and about to run out",
+	"UPS电池供电，剩余续航仅 %d 秒":         "UPS on battery, only %d seconds of runtime left",
+	"UPS剩余续航已恢复正常":               "UPS runtime is back to normal",
+	"upsd 中没有配置UPS":              "No UPS is configured in upsd",
+	"upsd 返回错误: %s":              "upsd returned an error: %s",
+	"apcupsd 没有返回状态":             "apcupsd returned no status",
+	"apcupsd 与UPS的通信中断":          "apcupsd lost communication with the UPS",
+	"风扇故障: %s（%s）":               "Fan failure: %s (%s)",
+	"电源故障: %s（%s）":               "Power supply failure: %s (%s)",
+	"%s 已恢复正常":                   "%s is back to normal",
+	"ipmitool 没有返回传感器读数":         "ipmitool returned no sensor readings",
+	"RAID阵列 %s 未激活":              "RAID array %s is inactive",
+	"RAID阵列 %s 已降级: %d/%d 个成员正常": "RAID array %s is degraded: %d/%d members working",
+	"，%d 个成员故障":                  ", %d failed",
+	"，正在重建 %.1f%%（预计剩余 %s）":      ", rebuilding %.1f%% (%s remaining)",
+	"RAID阵列 %s 已恢复正常":            "RAID array %s is back to normal",
+	"获取RAID阵列状态失败":               "Failed to get RAID array status",
+	"ZFS存储池 %s 状态为 %s":           "ZFS pool %s is %s",
+	"ZFS存储池 %s 已用 %.0f%%":        "ZFS pool %s is %.0f%% full",
+	"ZFS存储池 %s 已恢复ONLINE":        "ZFS pool %s is back ONLINE",
+	"ZFS存储池 %s 已用容量已恢复正常":        "ZFS pool %s usage is back to normal",
+	"获取ZFS存储池状态失败":               "Failed to get ZFS pool status",
+	"磁盘 %s 预计 %.1f 天后写满（当前 %.1f%%，每天增长 %.2f GB）":                  "Disk %s is expected to be full in %.1f days (%.1f%% used, growing %.2f GB per day)",
+	"磁盘 %s 预计不会在 %.0f 天内写满":                                       "Disk %s is no longer expected to be full within %.0f days",
+	"获取磁盘写满预测失败":                                                  "Failed to forecast disk usage",
+	"数据源 %s 没有数值字段: %s":                                           "Source %s has no numeric field: %s",
+	"%s 异常偏高: 最近 %d 分钟均值 %.2f，过去 %d 小时均值 %.2f±%.2f（偏离 %.1f 倍标准差）": "%s is unusually high: %d-minute average %.2f, %d-hour baseline %.2f±%.2f (%.1f standard deviations)",
+	"%s 异常偏低: 最近 %d 分钟均值 %.2f，过去 %d 小时均值 %.2f±%.2f（偏离 %.1f 倍标准差）": "%s is unusually low: %d-minute average %.2f, %d-hour baseline %.2f±%.2f (%.1f standard deviations)",
+	"%s 已回到正常范围":                                                  "%s is back within its normal range",
+	"days 必须是2到3650之间的整数":                                         "days must be an integer between 2 and 3650",
+	"生成容量规划报告失败":                                                  "Failed to build capacity report",
+	"每日汇总的数据不足，无法预测增长趋势（需要启用 rollup 并积累至少 %d 天的数据）":               "Not enough daily summaries to predict growth (enable rollup and collect at least %d days of data)",
+	"未来 %d 天内各项资源预计不会达到上限，暂不需要扩容":                                 "No resource is expected to reach its limit within %d days; no upgrade needed yet",
+	"磁盘 %s":                       "Disk %s",
+	"建议扩容磁盘或清理数据":                 "consider a larger disk or cleaning up data",
+	"内存":                          "Memory",
+	"建议升级到内存更大的配置":                "consider a plan with more memory",
+	"网卡 %s 的峰值速率":                 "Peak rate on %s",
+	"建议升级带宽":                      "consider upgrading bandwidth",
+	"%s 已达到上限 %g%s（当前 %.1f%s），%s": "%s has reached its limit of %g%s (currently %.1f%s); %s",
+	"%s 预计 %.0f 天后（%s）达到 %g%s（当前 %.1f%s，每月增长 %.1f%s），%s":       "%[1]s is expected to reach %[4]g%[5]s in %[2].0f days (%[3]s; currently %[6].1f%[7]s, growing %[8].1f%[9]s per month); %[10]s",
+	"服务 %s 的最长检查时间（%d秒）必须小于 monitor.service_interval（%d秒）":     "Longest check time of service %s (%d seconds) must be less than monitor.service_interval (%d seconds)",
+	"服务检查连续 %d 次失败: %v":                                        "Service check failed %d times in a row: %v",
+	"服务 %s 的重试次数必须在0到10之间":                                     "Retries for service %s must be between 0 and 10",
+	"服务 %s 的重试间隔必须在0到300秒之间":                                   "Retry interval for service %s must be between 0 and 300 seconds",
+	"服务 %s 的超时时间必须在0到300秒之间":                                   "Timeout for service %s must be between 0 and 300 seconds",
+	"服务 %s 的响应时间阈值不能为负数":                                       "Latency thresholds for service %s cannot be negative",
+	"服务 %s 的 latency_critical（%dms）必须大于 latency_warning（%dms）": "latency_critical (%[2]dms) for service %[1]s must be greater than latency_warning (%[3]dms)",
+	"DNSBL拒绝了查询": "The DNSBL refused the query",
+	"确认告警":       "Acknowledge alert",
+	"解决告警":       "Resolve alert",
+	"静默告警":       "Silence alert",
+}
@@ -0,0 +1,108 @@
+package i18n
+
+import (
+	"fmt"
+	"server-monitor/config"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 支持的语言
+const (
+	ZhCN = "zh-CN"
+	EnUS = "en-US"
+)
+
+// catalogs 各语言的消息目录，以中文原文为键；zh-CN直接使用原文，不需要目录
+var catalogs = map[string]map[string]string{
+	EnUS: enUS,
+}
+
+// Message 可翻译的消息，同时实现 error：Error() 使用 i18n.locale，In 翻译为指定语言；
+// 参数中的 Message 随外层消息一起翻译，第一个 error 参数作为被包装的错误，可用 errors.Is 判断
+type Message struct {
+	ID   string
+	Args []interface{}
+}
+
+// Errorf 创建可翻译的错误，msgid 为中文格式化字符串
+func Errorf(msgid string, args ...interface{}) error {
+	return &Message{ID: msgid, Args: args}
+}
+
+func (m *Message) Error() string {
+	return m.In(config.AppConfig.I18n.Locale)
+}
+
+// In 将消息翻译为指定语言
+func (m *Message) In(locale string) string {
+	return T(locale, m.ID, m.Args...)
+}
+
+// Unwrap 返回参数中的第一个 error
+func (m *Message) Unwrap() error {
+	for _, arg := range m.Args {
+		if err, ok := arg.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// T 将消息翻译为指定语言，有参数时作为格式化字符串处理；目录中没有的消息使用中文原文
+func T(locale, msgid string, args ...interface{}) string {
+	format := msgid
+	if translated, ok := catalogs[locale][msgid]; ok {
+		format = translated
+	}
+	if len(args) == 0 {
+		return format
+	}
+	localized := make([]interface{}, len(args))
+	for i, arg := range args {
+		if m, ok := arg.(*Message); ok {
+			arg = m.In(locale)
+		}
+		localized[i] = arg
+	}
+	return fmt.Sprintf(format, localized...)
+}
+
+// Sprintf 按 i18n.locale 翻译消息，用于告警、系统日志等服务端生成的消息
+func Sprintf(msgid string, args ...interface{}) string {
+	return T(config.AppConfig.I18n.Locale, msgid, args...)
+}
+
+// Match 按权重从 Accept-Language 中选择第一个支持的语言，如 en-GB 对应 en-US、zh-TW 对应 zh-CN；没有时返回空字符串
+func Match(acceptLanguage string) string {
+	type tag struct {
+		name   string
+		weight float64
+	}
+	tags := []tag{}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if w, err := strconv.ParseFloat(q, 64); err == nil {
+				weight = w
+			}
+		}
+		if name != "" && weight > 0 {
+			tags = append(tags, tag{strings.ToLower(name), weight})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].weight > tags[j].weight })
+
+	for _, t := range tags {
+		language, _, _ := strings.Cut(t.name, "-")
+		switch language {
+		case "zh":
+			return ZhCN
+		case "en":
+			return EnUS
+		}
+	}
+	return ""
+}
@@ -0,0 +1,336 @@
+package instance
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"server-monitor/database"
+	"server-monitor/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 归档格式版本，格式不兼容时递增
+const archiveVersion = 1
+
+// 导入时每批写入的记录数
+const restoreBatch = 500
+
+const (
+	manifestFile = "manifest.json"
+	configFile   = "config.yaml"
+	tableDir     = "tables/"
+)
+
+// Manifest 归档说明：导出时间、来源主机及各数据表的记录数
+type Manifest struct {
+	Version   int              `json:"version"`
+	CreatedAt time.Time        `json:"created_at"`
+	Hostname  string           `json:"hostname"`
+	History   bool             `json:"history"` // 是否包含历史数据
+	Config    bool             `json:"config"`  // 是否包含配置文件
+	Tables    map[string]int64 `json:"tables"`  // 数据表 -> 记录数
+}
+
+// table 可导出的数据表，history 为历史数据，只在导出时指定包含历史数据才导出
+type table struct {
+	name    string
+	history bool
+	dump    func(tx *gorm.DB, w io.Writer) (int64, error)
+	restore func(tx *gorm.DB, r io.Reader) (int64, error)
+}
+
+// 按导入顺序排列：先导入设置和定义，再导入历史数据
+var tables = []table{
+	newTable[models.Setting]("settings", false),
+	newTable[models.AlertRule]("alert_rules", false),
+	newTable[models.ServiceStatus]("service_statuses", false),
+	newTable[models.ServiceCheck]("service_checks", false),
+	newTable[models.ServiceCheckResult]("service_check_results", true),
+	newTable[models.ProcessWatch]("process_watches", false),
+	newTable[models.Certificate]("certificates", false),
+	newTable[models.AlertFixture]("alert_fixtures", false),
+	newTable[models.Action]("actions", false),
+	newTable[models.Server]("servers", false),
+
+	newTable[models.SystemMetrics]("system_metrics", true),
+	newTable[models.NetworkTraffic]("network_traffics", true),
+	newTable[models.DiskUsage]("disk_usages", true),
+	newTable[models.ProcessInfo]("process_infos", true),
+	newTable[models.SystemLog]("system_logs", true),
+	newTable[models.Alert]("alerts", true),
+	newTable[models.AlertEvent]("alert_events", true),
+	newTable[models.AlertComment]("alert_comments", true),
+	newTable[models.Annotation]("annotations", true),
+	newTable[models.Series]("series", true),
+	newTable[models.SeriesTag]("series_tags", true),
+	newTable[models.IPReputation]("ip_reputations", true),
+	newTable[models.IPAddressChange]("ip_address_changes", true),
+	newTable[models.PortChange]("port_changes", true),
+	newTable[models.SSHLogin]("ssh_logins", true),
+	newTable[models.Fail2banBan]("fail2ban_bans", true),
+	newTable[models.UpdateCheck]("update_checks", true),
+	newTable[models.ClockCheck]("clock_checks", true),
+	newTable[models.SensorReading]("sensor_readings", true),
+	newTable[models.RAIDStatus]("raid_statuses", true),
+	newTable[models.ZFSPool]("zfs_pools", true),
+	newTable[models.IPGeolocation]("ip_geolocations", true),
+	newTable[models.JobRun]("job_runs", true),
+	newTable[models.AuditLog]("audit_logs", true),
+	newTable[models.AccessLog]("access_logs", true),
+	newTable[models.DailySummary]("daily_summaries", true),
+	newTable[models.ActionRun]("action_runs", true),
+}
+
+// newTable 按模型的json标签导出和导入数据表，逐条读写，不把整张表加载到内存
+func newTable[T any](name string, history bool) table {
+	return table{
+		name:    name,
+		history: history,
+		dump: func(tx *gorm.DB, w io.Writer) (int64, error) {
+			rows, err := tx.Model(new(T)).Order("rowid").Rows()
+			if err != nil {
+				return 0, err
+			}
+			defer rows.Close()
+
+			encoder := json.NewEncoder(w)
+			var count int64
+			for rows.Next() {
+				var row T
+				if err := tx.ScanRows(rows, &row); err != nil {
+					return count, err
+				}
+				if err := encoder.Encode(&row); err != nil {
+					return count, err
+				}
+				count++
+			}
+			return count, rows.Err()
+		},
+		restore: func(tx *gorm.DB, r io.Reader) (int64, error) {
+			// 清空后按原主键写入，跳过钩子以保留原创建时间
+			if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(new(T)).Error; err != nil {
+				return 0, err
+			}
+			tx = tx.Session(&gorm.Session{SkipHooks: true})
+
+			decoder := json.NewDecoder(r)
+			batch := make([]T, 0, restoreBatch)
+			var count int64
+			for {
+				var row T
+				err := decoder.Decode(&row)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return count, fmt.Errorf("数据格式错误: %v", err)
+				}
+				if batch = append(batch, row); len(batch) == restoreBatch {
+					if err := tx.Create(&batch).Error; err != nil {
+						return count, err
+					}
+					count += int64(len(batch))
+					batch = batch[:0]
+				}
+			}
+			if len(batch) > 0 {
+				if err := tx.Create(&batch).Error; err != nil {
+					return count, err
+				}
+				count += int64(len(batch))
+			}
+			return count, nil
+		},
+	}
+}
+
+// Export 将配置文件、设置、告警规则和服务定义（history 为 true 时包括历史数据）导出为 tar.gz 归档；
+// configData 为空时不包含配置文件。所有数据表在同一个读事务中导出，得到一致的快照
+func Export(w io.Writer, configData []byte, history bool) (*Manifest, error) {
+	hostname, _ := os.Hostname()
+	manifest := &Manifest{
+		Version:   archiveVersion,
+		CreatedAt: time.Now(),
+		Hostname:  hostname,
+		History:   history,
+		Config:    len(configData) > 0,
+		Tables:    make(map[string]int64),
+	}
+
+	// tar需要预先知道每个文件的大小，数据表先写入临时文件
+	dumps := make([]*os.File, 0, len(tables))
+	defer func() {
+		for _, f := range dumps {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, t := range tables {
+			if t.history && !history {
+				continue
+			}
+			f, err := os.CreateTemp("", "server-monitor-"+t.name+"-*.json")
+			if err != nil {
+				return err
+			}
+			dumps = append(dumps, f)
+			count, err := t.dump(tx, f)
+			if err != nil {
+				return fmt.Errorf("导出 %s 失败: %v", t.name, err)
+			}
+			manifest.Tables[t.name] = count
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(archive, manifestFile, encoded); err != nil {
+		return nil, err
+	}
+	if manifest.Config {
+		if err := writeEntry(archive, configFile, configData); err != nil {
+			return nil, err
+		}
+	}
+
+	i := 0
+	for _, t := range tables {
+		if t.history && !history {
+			continue
+		}
+		if err := copyEntry(archive, tableDir+t.name+".json", dumps[i]); err != nil {
+			return nil, err
+		}
+		i++
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, gz.Close()
+}
+
+// writeEntry 写入归档中的一个文件
+func writeEntry(archive *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: time.Now()}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := archive.Write(data)
+	return err
+}
+
+// copyEntry 将临时文件的内容写入归档
+func copyEntry(archive *tar.Writer, name string, f *os.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	header := &tar.Header{Name: name, Mode: 0600, Size: info.Size(), ModTime: time.Now()}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(archive, f)
+	return err
+}
+
+// Archive 正在读取的归档，先读取说明和配置文件，再由 Restore 导入数据表
+type Archive struct {
+	Manifest *Manifest
+	Config   []byte // 归档中的配置文件，不包含时为空
+
+	gz     *gzip.Reader
+	reader *tar.Reader
+	next   *tar.Header // 已读取但尚未处理的文件头
+}
+
+// Open 读取归档的说明和配置文件，校验归档格式版本
+func Open(r io.Reader) (*Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("归档格式错误: %v", err)
+	}
+	a := &Archive{gz: gz, reader: tar.NewReader(gz)}
+
+	header, err := a.reader.Next()
+	if err != nil || header.Name != manifestFile {
+		return nil, fmt.Errorf("归档格式错误: 缺少 %s", manifestFile)
+	}
+	if err := json.NewDecoder(a.reader).Decode(&a.Manifest); err != nil {
+		return nil, fmt.Errorf("归档格式错误: %v", err)
+	}
+	if a.Manifest.Version != archiveVersion {
+		return nil, fmt.Errorf("不支持的归档版本: %d", a.Manifest.Version)
+	}
+
+	header, err = a.reader.Next()
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("归档格式错误: %v", err)
+	}
+	if header != nil && header.Name == configFile {
+		if a.Config, err = io.ReadAll(a.reader); err != nil {
+			return nil, fmt.Errorf("归档格式错误: %v", err)
+		}
+		header, err = a.reader.Next()
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("归档格式错误: %v", err)
+		}
+	}
+	a.next = header
+	return a, nil
+}
+
+// Restore 在一个事务中导入归档中的数据表：每张表先清空再写入，任一表失败时数据库保持不变；
+// 归档中没有的表（如未导出的历史数据）不受影响，返回各表导入的记录数
+func (a *Archive) Restore() (map[string]int64, error) {
+	known := make(map[string]table, len(tables))
+	for _, t := range tables {
+		known[tableDir+t.name+".json"] = t
+	}
+
+	result := make(map[string]int64)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for header := a.next; header != nil; {
+			t, ok := known[header.Name]
+			if !ok {
+				return fmt.Errorf("归档中包含未知的文件: %s", header.Name)
+			}
+			count, err := t.restore(tx, a.reader)
+			if err != nil {
+				return fmt.Errorf("导入 %s 失败: %v", t.name, err)
+			}
+			result[t.name] = count
+
+			header, err = a.reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("归档格式错误: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, a.gz.Close()
+}
@@ -1,39 +1,108 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // 内置时区数据库，精简的容器镜像中没有 /usr/share/zoneinfo 时 i18n.timezone 依然可用
 
 	"server-monitor/api"
 	"server-monitor/config"
 	"server-monitor/database"
+	"server-monitor/https"
+	"server-monitor/instance"
+	"server-monitor/notify"
 	"server-monitor/scheduler"
+	"server-monitor/selftest"
+	"server-monitor/settings"
+	"server-monitor/upgrade"
 	"server-monitor/websocket"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// 子命令：生成带注释的默认配置文件后退出
+	if len(os.Args) > 1 && os.Args[1] == "generate-config" {
+		generateConfig(os.Args[2:])
+		return
+	}
+	// 子命令：导出或导入实例的配置、规则、服务定义及历史数据，用于迁移到新主机
+	if len(os.Args) > 1 && os.Args[1] == "export-instance" {
+		exportInstance(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import-instance" {
+		importInstance(os.Args[2:])
+		return
+	}
+
+	selfTestOnly := flag.Bool("selftest", false, "run the startup self-test and exit (non-zero on failure)")
+	configFile := flag.String("config", "", "path to the config file (default ./config/config.yaml)")
+	port := flag.String("port", "", "HTTP listen port, overrides server.port")
+	dbPath := flag.String("db", "", "SQLite database file, overrides database.database")
+	encrypt := flag.Bool("encrypt", false, "read a secret from stdin and print its encrypted form for the config file")
+	minimal := flag.Bool("minimal", false, "keep all data in memory only (no database file, short history), overrides minimal.enabled")
+	flag.Parse()
+
 	// 设置日志格式
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	log.Println("Starting Server Monitor...")
 
-	// 加载配置
+	// 加载配置，命令行参数优先于环境变量和配置文件
+	config.SetConfigFile(*configFile)
+	config.Override("server.port", *port)
+	config.Override("database.database", *dbPath)
+	if *minimal {
+		config.Override("minimal.enabled", "true")
+	}
 	if err := config.LoadConfig(); err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// 加密配置值后退出
+	if *encrypt {
+		encryptSecret()
+		return
+	}
+
 	// 初始化数据库
 	if err := database.InitDatabase(); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
+	// 加载运行时设置，覆盖配置文件；设置无效时继续使用配置文件启动
+	if err := settings.Load(); err != nil {
+		log.Printf("Warning: failed to apply saved settings, using config file: %v", err)
+	}
+
+	// 只执行自检，用于部署流水线
+	if *selfTestOnly {
+		if !selftest.Run().Passed {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// 启动自检
+	if config.AppConfig.SelfTest.Enabled {
+		if !selftest.Run().Passed && config.AppConfig.SelfTest.FailOnError {
+			log.Fatalf("Self-test failed, refusing to start")
+		}
+	}
+
+	// 注册告警通知
+	notify.Init()
+
 	// 创建WebSocket Hub
 	hub := websocket.NewHub()
 	go hub.Run()
@@ -49,10 +118,10 @@ func main() {
 	}
 
 	// 设置路由
-	router := api.SetupRoutes()
+	router := api.SetupRoutes(hub)
 
 	// 添加WebSocket路由
-	router.GET("/ws", websocket.ServeWebSocket(hub))
+	router.GET("/ws", api.RestrictNetworks(), api.LimitWebSocket(), websocket.ServeWebSocket(hub))
 
 	// 添加静态文件服务（用于前端页面和静态资源）
 	router.Static("/static", "./static")
@@ -61,23 +130,76 @@ func main() {
 		c.File("index.html")
 	})
 
-	// 创建HTTP服务器
+	// 创建HTTP服务器，升级启动时复用旧进程的监听套接字
 	server := &http.Server{
 		Addr:    fmt.Sprintf("%s:%s", config.AppConfig.Server.Host, config.AppConfig.Server.Port),
 		Handler: router,
 	}
+	listener, err := upgrade.Listen(server.Addr)
+	if err != nil {
+		log.Fatalf("Failed to listen on %s: %v", server.Addr, err)
+	}
+
+	// 启用HTTPS时加载证书或准备自动申请证书
+	server.TLSConfig, err = https.TLSConfig()
+	if err != nil {
+		log.Fatalf("Failed to configure TLS: %v", err)
+	}
 
 	// 启动调度器
 	sched.Start()
 
+	// 配置热加载：监听配置文件变化，并响应SIGHUP信号
+	if config.AppConfig.Server.WatchConfig {
+		config.WatchConfig()
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			log.Println("Received SIGHUP, reloading config...")
+			if err := config.Reload(); err != nil {
+				log.Printf("Error reloading config, keeping previous settings: %v", err)
+			}
+		}
+	}()
+
 	// 启动HTTP服务器
 	go func() {
-		log.Printf("Server starting on %s:%s", config.AppConfig.Server.Host, config.AppConfig.Server.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if server.TLSConfig != nil {
+			log.Printf("Server starting on https://%s", listener.Addr())
+			err = server.ServeTLS(listener, "", "")
+		} else {
+			log.Printf("Server starting on %s", listener.Addr())
+			err = server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
 
+	// HTTP重定向到HTTPS
+	https.StartRedirect()
+
+	// 启动完成，升级启动时通知旧进程退出
+	upgrade.Ready()
+
+	// 不停机升级：收到SIGUSR2时启动新版本进程并移交监听套接字；
+	// 新进程启动后会运行自己的调度器，先停止本进程的调度器，避免两个进程同时写入数据库，未能接管时恢复
+	upgrades := make(chan os.Signal, 1)
+	upgrade.Notify(upgrades)
+	go func() {
+		for range upgrades {
+			log.Println("Received SIGUSR2, upgrading...")
+			sched.Stop()
+			if err := upgrade.Start(listener, sched.Resume); err != nil {
+				log.Printf("Error starting new process: %v", err)
+				sched.Resume()
+			}
+		}
+	}()
+
 	// 等待中断信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -85,16 +207,204 @@ func main() {
 
 	log.Println("Shutting down server...")
 
-	// 停止调度器
+	// 停止调度器（升级移交时已停止）
 	sched.Stop()
 
 	// 优雅关闭HTTP服务器
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// 升级移交时新连接由新进程接受，已连接的WebSocket/SSE客户端不断开，等待其自行断开；
+	// 否则通知客户端重连并等待连接正常关闭
+	handoff := upgrade.HandingOff()
+	if handoff {
+		hub.Drain(ctx)
+	} else {
+		hub.Shutdown(ctx)
+	}
+
+	if err := https.ShutdownRedirect(ctx); err != nil {
+		log.Printf("Error shutting down HTTP redirect: %v", err)
+	}
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		if !handoff {
+			log.Fatalf("Server forced to shutdown: %v", err)
+		}
+		log.Printf("Closing connections still open after handoff: %v", err)
+		server.Close()
 	}
 
+	// 写入缓冲的访问日志
+	api.FlushAccessLog()
+
 	log.Println("Server exited")
-} 
\ No newline at end of file
+} 
+
+// encryptSecret 从标准输入读取一行明文，输出可写入配置文件的加密值
+func encryptSecret() {
+	fmt.Fprintln(os.Stderr, "Enter the value to encrypt:")
+	reader := bufio.NewReader(os.Stdin)
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		log.Fatalf("Failed to read value: %v", err)
+	}
+
+	encrypted, err := config.EncryptSecret(strings.TrimRight(line, "\r\n"))
+	if err != nil {
+		log.Fatalf("Failed to encrypt value: %v", err)
+	}
+	fmt.Println(encrypted)
+}
+
+// generateConfig 以默认值生成带注释的配置文件，输出到标准输出或原子地写入 -o 指定的文件
+func generateConfig(args []string) {
+	fs := flag.NewFlagSet("generate-config", flag.ExitOnError)
+	output := fs.String("o", "", "write the config to this file instead of stdout")
+	force := fs.Bool("force", false, "overwrite the output file if it exists")
+	fs.Parse(args)
+
+	data, err := config.Generate()
+	if err != nil {
+		log.Fatalf("Failed to generate config: %v", err)
+	}
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+
+	if _, err := os.Stat(*output); err == nil && !*force {
+		log.Fatalf("%s already exists, use -force to overwrite", *output)
+	}
+	if err := config.WriteFile(*output, data); err != nil {
+		log.Fatalf("Failed to write config: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Config written to %s\n", *output)
+}
+
+// exportInstance 将配置文件、设置、告警规则、服务定义（-history 时包括历史数据）导出为一个归档文件
+func exportInstance(args []string) {
+	fs := flag.NewFlagSet("export-instance", flag.ExitOnError)
+	output := fs.String("o", "server-monitor-instance.tar.gz", "archive file to write, - for stdout")
+	history := fs.Bool("history", false, "include collected history (metrics, logs, alerts)")
+	force := fs.Bool("force", false, "overwrite the archive file if it exists")
+	configFile := fs.String("config", "", "path to the config file (default ./config/config.yaml)")
+	dbPath := fs.String("db", "", "SQLite database file, overrides database.database")
+	fs.Parse(args)
+
+	config.SetConfigFile(*configFile)
+	config.Override("database.database", *dbPath)
+	if err := config.LoadConfig(); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if config.AppConfig.Minimal.Enabled {
+		log.Fatalf("Cannot export in minimal mode: data is only kept in the memory of the running process")
+	}
+
+	var configData []byte
+	if path := config.FileUsed(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read config: %v", err)
+		}
+		configData = data
+	}
+	if err := database.InitDatabase(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	out := os.Stdout
+	if *output != "-" {
+		mode := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+		if *force {
+			mode = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		}
+		f, err := os.OpenFile(*output, mode, 0600)
+		if os.IsExist(err) {
+			log.Fatalf("%s already exists, use -force to overwrite", *output)
+		}
+		if err != nil {
+			log.Fatalf("Failed to create archive: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	manifest, err := instance.Export(out, configData, *history)
+	if err != nil {
+		if *output != "-" {
+			os.Remove(*output)
+		}
+		log.Fatalf("Failed to export instance: %v", err)
+	}
+	var rows int64
+	for _, count := range manifest.Tables {
+		rows += count
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d tables (%d rows) to %s\n", len(manifest.Tables), rows, *output)
+}
+
+// importInstance 从 export-instance 生成的归档恢复配置文件和数据库，目标已存在时需要 -force
+func importInstance(args []string) {
+	fs := flag.NewFlagSet("import-instance", flag.ExitOnError)
+	force := fs.Bool("force", false, "overwrite an existing config file and replace data in an existing database")
+	configFile := fs.String("config", "", "where to write the archived config file (default ./config/config.yaml)")
+	dbPath := fs.String("db", "", "SQLite database file, overrides database.database")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatalf("Usage: import-instance [-force] [-config path] [-db path] <archive>")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Failed to open archive: %v", err)
+	}
+	defer f.Close()
+	archive, err := instance.Open(f)
+	if err != nil {
+		log.Fatalf("Failed to read archive: %v", err)
+	}
+
+	// 配置文件校验通过后原子地写入，之后按新配置连接数据库
+	if archive.Config != nil {
+		path := *configFile
+		if path == "" {
+			path = "config/config.yaml"
+		}
+		if _, err := os.Stat(path); err == nil && !*force {
+			log.Fatalf("%s already exists, use -force to overwrite", path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			log.Fatalf("Failed to write config: %v", err)
+		}
+		if err := config.WriteFile(path, archive.Config); err != nil {
+			log.Fatalf("Failed to write config: %v", err)
+		}
+		*configFile = path
+	}
+
+	config.SetConfigFile(*configFile)
+	config.Override("database.database", *dbPath)
+	if err := config.LoadConfig(); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if config.AppConfig.Minimal.Enabled {
+		log.Fatalf("Cannot import in minimal mode: data is only kept in memory")
+	}
+	if _, err := os.Stat(config.AppConfig.Database.Database); err == nil && !*force {
+		log.Fatalf("%s already exists, use -force to replace its data", config.AppConfig.Database.Database)
+	}
+	if err := database.InitDatabase(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	tables, err := archive.Restore()
+	if err != nil {
+		log.Fatalf("Failed to import instance: %v", err)
+	}
+	var rows int64
+	for _, count := range tables {
+		rows += count
+	}
+	fmt.Fprintf(os.Stderr, "Imported %d tables (%d rows) exported from %s at %s\n",
+		len(tables), rows, archive.Manifest.Hostname, archive.Manifest.CreatedAt.Format(time.RFC3339))
+}
\ No newline at end of file
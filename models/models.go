@@ -1,6 +1,7 @@
 package models
 
 import (
+	"sync"
 	"time"
 	"gorm.io/gorm"
 )
@@ -8,12 +9,25 @@ import (
 // SystemMetrics 系统指标数据
 type SystemMetrics struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
+	ServerID  uint      `json:"server_id" gorm:"index;default:0"` // 所属主机（Server）的ID，本机为0
 	Timestamp time.Time `json:"timestamp"`
 	CPU       float64   `json:"cpu"`        // CPU使用率
+	CPUUser   float64   `json:"cpu_user"`   // 用户态CPU时间占比
+	CPUSystem float64   `json:"cpu_system"` // 内核态CPU时间占比
+	CPUIowait float64   `json:"cpu_iowait"` // 等待磁盘IO的CPU时间占比
+	CPUSteal  float64   `json:"cpu_steal"`  // 被宿主机其他虚拟机占用的CPU时间占比
 	Memory    float64   `json:"memory"`     // 内存使用率
 	Disk      float64   `json:"disk"`       // 磁盘使用率
 	Upload    float64   `json:"upload"`     // 上传速度 MB/s
 	Download  float64   `json:"download"`   // 下载速度 MB/s
+
+	// 内存明细（MB），Linux的缓存会被回收，已用内存不含缓存和缓冲区
+	MemTotal     float64 `json:"mem_total"`
+	MemAvailable float64 `json:"mem_available"` // 内核估算的可用内存，包含可回收的缓存
+	MemUsed      float64 `json:"mem_used"`      // 已用内存，不含缓存和缓冲区
+	MemCached    float64 `json:"mem_cached"`
+	MemBuffers   float64 `json:"mem_buffers"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -21,12 +35,61 @@ type SystemMetrics struct {
 // ServiceStatus 服务状态
 type ServiceStatus struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
-	Name      string    `json:"name"`       // 服务名称
-	Status    string    `json:"status"`     // 状态: running, warning, error
-	Host      string    `json:"host"`       // 服务地址
-	Port      string    `json:"port"`       // 服务端口
-	LastCheck time.Time `json:"last_check"` // 最后检查时间
-	Response  int       `json:"response"`   // 响应时间(ms)
+	ServerID  uint      `json:"server_id" gorm:"index;default:0"` // 所属主机（Server）的ID，本机为0
+	Name      string    `json:"name"`                    // 服务名称
+	Status    string    `json:"status"`                  // 状态: running, warning, error
+	Host      string    `json:"host"`                    // 服务地址
+	Port      string    `json:"port"`                    // 服务端口
+	LastCheck time.Time `json:"last_check"`              // 最后检查时间
+	Response  int       `json:"response"`                // 响应时间(ms)
+	Weight    int       `json:"weight" gorm:"default:1"` // 对主机健康度的权重，0表示不计入
+	Critical  bool      `json:"critical"`                // 关键服务，异常时主机直接判定为critical
+	Public    bool      `json:"public"`                  // 是否在公开状态页展示，默认不公开
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ServiceCheck 自定义服务检查（TCP端口或HTTP地址），检查结果与内置服务一样写入ServiceStatus
+type ServiceCheck struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	Name            string    `json:"name" gorm:"uniqueIndex"` // 服务名称，即ServiceStatus中的名称
+	Type            string    `json:"type"`                    // 检查方式: tcp, http
+	Host            string    `json:"host"`                    // tcp检查的地址
+	Port            string    `json:"port"`                    // tcp检查的端口
+	URL             string    `json:"url"`                     // http检查的地址，2xx/3xx视为正常
+	Weight          int       `json:"weight"`                  // 对主机健康度的权重
+	Critical        bool      `json:"critical"`                // 关键服务
+	Retries         int       `json:"retries"`                 // 检查失败后的重试次数，连续失败 retries+1 次才判定为异常
+	RetryInterval   int       `json:"retry_interval"`          // 重试间隔（秒）
+	Timeout         int       `json:"timeout"`                 // 超时时间（秒），0表示默认（tcp为5，http为10）
+	LatencyWarning  int       `json:"latency_warning"`         // 响应时间达到该值（毫秒）时为warning，0表示默认（tcp为100，http为200）
+	LatencyCritical int       `json:"latency_critical"`        // 响应时间达到该值（毫秒）时为error，0表示默认（tcp为500，http为1000）
+	Bundle          string    `json:"bundle"`                  // 导入来源的检查包，手动创建时为空
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ServiceCheckResult 每次服务检查的结果，用于绘制响应时间曲线和计算可用率
+type ServiceCheckResult struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ServiceID uint      `json:"service_id" gorm:"index:idx_service_check_time"` // ServiceStatus的ID
+	Status    string    `json:"status"`                                         // 状态: running, warning, error
+	Response  int       `json:"response"`                                       // 响应时间(ms)
+	Error     string    `json:"error"`                                          // 检查失败的原因
+	StateType string    `json:"state_type" gorm:"default:hard"`                 // hard（确定的状态，计入服务状态和可用率）或 soft（之后还会重试的失败）
+	Attempt   int       `json:"attempt" gorm:"default:1"`                       // 本轮检查的第几次尝试，从1开始
+	Timestamp time.Time `json:"timestamp" gorm:"index:idx_service_check_time"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProcessWatch 进程监视：匹配的进程数少于要求时告警
+type ProcessWatch struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"uniqueIndex"` // 监视名称
+	Pattern   string    `json:"pattern"`                 // 进程名或命令行中包含的字符串
+	MinCount  int       `json:"min_count"`               // 至少运行的进程数
+	Level     string    `json:"level"`                   // 告警级别: info, warning, error
+	Bundle    string    `json:"bundle"`                  // 导入来源的检查包，手动创建时为空
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -34,6 +97,7 @@ type ServiceStatus struct {
 // SystemLog 系统日志
 type SystemLog struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
+	ServerID  uint      `json:"server_id" gorm:"index;default:0"` // 所属主机（Server）的ID，本机为0
 	Level     string    `json:"level"`      // 日志级别: info, warning, error
 	Category  string    `json:"category"`   // 日志分类: system, security, database, network
 	Message   string    `json:"message"`    // 日志消息
@@ -44,6 +108,7 @@ type SystemLog struct {
 // DiskUsage 磁盘使用情况
 type DiskUsage struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
+	ServerID  uint      `json:"server_id" gorm:"index;default:0"` // 所属主机（Server）的ID，本机为0
 	Path      string    `json:"path"`       // 磁盘路径
 	Name      string    `json:"name"`       // 磁盘名称
 	Total     uint64    `json:"total"`      // 总容量(GB)
@@ -58,6 +123,7 @@ type DiskUsage struct {
 // Alert 告警信息
 type Alert struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
+	ServerID  uint      `json:"server_id" gorm:"index;default:0"` // 所属主机（Server）的ID，本机为0
 	Type      string    `json:"type"`       // 告警类型: cpu, memory, disk, service
 	Level     string    `json:"level"`      // 告警级别: info, warning, error
 	Message   string    `json:"message"`    // 告警消息
@@ -67,11 +133,16 @@ type Alert struct {
 	Timestamp time.Time `json:"timestamp"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	AcknowledgedAt *time.Time `json:"acknowledged_at"` // 确认时间，未确认时为空
+	SilencedUntil  *time.Time `json:"silenced_until"`  // 静默截止时间，期间同类型告警不再发送通知
+	Context        string     `json:"context"`         // 告警上下文，如CPU、内存告警时占用最高的容器和服务
 }
 
 // NetworkTraffic 网络流量数据
 type NetworkTraffic struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
+	ServerID  uint      `json:"server_id" gorm:"index;default:0"` // 所属主机（Server）的ID，本机为0
 	Interface string    `json:"interface"`  // 网络接口
 	Upload    uint64    `json:"upload"`     // 上传字节数
 	Download  uint64    `json:"download"`   // 下载字节数
@@ -94,6 +165,360 @@ type ProcessInfo struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// IPReputation 公网IP黑名单检查结果
+type IPReputation struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	IP        string    `json:"ip"`        // 公网IP
+	Blocklist string    `json:"blocklist"` // DNSBL名称
+	Listed    bool      `json:"listed"`    // 是否被列入黑名单
+	Response  string    `json:"response"`  // DNSBL返回的记录
+	Error     string    `json:"error"`     // 查询失败的原因，非空时收录情况未知
+	Timestamp time.Time `json:"timestamp"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Certificate TLS证书信息
+type Certificate struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Endpoint  string    `json:"endpoint" gorm:"uniqueIndex"` // 证书来源：host:port 或本地文件路径
+	Source    string    `json:"source"`                      // 来源类型: endpoint, file, manual
+	Subject   string    `json:"subject"`                     // 证书主体
+	Issuer    string    `json:"issuer"`                      // 签发者
+	DNSNames  string    `json:"dns_names"`                   // 证书包含的域名，逗号分隔
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	DaysLeft  int       `json:"days_left"` // 剩余有效天数
+	Error     string    `json:"error"`     // 最近一次检查的错误
+	LastCheck time.Time `json:"last_check"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Annotation 图表注释，用于解释数据空洞等事件
+type Annotation struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Type      string    `json:"type"`       // 注释类型: gap
+	Message   string    `json:"message"`    // 注释内容
+	StartTime time.Time `json:"start_time"` // 事件开始时间
+	EndTime   time.Time `json:"end_time"`   // 事件结束时间
+	Timestamp time.Time `json:"timestamp"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AlertRule 告警规则
+type AlertRule struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name"`      // 规则名称
+	Type      string    `json:"type"`      // 规则类型: no_data, comparison, api
+	Metric    string    `json:"metric"`    // no_data规则为数据源，如 system_metrics；comparison规则为指标，如 network_traffic.download_speed；api规则为 error_rate、p50、p95、p99
+	Intervals int       `json:"intervals"` // no_data规则：连续多少个采集周期无数据时告警
+
+	// comparison规则：最近一个窗口的均值与昨天/上周同一窗口相比
+	Tags      string  `json:"tags"`       // 标签过滤，如 interface=eth0
+	Window    int     `json:"window"`     // 聚合窗口（秒），默认3600
+	CompareTo string  `json:"compare_to"` // 对比时段: day, week
+	Direction string  `json:"direction"`  // 变化方向: drop（下降）, rise（上升）
+	Percent   float64 `json:"percent"`    // 变化超过该百分比时告警
+
+	// api规则：监控程序自身API在最近 window 秒内的错误率或延迟超过阈值
+	Threshold float64 `json:"threshold"` // 阈值，错误率为百分比，延迟为毫秒
+
+	Level     string    `json:"level"`     // 告警级别: info, warning, error
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AlertEvent 告警状态变化记录，构成告警的时间线
+type AlertEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	AlertID   uint      `json:"alert_id" gorm:"index"`
+	Event     string    `json:"event"`   // 事件: fired, acknowledged, silenced, escalated, deescalated, resolved
+	Status    string    `json:"status"`  // 事件发生后的告警状态
+	Level     string    `json:"level"`   // 事件发生时的告警级别
+	Message   string    `json:"message"` // 事件发生时的告警消息
+	Timestamp time.Time `json:"timestamp"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AlertComment 告警的处理备注，记录排查发现，交接时无需重复诊断
+type AlertComment struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	AlertID   uint      `json:"alert_id" gorm:"index"`
+	Author    string    `json:"author"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Server 被监控的主机：远程主机首次推送数据时自动登记，本机不登记，本机数据的ServerID为0
+type Server struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"uniqueIndex"` // 主机标识，即推送数据和查询参数中的 host
+	LastSeen  time.Time `json:"last_seen"`               // 最后一次推送数据的时间
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Series 指标序列登记（指标名+标签），用于指标名和标签值的发现查询
+type Series struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Key       string    `json:"key" gorm:"uniqueIndex"` // 序列唯一键，如 network_traffic.upload_speed{interface=eth0}
+	Metric    string    `json:"metric" gorm:"index"`    // 指标名，如 network_traffic.upload_speed
+	Tags      string    `json:"tags"`                   // 按键排序的标签，如 interface=eth0
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SeriesTag 序列标签倒排索引，按 指标名+标签键+标签值 查找序列
+type SeriesTag struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	SeriesID uint   `json:"series_id" gorm:"index"`
+	Metric   string `json:"metric" gorm:"index:idx_series_tag"`
+	Key      string `json:"key" gorm:"index:idx_series_tag"`
+	Value    string `json:"value" gorm:"index:idx_series_tag"`
+}
+
+// IPAddressChange IP地址变化记录，每次网卡地址或公网IP变化时写入一条
+type IPAddressChange struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Interface    string    `json:"interface" gorm:"index"` // 网卡名称，公网IP为 public
+	OldAddresses string    `json:"old_addresses"`          // 变化前的地址，逗号分隔
+	NewAddresses string    `json:"new_addresses"`          // 变化后的地址，逗号分隔，网卡消失时为空
+	Timestamp    time.Time `json:"timestamp" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PortChange 监听端口变化记录，端口开始或停止监听时写入一条
+type PortChange struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Protocol  string    `json:"protocol"`             // tcp, tcp6, udp, udp6
+	Address   string    `json:"address"`              // 监听地址，如 0.0.0.0、::
+	Port      uint32    `json:"port" gorm:"index"`    // 端口
+	Process   string    `json:"process"`              // 监听的进程名，无权限查看时为空
+	Event     string    `json:"event"`                // opened 开始监听，closed 停止监听
+	Timestamp time.Time `json:"timestamp" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SSHLogin SSH登录记录，从sshd的认证日志中解析
+type SSHLogin struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	User      string    `json:"user" gorm:"index"`      // 登录的用户名，不存在的用户也会记录
+	IP        string    `json:"ip" gorm:"index"`        // 来源地址
+	Method    string    `json:"method"`                 // password, publickey, keyboard-interactive；不存在的用户为 invalid_user
+	Success   bool      `json:"success" gorm:"index"`   // 是否登录成功
+	Timestamp time.Time `json:"timestamp" gorm:"index"` // 日志中记录的时间
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Fail2banBan fail2ban封禁记录，同步时发现新封禁的地址写入一条，解封后记录解封时间
+type Fail2banBan struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Jail       string     `json:"jail" gorm:"index"`
+	IP         string     `json:"ip" gorm:"index"`
+	BannedAt   time.Time  `json:"banned_at" gorm:"index"` // 发现封禁的时间，精度为同步间隔
+	UnbannedAt *time.Time `json:"unbanned_at"`            // 解封时间，仍在封禁中时为空
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// UpdateCheck 软件包更新检查结果，每次检查写入一条
+type UpdateCheck struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	Manager          string    `json:"manager"`           // apt, dnf, yum
+	Pending          int       `json:"pending"`           // 可更新的软件包数
+	Security         int       `json:"security"`          // 其中的安全更新数
+	SecurityPackages string    `json:"security_packages"` // 有安全更新的软件包，逗号分隔
+	RebootRequired   bool      `json:"reboot_required"`   // 是否需要重启
+	RebootPackages   string    `json:"reboot_packages"`   // 要求重启的软件包，逗号分隔；只有Debian/Ubuntu提供
+	Timestamp        time.Time `json:"timestamp" gorm:"index"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ClockCheck 时钟偏差检查结果，每次检查写入一条
+type ClockCheck struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Server    string    `json:"server"`  // 应答的NTP服务器
+	Offset    float64   `json:"offset"`  // NTP服务器时间减本机时间（秒），正数表示本机时钟慢
+	Delay     float64   `json:"delay"`   // 往返延迟（秒）
+	Stratum   int       `json:"stratum"` // 服务器的层级
+	Timestamp time.Time `json:"timestamp" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SensorReading 硬件传感器读数（温度、风扇转速、电压、状态标志等），每次采集每个传感器写入一条
+type SensorReading struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Source    string    `json:"source" gorm:"index"` // 来源: thermal（thermal zone）, rpi（树莓派固件）, ups
+	Name      string    `json:"name" gorm:"index"`   // 传感器名称，如 cpu-thermal、under_voltage、battery_charge
+	Type      string    `json:"type"`                // temperature, fan, voltage, power, percent, duration, status
+	Value     float64   `json:"value"`               // 读数；状态标志为1（是）或0（否）
+	Unit      string    `json:"unit"`                // °C, RPM, V, W, %, s；状态标志为空
+	Timestamp time.Time `json:"timestamp" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RAIDStatus mdadm软RAID阵列的状态，每次检查每个阵列写入一条
+type RAIDStatus struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Device      string    `json:"device" gorm:"index"` // 阵列设备名，如 md0
+	Level       string    `json:"level"`               // RAID级别，如 raid1、raid5；未激活的阵列为空
+	State       string    `json:"state"`               // active, inactive；只读时带 (read-only)、(auto-read-only)
+	Degraded    bool      `json:"degraded"`            // 活动成员少于应有数量或有故障成员
+	Disks       int       `json:"disks"`               // 应有的成员数
+	ActiveDisks int       `json:"active_disks"`        // 正常工作的成员数
+	FailedDisks int       `json:"failed_disks"`        // 标记为故障（F）的成员数
+	SpareDisks  int       `json:"spare_disks"`         // 热备（S）成员数
+	Members     string    `json:"members"`             // 成员列表，如 sda1[0],sdb1[1](F)
+	Action      string    `json:"action"`              // 正在进行的操作: recovery, resync, reshape, check, repair；没有时为空
+	Progress    float64   `json:"progress"`            // 操作进度（%）
+	Finish      string    `json:"finish"`              // 预计剩余时间，如 80.2min
+	Timestamp   time.Time `json:"timestamp" gorm:"index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ZFSPool ZFS存储池的状态，每次检查每个存储池写入一条
+type ZFSPool struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Name          string     `json:"name" gorm:"index"`
+	Health        string     `json:"health"`        // ONLINE, DEGRADED, FAULTED, OFFLINE, UNAVAIL, REMOVED, SUSPENDED
+	Size          uint64     `json:"size"`          // 总容量（字节）
+	Allocated     uint64     `json:"allocated"`     // 已用（字节）
+	Free          uint64     `json:"free"`          // 可用（字节）
+	Capacity      float64    `json:"capacity"`      // 已用容量（%）
+	Fragmentation *float64   `json:"fragmentation"` // 空闲空间碎片率（%），存储池不支持时为null
+	Scan          string     `json:"scan"`          // zpool status 的scan行，如 "scrub repaired 0B in 00:10:12 with 0 errors on ..."
+	ScanProgress  float64    `json:"scan_progress"` // 正在进行的scrub或resilver的进度（%）
+	LastScrub     *time.Time `json:"last_scrub"`    // 最近一次完成scrub的时间，从未scrub时为null
+	Errors        string     `json:"errors"`        // zpool status 的errors行，正常时为 "No known data errors"
+	Timestamp     time.Time  `json:"timestamp" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// IPGeolocation 公网IP的地理位置，每个IP只查询一次
+type IPGeolocation struct {
+	IP        string    `json:"ip" gorm:"primaryKey"`
+	Country   string    `json:"country"`
+	Region    string    `json:"region"`
+	City      string    `json:"city"`
+	Org       string    `json:"org"` // 运营商或ASN
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Setting 运行时设置，覆盖配置文件中的同名配置项
+type Setting struct {
+	Key       string    `json:"key" gorm:"primaryKey"` // 配置项，如 monitor.alert_cpu
+	Value     string    `json:"value"`                 // JSON编码的值
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AuditLog 修改操作的审计记录
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Action    string    `json:"action" gorm:"index"` // 操作，如 PUT /api/v1/alerts/:id/resolve
+	Target    string    `json:"target"`              // 路径参数，如 id=12
+	User      string    `json:"user" gorm:"index"`   // 操作者（用户或API Key），未认证时为空
+	IP        string    `json:"ip" gorm:"index"`     // 客户端IP
+	Payload   string    `json:"payload"`             // 请求体摘要，敏感字段已脱敏
+	Status    int       `json:"status"`              // HTTP状态码
+	Result    string    `json:"result"`              // 响应消息
+	Duration  int64     `json:"duration"`            // 处理耗时（毫秒）
+	Timestamp time.Time `json:"timestamp" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AccessLog HTTP访问记录，超过 access_log.max_rows 时删除最早的记录
+type AccessLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path" gorm:"index"` // 请求路径，不含查询参数
+	Route     string    `json:"route"`             // 匹配的路由，如 /api/v1/alerts/:id/resolve；未匹配时为空
+	Status    int       `json:"status"`            // HTTP状态码
+	Latency   float64   `json:"latency"`           // 处理耗时（毫秒）
+	Size      int       `json:"size"`              // 响应体字节数
+	IP        string    `json:"ip" gorm:"index"`   // 客户端IP
+	UserAgent string    `json:"user_agent"`
+	RequestID string    `json:"request_id" gorm:"index"` // 请求ID，与响应头 X-Request-ID 相同
+	Timestamp time.Time `json:"timestamp" gorm:"index"`
+}
+
+// AlertFixture 告警规则夹具：录制的一段指标数据，用于验证告警规则在这段数据上是否触发
+type AlertFixture struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex"` // 夹具名称
+	Description string    `json:"description"`
+	From        time.Time `json:"from"` // 录制的时间范围
+	To          time.Time `json:"to"`   // 规则按该时刻判定
+	Rows        int       `json:"rows"` // 录制的记录数
+	Data        string    `json:"data,omitempty"` // JSON编码的各数据表记录，列表接口不返回
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DailySummary 指标的每日汇总，原始数据清理后用于查询长期趋势
+type DailySummary struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Metric    string    `json:"metric" gorm:"uniqueIndex:idx_daily_summary"` // 指标名，如 network_traffic.download_speed
+	Tags      string    `json:"tags" gorm:"uniqueIndex:idx_daily_summary"`   // 按键排序的标签，如 interface=eth0
+	Day       string    `json:"day" gorm:"uniqueIndex:idx_daily_summary"`    // 日期（本地时间），如 2024-05-01
+	Min       float64   `json:"min"`
+	Avg       float64   `json:"avg"`
+	Max       float64   `json:"max"`
+	Samples   int64     `json:"samples"` // 参与汇总的原始记录数
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RollupState 各数据源最近一次每日汇总的时间，之后写入的更早日期的原始数据（远程主机补发）需要重新汇总
+type RollupState struct {
+	Source  string    `json:"source" gorm:"primaryKey"` // 数据源，如 system_metrics
+	LastRun time.Time `json:"last_run"`                 // 最近一次汇总开始的时间
+}
+
+// Action 定时维护操作：按cron表达式执行白名单中的命令，如重启服务、轮转日志、清理缓存目录
+type Action struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Name       string    `json:"name" gorm:"uniqueIndex"`      // 操作名称
+	Schedule   string    `json:"schedule"`                     // cron表达式（分 时 日 月 周），如 "0 4 * * *"
+	Command    string    `json:"command"`                      // 可执行文件的绝对路径，与参数一起必须匹配 actions.allowed_commands
+	Args       []string  `json:"args" gorm:"serializer:json"`  // 命令参数，不经过shell解析
+	Timeout    int       `json:"timeout"`                      // 超时时间（秒），0表示使用 actions.timeout
+	Level      string    `json:"level"`                        // 执行失败时的告警级别: info, warning, error
+	Enabled    bool      `json:"enabled"`
+	NextRun    time.Time `json:"next_run"`                     // 下一次计划执行的时间
+	LastRun    time.Time `json:"last_run"`                     // 最近一次执行的开始时间
+	LastStatus string    `json:"last_status"`                  // 最近一次执行的结果: success, failed
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ActionRun 维护操作的执行记录
+type ActionRun struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ActionID  uint      `json:"action_id" gorm:"index"`
+	Action    string    `json:"action"`  // 执行时的操作名称
+	Trigger   string    `json:"trigger"` // 触发方式: schedule, manual
+	Command   string    `json:"command"` // 实际执行的命令行
+	StartedAt time.Time `json:"started_at" gorm:"index"`
+	Duration  int64     `json:"duration"`  // 执行耗时（毫秒）
+	ExitCode  int       `json:"exit_code"` // 退出码，未能启动或超时被终止时为-1
+	Success   bool      `json:"success"`
+	Output    string    `json:"output"` // 标准输出和标准错误，超过 actions.max_output 时截断
+	Error     string    `json:"error"`  // 失败原因
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JobRun 定时任务执行记录
+type JobRun struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Job       string    `json:"job" gorm:"index"` // 任务名称
+	StartedAt time.Time `json:"started_at" gorm:"index"`
+	Duration  int64     `json:"duration"` // 执行耗时（毫秒）
+	Success   bool      `json:"success"`
+	Error     string    `json:"error"` // 失败原因
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // BeforeCreate GORM钩子，设置创建时间
 func (m *SystemMetrics) BeforeCreate(tx *gorm.DB) error {
 	m.CreatedAt = time.Now()
@@ -112,6 +537,30 @@ func (l *SystemLog) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+var (
+	systemLogHooksMu sync.RWMutex
+	systemLogHooks   []func(SystemLog)
+)
+
+// OnSystemLogCreated 注册系统日志写入后的回调，回调在独立协程中执行，不阻塞写入
+func OnSystemLogCreated(hook func(SystemLog)) {
+	systemLogHooksMu.Lock()
+	defer systemLogHooksMu.Unlock()
+	systemLogHooks = append(systemLogHooks, hook)
+}
+
+// AfterCreate GORM钩子，通知已注册的回调（如推送到WebSocket）
+func (l *SystemLog) AfterCreate(tx *gorm.DB) error {
+	systemLogHooksMu.RLock()
+	hooks := systemLogHooks
+	systemLogHooksMu.RUnlock()
+
+	for _, hook := range hooks {
+		go hook(*l)
+	}
+	return nil
+}
+
 func (d *DiskUsage) BeforeCreate(tx *gorm.DB) error {
 	d.CreatedAt = time.Now()
 	d.UpdatedAt = time.Now()
@@ -133,4 +582,129 @@ func (n *NetworkTraffic) BeforeCreate(tx *gorm.DB) error {
 func (p *ProcessInfo) BeforeCreate(tx *gorm.DB) error {
 	p.CreatedAt = time.Now()
 	return nil
-} 
\ No newline at end of file
+} 
+
+func (r *IPReputation) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	return nil
+}
+
+func (c *Certificate) BeforeCreate(tx *gorm.DB) error {
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+func (a *Annotation) BeforeCreate(tx *gorm.DB) error {
+	a.CreatedAt = time.Now()
+	return nil
+}
+
+func (r *AlertRule) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+func (e *AlertEvent) BeforeCreate(tx *gorm.DB) error {
+	e.CreatedAt = time.Now()
+	return nil
+}
+
+func (s *Series) BeforeCreate(tx *gorm.DB) error {
+	s.CreatedAt = time.Now()
+	return nil
+}
+
+func (j *JobRun) BeforeCreate(tx *gorm.DB) error {
+	j.CreatedAt = time.Now()
+	return nil
+}
+
+func (c *IPAddressChange) BeforeCreate(tx *gorm.DB) error {
+	c.CreatedAt = time.Now()
+	return nil
+}
+
+func (c *PortChange) BeforeCreate(tx *gorm.DB) error {
+	c.CreatedAt = time.Now()
+	return nil
+}
+
+func (l *SSHLogin) BeforeCreate(tx *gorm.DB) error {
+	l.CreatedAt = time.Now()
+	return nil
+}
+
+func (b *Fail2banBan) BeforeCreate(tx *gorm.DB) error {
+	b.CreatedAt = time.Now()
+	return nil
+}
+
+func (u *UpdateCheck) BeforeCreate(tx *gorm.DB) error {
+	u.CreatedAt = time.Now()
+	return nil
+}
+
+func (c *ClockCheck) BeforeCreate(tx *gorm.DB) error {
+	c.CreatedAt = time.Now()
+	return nil
+}
+
+func (r *SensorReading) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	return nil
+}
+
+func (r *RAIDStatus) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	return nil
+}
+
+func (z *ZFSPool) BeforeCreate(tx *gorm.DB) error {
+	z.CreatedAt = time.Now()
+	return nil
+}
+
+func (s *Setting) BeforeCreate(tx *gorm.DB) error {
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	a.CreatedAt = time.Now()
+	return nil
+}
+
+func (s *ServiceCheck) BeforeCreate(tx *gorm.DB) error {
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+func (w *ProcessWatch) BeforeCreate(tx *gorm.DB) error {
+	w.CreatedAt = time.Now()
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+func (f *AlertFixture) BeforeCreate(tx *gorm.DB) error {
+	f.CreatedAt = time.Now()
+	return nil
+}
+
+func (a *Action) BeforeCreate(tx *gorm.DB) error {
+	a.CreatedAt = time.Now()
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *ActionRun) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	return nil
+}
+
+func (r *ServiceCheckResult) BeforeCreate(tx *gorm.DB) error {
+	r.CreatedAt = time.Now()
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,238 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrActionRunning 操作的上一次执行尚未结束
+var ErrActionRunning = i18n.Errorf("该操作正在执行")
+
+// 正在执行的操作，同一操作不会并发执行（定时触发与手动触发之间也不会重叠）
+var (
+	runningMu      sync.Mutex
+	runningActions = make(map[uint]bool)
+)
+
+// ValidateAction 校验维护操作：cron表达式、命令是否在白名单中、超时和告警级别，并计算下一次执行时间
+func ValidateAction(action *models.Action) error {
+	if action.Name == "" {
+		return i18n.Errorf("操作名称不能为空")
+	}
+
+	schedule, err := cron.ParseStandard(action.Schedule)
+	if err != nil {
+		return i18n.Errorf("无效的cron表达式 %q: %v", action.Schedule, err)
+	}
+	if !filepath.IsAbs(action.Command) {
+		return i18n.Errorf("命令必须是可执行文件的绝对路径: %s", action.Command)
+	}
+	if !CommandAllowed(action.Command, action.Args) {
+		return i18n.Errorf("命令不在 actions.allowed_commands 白名单中: %s", commandLine(action.Command, action.Args))
+	}
+	if action.Timeout < 0 || action.Timeout > 86400 {
+		return i18n.Errorf("超时时间必须在0~86400秒之间")
+	}
+
+	switch action.Level {
+	case "":
+		action.Level = "error"
+	case "info", "warning", "error":
+	default:
+		return i18n.Errorf("不支持的告警级别: %s", action.Level)
+	}
+
+	action.NextRun = schedule.Next(time.Now().In(config.AppConfig.I18n.Location()))
+	return nil
+}
+
+// CommandAllowed 判断完整的命令行（命令和全部参数）是否与 actions.allowed_commands 中的某一项匹配
+func CommandAllowed(command string, args []string) bool {
+	for _, allowed := range config.AppConfig.Actions.AllowedCommands {
+		if commandMatches(strings.Fields(allowed), command, args) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandMatches 白名单项按空白拆分为命令和参数模式：命令按清理后的绝对路径精确匹配，参数个数必须相同，
+// 每个参数按 filepath.Match 的通配符匹配（* 不匹配 /），如 "/usr/bin/find /var/cache/app -mindepth 1 -mtime +* -delete"
+func commandMatches(pattern []string, command string, args []string) bool {
+	if len(pattern) != len(args)+1 || filepath.Clean(pattern[0]) != filepath.Clean(command) {
+		return false
+	}
+	for i, arg := range args {
+		if ok, err := filepath.Match(pattern[i+1], arg); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// commandLine 命令和参数拼接成的命令行，用于错误消息和执行记录
+func commandLine(command string, args []string) string {
+	return strings.Join(append([]string{command}, args...), " ")
+}
+
+// RunDueActions 执行所有已到计划时间的维护操作，每个操作在独立的协程中执行，不阻塞调度；
+// 停机期间错过的多次执行在启动后只补执行一次
+func RunDueActions() error {
+	if !config.AppConfig.Actions.Enabled {
+		return nil
+	}
+
+	now := time.Now()
+	var actions []models.Action
+	if err := database.DB.Where("enabled = ? AND next_run <= ?", true, now).Find(&actions).Error; err != nil {
+		return err
+	}
+
+	for _, action := range actions {
+		schedule, err := cron.ParseStandard(action.Schedule)
+		if err != nil {
+			log.Printf("Action %s has invalid schedule %q: %v", action.Name, action.Schedule, err)
+			continue
+		}
+		// 先推进下一次执行时间，执行时间超过调度间隔时也不会重复触发；cron表达式按显示时区计算
+		next := schedule.Next(now.In(config.AppConfig.I18n.Location()))
+		if err := database.DB.Model(&models.Action{}).Where("id = ?", action.ID).Update("next_run", next).Error; err != nil {
+			return err
+		}
+
+		go func(action models.Action) {
+			if _, err := RunAction(action, "schedule"); err != nil && !errors.Is(err, ErrActionRunning) {
+				log.Printf("Error running action %s: %v", action.Name, err)
+			}
+		}(action)
+	}
+	return nil
+}
+
+// RunAction 执行维护操作并记录执行结果；执行失败时触发告警，下一次执行成功后自动解除。
+// 执行前再次检查命令是否在白名单中，白名单收紧后已保存的操作不会再执行
+func RunAction(action models.Action, trigger string) (*models.ActionRun, error) {
+	if !config.AppConfig.Actions.Enabled {
+		return nil, i18n.Errorf("维护操作未启用（actions.enabled）")
+	}
+	if !CommandAllowed(action.Command, action.Args) {
+		return nil, i18n.Errorf("命令不在 actions.allowed_commands 白名单中: %s", commandLine(action.Command, action.Args))
+	}
+
+	runningMu.Lock()
+	if runningActions[action.ID] {
+		runningMu.Unlock()
+		return nil, ErrActionRunning
+	}
+	runningActions[action.ID] = true
+	runningMu.Unlock()
+	defer func() {
+		runningMu.Lock()
+		delete(runningActions, action.ID)
+		runningMu.Unlock()
+	}()
+
+	run := execute(action)
+	run.Trigger = trigger
+	if err := database.DB.Create(run).Error; err != nil {
+		log.Printf("Error recording run of action %s: %v", action.Name, err)
+	}
+
+	status := "success"
+	if !run.Success {
+		status = "failed"
+	}
+	database.DB.Model(&models.Action{}).Where("id = ?", action.ID).
+		Updates(map[string]interface{}{"last_run": run.StartedAt, "last_status": status})
+
+	alertType := fmt.Sprintf("action_%d", action.ID)
+	if run.Success {
+		log.Printf("Action %s finished in %dms", action.Name, run.Duration)
+		resolveAlert(alertType, "action", i18n.Sprintf("维护操作 %s 已恢复正常执行", action.Name))
+	} else {
+		log.Printf("Action %s failed: %s", action.Name, run.Error)
+		raiseAlert(alertType, action.Level, "action",
+			i18n.Sprintf("维护操作 %s 执行失败: %s", action.Name, run.Error), float64(run.ExitCode), 0)
+	}
+	return run, nil
+}
+
+// execute 不经过shell直接执行命令，超时后终止进程，合并保存标准输出和标准错误
+func execute(action models.Action) *models.ActionRun {
+	timeout := action.Timeout
+	if timeout <= 0 {
+		timeout = config.AppConfig.Actions.Timeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	output := &limitedBuffer{limit: config.AppConfig.Actions.MaxOutput}
+	cmd := exec.CommandContext(ctx, action.Command, action.Args...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// 命令的子进程继承了输出管道时，终止命令后最多再等待5秒
+	cmd.WaitDelay = 5 * time.Second
+
+	run := &models.ActionRun{
+		ActionID:  action.ID,
+		Action:    action.Name,
+		Command:   commandLine(action.Command, action.Args),
+		StartedAt: time.Now(),
+		ExitCode:  -1,
+	}
+	err := cmd.Run()
+	run.Duration = time.Since(run.StartedAt).Milliseconds()
+	run.Output = output.String()
+	if cmd.ProcessState != nil {
+		run.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		run.Error = i18n.Sprintf("执行超时（%d秒），已终止", timeout)
+	case err != nil:
+		run.Error = err.Error()
+	default:
+		run.Success = true
+	}
+	return run
+}
+
+// limitedBuffer 只保留前limit个字节的输出，超出部分丢弃但不报错，避免命令因写入失败而中断
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); remaining < len(p) {
+		b.truncated = true
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "\n...（输出已截断）"
+	}
+	return b.buf.String()
+}
@@ -0,0 +1,37 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommandMatches(t *testing.T) {
+	tests := []struct {
+		allowed string
+		command string
+		args    []string
+		want    bool
+	}{
+		{"/usr/bin/systemctl restart nginx", "/usr/bin/systemctl", []string{"restart", "nginx"}, true},
+		{"/usr/bin/systemctl restart nginx", "/usr/bin/systemctl", []string{"stop", "nginx"}, false},
+		{"/usr/bin/systemctl restart nginx", "/usr/bin/systemctl", []string{"restart", "nginx", "sshd"}, false},
+		{"/usr/bin/systemctl restart nginx", "/usr/bin/systemctl", []string{"restart"}, false},
+		{"/usr/bin/systemctl", "/usr/bin/systemctl", nil, true},
+		{"/usr/bin/systemctl", "/usr/bin/systemctl", []string{"poweroff"}, false},
+		{"/usr/bin/systemctl restart *", "/usr/bin/systemctl", []string{"restart", "php-fpm"}, true},
+		{"/usr/bin/systemctl restart *", "/usr/bin/systemctl", []string{"restart", "a", "b"}, false},
+		{"/usr/bin/find /var/cache/app -mtime +* -delete", "/usr/bin/find", []string{"/var/cache/app", "-mtime", "+7", "-delete"}, true},
+		{"/usr/bin/find /var/cache/* -delete", "/usr/bin/find", []string{"/var/cache/app/../../..", "-delete"}, false},
+		{"/usr/bin/find /var/cache/* -delete", "/usr/bin/find", []string{"/var/cache/app", "-delete"}, true},
+		{"/usr/bin/../bin/systemctl restart nginx", "/usr/bin/systemctl", []string{"restart", "nginx"}, true},
+		{"/usr/bin/systemctl restart nginx", "/tmp/systemctl", []string{"restart", "nginx"}, false},
+		{"/usr/bin/systemctl restart [", "/usr/bin/systemctl", []string{"restart", "["}, false},
+	}
+
+	for _, tt := range tests {
+		line := strings.Join(append([]string{tt.command}, tt.args...), " ")
+		if got := commandMatches(strings.Fields(tt.allowed), tt.command, tt.args); got != tt.want {
+			t.Errorf("allowed %q, command %q: got %v, want %v", tt.allowed, line, got, tt.want)
+		}
+	}
+}
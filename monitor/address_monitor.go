@@ -0,0 +1,226 @@
+package monitor
+
+import (
+	"log"
+	"net"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PublicInterface 公网IP在地址变化记录中使用的网卡名称
+const PublicInterface = "public"
+
+type AddressMonitor struct {
+	known map[string]string // 每个网卡最近一次记录的地址，首次检查时从数据库加载
+}
+
+// NewAddressMonitor 创建IP地址变化检测实例
+func NewAddressMonitor() *AddressMonitor {
+	return &AddressMonitor{}
+}
+
+// CollectAddresses 获取各网卡当前的地址（排除回环和链路本地地址），开启公网IP跟踪时包含公网IPv4和IPv6
+func (am *AddressMonitor) CollectAddresses() (map[string]string, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]string)
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagLoopback != 0 || !trackedInterface(iface.Name) {
+			continue
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			log.Printf("Error getting addresses of interface %s: %v", iface.Name, err)
+			continue
+		}
+
+		var ips []string
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			ips = append(ips, ipNet.IP.String())
+		}
+		if len(ips) > 0 {
+			sort.Strings(ips)
+			current[iface.Name] = strings.Join(ips, ",")
+		}
+	}
+
+	if config.AppConfig.Addresses.PublicIP {
+		ip, err := detectPublicIP("tcp4", publicIPv4URLs())
+		if err != nil {
+			return nil, i18n.Errorf("探测公网IP失败: %v", err)
+		}
+		current[PublicInterface] = ip
+
+		// 很多网络没有IPv6，探测失败时只记录日志，沿用上次的记录
+		if urls := config.AppConfig.Addresses.PublicIPv6URLs; len(urls) > 0 {
+			if ip, err := detectPublicIP("tcp6", urls); err != nil {
+				log.Printf("Error detecting public IPv6 address: %v", err)
+			} else {
+				current[PublicInterface6] = ip
+			}
+		}
+	}
+
+	return current, nil
+}
+
+// trackedInterface 判断网卡是否在配置的跟踪范围内
+func trackedInterface(name string) bool {
+	if len(config.AppConfig.Addresses.Interfaces) == 0 {
+		return true
+	}
+	for _, iface := range config.AppConfig.Addresses.Interfaces {
+		if iface == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectChanges 与上次记录的地址比较，返回发生变化的网卡；首次见到的网卡也会记录，但不视为意外变化
+func (am *AddressMonitor) DetectChanges(current map[string]string) ([]models.IPAddressChange, error) {
+	if am.known == nil {
+		known, err := GetCurrentAddresses()
+		if err != nil {
+			return nil, err
+		}
+		am.known = known
+	}
+
+	now := time.Now()
+	var changes []models.IPAddressChange
+	for iface, addresses := range current {
+		if am.known[iface] != addresses {
+			changes = append(changes, models.IPAddressChange{
+				Interface:    iface,
+				OldAddresses: am.known[iface],
+				NewAddresses: addresses,
+				Timestamp:    now,
+			})
+		}
+	}
+	for iface, addresses := range am.known {
+		if _, ok := current[iface]; !ok && addresses != "" {
+			// 公网IP探测未开启或探测失败时不记录为消失
+			if isPublicInterface(iface) {
+				continue
+			}
+			changes = append(changes, models.IPAddressChange{
+				Interface:    iface,
+				OldAddresses: addresses,
+				Timestamp:    now,
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Interface < changes[j].Interface })
+	return changes, nil
+}
+
+// SaveChanges 保存地址变化记录并写入系统日志
+func (am *AddressMonitor) SaveChanges(changes []models.IPAddressChange) error {
+	for _, change := range changes {
+		if err := database.DB.Create(&change).Error; err != nil {
+			return err
+		}
+		am.known[change.Interface] = change.NewAddresses
+
+		if change.OldAddresses == "" {
+			continue
+		}
+		systemLog := models.SystemLog{
+			Level:     "info",
+			Category:  "network",
+			Message:   i18n.Sprintf("网卡 %s 地址变化: %s -> %s", change.Interface, change.OldAddresses, displayAddresses(change.NewAddresses)),
+			Timestamp: change.Timestamp,
+		}
+		database.DB.Create(&systemLog)
+	}
+	return nil
+}
+
+// CheckAlerts 公网IPv4或IPv6变化、配置的固定地址丢失时告警
+func (am *AddressMonitor) CheckAlerts(current map[string]string, changes []models.IPAddressChange) {
+	for _, change := range changes {
+		if !isPublicInterface(change.Interface) || change.OldAddresses == "" {
+			continue
+		}
+		alertType, family := "public_ip_change", i18n.Sprintf("公网IP")
+		if change.Interface == PublicInterface6 {
+			alertType, family = "public_ipv6_change", i18n.Sprintf("公网IPv6")
+		}
+		message := i18n.Sprintf("%s发生变化: %s -> %s", family, change.OldAddresses, change.NewAddresses)
+		if location := describeLocation(change.NewAddresses); location != "" {
+			message += i18n.Sprintf("（%s）", location)
+		}
+		// 告警保持活跃直到手动解除，提醒更新DNS记录
+		raiseAlert(alertType, "warning", "network", i18n.Sprintf("%s，请检查DNS记录", message), 0, 0)
+	}
+
+	if len(config.AppConfig.Addresses.StaticIPs) == 0 {
+		return
+	}
+
+	present := make(map[string]bool)
+	for iface, addresses := range current {
+		if isPublicInterface(iface) {
+			continue
+		}
+		for _, ip := range strings.Split(addresses, ",") {
+			present[ip] = true
+		}
+	}
+
+	var missing []string
+	for _, ip := range config.AppConfig.Addresses.StaticIPs {
+		if !present[ip] {
+			missing = append(missing, ip)
+		}
+	}
+
+	if len(missing) > 0 {
+		raiseAlert("static_ip_missing", "error", "network",
+			i18n.Sprintf("固定地址已从本机网卡上消失: %s", strings.Join(missing, ", ")),
+			float64(len(missing)), 0)
+	} else {
+		resolveAlert("static_ip_missing", "network", i18n.Sprintf("所有固定地址均已恢复"))
+	}
+}
+
+// GetCurrentAddresses 从变化记录中获取每个网卡最近一次记录的地址
+func GetCurrentAddresses() (map[string]string, error) {
+	var latest []models.IPAddressChange
+	err := database.DB.Where("id IN (?)",
+		database.DB.Model(&models.IPAddressChange{}).Select("MAX(id)").Group("interface")).
+		Find(&latest).Error
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]string, len(latest))
+	for _, change := range latest {
+		known[change.Interface] = change.NewAddresses
+	}
+	return known, nil
+}
+
+// displayAddresses 地址为空时显示为"无"
+func displayAddresses(addresses string) string {
+	if addresses == "" {
+		return i18n.Sprintf("无")
+	}
+	return addresses
+}
@@ -0,0 +1,269 @@
+package monitor
+
+import (
+	"log"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"sync"
+	"time"
+)
+
+// 告警生命周期事件
+const (
+	AlertFired        = "fired"
+	AlertResolved     = "resolved"
+	AlertAcknowledged = "acknowledged"
+	AlertSilenced     = "silenced"
+	AlertEscalated    = "escalated"
+	AlertDeescalated  = "deescalated"
+)
+
+// 告警级别的严重程度，用于判断级别变化是升级还是降级
+var alertLevelRank = map[string]int{
+	"info":    0,
+	"warning": 1,
+	"error":   2,
+}
+
+// ErrAlertResolved 告警已解决，不能再确认或静默
+var ErrAlertResolved = i18n.Errorf("告警已解决")
+
+// AlertHook 告警生命周期事件回调
+type AlertHook func(event string, alert models.Alert)
+
+var (
+	alertHooksMu sync.RWMutex
+	alertHooks   []AlertHook
+)
+
+// OnAlertEvent 注册告警生命周期事件回调，回调在独立协程中执行，不阻塞监控流程
+func OnAlertEvent(hook AlertHook) {
+	alertHooksMu.Lock()
+	defer alertHooksMu.Unlock()
+	alertHooks = append(alertHooks, hook)
+}
+
+// emitAlertEvent 记录告警状态变化到时间线，并通知所有已注册的回调
+func emitAlertEvent(event string, alert models.Alert) {
+	record := models.AlertEvent{
+		AlertID:   alert.ID,
+		Event:     event,
+		Status:    alert.Status,
+		Level:     alert.Level,
+		Message:   alert.Message,
+		Timestamp: time.Now(),
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		log.Printf("Error recording alert event %s for alert %d: %v", event, alert.ID, err)
+	}
+
+	alertHooksMu.RLock()
+	hooks := alertHooks
+	alertHooksMu.RUnlock()
+
+	for _, hook := range hooks {
+		go hook(event, alert)
+	}
+}
+
+// raiseAlert 触发告警：已有同类型的活跃告警时只更新值，否则创建新告警并记录系统日志
+func raiseAlert(alertType, level, category, message string, value, threshold float64) {
+	raiseServerAlert(0, alertType, level, category, message, value, threshold)
+}
+
+// raiseServerAlert 触发主机的告警，告警及其系统日志归属该主机，本机为0
+func raiseServerAlert(serverID uint, alertType, level, category, message string, value, threshold float64) {
+	var existingAlert models.Alert
+	result := database.DB.Where("type = ? AND status = ?", alertType, "active").First(&existingAlert)
+
+	if result.Error != nil {
+		// 没有活跃告警，创建新的
+		alert := models.Alert{
+			ServerID:  serverID,
+			Type:      alertType,
+			Level:     level,
+			Message:   message,
+			Value:     value,
+			Threshold: threshold,
+			Status:    "active",
+			Timestamp: time.Now(),
+			Context:   alertContext(alertType),
+		}
+		database.DB.Create(&alert)
+
+		// 同时创建系统日志
+		systemLog := models.SystemLog{
+			ServerID:  serverID,
+			Level:     level,
+			Category:  category,
+			Message:   message,
+			Timestamp: time.Now(),
+		}
+		database.DB.Create(&systemLog)
+
+		emitAlertEvent(AlertFired, alert)
+		return
+	}
+
+	// 已有活跃告警，更新值；级别变化时记录升级/降级
+	previousLevel := existingAlert.Level
+	existingAlert.Value = value
+	existingAlert.Message = message
+	existingAlert.Level = level
+	existingAlert.UpdatedAt = time.Now()
+	database.DB.Save(&existingAlert)
+
+	if level != previousLevel {
+		if alertLevelRank[level] > alertLevelRank[previousLevel] {
+			emitAlertEvent(AlertEscalated, existingAlert)
+		} else {
+			emitAlertEvent(AlertDeescalated, existingAlert)
+		}
+	}
+}
+
+// resolveAlert 将指定类型的活跃告警标记为已解决，并记录恢复日志
+func resolveAlert(alertType, category, message string) {
+	resolveServerAlert(0, alertType, category, message)
+}
+
+// resolveServerAlert 解决主机的告警，恢复日志归属该主机
+func resolveServerAlert(serverID uint, alertType, category, message string) {
+	var existingAlert models.Alert
+	if database.DB.Where("type = ? AND status = ?", alertType, "active").First(&existingAlert).Error != nil {
+		return
+	}
+
+	existingAlert.Status = "resolved"
+	existingAlert.UpdatedAt = time.Now()
+	database.DB.Save(&existingAlert)
+
+	// 创建解决日志
+	systemLog := models.SystemLog{
+		ServerID:  serverID,
+		Level:     "info",
+		Category:  category,
+		Message:   message,
+		Timestamp: time.Now(),
+	}
+	database.DB.Create(&systemLog)
+
+	// 时间线和通知中使用恢复消息
+	resolved := existingAlert
+	resolved.Message = message
+	emitAlertEvent(AlertResolved, resolved)
+}
+
+// AcknowledgeAlert 确认告警，告警保持活跃，表示已有人在处理
+func AcknowledgeAlert(id uint) (*models.Alert, error) {
+	var alert models.Alert
+	if err := database.DB.First(&alert, id).Error; err != nil {
+		return nil, err
+	}
+	if alert.Status != "active" {
+		return nil, ErrAlertResolved
+	}
+
+	now := time.Now()
+	alert.AcknowledgedAt = &now
+	if err := database.DB.Save(&alert).Error; err != nil {
+		return nil, err
+	}
+
+	emitAlertEvent(AlertAcknowledged, alert)
+	return &alert, nil
+}
+
+// ResolveAlertByID 手动解决告警
+func ResolveAlertByID(id uint) (*models.Alert, error) {
+	var alert models.Alert
+	if err := database.DB.First(&alert, id).Error; err != nil {
+		return nil, err
+	}
+
+	// 已解决的告警保持原来的解决时间，不重复记录到时间线
+	if alert.Status != "active" {
+		return &alert, nil
+	}
+
+	alert.Status = "resolved"
+	alert.UpdatedAt = time.Now()
+	if err := database.DB.Save(&alert).Error; err != nil {
+		return nil, err
+	}
+
+	emitAlertEvent(AlertResolved, alert)
+	return &alert, nil
+}
+
+// SilenceAlert 静默告警，静默期间同类型告警的触发和恢复不再发送通知
+func SilenceAlert(id uint, duration time.Duration) (*models.Alert, error) {
+	var alert models.Alert
+	if err := database.DB.First(&alert, id).Error; err != nil {
+		return nil, err
+	}
+	if alert.Status != "active" {
+		return nil, ErrAlertResolved
+	}
+
+	until := time.Now().Add(duration)
+	alert.SilencedUntil = &until
+	if err := database.DB.Save(&alert).Error; err != nil {
+		return nil, err
+	}
+
+	emitAlertEvent(AlertSilenced, alert)
+	return &alert, nil
+}
+
+// GetAlertTimeline 获取告警的状态变化时间线（按时间先后排列）
+func GetAlertTimeline(id uint) (*models.Alert, []models.AlertEvent, error) {
+	var alert models.Alert
+	if err := database.DB.First(&alert, id).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var events []models.AlertEvent
+	if err := database.DB.Where("alert_id = ?", id).Order("timestamp asc, id asc").Find(&events).Error; err != nil {
+		return nil, nil, err
+	}
+	return &alert, events, nil
+}
+
+// AddAlertComment 为告警添加备注，已解决的告警也可以补充备注
+func AddAlertComment(id uint, author, content string) (*models.AlertComment, error) {
+	var alert models.Alert
+	if err := database.DB.First(&alert, id).Error; err != nil {
+		return nil, err
+	}
+
+	comment := models.AlertComment{AlertID: alert.ID, Author: author, Content: content}
+	if err := database.DB.Create(&comment).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// GetAlertComments 获取告警的备注，按添加时间升序
+func GetAlertComments(id uint) ([]models.AlertComment, error) {
+	var alert models.Alert
+	if err := database.DB.First(&alert, id).Error; err != nil {
+		return nil, err
+	}
+
+	comments := []models.AlertComment{}
+	if err := database.DB.Where("alert_id = ?", id).Order("created_at asc, id asc").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// IsAlertSilenced 判断指定类型的告警当前是否处于静默期
+func IsAlertSilenced(alertType string) bool {
+	var count int64
+	database.DB.Model(&models.Alert{}).
+		Where("type = ? AND silenced_until > ?", alertType, time.Now()).
+		Count(&count)
+	return count > 0
+}
@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 告警统计中列出的最频繁告警类型数量
+const noisiestAlertTypes = 10
+
+// resolutionSeconds 已解决告警从触发到解决的秒数，解决时间取告警最后更新时间
+const resolutionSeconds = "(julianday(updated_at) - julianday(created_at)) * 86400"
+
+// AlertTypeStats 单个告警类型的触发次数和平均解决时间
+type AlertTypeStats struct {
+	Type     string   `json:"type"`
+	Count    int64    `json:"count"`
+	Resolved int64    `json:"resolved"`
+	MTTR     *float64 `json:"mttr_seconds"` // 平均解决时间（秒），没有已解决的告警时为null
+}
+
+// AlertStats 一段时间内的告警统计，用于观察阈值调整前后告警数量的变化
+type AlertStats struct {
+	Days     int              `json:"days"`
+	Start    time.Time        `json:"start"`
+	End      time.Time        `json:"end"`
+	Total    int64            `json:"total"`
+	Active   int64            `json:"active"`
+	Resolved int64            `json:"resolved"`
+	MTTR     *float64         `json:"mttr_seconds"` // 平均解决时间（秒），没有已解决的告警时为null
+	ByType   []AlertCount     `json:"by_type"`
+	ByLevel  []AlertCount     `json:"by_level"`
+	ByDay    []AlertCount     `json:"by_day"` // 每天触发的告警数，没有告警的日期计为0
+	Noisiest []AlertTypeStats `json:"noisiest"`
+}
+
+// GetAlertStats 统计最近days天内触发的告警，scopes 用于进一步筛选告警，如按主机
+func GetAlertStats(days int, scopes ...func(*gorm.DB) *gorm.DB) (*AlertStats, error) {
+	end := time.Now()
+	location := config.AppConfig.I18n.Location()
+	now := end.In(location)
+	start := time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, location)
+	stats := &AlertStats{Days: days, Start: start, End: now}
+
+	alerts := database.DB.Model(&models.Alert{}).Scopes(scopes...).Where("created_at >= ? AND created_at < ?", start, end)
+	var summary struct {
+		Total    int64
+		Active   int64
+		Resolved int64
+		MTTR     *float64
+	}
+	if err := alerts.Session(&gorm.Session{}).Select(
+		"COUNT(*) AS total, " +
+			"COALESCE(SUM(CASE WHEN status = 'active' THEN 1 ELSE 0 END), 0) AS active, " +
+			"COALESCE(SUM(CASE WHEN status = 'resolved' THEN 1 ELSE 0 END), 0) AS resolved, " +
+			"AVG(CASE WHEN status = 'resolved' THEN " + resolutionSeconds + " END) AS mttr").
+		Scan(&summary).Error; err != nil {
+		return nil, i18n.Errorf("统计告警失败: %v", err)
+	}
+	stats.Total, stats.Active, stats.Resolved, stats.MTTR = summary.Total, summary.Active, summary.Resolved, summary.MTTR
+
+	stats.ByType = []AlertCount{}
+	if err := alerts.Session(&gorm.Session{}).Select("type AS key, COUNT(*) AS count").
+		Group("type").Order("count DESC").Scan(&stats.ByType).Error; err != nil {
+		return nil, i18n.Errorf("统计告警失败: %v", err)
+	}
+	stats.ByLevel = []AlertCount{}
+	if err := alerts.Session(&gorm.Session{}).Select("level AS key, COUNT(*) AS count").
+		Group("level").Order("count DESC").Scan(&stats.ByLevel).Error; err != nil {
+		return nil, i18n.Errorf("统计告警失败: %v", err)
+	}
+
+	var perDay []AlertCount
+	if err := alerts.Session(&gorm.Session{}).Select(database.LocalDate("created_at") + " AS key, COUNT(*) AS count").
+		Group("key").Scan(&perDay).Error; err != nil {
+		return nil, i18n.Errorf("统计告警失败: %v", err)
+	}
+	counts := make(map[string]int64, len(perDay))
+	for _, d := range perDay {
+		counts[d.Key] = d.Count
+	}
+	stats.ByDay = make([]AlertCount, 0, days)
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		stats.ByDay = append(stats.ByDay, AlertCount{Key: key, Count: counts[key]})
+	}
+
+	stats.Noisiest = []AlertTypeStats{}
+	if err := alerts.Session(&gorm.Session{}).Select(
+		"type, COUNT(*) AS count, " +
+			"SUM(CASE WHEN status = 'resolved' THEN 1 ELSE 0 END) AS resolved, " +
+			"AVG(CASE WHEN status = 'resolved' THEN " + resolutionSeconds + " END) AS mttr").
+		Group("type").Order("count DESC").Limit(noisiestAlertTypes).Scan(&stats.Noisiest).Error; err != nil {
+		return nil, i18n.Errorf("统计告警失败: %v", err)
+	}
+
+	return stats, nil
+}
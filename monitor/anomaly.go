@@ -0,0 +1,133 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 标准差低于均值的该比例时按该比例计算，避免平稳的指标因微小波动被标记为异常
+const anomalyMinDeviation = 0.05
+
+// AnomalyMetric 解析后的异常检测指标
+type AnomalyMetric struct {
+	Name   string // 配置中的原始写法，用作告警类型的后缀
+	Source string
+	Field  string
+	Tags   map[string]string
+}
+
+// ParseAnomalyMetrics 解析 anomaly.metrics，格式为 数据源.字段，可带 {k=v,...} 标签过滤；
+// 返回可检测的指标和无法识别的指标对应的错误
+func ParseAnomalyMetrics(metrics []string) ([]AnomalyMetric, []error) {
+	var parsed []AnomalyMetric
+	var errs []error
+	for _, name := range metrics {
+		metric, tags, _ := strings.Cut(name, "{")
+		source, field, err := splitComparisonMetric(metric)
+		if err == nil && !isSourceField(source, field) {
+			err = i18n.Errorf("数据源 %s 没有数值字段: %s", source, field)
+		}
+		var filter map[string]string
+		if err == nil {
+			filter, err = parseComparisonTags(source, strings.TrimSuffix(tags, "}"))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		parsed = append(parsed, AnomalyMetric{Name: name, Source: source, Field: field, Tags: filter})
+	}
+	return parsed, errs
+}
+
+// CheckAnomalies 比较每个指标最近 anomaly.window 秒的均值与之前 anomaly.baseline_hours 小时的均值和标准差，
+// 偏离超过 anomaly.threshold 倍标准差时触发 anomaly:<指标> 告警（info），回到正常范围后自动解除；基线样本不足时跳过
+func CheckAnomalies(metrics []AnomalyMetric) error {
+	cfg := config.AppConfig.Anomaly
+	now := time.Now()
+	windowStart := now.Add(-time.Duration(cfg.Window) * time.Second)
+	baselineStart := windowStart.Add(-time.Duration(cfg.BaselineHours) * time.Hour)
+	minutes := cfg.Window / 60
+
+	current := make(map[string]bool)
+	skipped := make(map[string]bool)
+	for _, metric := range metrics {
+		alertType := "anomaly:" + metric.Name
+		mean, std, samples, err := baselineStats(database.DB, metric.Source, metric.Field, metric.Tags, baselineStart, windowStart)
+		if err != nil {
+			return err
+		}
+		recent, ok, err := windowAverage(database.DB, metric.Source, metric.Field, metric.Tags, windowStart, now)
+		if err != nil {
+			return err
+		}
+		// 数据不足时保持原有告警，等下一次检查
+		if samples < int64(cfg.MinSamples) || !ok {
+			skipped[alertType] = true
+			continue
+		}
+
+		std = math.Max(std, math.Abs(mean)*anomalyMinDeviation)
+		if std == 0 {
+			skipped[alertType] = true
+			continue
+		}
+		deviation := (recent - mean) / std
+		if math.Abs(deviation) < cfg.Threshold {
+			continue
+		}
+
+		current[alertType] = true
+		message := i18n.Sprintf("%s 异常偏高: 最近 %d 分钟均值 %.2f，过去 %d 小时均值 %.2f±%.2f（偏离 %.1f 倍标准差）",
+			metric.Name, minutes, recent, cfg.BaselineHours, mean, std, deviation)
+		if deviation < 0 {
+			message = i18n.Sprintf("%s 异常偏低: 最近 %d 分钟均值 %.2f，过去 %d 小时均值 %.2f±%.2f（偏离 %.1f 倍标准差）",
+				metric.Name, minutes, recent, cfg.BaselineHours, mean, std, -deviation)
+		}
+		raiseAlert(alertType, "info", "system", message, deviation, cfg.Threshold)
+	}
+
+	// 包括重启前触发的告警和已从配置中移除的指标
+	var active []models.Alert
+	database.DB.Where("type LIKE ? AND status = ?", "anomaly:%", "active").Find(&active)
+	for _, alert := range active {
+		if !current[alert.Type] && !skipped[alert.Type] {
+			resolveAlert(alert.Type, "system", i18n.Sprintf("%s 已回到正常范围", strings.TrimPrefix(alert.Type, "anomaly:")))
+		}
+	}
+	return nil
+}
+
+// baselineStats 数据源字段在 [from, to) 内的均值、总体标准差和样本数，过滤方式与 windowAverage 相同
+func baselineStats(db *gorm.DB, source, field string, tags map[string]string, from, to time.Time) (float64, float64, int64, error) {
+	query := db.Table(comparisonSources[source].table).
+		Where("timestamp >= ? AND timestamp < ?", from, to)
+	for key, value := range tags {
+		query = query.Where(key+" = ?", value)
+	}
+	if _, ok := tags["server_id"]; !ok {
+		query = query.Scopes(database.HostScope(""))
+	}
+
+	var result struct {
+		Average float64
+		Square  float64
+		Samples int64
+	}
+	err := query.Select(fmt.Sprintf("AVG(%[1]s) AS average, AVG(%[1]s * %[1]s) AS square, COUNT(*) AS samples", field)).
+		Scan(&result).Error
+	if err != nil || result.Samples == 0 {
+		return 0, 0, 0, err
+	}
+	// 方差 = E[x²] - E[x]²，浮点误差可能使其略小于0
+	variance := math.Max(result.Square-result.Average*result.Average, 0)
+	return result.Average, math.Sqrt(variance), result.Samples, nil
+}
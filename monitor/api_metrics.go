@@ -0,0 +1,333 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// API请求按分钟分桶统计，最多保留的分钟数，即统计窗口的上限
+const apiMetricsMinutes = 60
+
+// 请求数少于该值时不判定延迟和错误率，避免个别请求触发告警
+const apiRuleMinRequests = 10
+
+// 延迟直方图的桶上界（毫秒），最后一个桶为无上界
+var apiLatencyBuckets = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// API规则可用的指标
+var apiRuleMetrics = map[string]string{
+	"error_rate": "错误率",
+	"p50":        "P50延迟",
+	"p95":        "P95延迟",
+	"p99":        "P99延迟",
+}
+
+// 监控程序启动时间
+var startTime = time.Now()
+
+var (
+	apiMetricsMu sync.Mutex
+	apiRoutes    = make(map[string]*routeBuckets) // 路由（方法 路径）-> 分钟桶
+)
+
+// routeBuckets 单个路由最近 apiMetricsMinutes 分钟的统计，按分钟循环使用
+type routeBuckets struct {
+	buckets [apiMetricsMinutes]apiBucket
+}
+
+type apiBucket struct {
+	minute   int64 // Unix分钟数，用于识别过期的桶
+	requests int64
+	errors   int64
+	latency  [14]int64 // 对应 apiLatencyBuckets 及无上界的桶
+	max      float64
+}
+
+// APIRouteStats 路由在统计窗口内的请求数、错误率和延迟分位数（毫秒）
+type APIRouteStats struct {
+	Route     string  `json:"route"`
+	Requests  int64   `json:"requests"`
+	Errors    int64   `json:"errors"`     // 5xx响应数
+	ErrorRate float64 `json:"error_rate"` // 百分比
+	P50       float64 `json:"p50"`
+	P95       float64 `json:"p95"`
+	P99       float64 `json:"p99"`
+	Max       float64 `json:"max"`
+}
+
+// SelfMetrics 监控程序自身的运行状态
+type SelfMetrics struct {
+	Uptime     int64           `json:"uptime"` // 运行时长（秒）
+	Goroutines int             `json:"goroutines"`
+	HeapAlloc  uint64          `json:"heap_alloc"` // 堆内存占用（字节）
+	Sys        uint64          `json:"sys"`        // 从系统申请的内存（字节）
+	NumGC      uint32          `json:"num_gc"`
+	Window     int             `json:"window"` // API统计窗口（秒）
+	Routes     []APIRouteStats `json:"routes"`
+}
+
+// RecordAPIRequest 记录一次API请求，5xx响应计为错误
+func RecordAPIRequest(route string, status int, duration time.Duration) {
+	now := time.Now()
+	minute := now.Unix() / 60
+	ms := float64(duration) / float64(time.Millisecond)
+
+	apiMetricsMu.Lock()
+	defer apiMetricsMu.Unlock()
+
+	buckets, ok := apiRoutes[route]
+	if !ok {
+		buckets = &routeBuckets{}
+		apiRoutes[route] = buckets
+	}
+	bucket := &buckets.buckets[minute%apiMetricsMinutes]
+	if bucket.minute != minute {
+		*bucket = apiBucket{minute: minute}
+	}
+
+	bucket.requests++
+	if status >= 500 {
+		bucket.errors++
+	}
+	bucket.latency[sort.SearchFloat64s(apiLatencyBuckets, ms)]++
+	if ms > bucket.max {
+		bucket.max = ms
+	}
+}
+
+// GetAPIRouteStats 汇总各路由最近window内的请求统计，route为空时返回全部路由，按请求数降序
+func GetAPIRouteStats(window time.Duration, route string) []APIRouteStats {
+	apiMetricsMu.Lock()
+	defer apiMetricsMu.Unlock()
+
+	result := make([]APIRouteStats, 0, len(apiRoutes))
+	for name, buckets := range apiRoutes {
+		if route != "" && name != route {
+			continue
+		}
+		stats := buckets.sum(window)
+		if stats.requests == 0 {
+			continue
+		}
+		stats.route = name
+		result = append(result, stats.toStats())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Requests != result[j].Requests {
+			return result[i].Requests > result[j].Requests
+		}
+		return result[i].Route < result[j].Route
+	})
+	return result
+}
+
+// GetAPITotalStats 汇总所有路由最近window内的请求统计
+func GetAPITotalStats(window time.Duration) APIRouteStats {
+	apiMetricsMu.Lock()
+	defer apiMetricsMu.Unlock()
+
+	total := apiBucket{}
+	for _, buckets := range apiRoutes {
+		total.add(buckets.sum(window).apiBucket)
+	}
+	return windowStats{apiBucket: total, route: "*"}.toStats()
+}
+
+// Uptime 监控程序的运行时长
+func Uptime() time.Duration {
+	return time.Since(startTime)
+}
+
+// GetSelfMetrics 获取监控程序自身的运行状态及最近window内的API统计
+func GetSelfMetrics(window time.Duration) SelfMetrics {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return SelfMetrics{
+		Uptime:     int64(Uptime().Seconds()),
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  mem.HeapAlloc,
+		Sys:        mem.Sys,
+		NumGC:      mem.NumGC,
+		Window:     int(window.Seconds()),
+		Routes:     GetAPIRouteStats(window, ""),
+	}
+}
+
+type windowStats struct {
+	apiBucket
+	route string
+}
+
+// sum 汇总最近window内（按整分钟）的桶
+func (r *routeBuckets) sum(window time.Duration) windowStats {
+	minutes := int64(math.Ceil(window.Minutes()))
+	if minutes > apiMetricsMinutes {
+		minutes = apiMetricsMinutes
+	}
+	oldest := time.Now().Unix()/60 - minutes + 1
+
+	var total apiBucket
+	for _, bucket := range r.buckets {
+		if bucket.requests > 0 && bucket.minute >= oldest {
+			total.add(bucket)
+		}
+	}
+	return windowStats{apiBucket: total}
+}
+
+// add 累加另一个桶的统计
+func (b *apiBucket) add(other apiBucket) {
+	b.requests += other.requests
+	b.errors += other.errors
+	for i := range b.latency {
+		b.latency[i] += other.latency[i]
+	}
+	if other.max > b.max {
+		b.max = other.max
+	}
+}
+
+func (s windowStats) toStats() APIRouteStats {
+	stats := APIRouteStats{
+		Route:    s.route,
+		Requests: s.requests,
+		Errors:   s.errors,
+		P50:      s.percentile(50),
+		P95:      s.percentile(95),
+		P99:      s.percentile(99),
+		Max:      math.Round(s.max*100) / 100,
+	}
+	if s.requests > 0 {
+		stats.ErrorRate = math.Round(float64(s.errors)/float64(s.requests)*10000) / 100
+	}
+	return stats
+}
+
+// percentile 按直方图估算延迟分位数，在所在桶内线性插值，不超过实际最大值
+func (b apiBucket) percentile(p float64) float64 {
+	if b.requests == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(b.requests)
+	var seen float64
+	for i, count := range b.latency {
+		if count == 0 {
+			continue
+		}
+		if seen+float64(count) < rank {
+			seen += float64(count)
+			continue
+		}
+		lower, upper := 0.0, b.max
+		if i > 0 {
+			lower = apiLatencyBuckets[i-1]
+		}
+		if i < len(apiLatencyBuckets) && apiLatencyBuckets[i] < upper {
+			upper = apiLatencyBuckets[i]
+		}
+		value := lower + (upper-lower)*(rank-seen)/float64(count)
+		return math.Round(math.Min(value, b.max)*100) / 100
+	}
+	return math.Round(b.max*100) / 100
+}
+
+// ValidateAPIRule 校验API规则：指标为 error_rate（百分比）或 p50、p95、p99（毫秒），
+// 标签 route=方法 路径 只检查单个路由，为空时检查全部API请求
+func ValidateAPIRule(rule *models.AlertRule) error {
+	if _, ok := apiRuleMetrics[rule.Metric]; !ok {
+		return i18n.Errorf("不支持的指标: %s，可选 error_rate、p50、p95、p99", rule.Metric)
+	}
+	if _, err := parseAPIRoute(rule.Tags); err != nil {
+		return err
+	}
+	if rule.Threshold <= 0 {
+		return i18n.Errorf("阈值必须大于0")
+	}
+	if rule.Metric == "error_rate" && rule.Threshold > 100 {
+		return i18n.Errorf("错误率阈值不能超过100")
+	}
+
+	if rule.Window == 0 {
+		rule.Window = 300
+	}
+	if rule.Window < 60 || rule.Window > apiMetricsMinutes*60 {
+		return i18n.Errorf("统计窗口必须在60秒到%d秒之间", apiMetricsMinutes*60)
+	}
+	return nil
+}
+
+// parseAPIRoute 解析 route=GET /api/v1/metrics 形式的路由过滤
+func parseAPIRoute(tags string) (string, error) {
+	if tags == "" {
+		return "", nil
+	}
+	kv := strings.SplitN(strings.TrimSpace(tags), "=", 2)
+	if len(kv) != 2 || kv[0] != "route" || kv[1] == "" {
+		return "", i18n.Errorf("标签格式应为 route=方法 路径，如 route=GET /api/v1/metrics")
+	}
+	return kv[1], nil
+}
+
+// CheckAPIRules 检查所有启用的API规则：统计窗口内的错误率或延迟分位数超过阈值时告警，
+// 使监控程序自身API变慢或出错时也能被发现
+func CheckAPIRules() error {
+	var rules []models.AlertRule
+	if err := database.DB.Where("type = ? AND enabled = ?", "api", true).Find(&rules).Error; err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		route, err := parseAPIRoute(rule.Tags)
+		if err != nil {
+			continue
+		}
+		window := time.Duration(rule.Window) * time.Second
+
+		var stats APIRouteStats
+		if route == "" {
+			stats = GetAPITotalStats(window)
+		} else if routes := GetAPIRouteStats(window, route); len(routes) > 0 {
+			stats = routes[0]
+		}
+		// 请求太少时分位数和错误率没有意义，保持当前状态
+		if stats.Requests < apiRuleMinRequests {
+			continue
+		}
+
+		value := map[string]float64{
+			"error_rate": stats.ErrorRate,
+			"p50":        stats.P50,
+			"p95":        stats.P95,
+			"p99":        stats.P99,
+		}[rule.Metric]
+		unit := "ms"
+		if rule.Metric == "error_rate" {
+			unit = "%"
+		}
+		target := route
+		if target == "" {
+			target = "API"
+		}
+
+		alertType := fmt.Sprintf("api_%d", rule.ID)
+		if value > rule.Threshold {
+			raiseAlert(alertType, rule.Level, "api",
+				i18n.Sprintf("%s: %s最近%d秒%s %.2f%s，超过阈值 %.2f%s（%d个请求）",
+					rule.Name, target, rule.Window, i18n.Sprintf(apiRuleMetrics[rule.Metric]), value, unit, rule.Threshold, unit, stats.Requests),
+				value, rule.Threshold)
+		} else {
+			resolveAlert(alertType, "api",
+				i18n.Sprintf("%s: %s%s已恢复到 %.2f%s", rule.Name, target, i18n.Sprintf(apiRuleMetrics[rule.Metric]), value, unit))
+		}
+	}
+
+	return nil
+}
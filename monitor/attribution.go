@@ -0,0 +1,259 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"server-monitor/config"
+	"server-monitor/i18n"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// 计算进程CPU占用的采样时长
+const attributionSample = time.Second
+
+// 容器运行时创建的cgroup，如 docker-<id>.scope、/docker/<id>、cri-containerd-<id>.scope、libpod-<id>.scope
+var containerCgroup = regexp.MustCompile(`(?:docker|libpod|cri-containerd|crio)[-/]([0-9a-f]{12,64})`)
+
+// ResourceGroup 按cgroup（容器、systemd服务、slice）汇总的资源占用，用于定位CPU、内存告警的来源
+type ResourceGroup struct {
+	Name          string  `json:"name"`           // 容器ID、systemd单元名，不在独立cgroup中时为进程名
+	Kind          string  `json:"kind"`           // container, service, scope, slice, cgroup, process
+	Cgroup        string  `json:"cgroup"`         // cgroup路径
+	CPU           float64 `json:"cpu"`            // 占整机CPU的百分比
+	Memory        uint64  `json:"memory"`         // 常驻内存（字节）
+	MemoryPercent float64 `json:"memory_percent"` // 占总内存的百分比
+	Processes     int     `json:"processes"`      // 进程数
+	TopProcess    string  `json:"top_process"`    // 组内占用最高的进程名
+}
+
+// 告警触发时附加的上下文，说明资源被谁占用
+var alertContexts = map[string]func() string{
+	"cpu":    func() string { return attributionContext("cpu") },
+	"memory": func() string { return attributionContext("memory") },
+}
+
+// alertContext 生成告警上下文，没有对应的上下文时返回空字符串
+func alertContext(alertType string) string {
+	build, ok := alertContexts[alertType]
+	if !ok {
+		return ""
+	}
+	return build()
+}
+
+// attributionContext 列出占用最高的几个资源组，如 "CPU占用最高: mysql.service（服务，mysqld）45.20%"
+func attributionContext(sortBy string) string {
+	limit := config.AppConfig.Monitor.AttributionGroups
+	if limit <= 0 {
+		return ""
+	}
+
+	groups, err := GetResourceGroups(sortBy, limit)
+	if err != nil || len(groups) == 0 {
+		return ""
+	}
+
+	kindNames := map[string]string{
+		"container": "容器",
+		"service":   "服务",
+		"scope":     "会话",
+		"slice":     "分组",
+		"cgroup":    "cgroup",
+		"process":   "进程",
+	}
+	parts := make([]string, 0, len(groups))
+	for _, g := range groups {
+		if g.CPU == 0 && sortBy == "cpu" || g.Memory == 0 && sortBy == "memory" {
+			continue
+		}
+		usage := fmt.Sprintf("%.2f%%", g.CPU)
+		if sortBy == "memory" {
+			usage = i18n.Sprintf("%.1fMB（%.2f%%）", float64(g.Memory)/1024/1024, g.MemoryPercent)
+		}
+		parts = append(parts, i18n.Sprintf("%s（%s，%s）%s", g.Name, i18n.Sprintf(kindNames[g.Kind]), g.TopProcess, usage))
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	title := "CPU占用最高: %s"
+	if sortBy == "memory" {
+		title = "内存占用最高: %s"
+	}
+	return i18n.Sprintf(title, strings.Join(parts, i18n.Sprintf("；")))
+}
+
+// GetResourceGroups 按cgroup汇总所有进程的CPU和内存占用，按sortBy（cpu或memory）降序返回前limit个；
+// 按CPU排序时需要采样1秒
+func GetResourceGroups(sortBy string, limit int) ([]ResourceGroup, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+
+	// 两次采样进程CPU时间，差值即采样期间的CPU占用
+	before := make(map[int32]float64)
+	elapsed := time.Duration(0)
+	if sortBy == "cpu" {
+		start := time.Now()
+		for _, p := range procs {
+			if times, err := p.Times(); err == nil {
+				before[p.Pid] = times.User + times.System
+			}
+		}
+		time.Sleep(attributionSample)
+		elapsed = time.Since(start)
+	}
+
+	cores, err := cpu.Counts(true)
+	if err != nil || cores == 0 {
+		cores = 1
+	}
+	var totalMemory uint64
+	if vm, err := mem.VirtualMemory(); err == nil {
+		totalMemory = vm.Total
+	}
+
+	type topUsage struct {
+		name  string
+		usage float64
+	}
+	groups := make(map[string]*ResourceGroup)
+	tops := make(map[string]topUsage)
+	for _, p := range procs {
+		name, err := p.Name()
+		if err != nil {
+			continue // 进程已退出
+		}
+
+		var cpuPercent float64
+		if start, ok := before[p.Pid]; ok {
+			if times, err := p.Times(); err == nil {
+				cpuPercent = (times.User + times.System - start) / elapsed.Seconds() / float64(cores) * 100
+			}
+		}
+		var rss uint64
+		if info, err := p.MemoryInfo(); err == nil {
+			rss = info.RSS
+		}
+
+		path := cgroupOf(p.Pid)
+		groupName, kind := describeCgroup(path)
+		if kind == "process" {
+			groupName = name
+		}
+		key := kind + ":" + groupName
+
+		group, ok := groups[key]
+		if !ok {
+			group = &ResourceGroup{Name: groupName, Kind: kind, Cgroup: path}
+			groups[key] = group
+		}
+		group.CPU += cpuPercent
+		group.Memory += rss
+		group.Processes++
+
+		usage := cpuPercent
+		if sortBy == "memory" {
+			usage = float64(rss)
+		}
+		if top, ok := tops[key]; !ok || usage > top.usage {
+			tops[key] = topUsage{name: name, usage: usage}
+		}
+	}
+
+	result := make([]ResourceGroup, 0, len(groups))
+	for key, group := range groups {
+		group.CPU = math.Round(group.CPU*100) / 100
+		if totalMemory > 0 {
+			group.MemoryPercent = math.Round(float64(group.Memory)/float64(totalMemory)*10000) / 100
+		}
+		group.TopProcess = tops[key].name
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if sortBy == "memory" {
+			return result[i].Memory > result[j].Memory
+		}
+		return result[i].CPU > result[j].CPU
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// cgroupOf 读取进程所属的cgroup路径（Linux），优先cgroup v2，其次systemd和cpu控制器；无法读取时返回 /
+func cgroupOf(pid int32) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "/"
+	}
+
+	paths := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[1] == "" {
+			paths["unified"] = fields[2]
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			paths[controller] = fields[2]
+		}
+	}
+
+	for _, controller := range []string{"unified", "name=systemd", "cpu", "cpuacct", "memory"} {
+		if path := paths[controller]; path != "" && path != "/" {
+			return path
+		}
+	}
+	return "/"
+}
+
+// describeCgroup 将cgroup路径转换为易读的名称和类型；根cgroup中的进程按进程名归类
+func describeCgroup(path string) (string, string) {
+	if match := containerCgroup.FindStringSubmatch(path); match != nil {
+		id := match[1]
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		return "container:" + id, "container"
+	}
+	if i := strings.Index(path, "lxc.payload."); i >= 0 {
+		return "lxc:" + strings.SplitN(path[i+len("lxc.payload."):], "/", 2)[0], "container"
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		switch {
+		case strings.HasSuffix(segment, ".service"):
+			return segment, "service"
+		case strings.HasSuffix(segment, ".scope"):
+			return segment, "scope"
+		case strings.HasSuffix(segment, ".slice"):
+			return segment, "slice"
+		}
+	}
+
+	// 其他cgroup（如Kubernetes的pod、自定义cgroup）使用最后一级名称
+	if last := segments[len(segments)-1]; last != "" {
+		if len(last) == 64 {
+			last = last[:12]
+		}
+		return last, "cgroup"
+	}
+	return "", "process"
+}
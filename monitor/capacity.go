@@ -0,0 +1,181 @@
+package monitor
+
+import (
+	"os"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"sort"
+	"time"
+)
+
+// 每日汇总中的速率单位为MB/s（1024*1024字节），换算为Mbps的系数
+const mbpsPerMBs = 8 * 1024 * 1024 / 1e6
+
+// 按30天计算每月的增长量
+const daysPerMonth = 30
+
+// CapacityTrend 一项资源的增长趋势和预计达到上限的时间
+type CapacityTrend struct {
+	Resource       string     `json:"resource"`         // disk、memory 或 bandwidth
+	Name           string     `json:"name"`             // 磁盘为挂载点，带宽为 网卡/方向（如 eth0/download），内存为空
+	Unit           string     `json:"unit"`             // % 或 Mbps
+	Current        float64    `json:"current"`          // 拟合的当前值，带宽为每日峰值速率
+	Limit          float64    `json:"limit"`            // 上限，未配置带宽时为0
+	GrowthPerMonth float64    `json:"growth_per_month"` // 每月的增长量，单位同 current
+	GrowthRate     *float64   `json:"growth_rate"`      // 每月相对当前值的增长率（%），当前值不大于0时为null
+	DaysUntilLimit *float64   `json:"days_until_limit"` // 预计还有多少天达到上限，没有增长或未配置上限时为null
+	LimitAt        *time.Time `json:"limit_at"`         // 预计达到上限的时间
+	Days           int        `json:"days"`             // 参与拟合的天数
+}
+
+// CapacityReport 容量规划报告：各资源的增长趋势和扩容建议
+type CapacityReport struct {
+	Hostname        string          `json:"hostname"`
+	Generated       time.Time       `json:"generated"`
+	Days            int             `json:"days"`         // 拟合窗口（天）
+	Method          string          `json:"method"`       // linear 或 holt
+	HorizonDays     int             `json:"horizon_days"` // 在该天数内达到上限的资源给出建议
+	Trends          []CapacityTrend `json:"trends"`
+	Recommendations []string        `json:"recommendations"`
+}
+
+// capacityResources 参与容量规划的指标：磁盘、内存按每日平均使用率，带宽按每日峰值速率
+var capacityResources = []struct {
+	resource string
+	metric   string
+	unit     string
+	peak     bool
+	scale    float64
+	name     func(tags map[string]string) string
+}{
+	{"disk", "disk_usage.usage", "%", false, 1, func(tags map[string]string) string { return tags["path"] }},
+	{"memory", "system_metrics.memory", "%", false, 1, func(map[string]string) string { return "" }},
+	{"bandwidth", "network_traffic.download_speed", "Mbps", true, mbpsPerMBs, func(tags map[string]string) string { return tags["interface"] + "/download" }},
+	{"bandwidth", "network_traffic.upload_speed", "Mbps", true, mbpsPerMBs, func(tags map[string]string) string { return tags["interface"] + "/upload" }},
+}
+
+// BuildCapacityReport 按最近 days 天的每日汇总拟合指定主机（本机为空）的磁盘、内存和带宽增长趋势，
+// 预测达到 capacity 配置的上限的时间，并对 capacity.horizon_days 天内达到上限的资源给出建议
+func BuildCapacityReport(host string, days int) (*CapacityReport, error) {
+	cfg := config.AppConfig.Capacity
+	method := config.AppConfig.Forecast.Method
+	hostname := host
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	now := time.Now()
+	report := &CapacityReport{
+		Hostname:    hostname,
+		Generated:   now,
+		Days:        days,
+		Method:      method,
+		HorizonDays: cfg.HorizonDays,
+		Trends:      []CapacityTrend{},
+	}
+	limits := map[string]float64{"disk": cfg.DiskLimit, "memory": cfg.MemoryLimit, "bandwidth": cfg.Bandwidth}
+	since := now.In(config.AppConfig.I18n.Location()).AddDate(0, 0, -days).Format("2006-01-02")
+
+	for _, resource := range capacityResources {
+		var tagSets []string
+		err := database.DB.Model(&models.DailySummary{}).Scopes(database.HostSummaryScope(host)).
+			Where("metric = ? AND day > ?", resource.metric, since).
+			Distinct("tags").Order("tags").Pluck("tags", &tagSets).Error
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tags := range tagSets {
+			points, err := dailySeries(resource.metric, tags, days, resource.peak)
+			if err != nil {
+				return nil, err
+			}
+			t, ok := fitTrend(points, method, config.AppConfig.Forecast.MinDays)
+			if !ok {
+				continue
+			}
+			report.Trends = append(report.Trends, capacityTrend(resource.resource, resource.name(parseTags(tags)), resource.unit,
+				t.Current*resource.scale, t.PerDay*resource.scale, limits[resource.resource], t, now))
+		}
+	}
+
+	report.Recommendations = capacityRecommendations(report.Trends, cfg.HorizonDays)
+	return report, nil
+}
+
+// capacityTrend 由拟合结果计算每月增长和达到上限的时间；已达到上限时剩余天数为0
+func capacityTrend(resource, name, unit string, current, perDay, limit float64, t trend, now time.Time) CapacityTrend {
+	result := CapacityTrend{
+		Resource:       resource,
+		Name:           name,
+		Unit:           unit,
+		Current:        current,
+		Limit:          limit,
+		GrowthPerMonth: perDay * daysPerMonth,
+		Days:           t.Days,
+	}
+	if current > 0 {
+		rate := result.GrowthPerMonth / current * 100
+		result.GrowthRate = &rate
+	}
+
+	switch {
+	case limit <= 0:
+	case current >= limit:
+		days := 0.0
+		result.DaysUntilLimit = &days
+		result.LimitAt = &t.At
+	case perDay > 0:
+		limitAt := t.At.Add(time.Duration((limit - current) / perDay * float64(24*time.Hour)))
+		days := limitAt.Sub(now).Hours() / 24
+		if days < 0 {
+			days = 0
+		}
+		result.DaysUntilLimit = &days
+		result.LimitAt = &limitAt
+	}
+	return result
+}
+
+// capacityRecommendations 对 horizon 天内达到上限的资源按紧急程度给出扩容建议
+func capacityRecommendations(trends []CapacityTrend, horizon int) []string {
+	if len(trends) == 0 {
+		return []string{i18n.Sprintf("每日汇总的数据不足，无法预测增长趋势（需要启用 rollup 并积累至少 %d 天的数据）", config.AppConfig.Forecast.MinDays)}
+	}
+
+	var urgent []CapacityTrend
+	for _, item := range trends {
+		if item.DaysUntilLimit != nil && *item.DaysUntilLimit <= float64(horizon) {
+			urgent = append(urgent, item)
+		}
+	}
+	if len(urgent) == 0 {
+		return []string{i18n.Sprintf("未来 %d 天内各项资源预计不会达到上限，暂不需要扩容", horizon)}
+	}
+	sort.SliceStable(urgent, func(i, j int) bool { return *urgent[i].DaysUntilLimit < *urgent[j].DaysUntilLimit })
+
+	location := config.AppConfig.I18n.Location()
+	recommendations := make([]string, 0, len(urgent))
+	for _, item := range urgent {
+		var label, advice string
+		switch item.Resource {
+		case "disk":
+			label, advice = i18n.Sprintf("磁盘 %s", item.Name), i18n.Sprintf("建议扩容磁盘或清理数据")
+		case "memory":
+			label, advice = i18n.Sprintf("内存"), i18n.Sprintf("建议升级到内存更大的配置")
+		default:
+			label, advice = i18n.Sprintf("网卡 %s 的峰值速率", item.Name), i18n.Sprintf("建议升级带宽")
+		}
+
+		if *item.DaysUntilLimit == 0 {
+			recommendations = append(recommendations, i18n.Sprintf("%s 已达到上限 %g%s（当前 %.1f%s），%s",
+				label, item.Limit, item.Unit, item.Current, item.Unit, advice))
+			continue
+		}
+		recommendations = append(recommendations, i18n.Sprintf("%s 预计 %.0f 天后（%s）达到 %g%s（当前 %.1f%s，每月增长 %.1f%s），%s",
+			label, *item.DaysUntilLimit, item.LimitAt.In(location).Format("2006-01-02"), item.Limit, item.Unit,
+			item.Current, item.Unit, item.GrowthPerMonth, item.Unit, advice))
+	}
+	return recommendations
+}
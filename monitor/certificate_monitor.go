@@ -0,0 +1,255 @@
+package monitor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Web服务器配置中引用证书文件的指令（nginx / apache）
+var certDirectivePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^\s*ssl_certificate\s+["']?([^;"'\s]+)["']?\s*;`),
+	regexp.MustCompile(`(?mi)^\s*SSLCertificateFile\s+["']?([^"'\s]+)["']?`),
+}
+
+type CertificateMonitor struct {
+	dialer *net.Dialer
+}
+
+// NewCertificateMonitor 创建证书监控实例
+func NewCertificateMonitor() *CertificateMonitor {
+	return &CertificateMonitor{
+		dialer: &net.Dialer{Timeout: 5 * time.Second},
+	}
+}
+
+// Discover 自动发现证书：扫描配置的主机端口以及本地Web服务器配置，返回新登记的证书数量
+func (cm *CertificateMonitor) Discover() int {
+	discovered := 0
+
+	for _, host := range config.AppConfig.Certificates.ScanHosts {
+		for _, port := range config.AppConfig.Certificates.ScanPorts {
+			endpoint := net.JoinHostPort(host, strconv.Itoa(port))
+			if _, err := cm.fetchEndpointCertificate(endpoint); err != nil {
+				continue
+			}
+			if cm.register(endpoint, "endpoint") {
+				discovered++
+			}
+		}
+	}
+
+	for _, path := range cm.scanConfigPaths() {
+		if cm.register(path, "file") {
+			discovered++
+		}
+	}
+
+	return discovered
+}
+
+// Register 手动登记需要监控的证书
+func (cm *CertificateMonitor) Register(endpoint string) (*models.Certificate, error) {
+	source := "manual"
+	if _, err := os.Stat(endpoint); err == nil {
+		source = "file"
+	} else if _, _, err := net.SplitHostPort(endpoint); err != nil {
+		return nil, i18n.Errorf("无效的证书地址: %s", endpoint)
+	}
+
+	cm.register(endpoint, source)
+
+	var cert models.Certificate
+	if err := database.DB.Where("endpoint = ?", endpoint).First(&cert).Error; err != nil {
+		return nil, err
+	}
+	cm.checkCertificate(&cert)
+	return &cert, nil
+}
+
+// register 登记证书，已存在时返回false
+func (cm *CertificateMonitor) register(endpoint, source string) bool {
+	var count int64
+	database.DB.Model(&models.Certificate{}).Where("endpoint = ?", endpoint).Count(&count)
+	if count > 0 {
+		return false
+	}
+
+	cert := models.Certificate{
+		Endpoint: endpoint,
+		Source:   source,
+	}
+	if err := database.DB.Create(&cert).Error; err != nil {
+		log.Printf("Error registering certificate %s: %v", endpoint, err)
+		return false
+	}
+
+	log.Printf("Certificate discovered: %s (%s)", endpoint, source)
+	return true
+}
+
+// CheckAll 检查所有已登记证书的有效期
+func (cm *CertificateMonitor) CheckAll() ([]models.Certificate, error) {
+	var certs []models.Certificate
+	if err := database.DB.Find(&certs).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range certs {
+		cm.checkCertificate(&certs[i])
+	}
+
+	return certs, nil
+}
+
+// checkCertificate 读取证书并更新有效期信息
+func (cm *CertificateMonitor) checkCertificate(cert *models.Certificate) {
+	var x509Cert *x509.Certificate
+	var err error
+
+	if cert.Source == "file" {
+		x509Cert, err = readCertificateFile(cert.Endpoint)
+	} else {
+		x509Cert, err = cm.fetchEndpointCertificate(cert.Endpoint)
+	}
+
+	cert.LastCheck = time.Now()
+	if err != nil {
+		cert.Error = err.Error()
+	} else {
+		cert.Error = ""
+		cert.Subject = x509Cert.Subject.CommonName
+		cert.Issuer = x509Cert.Issuer.CommonName
+		cert.DNSNames = strings.Join(x509Cert.DNSNames, ",")
+		cert.NotBefore = x509Cert.NotBefore
+		cert.NotAfter = x509Cert.NotAfter
+		cert.DaysLeft = int(time.Until(x509Cert.NotAfter).Hours() / 24)
+	}
+
+	database.DB.Save(cert)
+}
+
+// CheckAlerts 证书即将到期或已过期时触发告警
+func (cm *CertificateMonitor) CheckAlerts(certs []models.Certificate) {
+	warnDays := config.AppConfig.Certificates.WarnDays
+
+	var expiring []string
+	minDays := warnDays
+	for _, cert := range certs {
+		if cert.NotAfter.IsZero() || cert.DaysLeft > warnDays {
+			continue
+		}
+		expiring = append(expiring, i18n.Sprintf("%s(%d天)", cert.Endpoint, cert.DaysLeft))
+		if cert.DaysLeft < minDays {
+			minDays = cert.DaysLeft
+		}
+	}
+
+	if len(expiring) > 0 {
+		level := "warning"
+		if minDays <= 0 {
+			level = "error"
+		}
+		raiseAlert("certificate", level, "security",
+			i18n.Sprintf("TLS证书即将到期: %s", strings.Join(expiring, ", ")),
+			float64(minDays), float64(warnDays))
+	} else {
+		resolveAlert("certificate", "security", i18n.Sprintf("所有TLS证书有效期正常"))
+	}
+}
+
+// fetchEndpointCertificate 通过TLS握手获取服务端证书
+func (cm *CertificateMonitor) fetchEndpointCertificate(endpoint string) (*x509.Certificate, error) {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := tls.DialWithDialer(cm.dialer, "tcp", endpoint, &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true, // 只读取证书信息，自签名证书同样需要监控
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	peerCerts := conn.ConnectionState().PeerCertificates
+	if len(peerCerts) == 0 {
+		return nil, i18n.Errorf("服务端未返回证书")
+	}
+	return peerCerts[0], nil
+}
+
+// scanConfigPaths 解析本地Web服务器配置，返回其中引用的证书文件路径
+func (cm *CertificateMonitor) scanConfigPaths() []string {
+	seen := make(map[string]bool)
+	var paths []string
+
+	for _, root := range config.AppConfig.Certificates.ConfigPaths {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil || info.Size() > 1024*1024 {
+				return nil
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+
+			for _, pattern := range certDirectivePatterns {
+				for _, match := range pattern.FindAllStringSubmatch(string(content), -1) {
+					certPath := match[1]
+					// 跳过包含变量的路径，如 $ssl_certificate
+					if strings.Contains(certPath, "$") || seen[certPath] {
+						continue
+					}
+					if _, err := os.Stat(certPath); err != nil {
+						continue
+					}
+					seen[certPath] = true
+					paths = append(paths, certPath)
+				}
+			}
+			return nil
+		})
+	}
+
+	return paths
+}
+
+// readCertificateFile 读取PEM文件中的第一张证书
+func readCertificateFile(path string) (*x509.Certificate, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			return nil, i18n.Errorf("未找到PEM格式证书: %s", path)
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strings"
+	"time"
+)
+
+// NTP时间从1900年起算，与Unix时间相差的秒数
+const ntpEpochOffset = 2208988800
+
+// CheckClock 依次向 clock.servers 查询时间直到成功，保存本机时钟的偏差
+func CheckClock() (*models.ClockCheck, error) {
+	cfg := config.AppConfig.Clock
+	timeout := time.Duration(cfg.Timeout) * time.Second
+
+	var errs []string
+	for _, server := range cfg.Servers {
+		offset, delay, stratum, err := queryNTP(server, timeout)
+		if err != nil {
+			errs = append(errs, server+": "+err.Error())
+			continue
+		}
+		check := models.ClockCheck{
+			Server:    server,
+			Offset:    offset.Seconds(),
+			Delay:     delay.Seconds(),
+			Stratum:   stratum,
+			Timestamp: time.Now(),
+		}
+		if err := database.DB.Create(&check).Error; err != nil {
+			return nil, err
+		}
+		return &check, nil
+	}
+	return nil, i18n.Errorf("所有NTP服务器均查询失败: %s", strings.Join(errs, "; "))
+}
+
+// CheckClockAlerts 偏差超过 clock.threshold 时触发 clock_drift 告警，超过 clock.critical 时升级为error，恢复后自动解除
+func CheckClockAlerts(check *models.ClockCheck) {
+	cfg := config.AppConfig.Clock
+	drift := math.Abs(check.Offset)
+	if drift <= cfg.Threshold {
+		resolveAlert("clock_drift", "system", i18n.Sprintf("时钟偏差已恢复正常: %.3f秒", check.Offset))
+		return
+	}
+
+	level := "warning"
+	if cfg.Critical > 0 && drift > cfg.Critical {
+		level = "error"
+	}
+	message := i18n.Sprintf("本机时钟比 %s 快 %.3f 秒", check.Server, drift)
+	if check.Offset > 0 {
+		message = i18n.Sprintf("本机时钟比 %s 慢 %.3f 秒", check.Server, drift)
+	}
+	raiseAlert("clock_drift", level, "system", message, drift, cfg.Threshold)
+}
+
+// GetLatestClockCheck 最近一次时钟偏差检查的结果，没有检查过时返回nil
+func GetLatestClockCheck() (*models.ClockCheck, error) {
+	var checks []models.ClockCheck
+	if err := database.DB.Order("timestamp desc").Limit(1).Find(&checks).Error; err != nil {
+		return nil, err
+	}
+	if len(checks) == 0 {
+		return nil, nil
+	}
+	return &checks[0], nil
+}
+
+// queryNTP 发送一次SNTP请求（RFC 4330），返回服务器时间减本机时间的偏差、往返延迟和服务器层级
+func queryNTP(server string, timeout time.Duration) (offset, delay time.Duration, stratum int, err error) {
+	address := server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		address = net.JoinHostPort(server, "123")
+	}
+	conn, err := net.DialTimeout("udp", address, timeout)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	request := make([]byte, 48)
+	// LI=0（无闰秒），VN=4，Mode=3（客户端）
+	request[0] = 0x23
+	sent := time.Now()
+	// 服务器在应答的originate字段中原样返回发送时间，用于匹配请求
+	binary.BigEndian.PutUint64(request[40:], ntpTimestamp(sent))
+	if _, err := conn.Write(request); err != nil {
+		return 0, 0, 0, err
+	}
+
+	response := make([]byte, 48)
+	n, err := conn.Read(response)
+	received := time.Now()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if n < 48 || response[0]&0x07 != 4 {
+		return 0, 0, 0, errors.New("invalid NTP response")
+	}
+	if !bytes.Equal(response[24:32], request[40:48]) {
+		return 0, 0, 0, errors.New("NTP response does not match the request")
+	}
+	stratum = int(response[1])
+	// 层级0为Kiss-o'-Death，服务器要求降低请求频率或拒绝服务
+	if stratum == 0 || stratum > 15 {
+		return 0, 0, 0, errors.New("NTP server is unsynchronized or refused the request")
+	}
+
+	serverReceived := ntpTime(binary.BigEndian.Uint64(response[32:]))
+	serverSent := ntpTime(binary.BigEndian.Uint64(response[40:]))
+	// received 与 sent 之差使用单调时钟，不受检查期间时钟调整的影响
+	roundTrip := received.Sub(sent)
+	offset = (serverReceived.Sub(sent.Round(0)) + serverSent.Sub(sent.Round(0).Add(roundTrip))) / 2
+	delay = roundTrip - serverSent.Sub(serverReceived)
+	return offset, delay, stratum, nil
+}
+
+// ntpTimestamp 转换为NTP时间戳：高32位为1900年起的秒数，低32位为秒的小数部分
+func ntpTimestamp(t time.Time) uint64 {
+	seconds := uint64(t.Unix() + ntpEpochOffset)
+	fraction := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+	return seconds<<32 | fraction
+}
+
+// ntpTime NTP时间戳对应的时间
+func ntpTime(timestamp uint64) time.Time {
+	seconds := int64(timestamp>>32) - ntpEpochOffset
+	nanoseconds := int64((timestamp & 0xffffffff) * uint64(time.Second) >> 32)
+	return time.Unix(seconds, nanoseconds)
+}
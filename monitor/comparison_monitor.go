@@ -0,0 +1,226 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 同比规则可对比的时段
+var comparisonOffsets = map[string]time.Duration{
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
+var comparisonOffsetNames = map[string]string{
+	"day":  "昨天同期",
+	"week": "上周同期",
+}
+
+// 同比规则数据源对应的数据表及可过滤的标签
+var comparisonSources = map[string]struct {
+	table string
+	tags  map[string]bool
+}{
+	"system_metrics":  {table: "system_metrics", tags: map[string]bool{"server_id": true}},
+	"network_traffic": {table: "network_traffics", tags: map[string]bool{"server_id": true, "interface": true}},
+	"disk_usage":      {table: "disk_usages", tags: map[string]bool{"server_id": true, "path": true, "name": true}},
+}
+
+// ValidateComparisonRule 校验同比规则：指标为 数据源.字段（与序列指标名一致），标签只能是数据源的维度字段
+func ValidateComparisonRule(rule *models.AlertRule) error {
+	source, field, err := splitComparisonMetric(rule.Metric)
+	if err != nil {
+		return err
+	}
+	if _, err := parseComparisonTags(source, rule.Tags); err != nil {
+		return err
+	}
+	if !isSourceField(source, field) {
+		return i18n.Errorf("不支持的指标: %s", rule.Metric)
+	}
+
+	offset, ok := comparisonOffsets[rule.CompareTo]
+	if !ok {
+		return i18n.Errorf("不支持的对比时段: %s，可选 day、week", rule.CompareTo)
+	}
+	switch rule.Direction {
+	case "":
+		rule.Direction = "drop"
+	case "drop", "rise":
+	default:
+		return i18n.Errorf("不支持的变化方向: %s，可选 drop、rise", rule.Direction)
+	}
+	if rule.Percent <= 0 {
+		return i18n.Errorf("变化百分比必须大于0")
+	}
+
+	if rule.Window == 0 {
+		rule.Window = 3600
+	}
+	window := time.Duration(rule.Window) * time.Second
+	if window < time.Minute || window > offset {
+		return i18n.Errorf("聚合窗口必须在60秒到%d秒之间", int(offset.Seconds()))
+	}
+
+	retention := time.Duration(config.AppConfig.Monitor.HistoryHours) * time.Hour
+	if retention < offset+window {
+		return i18n.Errorf("历史数据只保留%d小时，对比%s至少需要%d小时，请调大 monitor.history_hours",
+			config.AppConfig.Monitor.HistoryHours, comparisonOffsetNames[rule.CompareTo], int(math.Ceil((offset + window).Hours())))
+	}
+	return nil
+}
+
+// splitComparisonMetric 拆分 数据源.字段
+func splitComparisonMetric(metric string) (string, string, error) {
+	parts := strings.SplitN(metric, ".", 2)
+	if len(parts) != 2 {
+		return "", "", i18n.Errorf("指标格式应为 数据源.字段，如 network_traffic.download_speed")
+	}
+	if _, ok := comparisonSources[parts[0]]; !ok {
+		return "", "", i18n.Errorf("不支持的数据源: %s", parts[0])
+	}
+	return parts[0], parts[1], nil
+}
+
+// isSourceField 判断字段是否为数据源登记的数值字段
+func isSourceField(source, field string) bool {
+	for _, f := range sourceSeriesFields[source] {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// parseComparisonTags 解析 k=v,k2=v2 形式的标签过滤
+func parseComparisonTags(source, tags string) (map[string]string, error) {
+	result := make(map[string]string)
+	if tags == "" {
+		return result, nil
+	}
+	for _, pair := range strings.Split(tags, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, i18n.Errorf("标签格式应为 key=value，当前为 %q", pair)
+		}
+		if !comparisonSources[source].tags[kv[0]] {
+			return nil, i18n.Errorf("数据源 %s 不支持标签: %s", source, kv[0])
+		}
+		result[kv[0]] = kv[1]
+	}
+	return result, nil
+}
+
+// windowAverage 计算指标在 [from, to) 内的平均值，没有数据时ok为false
+func windowAverage(db *gorm.DB, source, field string, tags map[string]string, from, to time.Time) (float64, bool, error) {
+	query := db.Table(comparisonSources[source].table).
+		Where("timestamp >= ? AND timestamp < ?", from, to)
+	for key, value := range tags {
+		query = query.Where(key+" = ?", value)
+	}
+	// 未指定主机时只统计本机数据
+	if _, ok := tags["server_id"]; !ok {
+		query = query.Scopes(database.HostScope(""))
+	}
+
+	var result struct {
+		Average float64
+		Samples int64
+	}
+	err := query.Select(fmt.Sprintf("AVG(%s) AS average, COUNT(*) AS samples", field)).Scan(&result).Error
+	if err != nil {
+		return 0, false, err
+	}
+	return result.Average, result.Samples > 0, nil
+}
+
+// CheckComparisonRules 检查所有启用的同比规则：最近一个窗口的均值与昨天/上周同一窗口相比，
+// 下降（或上升）超过设定的百分比时告警，用于发现静态阈值发现不了的缓慢退化
+func CheckComparisonRules() error {
+	var rules []models.AlertRule
+	if err := database.DB.Where("type = ? AND enabled = ?", "comparison", true).Find(&rules).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		result, err := evaluateComparison(database.DB, rule, now)
+		if err != nil {
+			return err
+		}
+		if result.Skipped != "" {
+			continue
+		}
+
+		alertType := fmt.Sprintf("comparison_%d", rule.ID)
+		if result.Fired {
+			raiseAlert(alertType, rule.Level, "system", result.Message, result.Value, result.Threshold)
+		} else {
+			resolveAlert(alertType, "system", result.Message)
+		}
+	}
+
+	return nil
+}
+
+// evaluateComparison 按db中的数据判定同比规则在now时刻是否触发，数据不足时在Skipped中说明原因
+func evaluateComparison(db *gorm.DB, rule models.AlertRule, now time.Time) (RuleResult, error) {
+	result := newRuleResult(rule)
+	result.Threshold = rule.Percent
+
+	source, field, err := splitComparisonMetric(rule.Metric)
+	if err != nil {
+		result.Skipped = err.Error()
+		return result, nil
+	}
+	tags, err := parseComparisonTags(source, rule.Tags)
+	if err != nil {
+		result.Skipped = err.Error()
+		return result, nil
+	}
+	offset := comparisonOffsets[rule.CompareTo]
+	window := time.Duration(rule.Window) * time.Second
+	label := i18n.Sprintf(comparisonOffsetNames[rule.CompareTo])
+
+	current, ok, err := windowAverage(db, source, field, tags, now.Add(-window), now)
+	if err != nil {
+		return result, err
+	}
+	if !ok {
+		result.Skipped = i18n.Sprintf("最近一个窗口没有数据")
+		return result, nil
+	}
+	baseline, ok, err := windowAverage(db, source, field, tags, now.Add(-offset-window), now.Add(-offset))
+	if err != nil {
+		return result, err
+	}
+	// 对比时段没有数据（刚部署或数据已清理）或基准为0时无法计算变化率
+	if !ok || baseline == 0 {
+		result.Skipped = i18n.Sprintf("%s没有数据，无法计算变化率", label)
+		return result, nil
+	}
+
+	change := (current - baseline) / baseline * 100
+	result.Value = math.Abs(change)
+	result.Fired = rule.Direction == "drop" && -change >= rule.Percent ||
+		rule.Direction == "rise" && change >= rule.Percent
+	if result.Fired {
+		verb := i18n.Sprintf("下降")
+		if change > 0 {
+			verb = i18n.Sprintf("上升")
+		}
+		result.Message = i18n.Sprintf("%s: %s较%s%s %.1f%%（当前 %.2f，%s %.2f）",
+			rule.Name, rule.Metric, label, verb, math.Abs(change), current, label, baseline)
+	} else {
+		result.Message = i18n.Sprintf("%s: %s已恢复到%s水平（当前 %.2f，%s %.2f）", rule.Name, rule.Metric, label, current, label, baseline)
+	}
+	return result, nil
+}
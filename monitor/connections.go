@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Connection 本机的一个TCP/UDP套接字及其所属进程，类似 ss -tunap 的一行
+type Connection struct {
+	Protocol   string `json:"protocol"`    // tcp, tcp6, udp, udp6
+	LocalAddr  string `json:"local_addr"`  // 本地地址
+	LocalPort  uint32 `json:"local_port"`  // 本地端口
+	RemoteAddr string `json:"remote_addr"` // 远端地址，监听中的套接字为空
+	RemotePort uint32 `json:"remote_port"` // 远端端口
+	State      string `json:"state"`       // TCP状态（LISTEN、ESTABLISHED等）；UDP未连接时为 UNCONN，已连接时为 ESTABLISHED
+	PID        int32  `json:"pid"`         // 所属进程，无权限查看其他用户的进程时为0
+	Process    string `json:"process"`     // 进程名
+}
+
+// Listening 套接字是否在等待连接：TCP的 LISTEN，或未连接的UDP套接字
+func (c Connection) Listening() bool {
+	return c.State == "LISTEN" || c.State == "UNCONN"
+}
+
+// ConnectionFilter 连接列表的筛选条件，零值表示不筛选
+type ConnectionFilter struct {
+	Protocol  string   // tcp 或 udp，同时包含IPv4和IPv6
+	States    []string // 状态，不区分大小写
+	Port      uint32   // 本地或远端端口
+	Process   string   // 进程名包含该字符串，不区分大小写
+	Listening bool     // 只返回监听中的套接字
+}
+
+// GetConnections 获取本机当前的TCP/UDP连接并关联所属进程，按协议和本地端口排序
+func GetConnections(filter ConnectionFilter) ([]Connection, error) {
+	stats, err := net.Connections("inet")
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int32]string)
+	connections := make([]Connection, 0, len(stats))
+	for _, stat := range stats {
+		conn := Connection{
+			Protocol:   connectionProtocol(stat),
+			LocalAddr:  stat.Laddr.IP,
+			LocalPort:  stat.Laddr.Port,
+			RemoteAddr: stat.Raddr.IP,
+			RemotePort: stat.Raddr.Port,
+			State:      stat.Status,
+			PID:        stat.Pid,
+		}
+		if stat.Type == syscall.SOCK_DGRAM {
+			conn.State = "UNCONN"
+			if conn.RemotePort != 0 {
+				conn.State = "ESTABLISHED"
+			}
+		}
+		if conn.Listening() {
+			conn.RemoteAddr = ""
+		}
+		if !filter.matches(conn) {
+			continue
+		}
+		if conn.PID > 0 {
+			name, ok := names[conn.PID]
+			if !ok {
+				if p, err := process.NewProcess(conn.PID); err == nil {
+					name, _ = p.Name()
+				}
+				names[conn.PID] = name
+			}
+			conn.Process = name
+		}
+		if filter.Process != "" && !strings.Contains(strings.ToLower(conn.Process), strings.ToLower(filter.Process)) {
+			continue
+		}
+		connections = append(connections, conn)
+	}
+
+	sort.Slice(connections, func(i, j int) bool {
+		a, b := connections[i], connections[j]
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		if a.LocalPort != b.LocalPort {
+			return a.LocalPort < b.LocalPort
+		}
+		return a.RemoteAddr < b.RemoteAddr
+	})
+	return connections, nil
+}
+
+// matches 按进程以外的条件筛选，进程名需要查询后再筛选
+func (f ConnectionFilter) matches(conn Connection) bool {
+	if f.Protocol != "" && !strings.HasPrefix(conn.Protocol, f.Protocol) {
+		return false
+	}
+	if f.Listening && !conn.Listening() {
+		return false
+	}
+	if f.Port != 0 && conn.LocalPort != f.Port && conn.RemotePort != f.Port {
+		return false
+	}
+	if len(f.States) > 0 {
+		found := false
+		for _, state := range f.States {
+			if strings.EqualFold(state, conn.State) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// connectionProtocol 套接字的协议名，如 tcp、udp6
+func connectionProtocol(stat net.ConnectionStat) string {
+	protocol := "tcp"
+	if stat.Type == syscall.SOCK_DGRAM {
+		protocol = "udp"
+	}
+	if stat.Family == syscall.AF_INET6 {
+		protocol += "6"
+	}
+	return protocol
+}
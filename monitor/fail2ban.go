@@ -0,0 +1,193 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fail2banJail fail2ban中一个jail的当前状态，对应 fail2ban-client status <jail> 的输出
+type Fail2banJail struct {
+	Name            string   `json:"name"`
+	CurrentlyFailed int      `json:"currently_failed"` // 当前在计数中的失败次数
+	TotalFailed     int      `json:"total_failed"`     // fail2ban启动以来的失败次数
+	CurrentlyBanned int      `json:"currently_banned"` // 当前封禁的地址数
+	TotalBanned     int      `json:"total_banned"`     // fail2ban启动以来的封禁次数
+	BannedIPs       []string `json:"banned_ips"`       // 当前封禁的地址
+}
+
+// ErrFail2banJailNotFound 解封时指定的jail不存在
+var ErrFail2banJailNotFound = i18n.Errorf("jail不存在")
+
+// GetFail2banStatus 读取所有jail的当前状态，按名称排序
+func GetFail2banStatus() ([]Fail2banJail, error) {
+	output, err := fail2banClient("status")
+	if err != nil {
+		return nil, err
+	}
+	names := parseFail2banJails(output)
+	sort.Strings(names)
+
+	jails := make([]Fail2banJail, 0, len(names))
+	for _, name := range names {
+		output, err := fail2banClient("status", name)
+		if err != nil {
+			return nil, err
+		}
+		jails = append(jails, parseFail2banJail(name, output))
+	}
+	return jails, nil
+}
+
+// SyncFail2ban 同步封禁状态：记录新封禁的地址，为已解封的地址记录解封时间，新封禁写入系统日志；
+// 封禁历史为空时只建立基线，不写系统日志。返回新封禁的地址数
+func SyncFail2ban() (int, error) {
+	jails, err := GetFail2banStatus()
+	if err != nil {
+		return 0, err
+	}
+
+	var active []models.Fail2banBan
+	if err := database.DB.Where("unbanned_at IS NULL").Find(&active).Error; err != nil {
+		return 0, err
+	}
+	var count int64
+	if err := database.DB.Model(&models.Fail2banBan{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	baseline := count == 0
+
+	known := make(map[string]bool, len(active))
+	for _, ban := range active {
+		known[ban.Jail+"/"+ban.IP] = true
+	}
+
+	now := time.Now()
+	current := make(map[string]bool)
+	banned := 0
+	for _, jail := range jails {
+		var added []string
+		for _, ip := range jail.BannedIPs {
+			current[jail.Name+"/"+ip] = true
+			if known[jail.Name+"/"+ip] {
+				continue
+			}
+			if err := database.DB.Create(&models.Fail2banBan{Jail: jail.Name, IP: ip, BannedAt: now}).Error; err != nil {
+				return banned, err
+			}
+			added = append(added, ip)
+		}
+		banned += len(added)
+		if len(added) > 0 && !baseline {
+			database.DB.Create(&models.SystemLog{
+				Level:     "info",
+				Category:  "security",
+				Message:   i18n.Sprintf("fail2ban %s 封禁了 %d 个地址: %s", jail.Name, len(added), strings.Join(added, ", ")),
+				Timestamp: now,
+			})
+		}
+	}
+
+	for _, ban := range active {
+		if !current[ban.Jail+"/"+ban.IP] {
+			database.DB.Model(&ban).Update("unbanned_at", now)
+		}
+	}
+	return banned, nil
+}
+
+// UnbanFail2ban 在指定jail中解封地址并记录解封时间；地址未被封禁时 fail2ban-client 同样返回成功
+func UnbanFail2ban(jail, ip string) error {
+	if net.ParseIP(ip) == nil {
+		return i18n.Errorf("无效的IP地址: %s", ip)
+	}
+	output, err := fail2banClient("status")
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(parseFail2banJails(output), jail) {
+		return ErrFail2banJailNotFound
+	}
+	if _, err := fail2banClient("set", jail, "unbanip", ip); err != nil {
+		return err
+	}
+
+	return database.DB.Model(&models.Fail2banBan{}).
+		Where("jail = ? AND ip = ? AND unbanned_at IS NULL", jail, ip).
+		Update("unbanned_at", time.Now()).Error
+}
+
+// fail2banClient 执行 fail2ban-client，配置了 fail2ban.socket 时通过 -s 指定套接字
+func fail2banClient(args ...string) (string, error) {
+	cfg := config.AppConfig.Fail2ban
+	if cfg.Socket != "" {
+		args = append([]string{"-s", cfg.Socket}, args...)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, cfg.Command, args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return string(output), nil
+}
+
+// fail2banFields 解析 fail2ban-client status 的树形输出，如 "|  |- Currently failed:	1"，返回各项的值
+func fail2banFields(output string) map[string]string {
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), "|`- \t")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return fields
+}
+
+// parseFail2banJails 从 fail2ban-client status 的输出中获取jail列表
+func parseFail2banJails(output string) []string {
+	var names []string
+	for _, name := range strings.Split(fail2banFields(output)["Jail list"], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseFail2banJail 解析 fail2ban-client status <jail> 的输出
+func parseFail2banJail(name, output string) Fail2banJail {
+	fields := fail2banFields(output)
+	number := func(key string) int {
+		n, _ := strconv.Atoi(fields[key])
+		return n
+	}
+	return Fail2banJail{
+		Name:            name,
+		CurrentlyFailed: number("Currently failed"),
+		TotalFailed:     number("Total failed"),
+		CurrentlyBanned: number("Currently banned"),
+		TotalBanned:     number("Total banned"),
+		BannedIPs:       append([]string{}, strings.Fields(fields["Banned IP list"])...),
+	}
+}
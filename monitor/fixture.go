@@ -0,0 +1,149 @@
+package monitor
+
+import (
+	"encoding/json"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 单个夹具最多录制的记录数，避免一次录制过长的时间范围
+const maxFixtureRows = 200000
+
+// RuleResult 告警规则的判定结果
+type RuleResult struct {
+	RuleID    uint    `json:"rule_id"`
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Fired     bool    `json:"fired"`
+	Message   string  `json:"message"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Skipped   string  `json:"skipped,omitempty"` // 无法判定的原因，如数据不足
+}
+
+func newRuleResult(rule models.AlertRule) RuleResult {
+	return RuleResult{RuleID: rule.ID, Name: rule.Name, Type: rule.Type}
+}
+
+// fixtureData 夹具录制的数据，与无数据、同比规则使用的数据源一致
+type fixtureData struct {
+	SystemMetrics  []models.SystemMetrics  `json:"system_metrics"`
+	NetworkTraffic []models.NetworkTraffic `json:"network_traffic"`
+	DiskUsage      []models.DiskUsage      `json:"disk_usage"`
+	ServiceStatus  []models.ServiceStatus  `json:"service_status"`
+}
+
+// RecordFixture 将 [from, to] 内的系统指标、网络流量、磁盘使用和服务状态录制为夹具；
+// 同比规则需要对比时段的数据，录制范围应包含昨天或上周的同一窗口
+func RecordFixture(name, description string, from, to time.Time) (*models.AlertFixture, error) {
+	if name == "" {
+		return nil, i18n.Errorf("夹具名称不能为空")
+	}
+	if !to.After(from) {
+		return nil, i18n.Errorf("结束时间必须晚于开始时间")
+	}
+
+	var data fixtureData
+	queries := []struct {
+		column string
+		dest   interface{}
+	}{
+		{"timestamp", &data.SystemMetrics},
+		{"timestamp", &data.NetworkTraffic},
+		{"timestamp", &data.DiskUsage},
+		{"last_check", &data.ServiceStatus},
+	}
+
+	rows := 0
+	for _, q := range queries {
+		var count int64
+		query := database.DB.Model(q.dest).Where(q.column+" >= ? AND "+q.column+" <= ?", from, to)
+		if err := query.Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if rows += int(count); rows > maxFixtureRows {
+			return nil, i18n.Errorf("时间范围内的记录超过%d条，请缩小录制范围", maxFixtureRows)
+		}
+		if err := query.Order(q.column + " asc").Find(q.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+	if rows == 0 {
+		return nil, i18n.Errorf("时间范围内没有数据")
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	fixture := &models.AlertFixture{
+		Name:        name,
+		Description: description,
+		From:        from,
+		To:          to,
+		Rows:        rows,
+		Data:        string(encoded),
+	}
+	if err := database.DB.Create(fixture).Error; err != nil {
+		return nil, err
+	}
+	return fixture, nil
+}
+
+// RunFixture 在夹具录制的数据上运行告警规则，按录制结束时刻判定每条规则是否触发，不产生告警；
+// API规则依据内存中的请求统计，不能在夹具上运行
+func RunFixture(fixture *models.AlertFixture, rules []models.AlertRule) ([]RuleResult, error) {
+	var data fixtureData
+	if err := json.Unmarshal([]byte(fixture.Data), &data); err != nil {
+		return nil, i18n.Errorf("夹具数据格式错误: %v", err)
+	}
+
+	db, err := loadFixture(&data)
+	if err != nil {
+		return nil, err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	results := make([]RuleResult, 0, len(rules))
+	for _, rule := range rules {
+		var result RuleResult
+		switch rule.Type {
+		case "no_data":
+			result = evaluateNoData(db, rule, fixture.To)
+		case "comparison":
+			if result, err = evaluateComparison(db, rule, fixture.To); err != nil {
+				return nil, err
+			}
+		default:
+			result = newRuleResult(rule)
+			result.Skipped = i18n.Sprintf("%s规则不能在夹具上运行", rule.Type)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// loadFixture 将夹具数据写入独立的内存数据库，规则的查询与线上使用同一套逻辑
+func loadFixture(data *fixtureData) (*gorm.DB, error) {
+	db, err := database.OpenMemory(&models.SystemMetrics{}, &models.NetworkTraffic{}, &models.DiskUsage{}, &models.ServiceStatus{})
+	if err != nil {
+		return nil, err
+	}
+
+	tx := db.Session(&gorm.Session{SkipHooks: true})
+	for _, rows := range []interface{}{data.SystemMetrics, data.NetworkTraffic, data.DiskUsage, data.ServiceStatus} {
+		if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+			return nil, err
+		}
+	}
+	return db, nil
+}
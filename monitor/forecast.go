@@ -0,0 +1,194 @@
+package monitor
+
+import (
+	"math"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Holt双指数平滑的水平和趋势平滑系数，越大越侧重近期数据
+const (
+	holtAlpha = 0.5
+	holtBeta  = 0.3
+)
+
+// trendPoint 参与拟合的一个数据点
+type trendPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// trend 序列的拟合结果：Current 为最后一个数据点处的拟合值，PerDay 为每天的变化量
+type trend struct {
+	Current float64
+	PerDay  float64
+	At      time.Time // 最后一个数据点的时间
+	Days    int       // 参与拟合的天数
+}
+
+// DiskForecast 一个挂载点的写满预测
+type DiskForecast struct {
+	Path          string     `json:"path"`
+	Name          string     `json:"name"`
+	Total         uint64     `json:"total"`           // 总容量（GB）
+	Usage         float64    `json:"usage"`           // 最新的使用率（%）
+	Trend         float64    `json:"trend"`           // 拟合的当前使用率（%）
+	GrowthPerDay  float64    `json:"growth_per_day"`  // 每天增长的使用率（百分点）
+	GrowthGBDay   float64    `json:"growth_gb_day"`   // 每天增长的容量（GB）
+	DaysUntilFull *float64   `json:"days_until_full"` // 预计还有多少天写满，使用量未增长或数据不足时为null
+	FullAt        *time.Time `json:"full_at"`         // 预计写满的时间
+	Days          int        `json:"days"`            // 参与拟合的天数
+	Method        string     `json:"method"`          // linear 或 holt
+}
+
+// ForecastDisks 按每日汇总预测指定主机（本机为空）各挂载点的写满时间，挂载点取自最近一次采集
+func ForecastDisks(host string) ([]DiskForecast, error) {
+	cfg := config.AppConfig.Forecast
+
+	var latest []models.DiskUsage
+	err := database.DB.Scopes(database.HostScope(host)).
+		Where("timestamp = (?)", database.DB.Model(&models.DiskUsage{}).Scopes(database.HostScope(host)).Select("MAX(timestamp)")).
+		Order("path").Find(&latest).Error
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	forecasts := make([]DiskForecast, 0, len(latest))
+	for _, disk := range latest {
+		forecast := DiskForecast{Path: disk.Path, Name: disk.Name, Total: disk.Total, Usage: disk.Usage, Method: cfg.Method}
+		tags := map[string]string{"path": disk.Path, "name": disk.Name}
+		if disk.ServerID != 0 {
+			tags["server_id"] = strconv.FormatUint(uint64(disk.ServerID), 10)
+		}
+		points, err := dailySeries("disk_usage.usage", formatTags(tags), cfg.WindowDays, false)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := fitTrend(points, cfg.Method, cfg.MinDays); ok {
+			forecast.Trend = t.Current
+			forecast.GrowthPerDay = t.PerDay
+			forecast.GrowthGBDay = t.PerDay * float64(disk.Total) / 100
+			forecast.Days = t.Days
+			if t.PerDay > 0 {
+				fullAt := t.At.Add(time.Duration((100 - t.Current) / t.PerDay * float64(24*time.Hour)))
+				days := math.Max(fullAt.Sub(now).Hours()/24, 0)
+				forecast.FullAt = &fullAt
+				forecast.DaysUntilFull = &days
+			}
+		}
+		forecasts = append(forecasts, forecast)
+	}
+	return forecasts, nil
+}
+
+// CheckDiskForecastAlerts 本机挂载点预计在 forecast.horizon_days 天内写满时触发 disk_forecast:<挂载点> 告警，
+// 不再满足时自动解除
+func CheckDiskForecastAlerts(forecasts []DiskForecast) {
+	horizon := float64(config.AppConfig.Forecast.HorizonDays)
+	current := make(map[string]bool)
+	for _, forecast := range forecasts {
+		if horizon == 0 || forecast.DaysUntilFull == nil || *forecast.DaysUntilFull >= horizon {
+			continue
+		}
+		alertType := "disk_forecast:" + forecast.Path
+		current[alertType] = true
+		raiseAlert(alertType, "warning", "system",
+			i18n.Sprintf("磁盘 %s 预计 %.1f 天后写满（当前 %.1f%%，每天增长 %.2f GB）",
+				forecast.Path, *forecast.DaysUntilFull, forecast.Usage, forecast.GrowthGBDay),
+			*forecast.DaysUntilFull, horizon)
+	}
+
+	var active []models.Alert
+	database.DB.Where("type LIKE ? AND status = ?", "disk_forecast:%", "active").Find(&active)
+	for _, alert := range active {
+		if !current[alert.Type] {
+			resolveAlert(alert.Type, "system", i18n.Sprintf("磁盘 %s 预计不会在 %.0f 天内写满", strings.TrimPrefix(alert.Type, "disk_forecast:"), horizon))
+		}
+	}
+}
+
+// dailySeries 读取指标最近 days 天的每日平均值（peak 为true时为每日峰值），数据点的时间为当天正午
+func dailySeries(metric, tags string, days int, peak bool) ([]trendPoint, error) {
+	location := config.AppConfig.I18n.Location()
+	since := time.Now().In(location).AddDate(0, 0, -days).Format("2006-01-02")
+
+	var summaries []models.DailySummary
+	err := database.DB.Where("metric = ? AND tags = ? AND day > ?", metric, tags, since).
+		Order("day").Find(&summaries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]trendPoint, 0, len(summaries))
+	for _, summary := range summaries {
+		day, err := time.ParseInLocation("2006-01-02", summary.Day, location)
+		if err != nil {
+			continue
+		}
+		value := summary.Avg
+		if peak {
+			value = summary.Max
+		}
+		points = append(points, trendPoint{Time: day.Add(12 * time.Hour), Value: value})
+	}
+	return points, nil
+}
+
+// fitTrend 用最小二乘直线（linear）或Holt双指数平滑（holt）拟合序列，数据点少于 minDays 时ok为false
+func fitTrend(points []trendPoint, method string, minDays int) (trend, bool) {
+	if len(points) < minDays || len(points) < 2 {
+		return trend{}, false
+	}
+	last := points[len(points)-1].Time
+	result := trend{At: last, Days: len(points)}
+	if method == "holt" {
+		result.Current, result.PerDay = fitHolt(points)
+	} else {
+		result.Current, result.PerDay = fitLinear(points)
+	}
+	return result, true
+}
+
+// fitLinear 最小二乘拟合直线，返回最后一个数据点处的拟合值和每天的斜率
+func fitLinear(points []trendPoint) (current, perDay float64) {
+	last := points[len(points)-1].Time
+	var sumX, sumY float64
+	for _, p := range points {
+		sumX += p.Time.Sub(last).Hours() / 24
+		sumY += p.Value
+	}
+	n := float64(len(points))
+	meanX, meanY := sumX/n, sumY/n
+
+	var sxy, sxx float64
+	for _, p := range points {
+		dx := p.Time.Sub(last).Hours()/24 - meanX
+		sxy += dx * (p.Value - meanY)
+		sxx += dx * dx
+	}
+	if sxx == 0 {
+		return meanY, 0
+	}
+	perDay = sxy / sxx
+	// 以最后一个数据点为原点（x=0）时的截距
+	return meanY - perDay*meanX, perDay
+}
+
+// fitHolt Holt双指数平滑，返回最后的水平值和每天的趋势；缺失的日期按间隔天数外推
+func fitHolt(points []trendPoint) (level, perDay float64) {
+	level = points[0].Value
+	perDay = (points[1].Value - points[0].Value) / math.Max(points[1].Time.Sub(points[0].Time).Hours()/24, 1)
+	for i := 1; i < len(points); i++ {
+		step := math.Max(points[i].Time.Sub(points[i-1].Time).Hours()/24, 1)
+		previous := level
+		level = holtAlpha*points[i].Value + (1-holtAlpha)*(previous+perDay*step)
+		perDay = holtBeta*(level-previous)/step + (1-holtBeta)*perDay
+	}
+	return level, perDay
+}
@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"math"
+	"server-monitor/database"
+	"server-monitor/models"
+)
+
+// 服务状态对健康度的贡献系数
+var statusFactor = map[string]float64{
+	"running": 1,
+	"warning": 0.5,
+	"error":   0,
+}
+
+// ServiceHealth 单个服务对主机健康度的影响
+type ServiceHealth struct {
+	ID       uint    `json:"id"`
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Weight   int     `json:"weight"`
+	Critical bool    `json:"critical"`
+	Impact   float64 `json:"impact"` // 该服务导致健康度降低的分数
+}
+
+// HostHealth 按服务权重计算的主机健康度
+type HostHealth struct {
+	Score    float64         `json:"score"`  // 0-100
+	Status   string          `json:"status"` // healthy, degraded, critical
+	Services []ServiceHealth `json:"services"`
+}
+
+// ComputeHealth 按权重汇总服务状态：关键服务异常时直接判定为critical，其余服务异常时为degraded
+func ComputeHealth(services []models.ServiceStatus) HostHealth {
+	totalWeight := 0
+	for _, service := range services {
+		totalWeight += service.Weight
+	}
+
+	health := HostHealth{
+		Score:    100,
+		Status:   "healthy",
+		Services: make([]ServiceHealth, 0, len(services)),
+	}
+	for _, service := range services {
+		factor := statusFactor[service.Status] // 未知状态按异常计算
+
+		impact := 0.0
+		if totalWeight > 0 {
+			impact = 100 * float64(service.Weight) * (1 - factor) / float64(totalWeight)
+		}
+		health.Score -= impact
+
+		switch {
+		case factor == 1:
+		case service.Critical && service.Status != "warning":
+			health.Status = "critical"
+		case service.Weight > 0 && health.Status == "healthy":
+			health.Status = "degraded"
+		}
+
+		health.Services = append(health.Services, ServiceHealth{
+			ID:       service.ID,
+			Name:     service.Name,
+			Status:   service.Status,
+			Weight:   service.Weight,
+			Critical: service.Critical,
+			Impact:   math.Round(impact*100) / 100,
+		})
+	}
+
+	health.Score = math.Round(math.Max(health.Score, 0)*100) / 100
+	return health
+}
+
+// GetHostHealth 按当前服务状态计算主机健康度，host为空时计算本机
+func GetHostHealth(host string) (HostHealth, error) {
+	var services []models.ServiceStatus
+	if err := database.DB.Scopes(database.HostScope(host)).Order("id asc").Find(&services).Error; err != nil {
+		return HostHealth{}, err
+	}
+	return ComputeHealth(services), nil
+}
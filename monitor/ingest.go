@@ -0,0 +1,215 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"server-monitor/i18n"
+	"sort"
+	"strings"
+)
+
+// 外部告警在系统日志中的分类
+const externalAlertCategory = "external"
+
+// ErrUnknownAlertFormat 无法识别的外部告警格式
+var ErrUnknownAlertFormat = i18n.Errorf("无法识别的告警格式，支持 Alertmanager、Grafana、Uptime Kuma 的Webhook")
+
+// 外部告警的severity标签映射到本系统的告警级别，未识别的按warning处理
+var externalLevels = map[string]string{
+	"critical": "error",
+	"error":    "error",
+	"high":     "error",
+	"major":    "error",
+	"page":     "error",
+	"warning":  "warning",
+	"warn":     "warning",
+	"medium":   "warning",
+	"minor":    "warning",
+	"info":     "info",
+	"low":      "info",
+	"none":     "info",
+}
+
+// ExternalAlert 从其他监控工具接收的告警
+type ExternalAlert struct {
+	Source   string  `json:"source"`   // 来源: alertmanager, grafana, uptime-kuma
+	Name     string  `json:"name"`     // 告警名称，如 Alertmanager 的 alertname、Uptime Kuma 的监控项名称
+	Instance string  `json:"instance"` // 告警对象，如 instance 标签
+	Level    string  `json:"level"`
+	Message  string  `json:"message"`
+	Value    float64 `json:"value"`
+	Firing   bool    `json:"firing"` // 触发为true，恢复为false
+}
+
+// Type 外部告警在本系统中的告警类型，同一来源、名称和对象的告警合并为一条，如 alertmanager:HighCPU@web1
+func (a ExternalAlert) Type() string {
+	return a.Source + ":" + a.subject()
+}
+
+// subject 告警名称和对象，如 HighCPU@web1
+func (a ExternalAlert) subject() string {
+	if a.Instance == "" {
+		return a.Name
+	}
+	return a.Name + "@" + a.Instance
+}
+
+// externalPayload 各工具Webhook消息体中用到的字段
+type externalPayload struct {
+	// Alertmanager 和 Grafana 统一告警
+	Alerts []struct {
+		Status      string             `json:"status"`
+		Labels      map[string]string  `json:"labels"`
+		Annotations map[string]string  `json:"annotations"`
+		Values      map[string]float64 `json:"values"`
+	} `json:"alerts"`
+	OrgID *int `json:"orgId"`
+
+	// Grafana 旧版告警
+	RuleName    string `json:"ruleName"`
+	State       string `json:"state"`
+	Title       string `json:"title"`
+	Message     string `json:"message"`
+	EvalMatches []struct {
+		Value  float64 `json:"value"`
+		Metric string  `json:"metric"`
+	} `json:"evalMatches"`
+
+	// Uptime Kuma
+	Heartbeat *struct {
+		Status int    `json:"status"` // 0 DOWN, 1 UP, 2 PENDING, 3 MAINTENANCE
+		Msg    string `json:"msg"`
+	} `json:"heartbeat"`
+	Monitor *struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"monitor"`
+	Msg *string `json:"msg"`
+}
+
+// ParseExternalAlerts 识别Webhook消息体的格式并转换为外部告警；Uptime Kuma 的测试通知等不含告警的消息返回空列表
+func ParseExternalAlerts(body []byte) ([]ExternalAlert, error) {
+	var p externalPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, i18n.Errorf("解析告警消息失败: %v", err)
+	}
+
+	switch {
+	case p.Alerts != nil:
+		source := "alertmanager"
+		if p.OrgID != nil {
+			source = "grafana"
+		}
+		alerts := make([]ExternalAlert, 0, len(p.Alerts))
+		for _, a := range p.Alerts {
+			name := a.Labels["alertname"]
+			if name == "" {
+				continue
+			}
+			alert := ExternalAlert{
+				Source:   source,
+				Name:     name,
+				Instance: a.Labels["instance"],
+				Level:    externalLevel(a.Labels["severity"]),
+				Message:  firstNonEmpty(a.Annotations["summary"], a.Annotations["description"], a.Annotations["message"], name),
+				Value:    firstValue(a.Values),
+				Firing:   a.Status == "firing",
+			}
+			alerts = append(alerts, alert)
+		}
+		return alerts, nil
+
+	case p.RuleName != "":
+		alert := ExternalAlert{
+			Source:  "grafana",
+			Name:    p.RuleName,
+			Level:   "warning",
+			Message: firstNonEmpty(p.Message, p.Title, p.RuleName),
+		}
+		switch p.State {
+		case "alerting":
+			alert.Level, alert.Firing = "error", true
+		case "no_data":
+			alert.Firing = true
+		case "ok":
+		default:
+			// paused、pending 不改变告警状态
+			return []ExternalAlert{}, nil
+		}
+		if len(p.EvalMatches) > 0 {
+			alert.Value = p.EvalMatches[0].Value
+			alert.Instance = p.EvalMatches[0].Metric
+		}
+		return []ExternalAlert{alert}, nil
+
+	case p.Heartbeat != nil || p.Monitor != nil || p.Msg != nil:
+		if p.Heartbeat == nil || p.Monitor == nil {
+			return []ExternalAlert{}, nil
+		}
+		alert := ExternalAlert{
+			Source:   "uptime-kuma",
+			Name:     p.Monitor.Name,
+			Instance: p.Monitor.URL,
+			Level:    "error",
+			Message:  firstNonEmpty(p.Heartbeat.Msg, p.Monitor.Name),
+		}
+		switch p.Heartbeat.Status {
+		case 0:
+			alert.Firing = true
+		case 1:
+		default:
+			// PENDING、MAINTENANCE 不改变告警状态
+			return []ExternalAlert{}, nil
+		}
+		return []ExternalAlert{alert}, nil
+	}
+
+	return nil, ErrUnknownAlertFormat
+}
+
+// IngestExternalAlerts 将外部告警写入告警表：触发时创建或更新活跃告警，恢复时解决对应告警，
+// 与本机告警一样记录时间线并发送通知，返回触发和恢复的数量
+func IngestExternalAlerts(alerts []ExternalAlert) (fired, resolved int) {
+	for _, a := range alerts {
+		label := fmt.Sprintf("[%s] %s", a.Source, a.subject())
+		if a.Firing {
+			raiseAlert(a.Type(), a.Level, externalAlertCategory, label+": "+a.Message, a.Value, 0)
+			fired++
+		} else {
+			resolveAlert(a.Type(), externalAlertCategory, i18n.Sprintf("%s 已恢复: %s", label, a.Message))
+			resolved++
+		}
+	}
+	return fired, resolved
+}
+
+// externalLevel 将severity标签转换为告警级别
+func externalLevel(severity string) string {
+	if level, ok := externalLevels[strings.ToLower(severity)]; ok {
+		return level
+	}
+	return "warning"
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// firstValue Grafana 告警的查询结果按名称排序后的第一个值
+func firstValue(values map[string]float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return values[keys[0]]
+}
@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"errors"
+	"log"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"strings"
+)
+
+// 告警消息中最多列出的完整性问题数
+const maxIntegrityProblems = 3
+
+// CheckDatabaseIntegrity 检查数据库完整性：发现损坏时告警，开启自动恢复时隔离损坏文件并重建数据库，保证采集继续
+func CheckDatabaseIntegrity() error {
+	problems, err := database.CheckIntegrity()
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		resolveAlert("database_corrupt", "system", i18n.Sprintf("数据库完整性检查通过"))
+		return nil
+	}
+
+	listed := problems
+	if len(listed) > maxIntegrityProblems {
+		listed = listed[:maxIntegrityProblems]
+	}
+	message := i18n.Sprintf("数据库完整性检查发现%d个问题: %s", len(problems), strings.Join(listed, "; "))
+	log.Printf("Database integrity check failed: %s", strings.Join(listed, "; "))
+
+	if !config.AppConfig.Database.AutoRecover {
+		raiseAlert("database_corrupt", "error", "system", message, float64(len(problems)), 0)
+		return errors.New(message)
+	}
+
+	quarantined, err := database.Quarantine()
+	if err != nil {
+		raiseAlert("database_corrupt", "error", "system", i18n.Sprintf("%s，自动恢复失败: %v", message, err), float64(len(problems)), 0)
+		return err
+	}
+
+	// 告警写入重建后的数据库，提示隔离文件位置以便人工恢复历史数据
+	raiseAlert("database_corrupt", "error", "system",
+		i18n.Sprintf("%s，已隔离到 %s 并重建数据库", message, quarantined), float64(len(problems)), 0)
+	return nil
+}
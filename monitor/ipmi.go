@@ -0,0 +1,325 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IPMIFault BMC报告故障的风扇或电源
+type IPMIFault struct {
+	Kind   string // fan 或 psu
+	Name   string // 传感器名称
+	Level  string // warning（如转速低于非临界阈值、预测性故障）或 error
+	Detail string // BMC报告的状态，如 lcr、Failure detected、Critical
+}
+
+// ipmitool sdr 状态列中表示越过阈值的取值：c 为临界，nr 为不可恢复，nc 为非临界
+var ipmiThresholdLevels = map[string]string{
+	"cr": "error", "lcr": "error", "ucr": "error",
+	"nr": "error", "lnr": "error", "unr": "error",
+	"nc": "warning", "lnc": "warning", "unc": "warning",
+}
+
+// ipmitool 读数的单位对应的读数类型和单位
+var ipmiUnits = map[string]struct{ typ, unit string }{
+	"RPM":       {"fan", "RPM"},
+	"degrees C": {"temperature", "°C"},
+	"Volts":     {"voltage", "V"},
+	"Watts":     {"power", "W"},
+}
+
+// CollectIPMI 按 ipmi.source 读取BMC传感器，返回来源为 ipmi 的读数和故障的风扇、电源；
+// 电源保存为状态读数，1表示故障
+func CollectIPMI() ([]models.SensorReading, []IPMIFault, error) {
+	cfg := config.AppConfig.IPMI
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Timeout)*time.Second)
+	defer cancel()
+
+	if cfg.Source == "redfish" {
+		return redfishSensors(ctx, cfg)
+	}
+	return ipmitoolSensors(ctx, cfg)
+}
+
+// CheckIPMIAlerts 为每个故障的风扇、电源触发 ipmi_fan:<名称>、ipmi_psu:<名称> 告警，不再故障时自动解除
+func CheckIPMIAlerts(faults []IPMIFault) {
+	cfg := config.AppConfig.IPMI
+	enabled := map[string]bool{"fan": cfg.AlertFans, "psu": cfg.AlertPSU}
+
+	current := make(map[string]bool)
+	for _, fault := range faults {
+		if !enabled[fault.Kind] {
+			continue
+		}
+		alertType := "ipmi_" + fault.Kind + ":" + fault.Name
+		current[alertType] = true
+		message := i18n.Sprintf("风扇故障: %s（%s）", fault.Name, fault.Detail)
+		if fault.Kind == "psu" {
+			message = i18n.Sprintf("电源故障: %s（%s）", fault.Name, fault.Detail)
+		}
+		raiseAlert(alertType, fault.Level, "system", message, 1, 0)
+	}
+
+	// 包括重启前触发的告警
+	var active []models.Alert
+	database.DB.Where("(type LIKE ? OR type LIKE ?) AND status = ?", "ipmi_fan:%", "ipmi_psu:%", "active").Find(&active)
+	for _, alert := range active {
+		if current[alert.Type] {
+			continue
+		}
+		_, name, _ := strings.Cut(alert.Type, ":")
+		resolveAlert(alert.Type, "system", i18n.Sprintf("%s 已恢复正常", name))
+	}
+}
+
+// ipmitoolSensors 解析 ipmitool sdr elist 的输出，每行为 "名称 | 编号 | 状态 | 实体 | 读数"，如
+// "FAN1 | 30h | ok | 29.1 | 4800 RPM"、"PS1 Status | 71h | ok | 10.1 | Presence detected, Failure detected"
+func ipmitoolSensors(ctx context.Context, cfg config.IPMIConfig) ([]models.SensorReading, []IPMIFault, error) {
+	var args []string
+	if cfg.Host != "" {
+		// -E 从环境变量 IPMI_PASSWORD 读取密码，避免出现在进程列表中
+		args = append(args, "-I", cfg.Interface, "-H", cfg.Host, "-U", cfg.Username, "-E")
+	}
+	cmd := exec.CommandContext(ctx, cfg.Command, append(args, "sdr", "elist")...)
+	cmd.Env = append(os.Environ(), "IPMI_PASSWORD="+cfg.Password)
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	names := make(map[string]int)
+	var readings []models.SensorReading
+	var faults []IPMIFault
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) != 5 {
+			continue
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		name, status, entity, value := fields[0], fields[2], fields[3], fields[4]
+
+		number, unit, _ := strings.Cut(value, " ")
+		if kind, ok := ipmiUnits[unit]; ok {
+			reading, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				continue
+			}
+			name = uniqueSensorName(names, name)
+			readings = append(readings, models.SensorReading{Source: "ipmi", Name: name, Type: kind.typ, Value: reading, Unit: kind.unit, Timestamp: now})
+			if level, ok := ipmiThresholdLevels[status]; ok && kind.typ == "fan" {
+				faults = append(faults, IPMIFault{Kind: "fan", Name: name, Level: level, Detail: status})
+			}
+			continue
+		}
+
+		// 电源的实体编号为10，状态传感器的读数为事件描述；未安装的电源和没有读数（No Reading）的传感器跳过
+		if strings.HasPrefix(entity, "10.") && strings.Contains(value, "Presence detected") {
+			name = uniqueSensorName(names, name)
+			level, detail := ipmiPSUFault(value)
+			readings = append(readings, models.SensorReading{Source: "ipmi", Name: name, Type: "status", Value: boolValue(level != ""), Timestamp: now})
+			if level != "" {
+				faults = append(faults, IPMIFault{Kind: "psu", Name: name, Level: level, Detail: detail})
+			}
+		}
+	}
+	if len(readings) == 0 {
+		return nil, nil, i18n.Errorf("ipmitool 没有返回传感器读数")
+	}
+	return readings, faults, nil
+}
+
+// ipmiPSUFault 从电源传感器的事件描述判断故障：故障、断电为error，预测性故障、配置错误为warning
+func ipmiPSUFault(events string) (level, detail string) {
+	for _, event := range strings.Split(events, ",") {
+		event = strings.TrimSpace(event)
+		lower := strings.ToLower(event)
+		switch {
+		case strings.Contains(lower, "failure detected"), strings.Contains(lower, "ac lost"):
+			return "error", event
+		case strings.Contains(lower, "predictive failure"), strings.Contains(lower, "config error"):
+			level, detail = "warning", event
+		}
+	}
+	return level, detail
+}
+
+// redfishStatus Redfish资源的状态，State 为 Absent 表示未安装
+type redfishStatus struct {
+	State  string `json:"State"`
+	Health string `json:"Health"`
+}
+
+// redfishLink 指向另一个Redfish资源
+type redfishLink struct {
+	ID string `json:"@odata.id"`
+}
+
+// redfishSensors 从Redfish各机箱（/redfish/v1/Chassis）的 Thermal 和 Power 资源读取风扇、温度、电压、电源和功耗
+func redfishSensors(ctx context.Context, cfg config.IPMIConfig) ([]models.SensorReading, []IPMIFault, error) {
+	client := &http.Client{}
+	if cfg.Insecure {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		client.Transport = transport
+	}
+	get := func(path string, v interface{}) error {
+		req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(cfg.URL, "/")+path, nil)
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+		req.Header.Set("Accept", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("GET %s: %s", path, resp.Status)
+		}
+		return json.NewDecoder(resp.Body).Decode(v)
+	}
+
+	var collection struct {
+		Members []redfishLink `json:"Members"`
+	}
+	if err := get("/redfish/v1/Chassis", &collection); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	names := make(map[string]int)
+	var readings []models.SensorReading
+	var faults []IPMIFault
+	add := func(name, typ, unit string, value float64) string {
+		name = uniqueSensorName(names, name)
+		readings = append(readings, models.SensorReading{Source: "ipmi", Name: name, Type: typ, Value: value, Unit: unit, Timestamp: now})
+		return name
+	}
+
+	for _, member := range collection.Members {
+		var chassis struct {
+			Thermal *redfishLink `json:"Thermal"`
+			Power   *redfishLink `json:"Power"`
+		}
+		if err := get(member.ID, &chassis); err != nil {
+			return nil, nil, err
+		}
+
+		if chassis.Thermal != nil {
+			var thermal struct {
+				Fans []struct {
+					Name    string        `json:"Name"`
+					FanName string        `json:"FanName"` // 旧版本的名称字段
+					Reading *float64      `json:"Reading"`
+					Units   string        `json:"ReadingUnits"`
+					Status  redfishStatus `json:"Status"`
+				} `json:"Fans"`
+				Temperatures []struct {
+					Name    string        `json:"Name"`
+					Reading *float64      `json:"ReadingCelsius"`
+					Status  redfishStatus `json:"Status"`
+				} `json:"Temperatures"`
+			}
+			if err := get(chassis.Thermal.ID, &thermal); err != nil {
+				return nil, nil, err
+			}
+			for _, fan := range thermal.Fans {
+				if fan.Status.State == "Absent" || fan.Reading == nil {
+					continue
+				}
+				if fan.Name == "" {
+					fan.Name = fan.FanName
+				}
+				unit := "RPM"
+				if fan.Units == "Percent" {
+					unit = "%"
+				}
+				name := add(fan.Name, "fan", unit, *fan.Reading)
+				if level := redfishFaultLevel(fan.Status); level != "" {
+					faults = append(faults, IPMIFault{Kind: "fan", Name: name, Level: level, Detail: fan.Status.Health})
+				}
+			}
+			for _, temperature := range thermal.Temperatures {
+				if temperature.Status.State == "Absent" || temperature.Reading == nil {
+					continue
+				}
+				add(temperature.Name, "temperature", "°C", *temperature.Reading)
+			}
+		}
+
+		if chassis.Power != nil {
+			var power struct {
+				PowerControl []struct {
+					Name     string   `json:"Name"`
+					Consumed *float64 `json:"PowerConsumedWatts"`
+				} `json:"PowerControl"`
+				PowerSupplies []struct {
+					Name   string        `json:"Name"`
+					Status redfishStatus `json:"Status"`
+				} `json:"PowerSupplies"`
+				Voltages []struct {
+					Name    string        `json:"Name"`
+					Reading *float64      `json:"ReadingVolts"`
+					Status  redfishStatus `json:"Status"`
+				} `json:"Voltages"`
+			}
+			if err := get(chassis.Power.ID, &power); err != nil {
+				return nil, nil, err
+			}
+			for _, control := range power.PowerControl {
+				if control.Consumed != nil {
+					add(control.Name, "power", "W", *control.Consumed)
+				}
+			}
+			for _, supply := range power.PowerSupplies {
+				if supply.Status.State == "Absent" {
+					continue
+				}
+				level := redfishFaultLevel(supply.Status)
+				name := add(supply.Name, "status", "", boolValue(level != ""))
+				if level != "" {
+					faults = append(faults, IPMIFault{Kind: "psu", Name: name, Level: level, Detail: supply.Status.Health})
+				}
+			}
+			for _, voltage := range power.Voltages {
+				if voltage.Status.State == "Absent" || voltage.Reading == nil {
+					continue
+				}
+				add(voltage.Name, "voltage", "V", *voltage.Reading)
+			}
+		}
+	}
+	return readings, faults, nil
+}
+
+// redfishFaultLevel Health 为 Critical 时为error，Warning 时为warning，正常时为空
+func redfishFaultLevel(status redfishStatus) string {
+	switch status.Health {
+	case "Critical":
+		return "error"
+	case "Warning":
+		return "warning"
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 单条journal消息写入系统日志的最大字节数，超出部分截断
+const maxJournalMessage = 4096
+
+// 系统日志中journal记录的分类
+const journalCategory = "journal"
+
+var (
+	journalMu sync.Mutex
+	// 上次读到的journal位置；重启后为空，从系统日志中最后一条journal记录的时间继续
+	journalCursor string
+)
+
+// CollectJournal 读取systemd journal中新增的记录（journal.priority 及更严重的级别），按 journal.units 过滤后
+// 写入系统日志，分类为 journal；每次最多读取 journal.max_entries 条，剩余的下次继续。返回写入的条数
+func CollectJournal() (int, error) {
+	cfg := config.AppConfig.Journal
+	if !cfg.Enabled {
+		return 0, nil
+	}
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	args := []string{"--output=json", "--no-pager", "--priority=" + cfg.Priority}
+	var since time.Time
+	if journalCursor != "" {
+		args = append(args, "--after-cursor="+journalCursor)
+	} else {
+		since = journalStart(cfg)
+		args = append(args, fmt.Sprintf("--since=@%d", since.Unix()))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, cfg.Command, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	var logs []models.SystemLog
+	cursor, read := journalCursor, 0
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for read < cfg.MaxEntries && scanner.Scan() {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &fields); err != nil {
+			continue
+		}
+		read++
+		cursor = journalField(fields, "__CURSOR")
+
+		micros, err := strconv.ParseInt(journalField(fields, "__REALTIME_TIMESTAMP"), 10, 64)
+		if err != nil {
+			continue
+		}
+		timestamp := time.UnixMicro(micros)
+		// --since 只精确到秒，跳过上次已经写入的记录
+		if !timestamp.After(since) || !journalMatches(cfg, journalField(fields, "_SYSTEMD_UNIT"), journalField(fields, "_TRANSPORT")) {
+			continue
+		}
+
+		logs = append(logs, models.SystemLog{
+			Level:     journalLevel(journalField(fields, "PRIORITY")),
+			Category:  journalCategory,
+			Message:   journalMessage(fields),
+			Timestamp: timestamp,
+		})
+	}
+	complete := read < cfg.MaxEntries
+
+	// 读够条数后不再等待剩余的输出
+	if !complete {
+		cmd.Process.Kill()
+	}
+	if err := cmd.Wait(); err != nil && complete {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return 0, fmt.Errorf("%v: %s", err, message)
+		}
+		return 0, err
+	}
+
+	if len(logs) > 0 {
+		if err := database.DB.CreateInBatches(logs, 100).Error; err != nil {
+			return 0, err
+		}
+	}
+	journalCursor = cursor
+	return len(logs), nil
+}
+
+// journalStart 没有读取位置时的起始时间：系统日志中最后一条journal记录的时间，没有时为一个采集间隔之前
+func journalStart(cfg config.JournalConfig) time.Time {
+	var last []models.SystemLog
+	database.DB.Scopes(database.HostScope("")).Where("category = ?", journalCategory).
+		Order("timestamp desc").Limit(1).Find(&last)
+	if len(last) > 0 {
+		return last[0].Timestamp
+	}
+	return time.Now().Add(-time.Duration(cfg.Interval) * time.Second)
+}
+
+// journalMatches 记录是否属于 journal.units 中的单元，未配置单元时全部采集；journal.kernel 开启时内核消息总是采集
+func journalMatches(cfg config.JournalConfig, unit, transport string) bool {
+	if len(cfg.Units) == 0 || (cfg.Kernel && transport == "kernel") {
+		return true
+	}
+	for _, u := range cfg.Units {
+		if u == unit || u+".service" == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// journalLevel journal优先级对应的日志级别：0-3（emerg到err）为error，4为warning，其余为info
+func journalLevel(priority string) string {
+	p, err := strconv.Atoi(priority)
+	switch {
+	case err != nil:
+		return "info"
+	case p <= 3:
+		return "error"
+	case p == 4:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// journalMessage 以 来源: 消息 的形式记录，来源依次取 SYSLOG_IDENTIFIER、_SYSTEMD_UNIT、_COMM
+func journalMessage(fields map[string]json.RawMessage) string {
+	message := journalField(fields, "MESSAGE")
+	for _, key := range []string{"SYSLOG_IDENTIFIER", "_SYSTEMD_UNIT", "_COMM"} {
+		if source := journalField(fields, key); source != "" {
+			message = source + ": " + message
+			break
+		}
+	}
+	if len(message) > maxJournalMessage {
+		message = strings.ToValidUTF8(message[:maxJournalMessage], "")
+	}
+	return message
+}
+
+// journalField 读取journal字段：通常为字符串，不是有效UTF-8时为字节数组，同一字段出现多次时为数组（取第一个）
+func journalField(fields map[string]json.RawMessage, key string) string {
+	raw, ok := fields[key]
+	if !ok {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var numbers []int
+	if json.Unmarshal(raw, &numbers) == nil {
+		data := make([]byte, len(numbers))
+		for i, n := range numbers {
+			data[i] = byte(n)
+		}
+		return strings.ToValidUTF8(string(data), "�")
+	}
+	var values []json.RawMessage
+	if json.Unmarshal(raw, &values) == nil && len(values) > 0 {
+		return journalField(map[string]json.RawMessage{key: values[0]}, key)
+	}
+	return ""
+}
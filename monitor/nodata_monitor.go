@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// noDataSource 无数据检测的数据源：期望的采集间隔及最近一次上报时间
+type noDataSource struct {
+	interval func() time.Duration
+	latest   func(db *gorm.DB) (time.Time, error)
+}
+
+var noDataSources = map[string]noDataSource{
+	"system_metrics": {
+		interval: func() time.Duration { return time.Duration(config.AppConfig.Monitor.Interval) * time.Second },
+		latest: func(db *gorm.DB) (time.Time, error) {
+			var m models.SystemMetrics
+			err := db.Scopes(database.HostScope("")).Order("timestamp desc").First(&m).Error
+			return m.Timestamp, err
+		},
+	},
+	"network_traffic": {
+		interval: func() time.Duration { return time.Duration(config.AppConfig.Monitor.NetworkInterval) * time.Second },
+		latest: func(db *gorm.DB) (time.Time, error) {
+			var t models.NetworkTraffic
+			err := db.Scopes(database.HostScope("")).Order("timestamp desc").First(&t).Error
+			return t.Timestamp, err
+		},
+	},
+	"disk_usage": {
+		interval: func() time.Duration { return time.Duration(config.AppConfig.Monitor.DiskInterval) * time.Second },
+		latest: func(db *gorm.DB) (time.Time, error) {
+			var d models.DiskUsage
+			err := db.Scopes(database.HostScope("")).Order("timestamp desc").First(&d).Error
+			return d.Timestamp, err
+		},
+	},
+	"service_status": {
+		interval: func() time.Duration { return time.Duration(config.AppConfig.Monitor.ServiceInterval) * time.Second },
+		latest: func(db *gorm.DB) (time.Time, error) {
+			var s models.ServiceStatus
+			err := db.Scopes(database.HostScope("")).Order("last_check desc").First(&s).Error
+			return s.LastCheck, err
+		},
+	},
+}
+
+// IsNoDataMetric 判断数据源是否支持无数据检测
+func IsNoDataMetric(metric string) bool {
+	_, ok := noDataSources[metric]
+	return ok
+}
+
+type NoDataMonitor struct {
+	startedAt time.Time
+}
+
+// NewNoDataMonitor 创建无数据告警监控实例
+func NewNoDataMonitor() *NoDataMonitor {
+	return &NoDataMonitor{
+		startedAt: time.Now(),
+	}
+}
+
+// CheckRules 检查所有启用的无数据规则，数据源在N个采集周期内未上报时触发告警
+func (nm *NoDataMonitor) CheckRules() error {
+	var rules []models.AlertRule
+	if err := database.DB.Where("type = ? AND enabled = ?", "no_data", true).Find(&rules).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		source, ok := noDataSources[rule.Metric]
+		if !ok {
+			continue
+		}
+
+		window := time.Duration(rule.Intervals) * source.interval()
+		// 刚启动时还没有完整的采集周期，不判定为无数据
+		if now.Sub(nm.startedAt) < window {
+			continue
+		}
+
+		result := evaluateNoData(database.DB, rule, now)
+		alertType := "no_data_" + rule.Metric
+		if result.Fired {
+			raiseAlert(alertType, rule.Level, "system", result.Message, result.Value, result.Threshold)
+		} else {
+			resolveAlert(alertType, "system", result.Message)
+		}
+	}
+
+	return nil
+}
+
+// evaluateNoData 按db中的数据判定无数据规则在now时刻是否触发
+func evaluateNoData(db *gorm.DB, rule models.AlertRule, now time.Time) RuleResult {
+	result := newRuleResult(rule)
+	source, ok := noDataSources[rule.Metric]
+	if !ok {
+		result.Skipped = i18n.Sprintf("不支持的数据源: %s", rule.Metric)
+		return result
+	}
+
+	window := time.Duration(rule.Intervals) * source.interval()
+	result.Threshold = window.Seconds()
+	latest, err := source.latest(db)
+	if err != nil || now.Sub(latest) > window {
+		silence := window
+		if err == nil {
+			silence = now.Sub(latest)
+		}
+		result.Fired, result.Value = true, silence.Seconds()
+		result.Message = i18n.Sprintf("%s: 已超过 %v 未收到数据", rule.Name, silence.Round(time.Second))
+	} else {
+		result.Value = now.Sub(latest).Seconds()
+		result.Message = i18n.Sprintf("%s: 数据已恢复上报", rule.Name)
+	}
+	return result
+}
+
+// hostOfflineType 远程主机离线告警的类型，如 host_offline:web1
+func hostOfflineType(name string) string {
+	return "host_offline:" + name
+}
+
+// CheckServers 检查已登记的远程主机，超过 ingest.offline_after 秒未推送数据时触发主机离线告警，恢复推送后自动解除
+func (nm *NoDataMonitor) CheckServers() error {
+	window := time.Duration(config.AppConfig.Ingest.OfflineAfter) * time.Second
+	if window <= 0 {
+		return nil
+	}
+	// 监控程序停机期间的推送都已丢失，启动后等待一个完整的窗口再判定
+	now := time.Now()
+	if now.Sub(nm.startedAt) < window {
+		return nil
+	}
+
+	var servers []models.Server
+	if err := database.DB.Find(&servers).Error; err != nil {
+		return err
+	}
+	for _, server := range servers {
+		silence := now.Sub(server.LastSeen)
+		if silence > window {
+			message := i18n.Sprintf("主机 %s 已超过 %v 未推送数据", server.Name, silence.Round(time.Second))
+			raiseServerAlert(server.ID, hostOfflineType(server.Name), "error", "network", message, silence.Seconds(), window.Seconds())
+		} else {
+			resolveServerAlert(server.ID, hostOfflineType(server.Name), "network", i18n.Sprintf("主机 %s 已恢复推送数据", server.Name))
+		}
+	}
+	return nil
+}
+
+// DeleteServer 注销远程主机并解除其离线告警，用于主机下线后停止离线检测；
+// 已推送的数据保留到按保留时间清理，主机再次推送时重新登记
+func DeleteServer(id uint) (*models.Server, error) {
+	var server models.Server
+	if err := database.DB.First(&server, id).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Delete(&server).Error; err != nil {
+		return nil, err
+	}
+	resolveServerAlert(server.ID, hostOfflineType(server.Name), "network", i18n.Sprintf("主机 %s 已注销", server.Name))
+	return &server, nil
+}
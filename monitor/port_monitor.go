@@ -0,0 +1,217 @@
+package monitor
+
+import (
+	"fmt"
+	"net"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+type PortMonitor struct {
+	known    map[string]models.PortChange // 当前在监听的端口，首次检查时从数据库加载
+	baseline bool                         // 没有任何历史记录，本次检查只建立基线
+}
+
+// NewPortMonitor 创建监听端口变化检测实例
+func NewPortMonitor() *PortMonitor {
+	return &PortMonitor{}
+}
+
+// portKey 端口在清单中的键，如 tcp/0.0.0.0:22；同一端口在不同地址上监听时分别记录
+func portKey(protocol, address string, port uint32) string {
+	return protocol + "/" + net.JoinHostPort(address, fmt.Sprint(port))
+}
+
+// portMatches 端口是否与 ports.expected、ports.ignore 中的某一项匹配
+func portMatches(specs []string, protocol string, port uint32) bool {
+	for _, spec := range specs {
+		p, n, ok := config.ParsePortSpec(spec)
+		if ok && n == port && (p == "" || strings.HasPrefix(protocol, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectPorts 获取当前在监听的端口，按配置排除UDP、只监听回环地址的端口和 ports.ignore 中的端口
+func (pm *PortMonitor) CollectPorts() (map[string]models.PortChange, error) {
+	cfg := config.AppConfig.Ports
+	filter := ConnectionFilter{Listening: true}
+	if !cfg.UDP {
+		filter.Protocol = "tcp"
+	}
+	connections, err := GetConnections(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]models.PortChange)
+	for _, conn := range connections {
+		if ip := net.ParseIP(conn.LocalAddr); ip != nil && ip.IsLoopback() && !cfg.IncludeLoopback {
+			continue
+		}
+		if portMatches(cfg.Ignore, conn.Protocol, conn.LocalPort) {
+			continue
+		}
+		current[portKey(conn.Protocol, conn.LocalAddr, conn.LocalPort)] = models.PortChange{
+			Protocol: conn.Protocol,
+			Address:  conn.LocalAddr,
+			Port:     conn.LocalPort,
+			Process:  conn.Process,
+		}
+	}
+	return current, nil
+}
+
+// DetectChanges 与上次记录的端口比较，返回开始监听和停止监听的端口
+func (pm *PortMonitor) DetectChanges(current map[string]models.PortChange) ([]models.PortChange, error) {
+	if pm.known == nil {
+		var count int64
+		if err := database.DB.Model(&models.PortChange{}).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		known, err := GetListeningPorts()
+		if err != nil {
+			return nil, err
+		}
+		pm.known = make(map[string]models.PortChange, len(known))
+		for _, p := range known {
+			pm.known[portKey(p.Protocol, p.Address, p.Port)] = p
+		}
+		pm.baseline = count == 0
+	}
+
+	now := time.Now()
+	var changes []models.PortChange
+	for key, p := range current {
+		if _, ok := pm.known[key]; !ok {
+			p.Event, p.Timestamp = "opened", now
+			changes = append(changes, p)
+		}
+	}
+	for key, p := range pm.known {
+		if _, ok := current[key]; !ok {
+			closed := models.PortChange{
+				Protocol:  p.Protocol,
+				Address:   p.Address,
+				Port:      p.Port,
+				Process:   p.Process,
+				Event:     "closed",
+				Timestamp: now,
+			}
+			changes = append(changes, closed)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Port != changes[j].Port {
+			return changes[i].Port < changes[j].Port
+		}
+		return changes[i].Protocol < changes[j].Protocol
+	})
+	return changes, nil
+}
+
+// SaveChanges 保存端口变化记录并写入系统日志；首次检查建立基线时不写系统日志
+func (pm *PortMonitor) SaveChanges(changes []models.PortChange) error {
+	for _, change := range changes {
+		if err := database.DB.Create(&change).Error; err != nil {
+			return err
+		}
+		key := portKey(change.Protocol, change.Address, change.Port)
+		if change.Event == "opened" {
+			pm.known[key] = change
+		} else {
+			delete(pm.known, key)
+		}
+
+		if pm.baseline {
+			continue
+		}
+		message := i18n.Sprintf("端口 %s 开始监听%s", key, describeProcess(change.Process))
+		if change.Event == "closed" {
+			message = i18n.Sprintf("端口 %s 停止监听%s", key, describeProcess(change.Process))
+		}
+		systemLog := models.SystemLog{
+			Level:     "info",
+			Category:  "security",
+			Message:   message,
+			Timestamp: change.Timestamp,
+		}
+		database.DB.Create(&systemLog)
+	}
+	return nil
+}
+
+// CheckAlerts 新端口开始监听时告警（需手动解除），ports.expected 中的端口停止监听时告警、恢复后自动解除
+func (pm *PortMonitor) CheckAlerts(current map[string]models.PortChange, changes []models.PortChange) {
+	cfg := config.AppConfig.Ports
+	if pm.baseline {
+		// 基线只建立一次，之后的检查正常告警
+		pm.baseline = false
+	} else if cfg.AlertNew {
+		for _, change := range changes {
+			if change.Event != "opened" || portMatches(cfg.Expected, change.Protocol, change.Port) {
+				continue
+			}
+			// IPv4和IPv6同时监听同一端口时只触发一条告警
+			protocol := strings.TrimSuffix(change.Protocol, "6")
+			raiseAlert(fmt.Sprintf("port_opened:%s/%d", protocol, change.Port), "warning", "security",
+				i18n.Sprintf("新端口开始监听: %s%s", portKey(change.Protocol, change.Address, change.Port), describeProcess(change.Process)),
+				float64(change.Port), 0)
+		}
+	}
+
+	if len(cfg.Expected) == 0 {
+		return
+	}
+	var missing []string
+	for _, spec := range cfg.Expected {
+		protocol, port, ok := config.ParsePortSpec(spec)
+		if !ok {
+			continue
+		}
+		found := false
+		for _, p := range current {
+			if p.Port == port && (protocol == "" || strings.HasPrefix(p.Protocol, protocol)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, spec)
+		}
+	}
+
+	if len(missing) > 0 {
+		raiseAlert("port_missing", "error", "security",
+			i18n.Sprintf("应监听的端口已停止监听: %s", strings.Join(missing, ", ")),
+			float64(len(missing)), 0)
+	} else {
+		resolveAlert("port_missing", "security", i18n.Sprintf("所有应监听的端口均已恢复"))
+	}
+}
+
+// GetListeningPorts 从变化记录中获取当前在监听的端口（每个端口最近一次记录为开始监听），按端口排序
+func GetListeningPorts() ([]models.PortChange, error) {
+	var latest []models.PortChange
+	err := database.DB.Where("id IN (?)",
+		database.DB.Model(&models.PortChange{}).Select("MAX(id)").Group("protocol, address, port")).
+		Where("event = ?", "opened").
+		Order("port, protocol, address").
+		Find(&latest).Error
+	return latest, err
+}
+
+// describeProcess 端口所属进程的说明，如 （nginx），未知时为空
+func describeProcess(process string) string {
+	if process == "" {
+		return ""
+	}
+	return i18n.Sprintf("（%s）", process)
+}
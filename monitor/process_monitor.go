@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"fmt"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// CheckProcessWatches 检查所有进程监视，匹配的进程数少于要求时告警，恢复后自动解除
+func CheckProcessWatches() error {
+	var watches []models.ProcessWatch
+	if err := database.DB.Find(&watches).Error; err != nil {
+		return err
+	}
+	if len(watches) == 0 {
+		return nil
+	}
+
+	procs, err := process.Processes()
+	if err != nil {
+		return err
+	}
+
+	// 进程名和命令行只读取一次
+	type processInfo struct {
+		name    string
+		cmdline string
+	}
+	infos := make([]processInfo, 0, len(procs))
+	for _, p := range procs {
+		name, err := p.Name()
+		if err != nil {
+			continue // 进程已退出
+		}
+		cmdline, _ := p.Cmdline()
+		infos = append(infos, processInfo{name: name, cmdline: cmdline})
+	}
+
+	for _, watch := range watches {
+		count := 0
+		for _, info := range infos {
+			if strings.Contains(info.name, watch.Pattern) || strings.Contains(info.cmdline, watch.Pattern) {
+				count++
+			}
+		}
+
+		alertType := fmt.Sprintf("process_%d", watch.ID)
+		if count < watch.MinCount {
+			raiseAlert(alertType, watch.Level, "process",
+				i18n.Sprintf("进程 %s 未运行: 匹配 %q 的进程有 %d 个，至少需要 %d 个", watch.Name, watch.Pattern, count, watch.MinCount),
+				float64(count), float64(watch.MinCount))
+		} else {
+			resolveAlert(alertType, "process", i18n.Sprintf("进程 %s 已恢复运行: 匹配的进程有 %d 个", watch.Name, count))
+		}
+	}
+
+	return nil
+}
+
+// ValidateProcessWatch 校验进程监视
+func ValidateProcessWatch(watch *models.ProcessWatch) error {
+	if watch.Name == "" {
+		return i18n.Errorf("进程监视名称不能为空")
+	}
+	if watch.Pattern == "" {
+		return i18n.Errorf("进程监视 %s 缺少匹配的进程名（pattern）", watch.Name)
+	}
+	if watch.MinCount == 0 {
+		watch.MinCount = 1
+	}
+	if watch.MinCount < 0 {
+		return i18n.Errorf("进程监视 %s 的进程数不能为负数", watch.Name)
+	}
+
+	switch watch.Level {
+	case "":
+		watch.Level = "error"
+	case "info", "warning", "error":
+	default:
+		return i18n.Errorf("不支持的告警级别: %s", watch.Level)
+	}
+	return nil
+}
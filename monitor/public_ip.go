@@ -0,0 +1,244 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PublicInterface6 公网IPv6在地址变化记录中使用的网卡名称
+const PublicInterface6 = "public6"
+
+// isPublicInterface 判断地址记录是否为探测到的公网IP而不是本机网卡
+func isPublicInterface(name string) bool {
+	return name == PublicInterface || name == PublicInterface6
+}
+
+// publicIPClient 只通过指定协议族（tcp4或tcp6）连接的HTTP客户端，探测服务返回的就是该协议族的公网地址
+func publicIPClient(network string) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+// publicIPv4URLs IPv4探测地址，未单独配置时使用信誉检查的探测地址
+func publicIPv4URLs() []string {
+	if urls := config.AppConfig.Addresses.PublicIPv4URLs; len(urls) > 0 {
+		return urls
+	}
+	return []string{config.AppConfig.Reputation.PublicIPURL}
+}
+
+// detectPublicIP 依次请求探测服务，返回第一个有效的指定协议族地址；所有服务都失败时返回最后一个错误
+func detectPublicIP(network string, urls []string) (string, error) {
+	client := publicIPClient(network)
+	family := "IPv4"
+	if network == "tcp6" {
+		family = "IPv6"
+	}
+
+	var lastErr error
+	for _, url := range urls {
+		ip, err := fetchPublicIP(client, url)
+		if err == nil {
+			parsed := net.ParseIP(ip)
+			if (parsed.To4() != nil) == (network == "tcp4") {
+				return parsed.String(), nil
+			}
+			err = i18n.Errorf("%s 返回的地址 %s 不是%s地址", url, ip, family)
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = i18n.Errorf("未配置探测地址")
+	}
+	return "", lastErr
+}
+
+// fetchPublicIP 请求探测服务，响应内容应只包含IP地址
+func fetchPublicIP(client *http.Client, url string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", i18n.Errorf("%s HTTP状态码错误: %d", url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", err
+	}
+
+	ip := strings.TrimSpace(string(body))
+	if net.ParseIP(ip) == nil {
+		return "", i18n.Errorf("%s 返回了无效的公网IP: %q", url, ip)
+	}
+	return ip, nil
+}
+
+// LocatePublicIPs 查询尚未缓存的公网IP的地理位置，每个IP只查询一次，避免触发地理位置服务的频率限制
+func LocatePublicIPs(current map[string]string) {
+	if config.AppConfig.Addresses.GeoURL == "" {
+		return
+	}
+	for _, iface := range []string{PublicInterface, PublicInterface6} {
+		ip := current[iface]
+		if ip == "" {
+			continue
+		}
+		var count int64
+		database.DB.Model(&models.IPGeolocation{}).Where("ip = ?", ip).Count(&count)
+		if count > 0 {
+			continue
+		}
+
+		location, err := lookupGeolocation(ip)
+		if err != nil {
+			log.Printf("Error looking up geolocation of %s: %v", ip, err)
+			continue
+		}
+		if err := database.DB.Create(location).Error; err != nil {
+			log.Printf("Error saving geolocation of %s: %v", ip, err)
+		}
+	}
+}
+
+// lookupGeolocation 请求地理位置服务，兼容ipinfo.io、ip-api.com、ipapi.co等常见服务的字段名
+func lookupGeolocation(ip string) (*models.IPGeolocation, error) {
+	url := strings.ReplaceAll(config.AppConfig.Addresses.GeoURL, "{ip}", ip)
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, i18n.Errorf("HTTP状态码错误: %d", resp.StatusCode)
+	}
+	var fields map[string]interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&fields); err != nil {
+		return nil, i18n.Errorf("响应格式错误: %v", err)
+	}
+
+	location := &models.IPGeolocation{
+		IP:      ip,
+		Country: firstString(fields, "country_name", "country"),
+		Region:  firstString(fields, "regionName", "region"),
+		City:    firstString(fields, "city"),
+		Org:     firstString(fields, "org", "isp", "as"),
+	}
+	location.Latitude = firstNumber(fields, "lat", "latitude")
+	location.Longitude = firstNumber(fields, "lon", "longitude")
+	// ipinfo.io 以 "纬度,经度" 字符串返回坐标
+	if loc := firstString(fields, "loc"); loc != "" {
+		if parts := strings.SplitN(loc, ",", 2); len(parts) == 2 {
+			location.Latitude, _ = strconv.ParseFloat(parts[0], 64)
+			location.Longitude, _ = strconv.ParseFloat(parts[1], 64)
+		}
+	}
+	return location, nil
+}
+
+// firstString 返回第一个存在的非空字符串字段
+func firstString(fields map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := fields[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// firstNumber 返回第一个存在的数值字段
+func firstNumber(fields map[string]interface{}, keys ...string) float64 {
+	for _, key := range keys {
+		if value, ok := fields[key].(float64); ok {
+			return value
+		}
+	}
+	return 0
+}
+
+// describeLocation 地理位置的简短描述，如 "中国 广东 深圳 China Telecom"
+func describeLocation(ip string) string {
+	var location models.IPGeolocation
+	if database.DB.Where("ip = ?", ip).First(&location).Error != nil {
+		return ""
+	}
+	var parts []string
+	for _, part := range []string{location.Country, location.Region, location.City, location.Org} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// PublicAddress 公网地址及其获得时间和地理位置
+type PublicAddress struct {
+	IP       string                `json:"ip"`
+	Since    time.Time             `json:"since"` // 最近一次变化的时间
+	Location *models.IPGeolocation `json:"location"`
+}
+
+// HostInfo 主机标识：主机名、公网IPv4/IPv6和各网卡地址
+type HostInfo struct {
+	Hostname   string            `json:"hostname"`
+	PublicIPv4 *PublicAddress    `json:"public_ipv4"`
+	PublicIPv6 *PublicAddress    `json:"public_ipv6"`
+	Interfaces map[string]string `json:"interfaces"` // 网卡 -> 地址，逗号分隔
+}
+
+// GetHostInfo 从地址变化记录中获取主机当前的公网地址和网卡地址，未开启公网IP跟踪时公网地址为空
+func GetHostInfo() (*HostInfo, error) {
+	var latest []models.IPAddressChange
+	err := database.DB.Where("id IN (?)",
+		database.DB.Model(&models.IPAddressChange{}).Select("MAX(id)").Group("interface")).
+		Find(&latest).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	info := &HostInfo{Hostname: hostname, Interfaces: make(map[string]string)}
+	for _, change := range latest {
+		if !isPublicInterface(change.Interface) {
+			if change.NewAddresses != "" {
+				info.Interfaces[change.Interface] = change.NewAddresses
+			}
+			continue
+		}
+		if change.NewAddresses == "" {
+			continue
+		}
+
+		address := &PublicAddress{IP: change.NewAddresses, Since: change.Timestamp}
+		var location models.IPGeolocation
+		if database.DB.Where("ip = ?", change.NewAddresses).First(&location).Error == nil {
+			address.Location = &location
+		}
+		if change.Interface == PublicInterface {
+			info.PublicIPv4 = address
+		} else {
+			info.PublicIPv6 = address
+		}
+	}
+	return info, nil
+}
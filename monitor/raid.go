@@ -0,0 +1,166 @@
+package monitor
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"regexp"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 内核通过该文件提供mdadm软RAID阵列的状态
+var mdstatFile = "/proc/mdstat"
+
+var (
+	// 如 "[2/1] [_U]"：应有成员数/活动成员数
+	mdstatDisks = regexp.MustCompile(`\[(\d+)/(\d+)\]\s+\[[U_]+\]`)
+	// 如 "recovery =  8.5% (83063808/976630464) finish=80.2min"，等待中的为 "resync=DELAYED"、"resync=PENDING"
+	mdstatAction = regexp.MustCompile(`(recovery|resync|reshape|check|repair)\s*=\s*(?:([\d.]+)%|\w+)`)
+	mdstatFinish = regexp.MustCompile(`finish=(\S+)`)
+)
+
+// CheckRAID 解析 /proc/mdstat 并保存各阵列的状态；没有 /proc/mdstat（未加载md模块）或没有阵列时返回空
+func CheckRAID() ([]models.RAIDStatus, error) {
+	file, err := os.Open(mdstatFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	arrays, err := parseMdstat(bufio.NewScanner(file))
+	if err != nil || len(arrays) == 0 {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range arrays {
+		arrays[i].Timestamp = now
+	}
+	if err := database.DB.Create(&arrays).Error; err != nil {
+		return nil, err
+	}
+	return arrays, nil
+}
+
+// CheckRAIDAlerts 阵列降级、有故障成员或未激活时触发 raid_degraded:<阵列> 告警（error），
+// 重建进度随每次检查更新到告警消息中，恢复后自动解除
+func CheckRAIDAlerts(arrays []models.RAIDStatus) {
+	current := make(map[string]bool)
+	if config.AppConfig.RAID.AlertDegraded {
+		for _, array := range arrays {
+			alertType := "raid_degraded:" + array.Device
+			var message string
+			switch {
+			case strings.HasPrefix(array.State, "inactive"):
+				message = i18n.Sprintf("RAID阵列 %s 未激活", array.Device)
+			case array.Degraded:
+				message = i18n.Sprintf("RAID阵列 %s 已降级: %d/%d 个成员正常", array.Device, array.ActiveDisks, array.Disks)
+				if array.FailedDisks > 0 {
+					message += i18n.Sprintf("，%d 个成员故障", array.FailedDisks)
+				}
+				if array.Action == "recovery" {
+					message += i18n.Sprintf("，正在重建 %.1f%%（预计剩余 %s）", array.Progress, array.Finish)
+				}
+			default:
+				continue
+			}
+			current[alertType] = true
+			raiseAlert(alertType, "error", "system", message, float64(array.ActiveDisks), float64(array.Disks))
+		}
+	}
+
+	// 包括重启前触发的告警和已被移除的阵列
+	var active []models.Alert
+	database.DB.Where("type LIKE ? AND status = ?", "raid_degraded:%", "active").Find(&active)
+	for _, alert := range active {
+		if !current[alert.Type] {
+			resolveAlert(alert.Type, "system", i18n.Sprintf("RAID阵列 %s 已恢复正常", strings.TrimPrefix(alert.Type, "raid_degraded:")))
+		}
+	}
+}
+
+// GetLatestRAIDStatuses 最近一次检查时各阵列的状态，按设备名排序
+func GetLatestRAIDStatuses() ([]models.RAIDStatus, error) {
+	var arrays []models.RAIDStatus
+	err := database.DB.Where("timestamp = (?)", database.DB.Model(&models.RAIDStatus{}).Select("MAX(timestamp)")).
+		Order("device").Find(&arrays).Error
+	return arrays, err
+}
+
+// parseMdstat 解析 /proc/mdstat，每个阵列以 "md0 : active raid1 sdb1[1] sda1[0](F)" 开头，
+// 之后缩进的行包含成员数、状态位图和正在进行的操作，空行结束
+func parseMdstat(scanner *bufio.Scanner) ([]models.RAIDStatus, error) {
+	var arrays []models.RAIDStatus
+	var array *models.RAIDStatus
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			array = nil
+			continue
+		}
+
+		if device, rest, ok := strings.Cut(line, " : "); ok && strings.HasPrefix(device, "md") {
+			arrays = append(arrays, parseMdstatArray(device, rest))
+			array = &arrays[len(arrays)-1]
+			continue
+		}
+		if array == nil {
+			continue
+		}
+
+		if m := mdstatDisks.FindStringSubmatch(line); m != nil {
+			array.Disks, _ = strconv.Atoi(m[1])
+			array.ActiveDisks, _ = strconv.Atoi(m[2])
+			array.Degraded = array.Degraded || array.ActiveDisks < array.Disks
+		}
+		if m := mdstatAction.FindStringSubmatch(line); m != nil {
+			array.Action = m[1]
+			array.Progress, _ = strconv.ParseFloat(m[2], 64)
+			if m := mdstatFinish.FindStringSubmatch(line); m != nil {
+				array.Finish = m[1]
+			}
+		}
+	}
+	return arrays, scanner.Err()
+}
+
+// parseMdstatArray 解析阵列首行冒号后的部分：状态、可选的只读标记、RAID级别和成员
+func parseMdstatArray(device, rest string) models.RAIDStatus {
+	array := models.RAIDStatus{Device: device}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return array
+	}
+	array.State = fields[0]
+	fields = fields[1:]
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "(") {
+		array.State += " " + fields[0]
+		fields = fields[1:]
+	}
+	// 未激活的阵列没有级别，直接列出成员
+	if len(fields) > 0 && !strings.Contains(fields[0], "[") {
+		array.Level = fields[0]
+		fields = fields[1:]
+	}
+
+	for _, member := range fields {
+		switch {
+		case strings.HasSuffix(member, "(F)"):
+			array.FailedDisks++
+		case strings.HasSuffix(member, "(S)"):
+			array.SpareDisks++
+		}
+	}
+	array.Members = strings.Join(fields, ",")
+	array.Degraded = array.FailedDisks > 0
+	return array
+}
@@ -0,0 +1,190 @@
+package monitor
+
+import (
+	"regexp"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 单次推送最多包含的磁盘和网卡记录数
+const maxRemoteRows = 1000
+
+// 推送数据的时间戳最多允许超前本机时间多久，超出视为远程主机时钟错误
+const maxRemoteClockSkew = 5 * time.Minute
+
+// 时间戳早于接收时间超过该时长的数据视为补发的历史数据，只保存，不推送给WebSocket客户端
+const remoteRealtimeWindow = 5 * time.Minute
+
+// 远程主机标识：字母、数字开头，只含字母、数字、点、下划线和连字符，不会与标签的分隔符冲突
+var remoteHostPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ErrInvalidRemoteHost 远程主机标识无效
+var ErrInvalidRemoteHost = i18n.Errorf("host 无效：只能包含字母、数字、点、下划线和连字符，最长64个字符")
+
+// RemoteMetrics 远程主机推送的指标，字段与本机采集的数据相同
+type RemoteMetrics struct {
+	Host      string                  `json:"host"`      // 远程主机标识，如主机名
+	Timestamp time.Time               `json:"timestamp"` // 采集时间，为空时使用接收时间；单条记录可以单独指定
+	Metrics   *models.SystemMetrics   `json:"metrics"`
+	Disks     []models.DiskUsage      `json:"disks"`
+	Network   []models.NetworkTraffic `json:"network"`
+
+	dropped int // 早于 ingest.max_lateness 被丢弃的记录数
+}
+
+// Validate 校验推送的指标并补全时间戳：记录未指定时间时使用推送的时间，推送也未指定时使用接收时间；
+// 时间戳晚于当前时间5分钟以上时拒绝整个推送，早于 ingest.max_lateness 秒以上的记录被丢弃，其余记录正常保存
+func (p *RemoteMetrics) Validate() error {
+	if !remoteHostPattern.MatchString(p.Host) {
+		return ErrInvalidRemoteHost
+	}
+	if p.Metrics == nil && len(p.Disks) == 0 && len(p.Network) == 0 {
+		return i18n.Errorf("metrics、disks、network 至少需要一项")
+	}
+	if len(p.Disks)+len(p.Network) > maxRemoteRows {
+		return i18n.Errorf("单次最多推送%d条磁盘和网卡记录", maxRemoteRows)
+	}
+
+	now := time.Now()
+	if p.Timestamp.IsZero() {
+		p.Timestamp = now
+	}
+	timestamps := []*time.Time{}
+	if p.Metrics != nil {
+		timestamps = append(timestamps, &p.Metrics.Timestamp)
+	}
+	for i := range p.Disks {
+		timestamps = append(timestamps, &p.Disks[i].Timestamp)
+	}
+	for i := range p.Network {
+		timestamps = append(timestamps, &p.Network[i].Timestamp)
+	}
+	for _, t := range timestamps {
+		if t.IsZero() {
+			*t = p.Timestamp
+		}
+		if t.After(now.Add(maxRemoteClockSkew)) {
+			return i18n.Errorf("时间戳 %s 晚于当前时间，请检查远程主机的时钟", t.Format(time.RFC3339))
+		}
+	}
+
+	p.dropStale(now.Add(-time.Duration(config.AppConfig.Ingest.MaxLateness) * time.Second))
+	return nil
+}
+
+// dropStale 丢弃时间戳早于 cutoff 的记录，保留其余记录
+func (p *RemoteMetrics) dropStale(cutoff time.Time) {
+	if p.Metrics != nil && p.Metrics.Timestamp.Before(cutoff) {
+		p.Metrics = nil
+		p.dropped++
+	}
+	disks := p.Disks[:0]
+	for _, d := range p.Disks {
+		if d.Timestamp.Before(cutoff) {
+			p.dropped++
+			continue
+		}
+		disks = append(disks, d)
+	}
+	p.Disks = disks
+	network := p.Network[:0]
+	for _, t := range p.Network {
+		if t.Timestamp.Before(cutoff) {
+			p.dropped++
+			continue
+		}
+		network = append(network, t)
+	}
+	p.Network = network
+}
+
+// Dropped 早于 ingest.max_lateness 被丢弃的记录数
+func (p *RemoteMetrics) Dropped() int {
+	return p.dropped
+}
+
+// Realtime 推送的系统指标是否为实时数据：时间戳在最近5分钟内，补发的历史数据不作为主机的当前状态推送给WebSocket客户端
+func (p *RemoteMetrics) Realtime() bool {
+	return p.Metrics != nil && time.Since(p.Metrics.Timestamp) <= remoteRealtimeWindow
+}
+
+// Late 时间戳早于最近5分钟的记录数，即补发的历史数据
+func (p *RemoteMetrics) Late() int {
+	cutoff := time.Now().Add(-remoteRealtimeWindow)
+	late := 0
+	if p.Metrics != nil && p.Metrics.Timestamp.Before(cutoff) {
+		late++
+	}
+	for _, d := range p.Disks {
+		if d.Timestamp.Before(cutoff) {
+			late++
+		}
+	}
+	for _, t := range p.Network {
+		if t.Timestamp.Before(cutoff) {
+			late++
+		}
+	}
+	return late
+}
+
+// SaveRemoteMetrics 登记远程主机并保存已校验的指标，与本机数据写入同一张表、按 server_id 区分，返回写入的记录数
+func SaveRemoteMetrics(p *RemoteMetrics) (int, error) {
+	rows := 0
+	var serverID uint
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		server, err := database.TouchServer(tx, p.Host)
+		if err != nil {
+			return err
+		}
+		serverID = server.ID
+
+		// 忽略推送数据中的ID和创建时间，由本机生成
+		if m := p.Metrics; m != nil {
+			m.ID, m.ServerID = 0, serverID
+			if err := tx.Create(m).Error; err != nil {
+				return err
+			}
+			rows++
+		}
+		for i := range p.Disks {
+			d := &p.Disks[i]
+			d.ID, d.ServerID = 0, serverID
+			if err := tx.Create(d).Error; err != nil {
+				return err
+			}
+			rows++
+		}
+		for i := range p.Network {
+			t := &p.Network[i]
+			t.ID, t.ServerID = 0, serverID
+			if err := tx.Create(t).Error; err != nil {
+				return err
+			}
+			rows++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// 登记带 server_id 标签的序列，远程主机的指标可通过序列发现、查询构建器和同比规则使用
+	id := strconv.FormatUint(uint64(serverID), 10)
+	if p.Metrics != nil {
+		registerSourceSeries("system_metrics", map[string]string{"server_id": id})
+	}
+	for _, d := range p.Disks {
+		registerSourceSeries("disk_usage", map[string]string{"server_id": id, "path": d.Path, "name": d.Name})
+	}
+	for _, t := range p.Network {
+		registerSourceSeries("network_traffic", map[string]string{"server_id": id, "interface": t.Interface})
+	}
+	return rows, nil
+}
@@ -0,0 +1,368 @@
+package monitor
+
+import (
+	"database/sql"
+	"math"
+	"os"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// reportPeriods 摘要报告的统计周期
+var reportPeriods = map[string]time.Duration{
+	"daily":  24 * time.Hour,
+	"weekly": 7 * 24 * time.Hour,
+}
+
+// MetricStat 指标在统计周期内的平均值和峰值
+type MetricStat struct {
+	Avg  float64 `json:"avg"`
+	Peak float64 `json:"peak"`
+}
+
+// DiskGrowth 磁盘在统计周期内的已用空间变化
+type DiskGrowth struct {
+	Path   string  `json:"path"`
+	Start  uint64  `json:"start"`  // 周期开始时的已用空间(GB)
+	End    uint64  `json:"end"`    // 周期结束时的已用空间(GB)
+	Growth int64   `json:"growth"` // 增长量(GB)，负数表示释放了空间
+	Usage  float64 `json:"usage"`  // 周期结束时的使用率(%)
+}
+
+// InterfaceTraffic 网卡在统计周期内的上传、下载字节数
+type InterfaceTraffic struct {
+	Interface string `json:"interface"`
+	Upload    uint64 `json:"upload"`
+	Download  uint64 `json:"download"`
+}
+
+// AlertCount 告警数量
+type AlertCount struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// ServiceAvailability 服务在统计周期内的可用率
+type ServiceAvailability struct {
+	Name string `json:"name"`
+	UptimePeriod
+}
+
+// Report 摘要报告：统计周期内的CPU、内存、磁盘增长、流量、告警数量和服务可用率
+type Report struct {
+	Period    string                `json:"period"` // daily, weekly，或报告的时间范围如 30d
+	Hostname  string                `json:"hostname"`
+	Start     time.Time             `json:"start"`
+	End       time.Time             `json:"end"`
+	Source    string                `json:"source"` // 指标来源: raw（原始数据），daily（超出原始数据保留期时使用每日汇总）
+	CPU       MetricStat            `json:"cpu"`    // CPU使用率(%)
+	Memory    MetricStat            `json:"memory"` // 内存使用率(%)
+	Disks     []DiskGrowth          `json:"disks"`
+	Traffic   []InterfaceTraffic    `json:"traffic"`
+	Alerts    int64                 `json:"alerts"`          // 周期内触发的告警总数
+	AlertsBy  []AlertCount          `json:"alerts_by_level"` // 按级别统计
+	TopAlerts []AlertCount          `json:"top_alert_types"` // 触发次数最多的告警类型
+	Services  []ServiceAvailability `json:"services"`
+}
+
+// ValidReportPeriod 判断是否为支持的报告周期
+func ValidReportPeriod(period string) bool {
+	_, ok := reportPeriods[period]
+	return ok
+}
+
+// BuildReport 生成截止到end的摘要报告，period为daily（最近24小时）或weekly（最近7天）
+func BuildReport(period string, end time.Time) (*Report, error) {
+	duration, ok := reportPeriods[period]
+	if !ok {
+		return nil, i18n.Errorf("不支持的报告周期: %s，可选 daily、weekly", period)
+	}
+	return BuildReportRange(period, end.Add(-duration), end)
+}
+
+// BuildReportRange 生成[start, end)内的报告，period为报告的描述，如 30d
+func BuildReportRange(period string, start, end time.Time) (*Report, error) {
+	hostname, _ := os.Hostname()
+	report := &Report{
+		Period:   period,
+		Hostname: hostname,
+		Start:    start,
+		End:      end,
+		Source:   reportSource(start),
+	}
+
+	var err error
+	if report.Source == "daily" {
+		err = summaryMetricStats(report, start, end)
+	} else {
+		err = rawMetricStats(report, start, end)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := database.DB.Model(&models.Alert{}).Where("created_at >= ? AND created_at < ?", start, end)
+	if err := alerts.Session(&gorm.Session{}).Count(&report.Alerts).Error; err != nil {
+		return nil, i18n.Errorf("统计告警失败: %v", err)
+	}
+	report.AlertsBy = []AlertCount{}
+	if err := alerts.Session(&gorm.Session{}).Select("level AS key, COUNT(*) AS count").
+		Group("level").Order("count DESC").Scan(&report.AlertsBy).Error; err != nil {
+		return nil, i18n.Errorf("统计告警失败: %v", err)
+	}
+	report.TopAlerts = []AlertCount{}
+	if err := alerts.Session(&gorm.Session{}).Select("type AS key, COUNT(*) AS count").
+		Group("type").Order("count DESC").Limit(5).Scan(&report.TopAlerts).Error; err != nil {
+		return nil, i18n.Errorf("统计告警失败: %v", err)
+	}
+
+	var services []models.ServiceStatus
+	if err := database.DB.Scopes(database.HostScope("")).Order("id").Find(&services).Error; err != nil {
+		return nil, i18n.Errorf("统计服务可用率失败: %v", err)
+	}
+	report.Services = make([]ServiceAvailability, 0, len(services))
+	for _, service := range services {
+		availability, err := serviceAvailability(service.ID, start, end)
+		if err != nil {
+			return nil, i18n.Errorf("统计服务可用率失败: %v", err)
+		}
+		availability.Period = period
+		report.Services = append(report.Services, ServiceAvailability{Name: service.Name, UptimePeriod: *availability})
+	}
+	return report, nil
+}
+
+// localDay 时间在显示时区的日期，与每日汇总的 day 对应
+func localDay(t time.Time) string {
+	return t.In(config.AppConfig.I18n.Location()).Format("2006-01-02")
+}
+
+// reportSource 报告起始时间之后的原始数据已被清理（最早的原始数据晚于起始时间一小时以上，
+// 且更早的日期有每日汇总）时，指标改用每日汇总统计
+func reportSource(start time.Time) string {
+	if !config.AppConfig.Rollup.Enabled {
+		return "raw"
+	}
+	var oldest models.SystemMetrics
+	if err := database.DB.Scopes(database.HostScope("")).Select("timestamp").Order("timestamp").First(&oldest).Error; err != nil {
+		return "raw"
+	}
+	if !oldest.Timestamp.After(start.Add(time.Hour)) {
+		return "raw"
+	}
+
+	var summarized int64
+	database.DB.Model(&models.DailySummary{}).Scopes(database.HostSummaryScope("")).
+		Where("metric = ? AND day >= ? AND day < ?", "system_metrics.cpu",
+			localDay(start), localDay(oldest.Timestamp)).
+		Count(&summarized)
+	if summarized > 0 {
+		return "daily"
+	}
+	return "raw"
+}
+
+// rawMetricStats 从原始数据统计CPU、内存、磁盘增长和网络流量
+func rawMetricStats(report *Report, start, end time.Time) error {
+	err := database.DB.Model(&models.SystemMetrics{}).Scopes(database.HostScope("")).
+		Select("COALESCE(AVG(cpu), 0), COALESCE(MAX(cpu), 0), COALESCE(AVG(memory), 0), COALESCE(MAX(memory), 0)").
+		Where("timestamp >= ? AND timestamp < ?", start, end).
+		Row().Scan(&report.CPU.Avg, &report.CPU.Peak, &report.Memory.Avg, &report.Memory.Peak)
+	if err != nil {
+		return i18n.Errorf("统计系统指标失败: %v", err)
+	}
+
+	if report.Disks, err = reportDiskGrowth(start, end); err != nil {
+		return i18n.Errorf("统计磁盘增长失败: %v", err)
+	}
+	if report.Traffic, err = reportTraffic(start, end); err != nil {
+		return i18n.Errorf("统计网络流量失败: %v", err)
+	}
+	return nil
+}
+
+// summaryMetricStats 从每日汇总统计CPU、内存、磁盘增长和网络流量：平均值按样本数加权，
+// 磁盘增长为首尾两天平均已用空间之差，流量按每天的平均速率估算
+func summaryMetricStats(report *Report, start, end time.Time) error {
+	first, last := localDay(start), localDay(end)
+	days := database.DB.Model(&models.DailySummary{}).Scopes(database.HostSummaryScope("")).Where("day >= ? AND day <= ?", first, last)
+
+	for _, stat := range []struct {
+		metric string
+		target *MetricStat
+	}{{"system_metrics.cpu", &report.CPU}, {"system_metrics.memory", &report.Memory}} {
+		err := days.Session(&gorm.Session{}).
+			Select("COALESCE(SUM(avg * samples) / NULLIF(SUM(samples), 0), 0), COALESCE(MAX(max), 0)").
+			Where("metric = ?", stat.metric).
+			Row().Scan(&stat.target.Avg, &stat.target.Peak)
+		if err != nil {
+			return i18n.Errorf("统计系统指标失败: %v", err)
+		}
+	}
+
+	var disks []struct {
+		Tags  string
+		Start float64
+		End   float64
+		Usage float64
+	}
+	err := database.DB.Raw(`SELECT DISTINCT u.tags,
+		FIRST_VALUE(u.avg) OVER w AS "start",
+		LAST_VALUE(u.avg) OVER w AS "end",
+		LAST_VALUE(COALESCE(p.avg, 0)) OVER w AS usage
+		FROM daily_summaries u LEFT JOIN daily_summaries p
+			ON p.metric = 'disk_usage.usage' AND p.tags = u.tags AND p.day = u.day
+		WHERE u.metric = 'disk_usage.used' AND u.day >= ? AND u.day <= ? AND ',' || u.tags || ',' NOT LIKE '%,server_id=%'
+		WINDOW w AS (PARTITION BY u.tags ORDER BY u.day ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING)
+		ORDER BY u.tags`, first, last).Scan(&disks).Error
+	if err != nil {
+		return i18n.Errorf("统计磁盘增长失败: %v", err)
+	}
+	report.Disks = make([]DiskGrowth, 0, len(disks))
+	for _, disk := range disks {
+		growth := DiskGrowth{
+			Path:  parseTags(disk.Tags)["path"],
+			Start: uint64(math.Round(disk.Start)),
+			End:   uint64(math.Round(disk.End)),
+			Usage: disk.Usage,
+		}
+		growth.Growth = int64(growth.End) - int64(growth.Start)
+		report.Disks = append(report.Disks, growth)
+	}
+
+	// 速率单位为MB/s，每天的平均速率乘以一天的秒数估算当天的流量
+	var traffic []struct {
+		Tags     string
+		Upload   float64
+		Download float64
+	}
+	err = days.Session(&gorm.Session{}).
+		Select("tags, "+
+			"SUM(CASE WHEN metric = 'network_traffic.upload_speed' THEN avg ELSE 0 END) AS upload, "+
+			"SUM(CASE WHEN metric = 'network_traffic.download_speed' THEN avg ELSE 0 END) AS download").
+		Where("metric IN ?", []string{"network_traffic.upload_speed", "network_traffic.download_speed"}).
+		Group("tags").Order("tags").Scan(&traffic).Error
+	if err != nil {
+		return i18n.Errorf("统计网络流量失败: %v", err)
+	}
+	report.Traffic = make([]InterfaceTraffic, 0, len(traffic))
+	for _, t := range traffic {
+		report.Traffic = append(report.Traffic, InterfaceTraffic{
+			Interface: parseTags(t.Tags)["interface"],
+			Upload:    uint64(t.Upload * 86400 * 1024 * 1024),
+			Download:  uint64(t.Download * 86400 * 1024 * 1024),
+		})
+	}
+	return nil
+}
+
+// parseTags 解析 k=v 逗号分隔的标签
+func parseTags(tags string) map[string]string {
+	parsed := make(map[string]string)
+	for _, pair := range strings.Split(tags, ",") {
+		if key, value, ok := strings.Cut(pair, "="); ok {
+			parsed[key] = value
+		}
+	}
+	return parsed
+}
+
+// reportDiskGrowth 各磁盘周期内第一条和最后一条记录的已用空间之差
+func reportDiskGrowth(start, end time.Time) ([]DiskGrowth, error) {
+	disks := []DiskGrowth{}
+	err := database.DB.Raw(`SELECT DISTINCT path,
+		FIRST_VALUE(used) OVER w AS "start",
+		LAST_VALUE(used) OVER w AS "end",
+		LAST_VALUE(usage) OVER w AS usage
+		FROM disk_usages WHERE server_id = 0 AND timestamp >= ? AND timestamp < ?
+		WINDOW w AS (PARTITION BY path ORDER BY timestamp ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING)
+		ORDER BY path`, start, end).Scan(&disks).Error
+	for i := range disks {
+		disks[i].Growth = int64(disks[i].End) - int64(disks[i].Start)
+	}
+	return disks, err
+}
+
+// reportTraffic 按网卡累加相邻两条记录的计数器增量；计数器变小（重启或网卡重建）时从0重新累计
+func reportTraffic(start, end time.Time) ([]InterfaceTraffic, error) {
+	traffic := []InterfaceTraffic{}
+	err := database.DB.Raw(`SELECT interface,
+		COALESCE(SUM(CASE WHEN upload >= prev_upload THEN upload - prev_upload ELSE upload END), 0) AS upload,
+		COALESCE(SUM(CASE WHEN download >= prev_download THEN download - prev_download ELSE download END), 0) AS download
+		FROM (SELECT interface, upload, download,
+			LAG(upload) OVER w AS prev_upload,
+			LAG(download) OVER w AS prev_download
+			FROM network_traffics WHERE server_id = 0 AND timestamp >= ? AND timestamp < ?
+			WINDOW w AS (PARTITION BY interface ORDER BY timestamp))
+		WHERE prev_upload IS NOT NULL
+		GROUP BY interface ORDER BY interface`, start, end).Scan(&traffic).Error
+	return traffic, err
+}
+
+// ReportPoint 报告趋势图的数据点
+type ReportPoint struct {
+	Time     time.Time `json:"time"`
+	CPU      float64   `json:"cpu"`      // CPU使用率(%)
+	Memory   float64   `json:"memory"`   // 内存使用率(%)
+	Upload   float64   `json:"upload"`   // 上传速度 MB/s
+	Download float64   `json:"download"` // 下载速度 MB/s
+}
+
+// ReportPoints 报告时间范围内的趋势数据：原始数据按时间分桶取平均值（约points个点），使用每日汇总时每天一个点
+func ReportPoints(report *Report, points int) ([]ReportPoint, error) {
+	result := []ReportPoint{}
+	if report.Source == "daily" {
+		rows, err := database.DB.Model(&models.DailySummary{}).
+			Select("day, "+
+				"MAX(CASE WHEN metric = 'system_metrics.cpu' THEN avg END), "+
+				"MAX(CASE WHEN metric = 'system_metrics.memory' THEN avg END), "+
+				"MAX(CASE WHEN metric = 'system_metrics.upload' THEN avg END), "+
+				"MAX(CASE WHEN metric = 'system_metrics.download' THEN avg END)").
+			Where("tags = '' AND day >= ? AND day <= ?", localDay(report.Start), localDay(report.End)).
+			Group("day").Order("day").Rows()
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var day string
+			var values [4]sql.NullFloat64
+			if err := rows.Scan(&day, &values[0], &values[1], &values[2], &values[3]); err != nil {
+				return nil, err
+			}
+			t, err := time.ParseInLocation("2006-01-02", day, config.AppConfig.I18n.Location())
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, ReportPoint{Time: t, CPU: values[0].Float64, Memory: values[1].Float64, Upload: values[2].Float64, Download: values[3].Float64})
+		}
+		return result, rows.Err()
+	}
+
+	bucket := int64(report.End.Sub(report.Start).Seconds()) / int64(points)
+	if bucket < 1 {
+		bucket = 1
+	}
+	var rows []struct {
+		Bucket int64
+		ReportPoint
+	}
+	err := database.DB.Model(&models.SystemMetrics{}).Scopes(database.HostScope("")).
+		Select("(CAST(strftime('%s', timestamp) AS INTEGER) / ?) * ? AS bucket, "+
+			"AVG(cpu) AS cpu, AVG(memory) AS memory, AVG(upload) AS upload, AVG(download) AS download", bucket, bucket).
+		Where("timestamp >= ? AND timestamp < ?", report.Start, report.End).
+		Group("bucket").Order("bucket").
+		Scan(&rows).Error
+	for _, row := range rows {
+		row.Time = time.Unix(row.Bucket, 0)
+		result = append(result, row.ReportPoint)
+	}
+	return result, err
+}
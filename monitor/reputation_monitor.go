@@ -0,0 +1,154 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strings"
+	"time"
+)
+
+type ReputationMonitor struct {
+	httpClient *http.Client
+}
+
+// NewReputationMonitor 创建公网IP信誉监控实例
+func NewReputationMonitor() *ReputationMonitor {
+	return &ReputationMonitor{
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// DetectPublicIP 通过外部探测服务获取本机公网IP
+func (rm *ReputationMonitor) DetectPublicIP() (string, error) {
+	resp, err := rm.httpClient.Get(config.AppConfig.Reputation.PublicIPURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", i18n.Errorf("HTTP状态码错误: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", err
+	}
+
+	ip := strings.TrimSpace(string(body))
+	if net.ParseIP(ip) == nil {
+		return "", i18n.Errorf("无效的公网IP: %q", ip)
+	}
+	return ip, nil
+}
+
+// CheckBlocklists 查询公网IP在各DNSBL中的收录情况；只有NXDOMAIN表示未收录，
+// 超时、SERVFAIL等查询失败和DNSBL拒绝应答记录在 Error 中，收录情况视为未知
+func (rm *ReputationMonitor) CheckBlocklists(ip string) []models.IPReputation {
+	var results []models.IPReputation
+	now := time.Now()
+
+	reversed, err := reverseIP(ip)
+	if err != nil {
+		log.Printf("Error reversing IP %s: %v", ip, err)
+		return results
+	}
+
+	for _, blocklist := range config.AppConfig.Reputation.Blocklists {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		addrs, err := net.DefaultResolver.LookupHost(ctx, reversed+"."+blocklist)
+		cancel()
+
+		result := models.IPReputation{
+			IP:        ip,
+			Blocklist: blocklist,
+			Timestamp: now,
+		}
+
+		var dnsErr *net.DNSError
+		switch {
+		case err == nil:
+			// 127.255.255.x 是DNSBL对查询本身的拒绝/错误应答，不代表被收录
+			for _, addr := range addrs {
+				if !strings.HasPrefix(addr, "127.255.255.") {
+					result.Listed = true
+				}
+			}
+			result.Response = strings.Join(addrs, ",")
+			if !result.Listed {
+				result.Error = i18n.Sprintf("DNSBL拒绝了查询")
+			}
+		case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		default:
+			result.Error = err.Error()
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// SaveResults 保存黑名单检查结果
+func (rm *ReputationMonitor) SaveResults(results []models.IPReputation) error {
+	for _, r := range results {
+		if err := database.DB.Create(&r).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckAlerts 根据检查结果触发或解除黑名单告警；有DNSBL查询失败时不解除告警，等下一次检查
+func (rm *ReputationMonitor) CheckAlerts(ip string, results []models.IPReputation) {
+	var listed, unknown []string
+	for _, r := range results {
+		if r.Listed {
+			listed = append(listed, r.Blocklist)
+		} else if r.Error != "" {
+			unknown = append(unknown, r.Blocklist)
+		}
+	}
+
+	switch {
+	case len(listed) > 0:
+		raiseAlert("ip_blacklist", "error", "security",
+			i18n.Sprintf("公网IP %s 被列入黑名单: %s", ip, strings.Join(listed, ", ")),
+			float64(len(listed)), 0)
+	case len(unknown) > 0:
+		log.Printf("IP reputation unknown for %s on %s, keeping alert state", ip, strings.Join(unknown, ", "))
+	default:
+		resolveAlert("ip_blacklist", "security", i18n.Sprintf("公网IP %s 已从所有黑名单中移除", ip))
+	}
+}
+
+// reverseIP 将IP转换为DNSBL查询格式（IPv4反转八位组，IPv6反转半字节）
+func reverseIP(ip string) (string, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", i18n.Errorf("无效的IP: %s", ip)
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d", v4[3], v4[2], v4[1], v4[0]), nil
+	}
+
+	const hexDigits = "0123456789abcdef"
+	v6 := parsed.To16()
+	nibbles := make([]string, 0, 32)
+	for i := len(v6) - 1; i >= 0; i-- {
+		nibbles = append(nibbles, string(hexDigits[v6[i]&0x0f]), string(hexDigits[v6[i]>>4]))
+	}
+	return strings.Join(nibbles, "."), nil
+}
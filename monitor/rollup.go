@@ -0,0 +1,162 @@
+package monitor
+
+import (
+	"database/sql"
+	"fmt"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/models"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// RollupDaily 按天汇总系统指标、网络流量和磁盘使用的最小值、平均值、最大值，写入每日汇总表。
+// 每次从已有汇总的最后一天的前一天开始重新计算（今天的汇总随数据增加而更新），
+// 尚无汇总时从最早的原始数据开始，原始数据清理前的每一天都会被汇总；
+// 远程主机补发的数据落在更早的日期时，这些日期也会重新计算
+func RollupDaily() (int, error) {
+	var last string
+	if err := database.DB.Model(&models.DailySummary{}).Select("COALESCE(MAX(day), '')").Scan(&last).Error; err != nil {
+		return 0, err
+	}
+	location := config.AppConfig.I18n.Location()
+	var since time.Time
+	if last != "" {
+		day, err := time.ParseInLocation("2006-01-02", last, location)
+		if err != nil {
+			return 0, err
+		}
+		since = day.AddDate(0, 0, -1)
+	}
+
+	written := 0
+	for _, source := range []string{"system_metrics", "network_traffic", "disk_usage"} {
+		// 上一次汇总之后写入的旧日期数据需要重新汇总；本次从开始时间算起，汇总期间写入的数据留给下一次
+		started := time.Now()
+		var state models.RollupState
+		if err := database.DB.Where("source = ?", source).Limit(1).Find(&state).Error; err != nil {
+			return written, fmt.Errorf("%s: %v", source, err)
+		}
+
+		summaries, err := summarizeSource(source, since, time.Time{})
+		if err != nil {
+			return written, fmt.Errorf("%s: %v", source, err)
+		}
+
+		if !since.IsZero() && !state.LastRun.IsZero() {
+			days, err := lateDays(source, state.LastRun, since)
+			if err != nil {
+				return written, fmt.Errorf("%s: %v", source, err)
+			}
+			for _, day := range days {
+				from, err := time.ParseInLocation("2006-01-02", day, location)
+				if err != nil {
+					return written, err
+				}
+				late, err := summarizeSource(source, from, from.AddDate(0, 0, 1))
+				if err != nil {
+					return written, fmt.Errorf("%s: %v", source, err)
+				}
+				summaries = append(summaries, late...)
+			}
+		}
+		if len(summaries) > 0 {
+			err = database.DB.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "metric"}, {Name: "tags"}, {Name: "day"}},
+				DoUpdates: clause.AssignmentColumns([]string{"min", "avg", "max", "samples", "updated_at"}),
+			}).CreateInBatches(summaries, 100).Error
+			if err != nil {
+				return written, fmt.Errorf("%s: %v", source, err)
+			}
+			written += len(summaries)
+		}
+		if err := database.DB.Save(&models.RollupState{Source: source, LastRun: started}).Error; err != nil {
+			return written, fmt.Errorf("%s: %v", source, err)
+		}
+	}
+	return written, nil
+}
+
+// lateDays 上次汇总之后写入、时间戳早于since的原始数据所在的日期（显示时区），即远程主机补发数据涉及的已汇总日期
+func lateDays(source string, lastRollup, since time.Time) ([]string, error) {
+	var days []string
+	err := database.DB.Table(comparisonSources[source].table).
+		Where("created_at > ? AND timestamp < ?", lastRollup, since).
+		Distinct(database.LocalDate("timestamp")).Pluck(database.LocalDate("timestamp"), &days).Error
+	return days, err
+}
+
+// summarizeSource 按显示时区的日期和标签分组计算数据源各字段在 [from, to) 内的每日汇总，to 为零值时不限制结束时间
+func summarizeSource(source string, from, to time.Time) ([]models.DailySummary, error) {
+	fields := sourceSeriesFields[source]
+	tagColumns := make([]string, 0, len(comparisonSources[source].tags))
+	for tag := range comparisonSources[source].tags {
+		tagColumns = append(tagColumns, tag)
+	}
+	sort.Strings(tagColumns)
+
+	selects := append([]string{database.LocalDate("timestamp") + " AS day"}, tagColumns...)
+	selects = append(selects, "COUNT(*) AS samples")
+	for _, field := range fields {
+		selects = append(selects, fmt.Sprintf("MIN(%s), AVG(%s), MAX(%s)", field, field, field))
+	}
+	groups := append([]string{"day"}, tagColumns...)
+
+	query := database.DB.Table(comparisonSources[source].table).
+		Select(strings.Join(selects, ", ")).
+		Where("timestamp >= ?", from)
+	if !to.IsZero() {
+		query = query.Where("timestamp < ?", to)
+	}
+	rows, err := query.Group(strings.Join(groups, ", ")).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	var summaries []models.DailySummary
+	for rows.Next() {
+		var day string
+		var samples int64
+		tagValues := make([]sql.NullString, len(tagColumns))
+		values := make([]sql.NullFloat64, len(fields)*3)
+
+		dest := []interface{}{&day}
+		for i := range tagValues {
+			dest = append(dest, &tagValues[i])
+		}
+		dest = append(dest, &samples)
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		tags := make(map[string]string, len(tagColumns))
+		for i, column := range tagColumns {
+			// 本机数据的 server_id 为0，汇总不带该标签
+			if column == "server_id" && tagValues[i].String == "0" {
+				continue
+			}
+			tags[column] = tagValues[i].String
+		}
+		for i, field := range fields {
+			summaries = append(summaries, models.DailySummary{
+				Metric:    source + "." + field,
+				Tags:      formatTags(tags),
+				Day:       day,
+				Min:       values[i*3].Float64,
+				Avg:       values[i*3+1].Float64,
+				Max:       values[i*3+2].Float64,
+				Samples:   samples,
+				UpdatedAt: now,
+			})
+		}
+	}
+	return summaries, rows.Err()
+}
@@ -0,0 +1,39 @@
+package monitor
+
+import (
+	"server-monitor/database"
+	"server-monitor/models"
+	"strconv"
+	"time"
+)
+
+// SaveSensorReadings 保存一次采集的传感器读数
+func SaveSensorReadings(readings []models.SensorReading) error {
+	if len(readings) == 0 {
+		return nil
+	}
+	return database.DB.CreateInBatches(readings, 100).Error
+}
+
+// GetLatestSensorReadings 每个传感器（来源+名称）最近 within 时间内的最新读数，按来源和名称排序；
+// 超过 within 没有更新的传感器视为已不存在
+func GetLatestSensorReadings(source string, within time.Duration) ([]models.SensorReading, error) {
+	latest := database.DB.Model(&models.SensorReading{}).Select("MAX(id)").
+		Where("timestamp >= ?", time.Now().Add(-within)).Group("source, name")
+	if source != "" {
+		latest = latest.Where("source = ?", source)
+	}
+
+	var readings []models.SensorReading
+	err := database.DB.Where("id IN (?)", latest).Order("source, name").Find(&readings).Error
+	return readings, err
+}
+
+// uniqueSensorName 同一次采集中名称重复的传感器依次加 -1、-2 后缀，names 记录已使用的名称
+func uniqueSensorName(names map[string]int, name string) string {
+	names[name]++
+	if names[name] > 1 {
+		return name + "-" + strconv.Itoa(names[name]-1)
+	}
+	return name
+}
@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"log"
+	"server-monitor/database"
+	"server-monitor/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 各数据源登记为序列的数值字段，与查询构建器的数据源一致
+var sourceSeriesFields = map[string][]string{
+	"system_metrics":  {"cpu", "cpu_user", "cpu_system", "cpu_iowait", "cpu_steal", "memory", "mem_total", "mem_available", "mem_used", "mem_cached", "mem_buffers", "disk", "upload", "download"},
+	"network_traffic": {"upload", "download", "upload_speed", "download_speed"},
+	"disk_usage":      {"total", "used", "free", "usage"},
+}
+
+// 已登记序列刷新最近出现时间的间隔，避免每次采集都写库
+const seriesTouchInterval = time.Minute
+
+var (
+	seriesMu      sync.Mutex
+	seriesTouched = make(map[string]time.Time) // 序列键 -> 最近一次刷新last_seen的时间
+)
+
+// SeriesKey 由指标名和标签生成序列唯一键，如 network_traffic.upload_speed{interface=eth0}
+func SeriesKey(metric string, tags map[string]string) string {
+	return metric + "{" + formatTags(tags) + "}"
+}
+
+// formatTags 按键排序输出 k=v 列表，忽略空值标签
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key, value := range tags {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + tags[key]
+	}
+	return strings.Join(pairs, ",")
+}
+
+// RegisterSeries 登记指标序列及其标签索引，已登记的序列只定期刷新最近出现时间
+func RegisterSeries(metric string, tags map[string]string) error {
+	key := SeriesKey(metric, tags)
+	now := time.Now()
+
+	seriesMu.Lock()
+	defer seriesMu.Unlock()
+
+	if touched, ok := seriesTouched[key]; ok && now.Sub(touched) < seriesTouchInterval {
+		return nil
+	}
+
+	result := database.DB.Model(&models.Series{}).Where("key = ?", key).Update("last_seen", now)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			series := models.Series{
+				Key:       key,
+				Metric:    metric,
+				Tags:      formatTags(tags),
+				FirstSeen: now,
+				LastSeen:  now,
+			}
+			if err := tx.Create(&series).Error; err != nil {
+				return err
+			}
+
+			for tagKey, tagValue := range tags {
+				if tagValue == "" {
+					continue
+				}
+				tag := models.SeriesTag{
+					SeriesID: series.ID,
+					Metric:   metric,
+					Key:      tagKey,
+					Value:    tagValue,
+				}
+				if err := tx.Create(&tag).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	seriesTouched[key] = now
+	return nil
+}
+
+// registerSourceSeries 登记数据源中所有数值字段对应的序列
+func registerSourceSeries(source string, tags map[string]string) {
+	for _, field := range sourceSeriesFields[source] {
+		if err := RegisterSeries(source+"."+field, tags); err != nil {
+			log.Printf("Error registering series %s.%s: %v", source, field, err)
+		}
+	}
+}
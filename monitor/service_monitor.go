@@ -6,9 +6,13 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"server-monitor/config"
 	"server-monitor/database"
+	"server-monitor/i18n"
 	"server-monitor/models"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -18,53 +22,93 @@ type ServiceMonitor struct {
 
 // NewServiceMonitor 创建服务监控实例
 func NewServiceMonitor() *ServiceMonitor {
+	// 超时由每个服务的 timeout 控制
 	return &ServiceMonitor{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		httpClient: &http.Client{},
 	}
 }
 
 // CheckAllServices 检查所有服务状态
 func (sm *ServiceMonitor) CheckAllServices() error {
-	services := []struct {
-		name string
-		host string
-		port string
-		check func(string, string) (string, int, error)
-	}{
+	type serviceCheck struct {
+		name     string
+		host     string
+		port     string
+		check    func(string, string, config.ServiceCheckOptions) (string, int, error)
+		weight   int
+		critical bool
+		options  config.ServiceCheckOptions
+	}
+	services := []serviceCheck{
 		{
-			name:  "数据库服务",
-			host:  config.AppConfig.Services.Database.Host,
-			port:  config.AppConfig.Services.Database.Port,
-			check: sm.checkDatabaseService,
+			name:    "数据库服务",
+			host:    config.AppConfig.Services.Database.Host,
+			port:    config.AppConfig.Services.Database.Port,
+			check:   sm.checkDatabaseService,
+			options: config.AppConfig.Services.Database.ServiceCheckOptions,
 		},
 		{
-			name:  "Web服务",
-			host:  config.AppConfig.Services.Web.URL,
-			port:  config.AppConfig.Services.Web.Port,
-			check: sm.checkWebService,
+			name:    "Web服务",
+			host:    config.AppConfig.Services.Web.URL,
+			port:    config.AppConfig.Services.Web.Port,
+			check:   sm.checkWebService,
+			options: config.AppConfig.Services.Web.ServiceCheckOptions,
 		},
 		{
-			name:  "邮件服务",
-			host:  config.AppConfig.Services.Mail.Host,
-			port:  config.AppConfig.Services.Mail.Port,
-			check: sm.checkMailService,
+			name:    "邮件服务",
+			host:    config.AppConfig.Services.Mail.Host,
+			port:    config.AppConfig.Services.Mail.Port,
+			check:   sm.checkMailService,
+			options: config.AppConfig.Services.Mail.ServiceCheckOptions,
 		},
 		{
-			name:  "云存储服务",
-			host:  config.AppConfig.Services.Storage.Endpoint,
-			port:  "9000",
-			check: sm.checkStorageService,
+			name:    "云存储服务",
+			host:    config.AppConfig.Services.Storage.Endpoint,
+			port:    "9000",
+			check:   sm.checkStorageService,
+			options: config.AppConfig.Services.Storage.ServiceCheckOptions,
 		},
 	}
 
-	for _, service := range services {
-		status, responseTime, err := service.check(service.host, service.port)
+	// 自定义服务检查
+	var checks []models.ServiceCheck
+	if err := database.DB.Find(&checks).Error; err != nil {
+		log.Printf("Error loading service checks: %v", err)
+	}
+	for _, check := range checks {
+		service := serviceCheck{name: check.Name, host: check.Host, port: check.Port, weight: check.Weight, critical: check.Critical}
+		service.options = serviceCheckOptions(&check)
+		if check.Type == "http" {
+			service.host, service.port = check.URL, ""
+			service.check = func(target, _ string, options config.ServiceCheckOptions) (string, int, error) {
+				return sm.checkHTTPService(target, options)
+			}
+		} else {
+			service.check = sm.checkTCPService
+		}
+		services = append(services, service)
+	}
+
+	// 各服务并发检查，失败的服务等待重试时不耽误其他服务
+	attempts := make([][]serviceAttempt, len(services))
+	var wg sync.WaitGroup
+	for i, service := range services {
+		wg.Add(1)
+		go func(i int, service serviceCheck) {
+			defer wg.Done()
+			attempts[i] = runServiceCheck(service.check, service.host, service.port, service.options)
+		}(i, service)
+	}
+	wg.Wait()
+
+	for i, service := range services {
+		// 只有最后一次尝试的结果改变服务状态
+		final := attempts[i][len(attempts[i])-1]
+		status, responseTime, err := final.status, final.response, final.err
 		
 		// 更新或创建服务状态记录
 		var serviceStatus models.ServiceStatus
-		result := database.DB.Where("name = ?", service.name).First(&serviceStatus)
+		result := database.DB.Scopes(database.HostScope("")).Where("name = ?", service.name).First(&serviceStatus)
 		
 		if result.Error != nil {
 			// 创建新记录
@@ -73,133 +117,223 @@ func (sm *ServiceMonitor) CheckAllServices() error {
 				Host:      service.host,
 				Port:      service.port,
 				Status:    status,
-				LastCheck: time.Now(),
+				LastCheck: final.at,
 				Response:  responseTime,
+				Weight:    service.weight,
+				Critical:  service.critical,
 			}
 			database.DB.Create(&serviceStatus)
 		} else {
 			// 更新现有记录
 			serviceStatus.Status = status
-			serviceStatus.LastCheck = time.Now()
+			serviceStatus.LastCheck = final.at
 			serviceStatus.Response = responseTime
 			database.DB.Save(&serviceStatus)
 		}
 
-		// 记录日志
-		if err != nil {
+		// 保存每次尝试的结果，用于响应时间曲线和计算可用率；重试前的失败为soft，最后一次为hard
+		for n, attempt := range attempts[i] {
+			checkResult := models.ServiceCheckResult{
+				ServiceID: serviceStatus.ID,
+				Status:    attempt.status,
+				Response:  attempt.response,
+				StateType: "soft",
+				Attempt:   n + 1,
+				Timestamp: attempt.at,
+			}
+			if n == len(attempts[i])-1 {
+				checkResult.StateType = "hard"
+			}
+			if attempt.err != nil {
+				checkResult.Error = attempt.err.Error()
+			}
+			if err := database.DB.Create(&checkResult).Error; err != nil {
+				log.Printf("Error saving check result for %s: %v", service.name, err)
+			}
+		}
+
+		// 记录日志，重试前的失败只保存在检查历史中
+		if err != nil && len(attempts[i]) > 1 {
+			log.Printf("Service check failed for %s after %d attempts: %v", service.name, len(attempts[i]), err)
+			sm.logServiceEvent(service.name, "error", i18n.Sprintf("服务检查连续 %d 次失败: %v", len(attempts[i]), err))
+		} else if err != nil {
 			log.Printf("Service check failed for %s: %v", service.name, err)
-			sm.logServiceEvent(service.name, "error", fmt.Sprintf("服务检查失败: %v", err))
+			sm.logServiceEvent(service.name, "error", i18n.Sprintf("服务检查失败: %v", err))
 		} else {
-			sm.logServiceEvent(service.name, "info", fmt.Sprintf("服务状态: %s, 响应时间: %dms", status, responseTime))
+			sm.logServiceEvent(service.name, "info", i18n.Sprintf("服务状态: %s, 响应时间: %dms", status, responseTime))
 		}
 	}
 
 	return nil
 }
 
+// serviceAttempt 一次检查尝试的结果
+type serviceAttempt struct {
+	status   string
+	response int
+	err      error
+	at       time.Time
+}
+
+// runServiceCheck 执行服务检查，结果为error时每隔 RetryInterval 秒重试，最多重试 Retries 次；
+// 返回每次尝试的结果，最后一次为最终状态
+func runServiceCheck(check func(string, string, config.ServiceCheckOptions) (string, int, error), host, port string, options config.ServiceCheckOptions) []serviceAttempt {
+	var attempts []serviceAttempt
+	for n := 0; ; n++ {
+		status, response, err := check(host, port, options)
+		attempts = append(attempts, serviceAttempt{status: status, response: response, err: err, at: time.Now()})
+		if status != "error" || n >= options.Retries {
+			return attempts
+		}
+		time.Sleep(time.Duration(options.RetryInterval) * time.Second)
+	}
+}
+
 // checkDatabaseService 检查数据库服务
-func (sm *ServiceMonitor) checkDatabaseService(host, port string) (string, int, error) {
-	start := time.Now()
-	
+func (sm *ServiceMonitor) checkDatabaseService(host, port string, options config.ServiceCheckOptions) (string, int, error) {
 	// 尝试连接数据库端口
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%s", host, port), 5*time.Second)
-	if err != nil {
-		return "error", 0, err
-	}
-	defer conn.Close()
-	
-	responseTime := int(time.Since(start).Milliseconds())
-	
-	// 根据响应时间判断状态
-	if responseTime < 100 {
-		return "running", responseTime, nil
-	} else if responseTime < 500 {
-		return "warning", responseTime, nil
-	} else {
-		return "error", responseTime, fmt.Errorf("响应时间过长: %dms", responseTime)
-	}
+	return dialService(fmt.Sprintf("%s:%s", host, port), options)
 }
 
 // checkWebService 检查Web服务
-func (sm *ServiceMonitor) checkWebService(host, port string) (string, int, error) {
-	start := time.Now()
-	
+func (sm *ServiceMonitor) checkWebService(host, port string, options config.ServiceCheckOptions) (string, int, error) {
 	url := fmt.Sprintf("%s://%s:%s", config.AppConfig.Services.Web.Protocol, host, port)
-	
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	return sm.checkHTTPService(url, options)
+}
+
+// checkMailService 检查邮件服务
+func (sm *ServiceMonitor) checkMailService(host, port string, options config.ServiceCheckOptions) (string, int, error) {
+	// 尝试连接SMTP端口
+	return dialService(fmt.Sprintf("%s:%s", host, port), options)
+}
+
+// checkStorageService 检查云存储服务
+func (sm *ServiceMonitor) checkStorageService(host, port string, options config.ServiceCheckOptions) (string, int, error) {
+	// 尝试连接存储服务端口
+	return dialService(fmt.Sprintf("%s:%s", host, port), options)
+}
+
+// checkTCPService 检查TCP端口是否可以连接
+func (sm *ServiceMonitor) checkTCPService(host, port string, options config.ServiceCheckOptions) (string, int, error) {
+	return dialService(net.JoinHostPort(host, port), options)
+}
+
+// checkHTTPService 检查HTTP地址，2xx/3xx视为正常
+func (sm *ServiceMonitor) checkHTTPService(target string, options config.ServiceCheckOptions) (string, int, error) {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(options.Timeout)*time.Second)
 	defer cancel()
-	
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
 	if err != nil {
 		return "error", 0, err
 	}
-	
+
 	resp, err := sm.httpClient.Do(req)
 	if err != nil {
 		return "error", 0, err
 	}
 	defer resp.Body.Close()
-	
+
 	responseTime := int(time.Since(start).Milliseconds())
-	
+
 	// 根据HTTP状态码和响应时间判断状态
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		if responseTime < 200 {
-			return "running", responseTime, nil
-		} else if responseTime < 1000 {
-			return "warning", responseTime, nil
-		} else {
-			return "error", responseTime, fmt.Errorf("响应时间过长: %dms", responseTime)
-		}
-	} else {
-		return "error", responseTime, fmt.Errorf("HTTP状态码错误: %d", resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return "error", responseTime, i18n.Errorf("HTTP状态码错误: %d", resp.StatusCode)
 	}
+	return latencyStatus(responseTime, options)
 }
 
-// checkMailService 检查邮件服务
-func (sm *ServiceMonitor) checkMailService(host, port string) (string, int, error) {
+// dialService 连接TCP地址，按响应时间判断状态
+func dialService(address string, options config.ServiceCheckOptions) (string, int, error) {
 	start := time.Now()
-	
-	// 尝试连接SMTP端口
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%s", host, port), 5*time.Second)
+
+	conn, err := net.DialTimeout("tcp", address, time.Duration(options.Timeout)*time.Second)
 	if err != nil {
 		return "error", 0, err
 	}
 	defer conn.Close()
-	
-	responseTime := int(time.Since(start).Milliseconds())
-	
-	// 根据响应时间判断状态
-	if responseTime < 100 {
+
+	return latencyStatus(int(time.Since(start).Milliseconds()), options)
+}
+
+// latencyStatus 根据响应时间判断状态：低于 LatencyWarning 为running，低于 LatencyCritical 为warning，否则为error
+func latencyStatus(responseTime int, options config.ServiceCheckOptions) (string, int, error) {
+	if responseTime < options.LatencyWarning {
 		return "running", responseTime, nil
-	} else if responseTime < 500 {
+	} else if responseTime < options.LatencyCritical {
 		return "warning", responseTime, nil
-	} else {
-		return "error", responseTime, fmt.Errorf("响应时间过长: %dms", responseTime)
 	}
+	return "error", responseTime, i18n.Errorf("响应时间过长: %dms", responseTime)
 }
 
-// checkStorageService 检查云存储服务
-func (sm *ServiceMonitor) checkStorageService(host, port string) (string, int, error) {
-	start := time.Now()
-	
-	// 尝试连接存储服务端口
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%s", host, port), 5*time.Second)
-	if err != nil {
-		return "error", 0, err
+// serviceCheckDefaults 自定义服务检查未设置超时和响应时间分级时，按检查方式使用的默认值
+var serviceCheckDefaults = map[string]config.ServiceCheckOptions{
+	"tcp":  {Timeout: 5, LatencyWarning: 100, LatencyCritical: 500},
+	"http": {Timeout: 10, LatencyWarning: 200, LatencyCritical: 1000},
+}
+
+// serviceCheckOptions 自定义服务检查的重试、超时和响应时间分级，未设置（为0）的项取默认值
+func serviceCheckOptions(check *models.ServiceCheck) config.ServiceCheckOptions {
+	options := serviceCheckDefaults[check.Type]
+	options.Retries, options.RetryInterval = check.Retries, check.RetryInterval
+	if check.Timeout > 0 {
+		options.Timeout = check.Timeout
 	}
-	defer conn.Close()
-	
-	responseTime := int(time.Since(start).Milliseconds())
-	
-	// 根据响应时间判断状态
-	if responseTime < 100 {
-		return "running", responseTime, nil
-	} else if responseTime < 500 {
-		return "warning", responseTime, nil
-	} else {
-		return "error", responseTime, fmt.Errorf("响应时间过长: %dms", responseTime)
+	if check.LatencyWarning > 0 {
+		options.LatencyWarning = check.LatencyWarning
+	}
+	if check.LatencyCritical > 0 {
+		options.LatencyCritical = check.LatencyCritical
+	}
+	return options
+}
+
+// ValidateServiceCheck 校验自定义服务检查
+func ValidateServiceCheck(check *models.ServiceCheck) error {
+	if check.Name == "" {
+		return i18n.Errorf("服务名称不能为空")
 	}
+	switch check.Type {
+	case "tcp":
+		if check.Host == "" {
+			return i18n.Errorf("服务 %s 缺少地址（host）", check.Name)
+		}
+		if port, err := strconv.Atoi(check.Port); err != nil || port < 1 || port > 65535 {
+			return i18n.Errorf("服务 %s 的端口无效: %q", check.Name, check.Port)
+		}
+	case "http":
+		parsed, err := url.ParseRequestURI(check.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return i18n.Errorf("服务 %s 的地址必须是http或https地址: %q", check.Name, check.URL)
+		}
+	default:
+		return i18n.Errorf("服务 %s 的检查方式不支持: %q，可选 tcp、http", check.Name, check.Type)
+	}
+	if check.Weight < 0 {
+		return i18n.Errorf("服务 %s 的权重不能为负数", check.Name)
+	}
+	if check.Retries < 0 || check.Retries > 10 {
+		return i18n.Errorf("服务 %s 的重试次数必须在0到10之间", check.Name)
+	}
+	if check.RetryInterval < 0 || check.RetryInterval > 300 {
+		return i18n.Errorf("服务 %s 的重试间隔必须在0到300秒之间", check.Name)
+	}
+	if check.Timeout < 0 || check.Timeout > 300 {
+		return i18n.Errorf("服务 %s 的超时时间必须在0到300秒之间", check.Name)
+	}
+	if check.LatencyWarning < 0 || check.LatencyCritical < 0 {
+		return i18n.Errorf("服务 %s 的响应时间阈值不能为负数", check.Name)
+	}
+	options := serviceCheckOptions(check)
+	if options.LatencyCritical <= options.LatencyWarning {
+		return i18n.Errorf("服务 %s 的 latency_critical（%dms）必须大于 latency_warning（%dms）", check.Name, options.LatencyCritical, options.LatencyWarning)
+	}
+	if longest := options.LongestCheck(); longest >= config.AppConfig.Monitor.ServiceInterval {
+		return i18n.Errorf("服务 %s 的最长检查时间（%d秒）必须小于 monitor.service_interval（%d秒）", check.Name, longest, config.AppConfig.Monitor.ServiceInterval)
+	}
+	return nil
 }
 
 // logServiceEvent 记录服务事件
@@ -214,17 +348,17 @@ func (sm *ServiceMonitor) logServiceEvent(serviceName, level, message string) {
 	database.DB.Create(&log)
 }
 
-// GetServiceStatus 获取服务状态列表
+// GetServiceStatus 获取本机的服务状态列表
 func (sm *ServiceMonitor) GetServiceStatus() ([]models.ServiceStatus, error) {
 	var services []models.ServiceStatus
-	err := database.DB.Find(&services).Error
+	err := database.DB.Scopes(database.HostScope("")).Find(&services).Error
 	return services, err
 }
 
 // GetServiceStatusByName 根据名称获取服务状态
 func (sm *ServiceMonitor) GetServiceStatusByName(name string) (*models.ServiceStatus, error) {
 	var service models.ServiceStatus
-	err := database.DB.Where("name = ?", name).First(&service).Error
+	err := database.DB.Scopes(database.HostScope("")).Where("name = ?", name).First(&service).Error
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"server-monitor/config"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 内核通过该目录提供各thermal zone的类型和温度
+var thermalRoot = "/sys/class/thermal"
+
+// 树莓派固件通过该文件提供与 vcgencmd get_throttled 相同的标志（较新的内核）
+var rpiThrottledFile = "/sys/devices/platform/soc/soc:firmware/get_throttled"
+
+// rpiThrottleFlag vcgencmd get_throttled 的标志位：低4位为当前状态，第16~19位为启动以来是否发生过
+type rpiThrottleFlag struct {
+	bit   uint
+	name  string
+	label string
+}
+
+var rpiThrottleFlags = []rpiThrottleFlag{
+	{0, "under_voltage", "欠压"},
+	{1, "freq_capped", "频率受限"},
+	{2, "throttled", "降频"},
+	{3, "soft_temp_limit", "温度软限制"},
+}
+
+// CollectSoC 采集thermal zone温度和树莓派的欠压、降频标志；两者都没有时返回空
+func CollectSoC() []models.SensorReading {
+	now := time.Now()
+	readings := thermalZoneReadings(now)
+	if flags, ok := rpiThrottled(); ok {
+		for _, flag := range rpiThrottleFlags {
+			readings = append(readings,
+				models.SensorReading{Source: "rpi", Name: flag.name, Type: "status", Value: flagValue(flags, flag.bit), Timestamp: now},
+				models.SensorReading{Source: "rpi", Name: flag.name + "_occurred", Type: "status", Value: flagValue(flags, flag.bit+16), Timestamp: now})
+		}
+	}
+	return readings
+}
+
+// CheckSoCAlerts 温度达到 soc.alert_temperature 或当前处于欠压、降频状态时告警，恢复后自动解除
+func CheckSoCAlerts(readings []models.SensorReading) {
+	cfg := config.AppConfig.SoC
+
+	if cfg.AlertTemperature > 0 {
+		var hottest *models.SensorReading
+		for i, r := range readings {
+			if r.Type == "temperature" && (hottest == nil || r.Value > hottest.Value) {
+				hottest = &readings[i]
+			}
+		}
+		if hottest != nil && hottest.Value >= cfg.AlertTemperature {
+			raiseAlert("soc_temperature", "warning", "system",
+				i18n.Sprintf("SoC温度过高: %s %.1f°C", hottest.Name, hottest.Value), hottest.Value, cfg.AlertTemperature)
+		} else if hottest != nil {
+			resolveAlert("soc_temperature", "system", i18n.Sprintf("SoC温度恢复正常: %.1f°C", hottest.Value))
+		}
+	}
+
+	if cfg.AlertThrottling {
+		var active []string
+		found, underVoltage := false, false
+		for _, r := range readings {
+			if r.Source != "rpi" {
+				continue
+			}
+			found = true
+			for _, flag := range rpiThrottleFlags {
+				if r.Name == flag.name && r.Value == 1 {
+					active = append(active, i18n.Sprintf(flag.label))
+					underVoltage = underVoltage || flag.bit == 0
+				}
+			}
+		}
+		if len(active) > 0 {
+			// 欠压会导致数据损坏和随机重启，比单纯的温度降频更严重
+			level := "warning"
+			if underVoltage {
+				level = "error"
+			}
+			raiseAlert("soc_throttled", level, "system",
+				i18n.Sprintf("SoC处于受限状态: %s", strings.Join(active, "、")), float64(len(active)), 0)
+		} else if found {
+			resolveAlert("soc_throttled", "system", i18n.Sprintf("SoC已解除欠压和降频"))
+		}
+	}
+}
+
+// thermalZoneReadings 读取 /sys/class/thermal/thermal_zone*/temp（毫摄氏度），传感器名称为zone的type
+func thermalZoneReadings(now time.Time) []models.SensorReading {
+	zones, _ := filepath.Glob(filepath.Join(thermalRoot, "thermal_zone*"))
+	sort.Strings(zones)
+
+	var readings []models.SensorReading
+	names := make(map[string]int)
+	for _, zone := range zones {
+		data, err := os.ReadFile(filepath.Join(zone, "temp"))
+		if err != nil {
+			continue
+		}
+		millidegrees, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+		if err != nil {
+			continue
+		}
+		name := filepath.Base(zone)
+		if t, err := os.ReadFile(filepath.Join(zone, "type")); err == nil && strings.TrimSpace(string(t)) != "" {
+			name = strings.TrimSpace(string(t))
+		}
+		// 多个zone类型相同（如多个acpitz）时加序号区分
+		name = uniqueSensorName(names, name)
+		readings = append(readings, models.SensorReading{
+			Source:    "thermal",
+			Name:      name,
+			Type:      "temperature",
+			Value:     millidegrees / 1000,
+			Unit:      "°C",
+			Timestamp: now,
+		})
+	}
+	return readings
+}
+
+// rpiThrottled 读取树莓派的欠压和降频标志：优先使用 vcgencmd get_throttled（输出 throttled=0x50005），
+// 不存在时读取固件接口；都不可用（非树莓派）时返回false
+func rpiThrottled() (uint64, bool) {
+	if path, err := exec.LookPath(config.AppConfig.SoC.Vcgencmd); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if output, err := exec.CommandContext(ctx, path, "get_throttled").Output(); err == nil {
+			_, value, _ := strings.Cut(strings.TrimSpace(string(output)), "=")
+			if flags, err := strconv.ParseUint(value, 0, 64); err == nil {
+				return flags, true
+			}
+		}
+	}
+
+	data, err := os.ReadFile(rpiThrottledFile)
+	if err != nil {
+		return 0, false
+	}
+	flags, err := strconv.ParseUint(strings.TrimSpace(string(data)), 16, 64)
+	return flags, err == nil
+}
+
+func flagValue(flags uint64, bit uint) float64 {
+	if flags&(1<<bit) != 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,344 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 系统日志中SSH登录记录的分类
+const sshCategory = "security"
+
+// 登录失败汇总日志中最多列出的用户名数
+const maxSSHFailureUsers = 5
+
+var (
+	sshAcceptedPattern = regexp.MustCompile(`Accepted (\S+) for (\S+) from (\S+) port \d+`)
+	// 不存在的用户由 Invalid user 记录，其后的 Failed password for invalid user 不匹配，避免重复计数
+	sshFailedPattern  = regexp.MustCompile(`Failed (\S+) for (\S+) from (\S+) port \d+`)
+	sshInvalidPattern = regexp.MustCompile(`Invalid user (\S*) from (\S+) port \d+`)
+)
+
+type SSHAuthMonitor struct {
+	file   string      // 正在读取的认证日志文件
+	info   os.FileInfo // 上次读取时的文件信息，用于发现日志轮转
+	offset int64       // 已读取到的位置
+
+	cursor string    // journal读取位置
+	since  time.Time // 没有journal读取位置时的起始时间
+
+	failures map[string][]time.Time // 各地址在检测窗口内登录失败的时间
+	attacks  map[string]bool        // 已触发暴力破解告警的地址
+}
+
+// NewSSHAuthMonitor 创建SSH登录监控实例
+func NewSSHAuthMonitor() *SSHAuthMonitor {
+	return &SSHAuthMonitor{}
+}
+
+// Check 读取新增的sshd认证日志，保存登录记录并写入系统日志，检测暴力破解和来自新地址的登录；返回新增的登录记录数
+func (m *SSHAuthMonitor) Check() (int, error) {
+	cfg := config.AppConfig.SSHAuth
+	logins, err := m.read(cfg)
+	if err != nil {
+		return 0, err
+	}
+	if len(logins) > 0 {
+		if err := m.save(cfg, logins); err != nil {
+			return 0, err
+		}
+	}
+	m.checkBruteForce(cfg, logins)
+	return len(logins), nil
+}
+
+// read 按 ssh_auth.source 读取新增的登录记录：auto 在日志文件存在时读取文件，否则读取journal
+func (m *SSHAuthMonitor) read(cfg config.SSHAuthConfig) ([]models.SSHLogin, error) {
+	if cfg.Source != "journal" {
+		for _, path := range cfg.LogFiles {
+			if _, err := os.Stat(path); err == nil {
+				return m.readFile(path)
+			}
+		}
+		if cfg.Source == "file" {
+			return nil, i18n.Errorf("认证日志文件不存在: %s", strings.Join(cfg.LogFiles, ", "))
+		}
+	}
+	return m.readJournal()
+}
+
+// readFile 从上次读取的位置继续读取认证日志文件；首次读取时从文件末尾开始，文件被轮转（替换或截断）后从头读取，
+// 未写完的最后一行留到下次读取
+func (m *SSHAuthMonitor) readFile(path string) ([]models.SSHLogin, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if m.info == nil || m.file != path {
+		m.file, m.info, m.offset = path, info, info.Size()
+		return nil, nil
+	}
+	if !os.SameFile(m.info, info) || info.Size() < m.offset {
+		m.offset = 0
+	}
+	m.info = info
+	if _, err := f.Seek(m.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var logins []models.SSHLogin
+	reader := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, err := reader.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		m.offset += int64(len(line))
+		if !strings.Contains(line, " sshd[") && !strings.Contains(line, " sshd-session[") {
+			continue
+		}
+		if login, ok := parseSSHMessage(line); ok {
+			login.Timestamp = syslogTime(line, now)
+			logins = append(logins, login)
+		}
+	}
+	return logins, nil
+}
+
+// readJournal 读取journal中sshd的新增记录，使用 journal.command；首次读取时从当前时间开始
+func (m *SSHAuthMonitor) readJournal() ([]models.SSHLogin, error) {
+	args := []string{"--output=json", "--no-pager", "SYSLOG_IDENTIFIER=sshd", "SYSLOG_IDENTIFIER=sshd-session"}
+	var since time.Time
+	if m.cursor != "" {
+		args = append(args, "--after-cursor="+m.cursor)
+	} else {
+		if m.since.IsZero() {
+			m.since = time.Now()
+		}
+		since = m.since
+		args = append(args, fmt.Sprintf("--since=@%d", since.Unix()))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, config.AppConfig.Journal.Command, args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+
+	var logins []models.SSHLogin
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &fields); err != nil {
+			continue
+		}
+		m.cursor = journalField(fields, "__CURSOR")
+
+		micros, err := strconv.ParseInt(journalField(fields, "__REALTIME_TIMESTAMP"), 10, 64)
+		if err != nil {
+			continue
+		}
+		timestamp := time.UnixMicro(micros)
+		if !timestamp.After(since) {
+			continue
+		}
+		if login, ok := parseSSHMessage(journalField(fields, "MESSAGE")); ok {
+			login.Timestamp = timestamp
+			logins = append(logins, login)
+		}
+	}
+	return logins, nil
+}
+
+// parseSSHMessage 解析sshd的登录成功、登录失败和不存在的用户记录，其他记录返回false
+func parseSSHMessage(message string) (models.SSHLogin, bool) {
+	if match := sshAcceptedPattern.FindStringSubmatch(message); match != nil {
+		return models.SSHLogin{Method: match[1], User: match[2], IP: match[3], Success: true}, true
+	}
+	if match := sshFailedPattern.FindStringSubmatch(message); match != nil {
+		return models.SSHLogin{Method: match[1], User: match[2], IP: match[3]}, true
+	}
+	if match := sshInvalidPattern.FindStringSubmatch(message); match != nil {
+		return models.SSHLogin{Method: "invalid_user", User: match[1], IP: match[2]}, true
+	}
+	return models.SSHLogin{}, false
+}
+
+// syslogTime 日志行开头的时间，支持RFC3339（rsyslog高精度格式）和传统syslog格式（如 Oct 17 21:58:46，
+// 按本地时区和当前年份解析）；无法解析时为 now
+func syslogTime(line string, now time.Time) time.Time {
+	if i := strings.IndexByte(line, ' '); i > 0 {
+		if t, err := time.Parse(time.RFC3339Nano, line[:i]); err == nil {
+			return t
+		}
+	}
+	if len(line) >= len(time.Stamp) {
+		if t, err := time.ParseInLocation(time.Stamp, line[:len(time.Stamp)], time.Local); err == nil {
+			t = t.AddDate(now.Year(), 0, 0)
+			// 跨年时读到的12月的日志属于上一年
+			if t.After(now.Add(24 * time.Hour)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+			return t
+		}
+	}
+	return now
+}
+
+// save 保存登录记录并写入系统日志：每次登录成功写一条，登录失败按地址汇总；从未成功登录过的地址登录成功时告警
+func (m *SSHAuthMonitor) save(cfg config.SSHAuthConfig, logins []models.SSHLogin) error {
+	// 新地址需要在保存本次的记录之前判断
+	newIPs := make(map[string]models.SSHLogin)
+	if cfg.AlertNewIP {
+		var successes int64
+		database.DB.Model(&models.SSHLogin{}).Where("success = ?", true).Count(&successes)
+		// 首次记录到登录成功时只建立基线，不告警
+		for _, login := range logins {
+			if successes == 0 || !login.Success {
+				continue
+			}
+			if _, ok := newIPs[login.IP]; ok {
+				continue
+			}
+			var count int64
+			database.DB.Model(&models.SSHLogin{}).Where("success = ? AND ip = ?", true, login.IP).Count(&count)
+			if count == 0 {
+				newIPs[login.IP] = login
+			}
+		}
+	}
+
+	if err := database.DB.CreateInBatches(logins, 100).Error; err != nil {
+		return err
+	}
+
+	type failureSummary struct {
+		count int
+		users []string
+		last  time.Time
+	}
+	var logs []models.SystemLog
+	var order []string
+	failures := make(map[string]*failureSummary)
+	for _, login := range logins {
+		if login.Success {
+			logs = append(logs, models.SystemLog{
+				Level:     "info",
+				Category:  sshCategory,
+				Message:   i18n.Sprintf("SSH登录成功: %s@%s（%s）", login.User, login.IP, login.Method),
+				Timestamp: login.Timestamp,
+			})
+			continue
+		}
+		summary, ok := failures[login.IP]
+		if !ok {
+			summary = &failureSummary{}
+			failures[login.IP] = summary
+			order = append(order, login.IP)
+		}
+		summary.count++
+		summary.last = login.Timestamp
+		if len(summary.users) < maxSSHFailureUsers && !slices.Contains(summary.users, login.User) {
+			summary.users = append(summary.users, login.User)
+		}
+	}
+	for _, ip := range order {
+		summary := failures[ip]
+		logs = append(logs, models.SystemLog{
+			Level:     "warning",
+			Category:  sshCategory,
+			Message:   i18n.Sprintf("SSH登录失败 %d 次，来自 %s（用户: %s）", summary.count, ip, strings.Join(summary.users, ", ")),
+			Timestamp: summary.last,
+		})
+	}
+	if len(logs) > 0 {
+		database.DB.CreateInBatches(logs, 100)
+	}
+
+	for ip, login := range newIPs {
+		raiseAlert("ssh_new_ip:"+ip, "warning", sshCategory,
+			i18n.Sprintf("用户 %s 从新地址 %s 登录SSH（%s）", login.User, ip, login.Method), 0, 0)
+	}
+	return nil
+}
+
+// checkBruteForce 同一地址在 ssh_auth.window 秒内登录失败达到 ssh_auth.max_failures 次时触发 ssh_bruteforce:<地址> 告警，
+// 窗口内不再有失败记录后自动解除
+func (m *SSHAuthMonitor) checkBruteForce(cfg config.SSHAuthConfig, logins []models.SSHLogin) {
+	if cfg.MaxFailures == 0 {
+		return
+	}
+	if m.attacks == nil {
+		m.failures = make(map[string][]time.Time)
+		m.attacks = make(map[string]bool)
+		// 重启前触发的告警在攻击停止后同样能自动解除
+		var active []models.Alert
+		database.DB.Where("type LIKE ? AND status = ?", "ssh_bruteforce:%", "active").Find(&active)
+		for _, alert := range active {
+			m.attacks[strings.TrimPrefix(alert.Type, "ssh_bruteforce:")] = true
+		}
+	}
+
+	cutoff := time.Now().Add(-time.Duration(cfg.Window) * time.Second)
+	for _, login := range logins {
+		if !login.Success && login.Timestamp.After(cutoff) {
+			m.failures[login.IP] = append(m.failures[login.IP], login.Timestamp)
+		}
+	}
+
+	for ip, times := range m.failures {
+		recent := times[:0]
+		for _, t := range times {
+			if t.After(cutoff) {
+				recent = append(recent, t)
+			}
+		}
+		if len(recent) == 0 {
+			delete(m.failures, ip)
+			continue
+		}
+		m.failures[ip] = recent
+		if len(recent) >= cfg.MaxFailures {
+			m.attacks[ip] = true
+			raiseAlert("ssh_bruteforce:"+ip, "warning", sshCategory,
+				i18n.Sprintf("SSH暴力破解: %s 在%d秒内登录失败%d次", ip, cfg.Window, len(recent)),
+				float64(len(recent)), float64(cfg.MaxFailures))
+		}
+	}
+
+	for ip := range m.attacks {
+		if _, ok := m.failures[ip]; !ok {
+			resolveAlert("ssh_bruteforce:"+ip, sshCategory, i18n.Sprintf("%s 已停止SSH登录尝试", ip))
+			delete(m.attacks, ip)
+		}
+	}
+}
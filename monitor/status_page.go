@@ -0,0 +1,203 @@
+package monitor
+
+import (
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 状态页数据的缓存时间，公开页面被频繁刷新时不必每次都扫描检查历史
+const statusPageTTL = time.Minute
+
+// 状态页最多展示的已结束故障数
+const maxStatusIncidents = 50
+
+// StatusPage 公开状态页的数据，只包含设为公开的服务，不含服务地址和错误详情
+type StatusPage struct {
+	Title       string           `json:"title"`
+	Status      string           `json:"status"` // 总体状态: operational, degraded, outage
+	Services    []StatusService  `json:"services"`
+	Incidents   []StatusIncident `json:"incidents"` // 进行中的故障
+	History     []StatusIncident `json:"history"`   // 最近已结束的故障，最新的在前
+	HistoryDays int              `json:"history_days"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// StatusService 公开服务的当前状态、各统计周期的可用率和每日可用率
+type StatusService struct {
+	ID        uint           `json:"id"`
+	Name      string         `json:"name"`
+	Status    string         `json:"status"` // running, warning, error
+	LastCheck time.Time      `json:"last_check"`
+	Uptime    []UptimePeriod `json:"uptime"`
+	Days      []StatusDay    `json:"days"` // 从早到晚
+}
+
+// StatusDay 一天的检查次数和可用率
+type StatusDay struct {
+	Date         string   `json:"date"`
+	Checks       int64    `json:"checks"`
+	Availability *float64 `json:"availability"` // 当天没有检查记录时为null
+}
+
+// StatusIncident 一次故障：服务连续检查失败的时间段
+type StatusIncident struct {
+	Service  string     `json:"service"`
+	Start    time.Time  `json:"start"`
+	End      *time.Time `json:"end"`      // 恢复时间，进行中时为null
+	Duration int64      `json:"duration"` // 持续时间（秒），进行中时计算到当前
+}
+
+var (
+	statusPageMu    sync.Mutex
+	statusPageCache *StatusPage
+)
+
+// GetStatusPage 获取公开状态页的数据，结果缓存一分钟，修改服务的公开设置后最多一分钟生效
+func GetStatusPage() (*StatusPage, error) {
+	statusPageMu.Lock()
+	defer statusPageMu.Unlock()
+
+	cfg := config.AppConfig.StatusPage
+	if page := statusPageCache; page != nil && time.Since(page.UpdatedAt) < statusPageTTL &&
+		page.Title == cfg.Title && page.HistoryDays == cfg.HistoryDays {
+		return page, nil
+	}
+
+	page, err := buildStatusPage(cfg)
+	if err != nil {
+		return nil, err
+	}
+	statusPageCache = page
+	return page, nil
+}
+
+// buildStatusPage 根据公开服务的检查历史计算状态页数据
+func buildStatusPage(cfg config.StatusPageConfig) (*StatusPage, error) {
+	var services []models.ServiceStatus
+	if err := database.DB.Where("public = ?", true).Order("name").Find(&services).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(config.AppConfig.I18n.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := today.AddDate(0, 0, 1-cfg.HistoryDays)
+
+	page := &StatusPage{
+		Title:       cfg.Title,
+		Status:      "operational",
+		Services:    make([]StatusService, 0, len(services)),
+		Incidents:   []StatusIncident{},
+		History:     []StatusIncident{},
+		HistoryDays: cfg.HistoryDays,
+		UpdatedAt:   now,
+	}
+	for _, service := range services {
+		uptime, err := GetServiceUptime(service)
+		if err != nil {
+			return nil, err
+		}
+		days, incidents, err := serviceHistory(service, start, now)
+		if err != nil {
+			return nil, err
+		}
+
+		page.Services = append(page.Services, StatusService{
+			ID:        service.ID,
+			Name:      service.Name,
+			Status:    service.Status,
+			LastCheck: service.LastCheck,
+			Uptime:    uptime.Periods,
+			Days:      days,
+		})
+		for _, incident := range incidents {
+			if incident.End == nil {
+				page.Incidents = append(page.Incidents, incident)
+			} else {
+				page.History = append(page.History, incident)
+			}
+		}
+
+		switch {
+		case service.Status == "error":
+			page.Status = "outage"
+		case service.Status == "warning" && page.Status == "operational":
+			page.Status = "degraded"
+		}
+	}
+
+	// 已结束的故障按开始时间倒序
+	sort.Slice(page.History, func(i, j int) bool { return page.History[i].Start.After(page.History[j].Start) })
+	if len(page.History) > maxStatusIncidents {
+		page.History = page.History[:maxStatusIncidents]
+	}
+	return page, nil
+}
+
+// serviceHistory 按时间顺序扫描服务在[start, end)内的检查结果，统计每日可用率，
+// 并把连续的失败检查合并为故障：从第一次失败开始，到之后第一次成功的检查结束
+func serviceHistory(service models.ServiceStatus, start, end time.Time) ([]StatusDay, []StatusIncident, error) {
+	days := []StatusDay{}
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, StatusDay{Date: day.Format("2006-01-02")})
+	}
+	upChecks := make([]int64, len(days))
+
+	rows, err := database.DB.Model(&models.ServiceCheckResult{}).
+		Select("status, timestamp").
+		Where("service_id = ? AND timestamp >= ? AND timestamp < ?", service.ID, start, end).
+		Where("state_type <> ?", "soft").
+		Order("timestamp").
+		Rows()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	incidents := []StatusIncident{}
+	var current *StatusIncident
+	for rows.Next() {
+		var result models.ServiceCheckResult
+		if err := database.DB.ScanRows(rows, &result); err != nil {
+			return nil, nil, err
+		}
+
+		t := result.Timestamp.In(start.Location())
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		if i := int(day.Sub(start).Hours()/24 + 0.5); i >= 0 && i < len(days) {
+			days[i].Checks++
+			if result.Status != "error" {
+				upChecks[i]++
+			}
+		}
+
+		switch {
+		case result.Status == "error" && current == nil:
+			current = &StatusIncident{Service: service.Name, Start: result.Timestamp}
+		case result.Status != "error" && current != nil:
+			recovered := result.Timestamp
+			current.End = &recovered
+			current.Duration = int64(recovered.Sub(current.Start).Seconds())
+			incidents = append(incidents, *current)
+			current = nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if current != nil {
+		current.Duration = int64(end.Sub(current.Start).Seconds())
+		incidents = append(incidents, *current)
+	}
+
+	for i := range days {
+		if days[i].Checks > 0 {
+			availability := float64(upChecks[i]) / float64(days[i].Checks) * 100
+			days[i].Availability = &availability
+		}
+	}
+	return days, incidents, nil
+}
@@ -1,430 +1,515 @@
-package monitor
-
-import (
-	"fmt"
-	"log"
-	"math"
-	"server-monitor/config"
-	"server-monitor/database"
-	"server-monitor/models"
-	"time"
-
-	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/disk"
-	"github.com/shirou/gopsutil/v3/mem"
-	"github.com/shirou/gopsutil/v3/net"
-)
-
-type SystemMonitor struct {
-	lastNetworkStats map[string]net.IOCountersStat
-	lastNetworkTime  time.Time
-}
-
-// NewSystemMonitor 创建系统监控实例
-func NewSystemMonitor() *SystemMonitor {
-	return &SystemMonitor{
-		lastNetworkStats: make(map[string]net.IOCountersStat),
-		lastNetworkTime:  time.Now(),
-	}
-}
-
-// CollectSystemMetrics 收集系统指标
-func (sm *SystemMonitor) CollectSystemMetrics() (*models.SystemMetrics, error) {
-	metrics := &models.SystemMetrics{
-		Timestamp: time.Now(),
-	}
-
-	// 收集CPU使用率
-	cpuPercent, err := cpu.Percent(0, false)
-	if err != nil {
-		log.Printf("Error collecting CPU metrics: %v", err)
-		metrics.CPU = 0
-	} else if len(cpuPercent) > 0 {
-		metrics.CPU = math.Round(cpuPercent[0]*100) / 100
-	}
-
-	// 收集内存使用率
-	memory, err := mem.VirtualMemory()
-	if err != nil {
-		log.Printf("Error collecting memory metrics: %v", err)
-		metrics.Memory = 0
-	} else {
-		metrics.Memory = math.Round(memory.UsedPercent*100) / 100
-	}
-
-	// 收集磁盘使用率
-	partitions, err := disk.Partitions(false)
-	if err != nil {
-		log.Printf("Error collecting disk metrics: %v", err)
-		metrics.Disk = 0
-	} else {
-		var totalUsage float64
-		var partitionCount int
-		
-		for _, partition := range partitions {
-			usage, err := disk.Usage(partition.Mountpoint)
-			if err != nil {
-				continue
-			}
-			totalUsage += usage.UsedPercent
-			partitionCount++
-		}
-		
-		if partitionCount > 0 {
-			metrics.Disk = math.Round((totalUsage/float64(partitionCount))*100) / 100
-		}
-	}
-
-	// 收集网络流量
-	uploadSpeed, downloadSpeed, err := sm.getNetworkSpeed()
-	if err != nil {
-		log.Printf("Error collecting network metrics: %v", err)
-		metrics.Upload = 0
-		metrics.Download = 0
-	} else {
-		metrics.Upload = uploadSpeed
-		metrics.Download = downloadSpeed
-	}
-
-	return metrics, nil
-}
-
-// getNetworkSpeed 获取网络速度
-func (sm *SystemMonitor) getNetworkSpeed() (float64, float64, error) {
-	netStats, err := net.IOCounters(false)
-	if err != nil {
-		return 0, 0, err
-	}
-
-	now := time.Now()
-	timeDiff := now.Sub(sm.lastNetworkTime).Seconds()
-
-	if timeDiff == 0 {
-		return 0, 0, fmt.Errorf("time difference is zero")
-	}
-
-	var totalUploadBytes uint64
-	var totalDownloadBytes uint64
-
-	for _, stat := range netStats {
-		if lastStat, exists := sm.lastNetworkStats[stat.Name]; exists {
-			uploadDiff := stat.BytesSent - lastStat.BytesSent
-			downloadDiff := stat.BytesRecv - lastStat.BytesRecv
-			
-			totalUploadBytes += uploadDiff
-			totalDownloadBytes += downloadDiff
-		}
-		sm.lastNetworkStats[stat.Name] = stat
-	}
-
-	// 转换为MB/s
-	uploadSpeed := float64(totalUploadBytes) / (1024 * 1024 * timeDiff)
-	downloadSpeed := float64(totalDownloadBytes) / (1024 * 1024 * timeDiff)
-
-	sm.lastNetworkTime = now
-
-	return math.Round(uploadSpeed*100) / 100, math.Round(downloadSpeed*100) / 100, nil
-}
-
-// CollectDiskUsage 收集磁盘使用情况
-func (sm *SystemMonitor) CollectDiskUsage() ([]models.DiskUsage, error) {
-	partitions, err := disk.Partitions(false)
-	if err != nil {
-		return nil, err
-	}
-
-	var diskUsages []models.DiskUsage
-	now := time.Now()
-
-	for _, partition := range partitions {
-		usage, err := disk.Usage(partition.Mountpoint)
-		if err != nil {
-			continue
-		}
-
-		diskUsage := models.DiskUsage{
-			Path:      partition.Mountpoint,
-			Name:      partition.Device,
-			Total:     usage.Total / (1024 * 1024 * 1024), // 转换为GB
-			Used:      usage.Used / (1024 * 1024 * 1024),  // 转换为GB
-			Free:      usage.Free / (1024 * 1024 * 1024),  // 转换为GB
-			Usage:     math.Round(usage.UsedPercent*100) / 100,
-			Timestamp: now,
-		}
-
-		diskUsages = append(diskUsages, diskUsage)
-	}
-
-	return diskUsages, nil
-}
-
-// CollectNetworkTraffic 收集网络流量数据
-func (sm *SystemMonitor) CollectNetworkTraffic() ([]models.NetworkTraffic, error) {
-	netStats, err := net.IOCounters(true)
-	if err != nil {
-		return nil, err
-	}
-
-	var networkTraffic []models.NetworkTraffic
-	now := time.Now()
-
-	for _, stat := range netStats {
-		// 计算速度
-		var uploadSpeed, downloadSpeed float64
-		if lastStat, exists := sm.lastNetworkStats[stat.Name]; exists {
-			timeDiff := now.Sub(sm.lastNetworkTime).Seconds()
-			if timeDiff > 0 {
-				uploadDiff := stat.BytesSent - lastStat.BytesSent
-				downloadDiff := stat.BytesRecv - lastStat.BytesRecv
-				
-				uploadSpeed = float64(uploadDiff) / (1024 * 1024 * timeDiff)
-				downloadSpeed = float64(downloadDiff) / (1024 * 1024 * timeDiff)
-			}
-		}
-
-		traffic := models.NetworkTraffic{
-			Interface:      stat.Name,
-			Upload:         stat.BytesSent,
-			Download:       stat.BytesRecv,
-			UploadSpeed:    math.Round(uploadSpeed*100) / 100,
-			DownloadSpeed:  math.Round(downloadSpeed*100) / 100,
-			Timestamp:      now,
-		}
-
-		networkTraffic = append(networkTraffic, traffic)
-	}
-
-	return networkTraffic, nil
-}
-
-// SaveMetrics 保存监控指标到数据库
-func (sm *SystemMonitor) SaveMetrics(metrics *models.SystemMetrics) error {
-	return database.DB.Create(metrics).Error
-}
-
-// SaveDiskUsage 保存磁盘使用情况
-func (sm *SystemMonitor) SaveDiskUsage(diskUsages []models.DiskUsage) error {
-	for _, usage := range diskUsages {
-		if err := database.DB.Create(&usage).Error; err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// SaveNetworkTraffic 保存网络流量数据
-func (sm *SystemMonitor) SaveNetworkTraffic(traffic []models.NetworkTraffic) error {
-	for _, t := range traffic {
-		if err := database.DB.Create(&t).Error; err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// CheckAlerts 检查告警
-func (sm *SystemMonitor) CheckAlerts(metrics *models.SystemMetrics) error {
-	// 检查CPU告警
-	if metrics.CPU > float64(config.AppConfig.Monitor.AlertCPU) {
-		// 检查是否已有活跃的CPU告警
-		var existingAlert models.Alert
-		result := database.DB.Where("type = ? AND status = ?", "cpu", "active").First(&existingAlert)
-		
-		if result.Error != nil {
-			// 没有活跃告警，创建新的
-			alert := models.Alert{
-				Type:      "cpu",
-				Level:     "warning",
-				Message:   fmt.Sprintf("CPU使用率过高: %.2f%%", metrics.CPU),
-				Value:     metrics.CPU,
-				Threshold: float64(config.AppConfig.Monitor.AlertCPU),
-				Status:    "active",
-				Timestamp: time.Now(),
-			}
-			database.DB.Create(&alert)
-			
-			// 同时创建系统日志
-			systemLog := models.SystemLog{
-				Level:     "warning",
-				Category:  "system",
-				Message:   fmt.Sprintf("CPU使用率过高: %.2f%%", metrics.CPU),
-				Timestamp: time.Now(),
-			}
-			database.DB.Create(&systemLog)
-		} else {
-			// 已有活跃告警，只更新值
-			existingAlert.Value = metrics.CPU
-			existingAlert.Message = fmt.Sprintf("CPU使用率过高: %.2f%%", metrics.CPU)
-			existingAlert.UpdatedAt = time.Now()
-			database.DB.Save(&existingAlert)
-		}
-	} else {
-		// CPU使用率正常，如果有活跃告警则标记为已解决
-		var existingAlert models.Alert
-		if database.DB.Where("type = ? AND status = ?", "cpu", "active").First(&existingAlert).Error == nil {
-			existingAlert.Status = "resolved"
-			existingAlert.UpdatedAt = time.Now()
-			database.DB.Save(&existingAlert)
-			
-			// 创建解决日志
-			systemLog := models.SystemLog{
-				Level:     "info",
-				Category:  "system",
-				Message:   fmt.Sprintf("CPU使用率恢复正常: %.2f%%", metrics.CPU),
-				Timestamp: time.Now(),
-			}
-			database.DB.Create(&systemLog)
-		}
-	}
-
-	// 检查内存告警
-	if metrics.Memory > float64(config.AppConfig.Monitor.AlertMemory) {
-		// 检查是否已有活跃的内存告警
-		var existingAlert models.Alert
-		result := database.DB.Where("type = ? AND status = ?", "memory", "active").First(&existingAlert)
-		
-		if result.Error != nil {
-			// 没有活跃告警，创建新的
-			alert := models.Alert{
-				Type:      "memory",
-				Level:     "warning",
-				Message:   fmt.Sprintf("内存使用率过高: %.2f%%", metrics.Memory),
-				Value:     metrics.Memory,
-				Threshold: float64(config.AppConfig.Monitor.AlertMemory),
-				Status:    "active",
-				Timestamp: time.Now(),
-			}
-			database.DB.Create(&alert)
-			
-			// 同时创建系统日志
-			systemLog := models.SystemLog{
-				Level:     "warning",
-				Category:  "system",
-				Message:   fmt.Sprintf("内存使用率过高: %.2f%%", metrics.Memory),
-				Timestamp: time.Now(),
-			}
-			database.DB.Create(&systemLog)
-		} else {
-			// 已有活跃告警，只更新值
-			existingAlert.Value = metrics.Memory
-			existingAlert.Message = fmt.Sprintf("内存使用率过高: %.2f%%", metrics.Memory)
-			existingAlert.UpdatedAt = time.Now()
-			database.DB.Save(&existingAlert)
-		}
-	} else {
-		// 内存使用率正常，如果有活跃告警则标记为已解决
-		var existingAlert models.Alert
-		if database.DB.Where("type = ? AND status = ?", "memory", "active").First(&existingAlert).Error == nil {
-			existingAlert.Status = "resolved"
-			existingAlert.UpdatedAt = time.Now()
-			database.DB.Save(&existingAlert)
-			
-			// 创建解决日志
-			systemLog := models.SystemLog{
-				Level:     "info",
-				Category:  "system",
-				Message:   fmt.Sprintf("内存使用率恢复正常: %.2f%%", metrics.Memory),
-				Timestamp: time.Now(),
-			}
-			database.DB.Create(&systemLog)
-		}
-	}
-
-	// 检查磁盘告警
-	if metrics.Disk > float64(config.AppConfig.Monitor.AlertDisk) {
-		// 检查是否已有活跃的磁盘告警
-		var existingAlert models.Alert
-		result := database.DB.Where("type = ? AND status = ?", "disk", "active").First(&existingAlert)
-		
-		if result.Error != nil {
-			// 没有活跃告警，创建新的
-			alert := models.Alert{
-				Type:      "disk",
-				Level:     "warning",
-				Message:   fmt.Sprintf("磁盘使用率过高: %.2f%%", metrics.Disk),
-				Value:     metrics.Disk,
-				Threshold: float64(config.AppConfig.Monitor.AlertDisk),
-				Status:    "active",
-				Timestamp: time.Now(),
-			}
-			database.DB.Create(&alert)
-			
-			// 同时创建系统日志
-			systemLog := models.SystemLog{
-				Level:     "warning",
-				Category:  "system",
-				Message:   fmt.Sprintf("磁盘使用率过高: %.2f%%", metrics.Disk),
-				Timestamp: time.Now(),
-			}
-			database.DB.Create(&systemLog)
-		} else {
-			// 已有活跃告警，只更新值
-			existingAlert.Value = metrics.Disk
-			existingAlert.Message = fmt.Sprintf("磁盘使用率过高: %.2f%%", metrics.Disk)
-			existingAlert.UpdatedAt = time.Now()
-			database.DB.Save(&existingAlert)
-		}
-	} else {
-		// 磁盘使用率正常，如果有活跃告警则标记为已解决
-		var existingAlert models.Alert
-		if database.DB.Where("type = ? AND status = ?", "disk", "active").First(&existingAlert).Error == nil {
-			existingAlert.Status = "resolved"
-			existingAlert.UpdatedAt = time.Now()
-			database.DB.Save(&existingAlert)
-			
-			// 创建解决日志
-			systemLog := models.SystemLog{
-				Level:     "info",
-				Category:  "system",
-				Message:   fmt.Sprintf("磁盘使用率恢复正常: %.2f%%", metrics.Disk),
-				Timestamp: time.Now(),
-			}
-			database.DB.Create(&systemLog)
-		}
-	}
-
-	return nil
-}
-
-// HardwareInfo 结构体
-type HardwareInfo struct {
-	CPUModel   string  `json:"cpu_model"`
-	CPUCores   int     `json:"cpu_cores"`
-	CPUThreads int     `json:"cpu_threads"`
-	CPUFreq    float64 `json:"cpu_freq"`
-	MemorySize string  `json:"memory_size"`
-	MemoryType string  `json:"memory_type"`
-	MemorySpeed string `json:"memory_speed"`
-	DiskModel  string  `json:"disk_model"`
-	DiskSize   string  `json:"disk_size"`
-	DiskType   string  `json:"disk_type"`
-}
-
-// GetHardwareInfo 采集硬件信息
-func GetHardwareInfo() (*HardwareInfo, error) {
-	info := &HardwareInfo{}
-	// CPU信息
-	cpuInfos, err := cpu.Info()
-	if err == nil && len(cpuInfos) > 0 {
-		info.CPUModel = cpuInfos[0].ModelName
-		info.CPUCores = int(cpuInfos[0].Cores)
-		info.CPUThreads = len(cpuInfos)
-		info.CPUFreq = cpuInfos[0].Mhz
-	}
-	// 内存信息
-	mem, err := mem.VirtualMemory()
-	if err == nil {
-		info.MemorySize = fmt.Sprintf("%.0fGB", float64(mem.Total)/1024/1024/1024)
-		info.MemoryType = "N/A" // gopsutil不支持
-		info.MemorySpeed = "N/A"
-	}
-	// 磁盘信息
-	disks, err := disk.Partitions(false)
-	if err == nil && len(disks) > 0 {
-		usage, _ := disk.Usage(disks[0].Mountpoint)
-		info.DiskModel = disks[0].Device
-		info.DiskSize = fmt.Sprintf("%.0fGB", float64(usage.Total)/1024/1024/1024)
-		info.DiskType = "N/A"
-	}
-	return info, nil
-} 
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+type SystemMonitor struct {
+	// 系统指标和网络流量的采集间隔不同，各自保存上一次的网卡计数器作为计算速度的基准
+	mu          sync.Mutex
+	speedBase   networkBaseline
+	trafficBase networkBaseline
+	cpuTimes    *cpu.TimesStat // 上一次采集的CPU累计时间，用于计算各类CPU时间的占比
+}
+
+// networkBaseline 各网卡上一次采集的计数器，网卡接入、移除或计数器归零时重新建立基准
+type networkBaseline struct {
+	stats map[string]net.IOCountersStat
+	time  time.Time
+}
+
+// interfaceSpeed 网卡在两次采集之间的速度（MB/s）
+type interfaceSpeed struct {
+	upload   float64
+	download float64
+}
+
+// NewSystemMonitor 创建系统监控实例
+func NewSystemMonitor() *SystemMonitor {
+	return &SystemMonitor{}
+}
+
+// update 以本次采集的计数器更新基准，返回与上次都存在的网卡的速度，以及新出现和已消失的网卡。
+// 新网卡本次只建立基准；计数器变小（网卡重置、被删除后以同名重建如VPN重连、32位计数器回绕）时
+// 该方向的速度记为0并以新值作为基准，不会因无符号数相减下溢而得到极大的速度
+func (b *networkBaseline) update(stats []net.IOCountersStat, now time.Time) (map[string]interfaceSpeed, []string, []string) {
+	speeds := make(map[string]interfaceSpeed, len(stats))
+	current := make(map[string]net.IOCountersStat, len(stats))
+	var added, removed []string
+
+	elapsed := now.Sub(b.time).Seconds()
+	for _, stat := range stats {
+		current[stat.Name] = stat
+		last, exists := b.stats[stat.Name]
+		if !exists {
+			added = append(added, stat.Name)
+			continue
+		}
+		if elapsed <= 0 {
+			continue
+		}
+		sent, sentOK := counterDelta(stat.BytesSent, last.BytesSent)
+		recv, recvOK := counterDelta(stat.BytesRecv, last.BytesRecv)
+		if !sentOK || !recvOK {
+			log.Printf("Network counters of %s decreased (sent %d -> %d, recv %d -> %d), treating as reset",
+				stat.Name, last.BytesSent, stat.BytesSent, last.BytesRecv, stat.BytesRecv)
+		}
+		speeds[stat.Name] = interfaceSpeed{
+			upload:   float64(sent) / (1024 * 1024 * elapsed),
+			download: float64(recv) / (1024 * 1024 * elapsed),
+		}
+	}
+	for name := range b.stats {
+		if _, exists := current[name]; !exists {
+			removed = append(removed, name)
+		}
+	}
+
+	// 已消失的网卡不再保留在基准中
+	b.stats = current
+	b.time = now
+	sort.Strings(added)
+	sort.Strings(removed)
+	return speeds, added, removed
+}
+
+// counterDelta 计算累计计数器两次采集之间的增量；计数器变小说明发生了重置或回绕，
+// 此时无法得知期间的真实流量，增量记为0并返回false
+func counterDelta(current, last uint64) (uint64, bool) {
+	if current < last {
+		return 0, false
+	}
+	return current - last, true
+}
+
+// CollectSystemMetrics 收集系统指标
+func (sm *SystemMonitor) CollectSystemMetrics() (*models.SystemMetrics, error) {
+	metrics := &models.SystemMetrics{
+		Timestamp: time.Now(),
+	}
+
+	// 收集CPU使用率
+	cpuPercent, err := cpu.Percent(0, false)
+	if err != nil {
+		log.Printf("Error collecting CPU metrics: %v", err)
+		metrics.CPU = 0
+	} else if len(cpuPercent) > 0 {
+		metrics.CPU = math.Round(cpuPercent[0]*100) / 100
+	}
+
+	// 收集CPU时间分布：用户态、内核态、IO等待和被宿主机占用（steal）的时间
+	if times, err := cpu.Times(false); err != nil {
+		log.Printf("Error collecting CPU times: %v", err)
+	} else if len(times) > 0 {
+		sm.mu.Lock()
+		last := sm.cpuTimes
+		sm.cpuTimes = &times[0]
+		sm.mu.Unlock()
+		if last != nil {
+			setCPUBreakdown(metrics, last, &times[0])
+		}
+	}
+
+	// 收集内存使用率
+	memory, err := mem.VirtualMemory()
+	if err != nil {
+		log.Printf("Error collecting memory metrics: %v", err)
+		metrics.Memory = 0
+	} else {
+		metrics.Memory = math.Round(memory.UsedPercent*100) / 100
+		metrics.MemTotal = megabytes(memory.Total)
+		metrics.MemAvailable = megabytes(memory.Available)
+		metrics.MemUsed = megabytes(memory.Used)
+		metrics.MemCached = megabytes(memory.Cached)
+		metrics.MemBuffers = megabytes(memory.Buffers)
+	}
+
+	// 收集磁盘使用率
+	partitions, err := collectedPartitions()
+	if err != nil {
+		log.Printf("Error collecting disk metrics: %v", err)
+		metrics.Disk = 0
+	} else {
+		metrics.Disk = diskUsagePercent(partitions)
+	}
+
+	// 收集网络流量
+	uploadSpeed, downloadSpeed, err := sm.getNetworkSpeed()
+	if err != nil {
+		log.Printf("Error collecting network metrics: %v", err)
+		metrics.Upload = 0
+		metrics.Download = 0
+	} else {
+		metrics.Upload = uploadSpeed
+		metrics.Download = downloadSpeed
+	}
+
+	return metrics, nil
+}
+
+// megabytes 字节数转换为MB，保留两位小数
+func megabytes(bytes uint64) float64 {
+	return math.Round(float64(bytes)/(1024*1024)*100) / 100
+}
+
+// memoryAlertValue 按 monitor.alert_memory_basis 计算用于内存告警的使用率：
+// used 为不含缓存的已用内存占比，available 为不可用内存（总量减去内核估算的可用内存）占比，
+// with_cache 把缓存和缓冲区也算作已用
+func memoryAlertValue(metrics *models.SystemMetrics) (float64, string) {
+	if metrics.MemTotal <= 0 {
+		return metrics.Memory, i18n.Sprintf("内存使用率")
+	}
+	switch config.AppConfig.Monitor.AlertMemoryBasis {
+	case "available":
+		return math.Round((metrics.MemTotal-metrics.MemAvailable)/metrics.MemTotal*10000) / 100, i18n.Sprintf("内存使用率（按可用内存）")
+	case "with_cache":
+		used := metrics.MemUsed + metrics.MemCached + metrics.MemBuffers
+		return math.Round(used/metrics.MemTotal*10000) / 100, i18n.Sprintf("内存使用率（含缓存）")
+	default:
+		return metrics.Memory, i18n.Sprintf("内存使用率")
+	}
+}
+
+// setCPUBreakdown 按两次采集之间的CPU累计时间增量计算各类时间的占比，首次采集没有基准时保持为0。
+// steal较高说明VPS所在宿主机超售，iowait较高说明负载受磁盘限制而非计算
+func setCPUBreakdown(metrics *models.SystemMetrics, last, current *cpu.TimesStat) {
+	// Linux的user时间已包含guest时间，不使用会重复计算guest的Total()
+	sum := func(t *cpu.TimesStat) float64 {
+		return t.User + t.Nice + t.System + t.Idle + t.Iowait + t.Irq + t.Softirq + t.Steal
+	}
+	total := sum(current) - sum(last)
+	if total <= 0 {
+		return
+	}
+	percent := func(current, last float64) float64 {
+		delta := current - last
+		if delta < 0 {
+			delta = 0
+		}
+		return math.Round(delta/total*10000) / 100
+	}
+	metrics.CPUUser = percent(current.User+current.Nice, last.User+last.Nice)
+	metrics.CPUSystem = percent(current.System+current.Irq+current.Softirq, last.System+last.Irq+last.Softirq)
+	metrics.CPUIowait = percent(current.Iowait, last.Iowait)
+	metrics.CPUSteal = percent(current.Steal, last.Steal)
+}
+
+// collectedPartitions 获取物理分区，跳过 monitor.filesystems 过滤掉的分区（挂载点、设备或文件系统类型匹配）
+func collectedPartitions() ([]disk.PartitionStat, error) {
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := config.AppConfig.Monitor.Filesystems
+	result := partitions[:0]
+	for _, partition := range partitions {
+		if filter.Allows(partition.Mountpoint, partition.Device, partition.Fstype) {
+			result = append(result, partition)
+		}
+	}
+	return result, nil
+}
+
+// collectedInterfaces 获取各网卡的计数器，跳过 monitor.interfaces 过滤掉的网卡
+func collectedInterfaces() ([]net.IOCountersStat, error) {
+	stats, err := net.IOCounters(true)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := config.AppConfig.Monitor.Interfaces
+	result := stats[:0]
+	for _, stat := range stats {
+		if filter.Allows(stat.Name) {
+			result = append(result, stat)
+		}
+	}
+	return result, nil
+}
+
+// diskUsagePercent 按容量加权计算整体磁盘使用率：所有真实文件系统的已用空间之和除以容量之和，
+// 很小的分区（如/boot）写满不会大幅拉高整体数值；同一设备的多个挂载点（绑定挂载）只计算一次。
+// 与df一致，容量不含文件系统为root保留的空间；始终显示为已满的只读镜像（squashfs）默认已被 monitor.filesystems 过滤
+func diskUsagePercent(partitions []disk.PartitionStat) float64 {
+	devices := make(map[string]bool, len(partitions))
+	var used, capacity uint64
+	for _, partition := range partitions {
+		if devices[partition.Device] {
+			continue
+		}
+		usage, err := disk.Usage(partition.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			continue
+		}
+		devices[partition.Device] = true
+		used += usage.Used
+		capacity += usage.Used + usage.Free
+	}
+
+	if capacity == 0 {
+		return 0
+	}
+	return math.Round(float64(used)/float64(capacity)*10000) / 100
+}
+
+// getNetworkSpeed 获取网络速度：各网卡速度之和，接入或移除网卡时总计数器的跳变不会被算作流量
+func (sm *SystemMonitor) getNetworkSpeed() (float64, float64, error) {
+	netStats, err := collectedInterfaces()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sm.mu.Lock()
+	speeds, _, _ := sm.speedBase.update(netStats, time.Now())
+	sm.mu.Unlock()
+
+	var uploadSpeed, downloadSpeed float64
+	for _, speed := range speeds {
+		uploadSpeed += speed.upload
+		downloadSpeed += speed.download
+	}
+	return math.Round(uploadSpeed*100) / 100, math.Round(downloadSpeed*100) / 100, nil
+}
+
+// CollectDiskUsage 收集磁盘使用情况
+func (sm *SystemMonitor) CollectDiskUsage() ([]models.DiskUsage, error) {
+	partitions, err := collectedPartitions()
+	if err != nil {
+		return nil, err
+	}
+
+	var diskUsages []models.DiskUsage
+	now := time.Now()
+
+	for _, partition := range partitions {
+		usage, err := disk.Usage(partition.Mountpoint)
+		if err != nil {
+			continue
+		}
+
+		diskUsage := models.DiskUsage{
+			Path:      partition.Mountpoint,
+			Name:      partition.Device,
+			Total:     usage.Total / (1024 * 1024 * 1024), // 转换为GB
+			Used:      usage.Used / (1024 * 1024 * 1024),  // 转换为GB
+			Free:      usage.Free / (1024 * 1024 * 1024),  // 转换为GB
+			Usage:     math.Round(usage.UsedPercent*100) / 100,
+			Timestamp: now,
+		}
+
+		diskUsages = append(diskUsages, diskUsage)
+	}
+
+	return diskUsages, nil
+}
+
+// CollectNetworkTraffic 收集网络流量数据
+func (sm *SystemMonitor) CollectNetworkTraffic() ([]models.NetworkTraffic, error) {
+	netStats, err := collectedInterfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var networkTraffic []models.NetworkTraffic
+	now := time.Now()
+
+	sm.mu.Lock()
+	first := sm.trafficBase.stats == nil
+	speeds, added, removed := sm.trafficBase.update(netStats, now)
+	sm.mu.Unlock()
+
+	// 首次采集时所有网卡都是新出现的，不视为接入
+	if !first {
+		recordInterfaceChanges(added, removed, now)
+	}
+
+	for _, stat := range netStats {
+		speed := speeds[stat.Name]
+		traffic := models.NetworkTraffic{
+			Interface:      stat.Name,
+			Upload:         stat.BytesSent,
+			Download:       stat.BytesRecv,
+			UploadSpeed:    math.Round(speed.upload*100) / 100,
+			DownloadSpeed:  math.Round(speed.download*100) / 100,
+			Timestamp:      now,
+		}
+
+		networkTraffic = append(networkTraffic, traffic)
+	}
+
+	return networkTraffic, nil
+}
+
+// recordInterfaceChanges 记录网卡接入和移除（如USB网卡、VPN隧道、Docker网桥），写入图表注释和系统日志；
+// monitor.ignore_interfaces 中前缀匹配的网卡（默认veth，随容器频繁创建删除）不记录
+func recordInterfaceChanges(added, removed []string, now time.Time) {
+	events := []struct {
+		format, event string
+		names         []string
+	}{
+		{"网卡接入: %s", "added", filterInterfaces(added)},
+		{"网卡移除: %s", "removed", filterInterfaces(removed)},
+	}
+	for _, e := range events {
+		if len(e.names) == 0 {
+			continue
+		}
+		message := i18n.Sprintf(e.format, strings.Join(e.names, ", "))
+		log.Printf("Network interfaces %s: %s", e.event, strings.Join(e.names, ", "))
+
+		database.DB.Create(&models.Annotation{
+			Type:      "interface",
+			Message:   message,
+			StartTime: now,
+			EndTime:   now,
+			Timestamp: now,
+		})
+		database.DB.Create(&models.SystemLog{
+			Level:     "info",
+			Category:  "network",
+			Message:   message,
+			Timestamp: now,
+		})
+	}
+}
+
+// filterInterfaces 去掉不记录接入、移除事件的网卡
+func filterInterfaces(names []string) []string {
+	var result []string
+	for _, name := range names {
+		ignored := false
+		for _, prefix := range config.AppConfig.Monitor.IgnoreInterfaces {
+			if prefix != "" && strings.HasPrefix(name, prefix) {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// SaveMetrics 保存监控指标到数据库
+func (sm *SystemMonitor) SaveMetrics(metrics *models.SystemMetrics) error {
+	if err := database.DB.Create(metrics).Error; err != nil {
+		return err
+	}
+	registerSourceSeries("system_metrics", nil)
+	return nil
+}
+
+// SaveDiskUsage 保存磁盘使用情况
+func (sm *SystemMonitor) SaveDiskUsage(diskUsages []models.DiskUsage) error {
+	for _, usage := range diskUsages {
+		if err := database.DB.Create(&usage).Error; err != nil {
+			return err
+		}
+		registerSourceSeries("disk_usage", map[string]string{"path": usage.Path, "name": usage.Name})
+	}
+	return nil
+}
+
+// SaveNetworkTraffic 保存网络流量数据
+func (sm *SystemMonitor) SaveNetworkTraffic(traffic []models.NetworkTraffic) error {
+	for _, t := range traffic {
+		if err := database.DB.Create(&t).Error; err != nil {
+			return err
+		}
+		registerSourceSeries("network_traffic", map[string]string{"interface": t.Interface})
+	}
+	return nil
+}
+
+// CheckAlerts 检查告警
+func (sm *SystemMonitor) CheckAlerts(metrics *models.SystemMetrics) error {
+	// 检查CPU告警
+	if metrics.CPU > float64(config.AppConfig.Monitor.AlertCPU) {
+		raiseAlert("cpu", "warning", "system", i18n.Sprintf("CPU使用率过高: %.2f%%", metrics.CPU),
+			metrics.CPU, float64(config.AppConfig.Monitor.AlertCPU))
+	} else {
+		// CPU使用率正常，如果有活跃告警则标记为已解决
+		resolveAlert("cpu", "system", i18n.Sprintf("CPU使用率恢复正常: %.2f%%", metrics.CPU))
+	}
+
+	// 检查内存告警
+	memory, label := memoryAlertValue(metrics)
+	if memory > float64(config.AppConfig.Monitor.AlertMemory) {
+		raiseAlert("memory", "warning", "system", i18n.Sprintf("%s过高: %.2f%%", label, memory),
+			memory, float64(config.AppConfig.Monitor.AlertMemory))
+	} else {
+		// 内存使用率正常，如果有活跃告警则标记为已解决
+		resolveAlert("memory", "system", i18n.Sprintf("%s恢复正常: %.2f%%", label, memory))
+	}
+
+	// 检查磁盘告警
+	if metrics.Disk > float64(config.AppConfig.Monitor.AlertDisk) {
+		raiseAlert("disk", "warning", "system", i18n.Sprintf("磁盘使用率过高: %.2f%%", metrics.Disk),
+			metrics.Disk, float64(config.AppConfig.Monitor.AlertDisk))
+	} else {
+		// 磁盘使用率正常，如果有活跃告警则标记为已解决
+		resolveAlert("disk", "system", i18n.Sprintf("磁盘使用率恢复正常: %.2f%%", metrics.Disk))
+	}
+
+	return nil
+}
+
+// HardwareInfo 结构体
+type HardwareInfo struct {
+	CPUModel   string  `json:"cpu_model"`
+	CPUCores   int     `json:"cpu_cores"`
+	CPUThreads int     `json:"cpu_threads"`
+	CPUFreq    float64 `json:"cpu_freq"`
+	MemorySize string  `json:"memory_size"`
+	MemoryType string  `json:"memory_type"`
+	MemorySpeed string `json:"memory_speed"`
+	DiskModel  string  `json:"disk_model"`
+	DiskSize   string  `json:"disk_size"`
+	DiskType   string  `json:"disk_type"`
+}
+
+// GetHardwareInfo 采集硬件信息
+func GetHardwareInfo() (*HardwareInfo, error) {
+	info := &HardwareInfo{}
+	// CPU信息
+	cpuInfos, err := cpu.Info()
+	if err == nil && len(cpuInfos) > 0 {
+		info.CPUModel = cpuInfos[0].ModelName
+		info.CPUCores = int(cpuInfos[0].Cores)
+		info.CPUThreads = len(cpuInfos)
+		info.CPUFreq = cpuInfos[0].Mhz
+	}
+	// 内存信息
+	mem, err := mem.VirtualMemory()
+	if err == nil {
+		info.MemorySize = fmt.Sprintf("%.0fGB", float64(mem.Total)/1024/1024/1024)
+		info.MemoryType = "N/A" // gopsutil不支持
+		info.MemorySpeed = "N/A"
+	}
+	// 磁盘信息
+	disks, err := disk.Partitions(false)
+	if err == nil && len(disks) > 0 {
+		usage, _ := disk.Usage(disks[0].Mountpoint)
+		info.DiskModel = disks[0].Device
+		info.DiskSize = fmt.Sprintf("%.0fGB", float64(usage.Total)/1024/1024/1024)
+		info.DiskType = "N/A"
+	}
+	return info, nil
+} 
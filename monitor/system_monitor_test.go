@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestNetworkBaselineUpdate(t *testing.T) {
+	const mb = 1024 * 1024
+	start := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name         string
+		last         *net.IOCountersStat // nil 表示首次采集
+		sent, recv   uint64
+		wantSpeed    bool
+		wantUpload   float64
+		wantDownload float64
+	}{
+		{
+			name:      "first sample only sets baseline",
+			sent:      10 * mb,
+			recv:      20 * mb,
+			wantSpeed: false,
+		},
+		{
+			name:         "normal increase",
+			last:         &net.IOCountersStat{Name: "eth0", BytesSent: 10 * mb, BytesRecv: 20 * mb},
+			sent:         30 * mb,
+			recv:         60 * mb,
+			wantSpeed:    true,
+			wantUpload:   2,
+			wantDownload: 4,
+		},
+		{
+			name:         "counter decrease after interface reset",
+			last:         &net.IOCountersStat{Name: "eth0", BytesSent: 500 * mb, BytesRecv: 900 * mb},
+			sent:         1 * mb,
+			recv:         950 * mb,
+			wantSpeed:    true,
+			wantUpload:   0,
+			wantDownload: 5,
+		},
+		{
+			name:         "wrap near MaxUint64 clamps to zero",
+			last:         &net.IOCountersStat{Name: "eth0", BytesSent: math.MaxUint64 - 10, BytesRecv: math.MaxUint64 - 1},
+			sent:         5,
+			recv:         0,
+			wantSpeed:    true,
+			wantUpload:   0,
+			wantDownload: 0,
+		},
+		{
+			name:         "unchanged counters",
+			last:         &net.IOCountersStat{Name: "eth0", BytesSent: math.MaxUint64, BytesRecv: math.MaxUint64},
+			sent:         math.MaxUint64,
+			recv:         math.MaxUint64,
+			wantSpeed:    true,
+			wantUpload:   0,
+			wantDownload: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b networkBaseline
+			if tt.last != nil {
+				b.update([]net.IOCountersStat{*tt.last}, start)
+			}
+
+			stat := net.IOCountersStat{Name: "eth0", BytesSent: tt.sent, BytesRecv: tt.recv}
+			speeds, added, removed := b.update([]net.IOCountersStat{stat}, start.Add(10*time.Second))
+
+			speed, ok := speeds["eth0"]
+			if ok != tt.wantSpeed {
+				t.Fatalf("speed reported = %v, want %v", ok, tt.wantSpeed)
+			}
+			if (tt.last == nil) != (len(added) == 1) {
+				t.Errorf("added = %v", added)
+			}
+			if len(removed) != 0 {
+				t.Errorf("removed = %v, want none", removed)
+			}
+			if speed.upload != tt.wantUpload || speed.download != tt.wantDownload {
+				t.Errorf("speed = %.4f/%.4f MB/s, want %.4f/%.4f", speed.upload, speed.download, tt.wantUpload, tt.wantDownload)
+			}
+			if b.stats["eth0"] != stat {
+				t.Errorf("baseline = %+v, want %+v", b.stats["eth0"], stat)
+			}
+		})
+	}
+}
+
+func TestNetworkBaselineRemovedInterface(t *testing.T) {
+	var b networkBaseline
+	now := time.Unix(1700000000, 0)
+	b.update([]net.IOCountersStat{{Name: "eth0"}, {Name: "wg0", BytesSent: 100}}, now)
+
+	speeds, added, removed := b.update([]net.IOCountersStat{{Name: "eth0", BytesSent: 1024 * 1024}}, now.Add(time.Second))
+	if len(added) != 0 || len(removed) != 1 || removed[0] != "wg0" {
+		t.Fatalf("added = %v, removed = %v, want removed [wg0]", added, removed)
+	}
+	if speeds["eth0"].upload != 1 {
+		t.Errorf("upload = %v MB/s, want 1", speeds["eth0"].upload)
+	}
+	if _, ok := b.stats["wg0"]; ok {
+		t.Error("removed interface kept in baseline")
+	}
+}
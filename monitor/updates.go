@@ -0,0 +1,240 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 系统日志和告警中软件包更新的分类
+const updatesCategory = "system"
+
+// 告警消息中最多列出的软件包数
+const maxAlertPackages = 10
+
+// Debian/Ubuntu安装需要重启的更新后创建的标记文件，.pkgs 文件中列出要求重启的软件包
+const rebootRequiredFile = "/var/run/reboot-required"
+
+// CheckUpdates 统计可更新的软件包和其中的安全更新，检测是否需要重启，保存检查结果
+func CheckUpdates() (*models.UpdateCheck, error) {
+	manager, err := packageManager(config.AppConfig.Updates.Manager)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	var security []string
+	if manager == "apt" {
+		pending, security, err = aptUpdates()
+	} else {
+		pending, security, err = rpmUpdates(manager)
+	}
+	if err != nil {
+		return nil, err
+	}
+	reboot, rebootPackages := rebootRequired(manager)
+
+	check := models.UpdateCheck{
+		Manager:          manager,
+		Pending:          len(pending),
+		Security:         len(security),
+		SecurityPackages: strings.Join(security, ","),
+		RebootRequired:   reboot,
+		RebootPackages:   strings.Join(rebootPackages, ","),
+		Timestamp:        time.Now(),
+	}
+	if err := database.DB.Create(&check).Error; err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+// CheckUpdateAlerts 按 updates.alert_* 配置对安全更新、需要重启和可更新的软件包数告警，条件消失后自动解除
+func CheckUpdateAlerts(check *models.UpdateCheck) {
+	cfg := config.AppConfig.Updates
+
+	if cfg.AlertSecurity && check.Security > 0 {
+		raiseAlert("security_updates", "warning", "security",
+			i18n.Sprintf("有 %d 个安全更新待安装: %s", check.Security, abbreviatePackages(check.SecurityPackages)),
+			float64(check.Security), 0)
+	} else {
+		resolveAlert("security_updates", "security", i18n.Sprintf("安全更新已全部安装"))
+	}
+
+	if cfg.AlertReboot && check.RebootRequired {
+		message := i18n.Sprintf("系统需要重启以完成更新")
+		if check.RebootPackages != "" {
+			message = i18n.Sprintf("系统需要重启以完成更新: %s", abbreviatePackages(check.RebootPackages))
+		}
+		raiseAlert("reboot_required", "warning", updatesCategory, message, 1, 0)
+	} else {
+		resolveAlert("reboot_required", updatesCategory, i18n.Sprintf("系统已重启，不再需要重启"))
+	}
+
+	if cfg.AlertPending > 0 && check.Pending >= cfg.AlertPending {
+		raiseAlert("updates_pending", "warning", updatesCategory,
+			i18n.Sprintf("有 %d 个软件包可更新", check.Pending),
+			float64(check.Pending), float64(cfg.AlertPending))
+	} else {
+		resolveAlert("updates_pending", updatesCategory, i18n.Sprintf("待更新的软件包数已恢复正常"))
+	}
+}
+
+// GetLatestUpdateCheck 最近一次软件包更新检查的结果，没有检查过时返回nil
+func GetLatestUpdateCheck() (*models.UpdateCheck, error) {
+	var checks []models.UpdateCheck
+	if err := database.DB.Order("timestamp desc").Limit(1).Find(&checks).Error; err != nil {
+		return nil, err
+	}
+	if len(checks) == 0 {
+		return nil, nil
+	}
+	return &checks[0], nil
+}
+
+// packageManager 按 updates.manager 确定使用的包管理器，auto 按 apt、dnf、yum 的顺序使用第一个存在的
+func packageManager(setting string) (string, error) {
+	commands := map[string]string{"apt": "apt-get", "dnf": "dnf", "yum": "yum"}
+	candidates := []string{setting}
+	if setting == "auto" {
+		candidates = []string{"apt", "dnf", "yum"}
+	}
+	for _, manager := range candidates {
+		if _, err := exec.LookPath(commands[manager]); err == nil {
+			return manager, nil
+		}
+	}
+	if setting == "auto" {
+		return "", i18n.Errorf("未找到支持的包管理器（apt、dnf、yum）")
+	}
+	return "", i18n.Errorf("未找到包管理器 %s", commands[setting])
+}
+
+// aptUpdates 通过 apt-get -s dist-upgrade 模拟升级，来源含 security 的软件包为安全更新
+func aptUpdates() (pending, security []string, err error) {
+	output, _, err := runPackageCommand("apt-get", "-s", "-o", "Debug::NoLocking=true", "dist-upgrade")
+	if err != nil {
+		return nil, nil, err
+	}
+	pending, security = parseAptSimulation(output)
+	return pending, security, nil
+}
+
+// parseAptSimulation 解析模拟升级的输出，如 "Inst libssl3 [3.0.2-0ubuntu1.10] (3.0.2-0ubuntu1.12 Ubuntu:22.04/jammy-security [amd64])"
+func parseAptSimulation(output string) (pending, security []string) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "Inst" {
+			continue
+		}
+		pending = append(pending, fields[1])
+		if strings.Contains(strings.ToLower(scanner.Text()), "security") {
+			security = append(security, fields[1])
+		}
+	}
+	return pending, security
+}
+
+// rpmUpdates 通过 dnf/yum check-update 统计可更新的软件包，加 --security 统计安全更新；
+// 软件源没有提供安全公告（updateinfo）时安全更新为0
+func rpmUpdates(manager string) (pending, security []string, err error) {
+	output, code, err := runPackageCommand(manager, "-q", "check-update")
+	// check-update 有可更新的软件包时退出码为100
+	if err != nil && code != 100 {
+		return nil, nil, err
+	}
+	pending = parseCheckUpdate(output)
+
+	output, code, err = runPackageCommand(manager, "-q", "check-update", "--security")
+	if err != nil && code != 100 {
+		return nil, nil, err
+	}
+	return pending, parseCheckUpdate(output), nil
+}
+
+// parseCheckUpdate 解析 check-update 的输出，每行为 "软件包.架构 版本 软件源"，返回软件包名（不含架构）；
+// 之后的 Obsoleting Packages 部分不计入
+func parseCheckUpdate(output string) []string {
+	var packages []string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "Obsoleting") {
+			break
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasPrefix(line, " ") {
+			continue
+		}
+		name := fields[0]
+		if i := strings.LastIndex(name, "."); i > 0 {
+			name = name[:i]
+		}
+		packages = append(packages, name)
+	}
+	return packages
+}
+
+// rebootRequired 是否需要重启：Debian/Ubuntu检查 /var/run/reboot-required，dnf/yum 使用 needs-restarting -r（退出码1表示需要重启，
+// 未安装时视为不需要）
+func rebootRequired(manager string) (bool, []string) {
+	if manager == "apt" {
+		if _, err := os.Stat(rebootRequiredFile); err != nil {
+			return false, nil
+		}
+		data, _ := os.ReadFile(rebootRequiredFile + ".pkgs")
+		var packages []string
+		for _, name := range strings.Fields(string(data)) {
+			if !slices.Contains(packages, name) {
+				packages = append(packages, name)
+			}
+		}
+		return true, packages
+	}
+
+	if _, err := exec.LookPath("needs-restarting"); err != nil {
+		return false, nil
+	}
+	_, code, _ := runPackageCommand("needs-restarting", "-r")
+	return code == 1, nil
+}
+
+// runPackageCommand 执行包管理器命令（英文输出，最长10分钟），返回标准输出和退出码
+func runPackageCommand(name string, args ...string) (string, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if stderr := strings.TrimSpace(string(exitErr.Stderr)); stderr != "" {
+				err = fmt.Errorf("%v: %s", err, stderr)
+			}
+			return string(output), exitErr.ExitCode(), err
+		}
+		return string(output), -1, err
+	}
+	return string(output), 0, nil
+}
+
+// abbreviatePackages 逗号分隔的软件包列表，超过 maxAlertPackages 个时只列出前面的
+func abbreviatePackages(packages string) string {
+	names := strings.Split(packages, ",")
+	if len(names) <= maxAlertPackages {
+		return strings.Join(names, ", ")
+	}
+	return i18n.Sprintf("%s 等%d个", strings.Join(names[:maxAlertPackages], ", "), len(names))
+}
@@ -0,0 +1,270 @@
+package monitor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"server-monitor/config"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CollectUPS 通过 ups.driver 指定的接口读取UPS状态，返回来源为 ups 的读数：
+// on_battery、low_battery（状态标志）、battery_charge（%）、runtime（秒）、input_voltage（V）、load（%），UPS未提供的项不返回
+func CollectUPS() ([]models.SensorReading, error) {
+	cfg := config.AppConfig.UPS
+	timeout := time.Duration(cfg.Timeout) * time.Second
+
+	var values map[string]float64
+	var err error
+	if cfg.Driver == "apcupsd" {
+		values, err = apcupsdStatus(upsAddress(cfg.Address, "3551"), timeout)
+	} else {
+		values, err = nutStatus(upsAddress(cfg.Address, "3493"), cfg.Name, timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	units := map[string]struct{ typ, unit string }{
+		"on_battery":     {"status", ""},
+		"low_battery":    {"status", ""},
+		"battery_charge": {"percent", "%"},
+		"runtime":        {"duration", "s"},
+		"input_voltage":  {"voltage", "V"},
+		"load":           {"percent", "%"},
+	}
+	now := time.Now()
+	var readings []models.SensorReading
+	for _, name := range []string{"on_battery", "low_battery", "battery_charge", "runtime", "input_voltage", "load"} {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		readings = append(readings, models.SensorReading{
+			Source:    "ups",
+			Name:      name,
+			Type:      units[name].typ,
+			Value:     value,
+			Unit:      units[name].unit,
+			Timestamp: now,
+		})
+	}
+	return readings, nil
+}
+
+// CheckUPSAlerts 切换到电池供电时触发 ups_on_battery 告警；电池供电且剩余续航低于 ups.alert_runtime
+// 或UPS报告电量低时触发 ups_low_runtime 告警（error）。恢复市电后自动解除
+func CheckUPSAlerts(readings []models.SensorReading) {
+	cfg := config.AppConfig.UPS
+	values := make(map[string]float64, len(readings))
+	for _, r := range readings {
+		values[r.Name] = r.Value
+	}
+	onBattery := values["on_battery"] == 1
+	charge, hasCharge := values["battery_charge"]
+	runtime, hasRuntime := values["runtime"]
+
+	if cfg.AlertOnBattery && onBattery {
+		message := i18n.Sprintf("UPS已切换到电池供电")
+		if hasCharge {
+			message = i18n.Sprintf("UPS已切换到电池供电，电量 %.0f%%", charge)
+		}
+		raiseAlert("ups_on_battery", "warning", "system", message, charge, 0)
+	} else {
+		resolveAlert("ups_on_battery", "system", i18n.Sprintf("UPS已恢复市电供电"))
+	}
+
+	lowRuntime := hasRuntime && cfg.AlertRuntime > 0 && runtime < float64(cfg.AlertRuntime)
+	if onBattery && (lowRuntime || values["low_battery"] == 1) {
+		message := i18n.Sprintf("UPS电池电量低，即将耗尽")
+		if hasRuntime {
+			message = i18n.Sprintf("UPS电池供电，剩余续航仅 %d 秒", int(runtime))
+		}
+		raiseAlert("ups_low_runtime", "error", "system", message, runtime, float64(cfg.AlertRuntime))
+	} else {
+		resolveAlert("ups_low_runtime", "system", i18n.Sprintf("UPS剩余续航已恢复正常"))
+	}
+}
+
+// upsAddress 未配置地址时连接本机的默认端口
+func upsAddress(address, port string) string {
+	if address == "" {
+		return net.JoinHostPort("127.0.0.1", port)
+	}
+	return address
+}
+
+// nutStatus 通过NUT网络协议（LIST UPS、LIST VAR）读取UPS变量；name 为空时使用 upsd 列出的第一个UPS
+func nutStatus(address, name string, timeout time.Duration) (map[string]float64, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+	reader := bufio.NewReader(conn)
+
+	if name == "" {
+		lines, err := nutList(conn, reader, "UPS")
+		if err != nil {
+			return nil, err
+		}
+		if len(lines) == 0 {
+			return nil, i18n.Errorf("upsd 中没有配置UPS")
+		}
+		// UPS <名称> "<描述>"
+		name = strings.Fields(lines[0])[1]
+	}
+
+	lines, err := nutList(conn, reader, "VAR "+name)
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string, len(lines))
+	for _, line := range lines {
+		// VAR <UPS名称> <变量名> "<值>"
+		fields := strings.SplitN(line, " ", 4)
+		if len(fields) == 4 {
+			vars[fields[2]] = nutUnquote(fields[3])
+		}
+	}
+	fmt.Fprint(conn, "LOGOUT\n")
+
+	values := make(map[string]float64)
+	// ups.status 为空格分隔的标志，如 "OL CHRG"、"OB DISCHRG"、"OB LB"
+	if status, ok := vars["ups.status"]; ok {
+		flags := strings.Fields(status)
+		values["on_battery"] = boolValue(slices.Contains(flags, "OB"))
+		values["low_battery"] = boolValue(slices.Contains(flags, "LB"))
+	}
+	for name, variable := range map[string]string{
+		"battery_charge": "battery.charge",
+		"runtime":        "battery.runtime",
+		"input_voltage":  "input.voltage",
+		"load":           "ups.load",
+	} {
+		if value, err := strconv.ParseFloat(vars[variable], 64); err == nil {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// nutList 发送 LIST 命令，返回 BEGIN LIST 与 END LIST 之间的各行
+func nutList(conn net.Conn, reader *bufio.Reader, query string) ([]string, error) {
+	if _, err := fmt.Fprintf(conn, "LIST %s\n", query); err != nil {
+		return nil, err
+	}
+	var lines []string
+	begun := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "ERR "):
+			return nil, i18n.Errorf("upsd 返回错误: %s", strings.TrimPrefix(line, "ERR "))
+		case line == "BEGIN LIST "+query:
+			begun = true
+		case line == "END LIST "+query:
+			return lines, nil
+		case begun:
+			lines = append(lines, line)
+		}
+	}
+}
+
+// nutUnquote 去掉NUT变量值两侧的引号，并还原转义的 \" 和 \\
+func nutUnquote(value string) string {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return strings.Trim(value, `"`)
+}
+
+// apcupsdStatus 通过apcupsd的NIS协议读取 status 输出（与 apcaccess 相同），
+// 每条记录为2字节的长度加内容，长度为0表示结束
+func apcupsdStatus(address string, timeout time.Duration) (map[string]float64, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	command := []byte("status")
+	request := binary.BigEndian.AppendUint16(nil, uint16(len(command)))
+	if _, err := conn.Write(append(request, command...)); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string)
+	reader := bufio.NewReader(conn)
+	for {
+		var length uint16
+		if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if length == 0 {
+			break
+		}
+		record := make([]byte, length)
+		if _, err := io.ReadFull(reader, record); err != nil {
+			return nil, err
+		}
+		// 如 "BCHARGE  : 100.0 Percent"
+		if key, value, ok := strings.Cut(string(record), ":"); ok {
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, i18n.Errorf("apcupsd 没有返回状态")
+	}
+	if strings.Contains(fields["STATUS"], "COMMLOST") {
+		return nil, i18n.Errorf("apcupsd 与UPS的通信中断")
+	}
+
+	values := make(map[string]float64)
+	if status, ok := fields["STATUS"]; ok {
+		flags := strings.Fields(status)
+		values["on_battery"] = boolValue(slices.Contains(flags, "ONBATT"))
+		values["low_battery"] = boolValue(slices.Contains(flags, "LOWBATT"))
+	}
+	for name, key := range map[string]string{
+		"battery_charge": "BCHARGE",
+		"runtime":        "TIMELEFT",
+		"input_voltage":  "LINEV",
+		"load":           "LOADPCT",
+	} {
+		// 数值后带单位，如 "45.0 Minutes"
+		number, _, _ := strings.Cut(fields[key], " ")
+		if value, err := strconv.ParseFloat(number, 64); err == nil {
+			values[name] = value
+		}
+	}
+	// TIMELEFT 的单位为分钟
+	if runtime, ok := values["runtime"]; ok {
+		values["runtime"] = runtime * 60
+	}
+	return values, nil
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
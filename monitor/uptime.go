@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"time"
+)
+
+// ErrUnknownUptimePeriod 不支持的可用率统计周期
+var ErrUnknownUptimePeriod = i18n.Errorf("统计周期无效，可选 24h、7d、30d")
+
+// uptimePeriods 计算可用率的统计周期
+var uptimePeriods = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// UptimePeriod 一个统计周期内的检查次数、可用率和平均响应时间
+type UptimePeriod struct {
+	Period       string   `json:"period"`
+	Checks       int64    `json:"checks"`
+	UpChecks     int64    `json:"up_checks"`
+	Availability *float64 `json:"availability"` // 可用率(%)，周期内没有检查记录时为null
+	AvgResponse  *float64 `json:"avg_response"` // 正常检查的平均响应时间(ms)
+}
+
+// ServiceUptime 服务在各统计周期内的可用率
+type ServiceUptime struct {
+	ServiceID uint           `json:"service_id"`
+	Name      string         `json:"name"`
+	Status    string         `json:"status"`
+	Periods   []UptimePeriod `json:"periods"`
+}
+
+// GetServiceUptime 根据检查历史计算服务最近24小时、7天和30天的可用率，
+// 状态为running或warning的检查视为可用，error视为不可用
+func GetServiceUptime(service models.ServiceStatus) (*ServiceUptime, error) {
+	uptime := &ServiceUptime{
+		ServiceID: service.ID,
+		Name:      service.Name,
+		Status:    service.Status,
+		Periods:   make([]UptimePeriod, 0, len(uptimePeriods)),
+	}
+
+	now := time.Now()
+	for _, p := range uptimePeriods {
+		period, err := serviceAvailability(service.ID, now.Add(-p.duration), now)
+		if err != nil {
+			return nil, err
+		}
+		period.Period = p.name
+		uptime.Periods = append(uptime.Periods, *period)
+	}
+	return uptime, nil
+}
+
+// GetServiceAvailability 计算服务在一个统计周期（24h、7d、30d）内的可用率
+func GetServiceAvailability(service models.ServiceStatus, period string) (*UptimePeriod, error) {
+	for _, p := range uptimePeriods {
+		if p.name != period {
+			continue
+		}
+		now := time.Now()
+		result, err := serviceAvailability(service.ID, now.Add(-p.duration), now)
+		if err != nil {
+			return nil, err
+		}
+		result.Period = p.name
+		return result, nil
+	}
+	return nil, ErrUnknownUptimePeriod
+}
+
+// serviceAvailability 统计服务在[start, end)内的检查次数、可用率和平均响应时间
+func serviceAvailability(serviceID uint, start, end time.Time) (*UptimePeriod, error) {
+	var row struct {
+		Checks      int64
+		UpChecks    int64
+		AvgResponse *float64
+	}
+	err := database.DB.Model(&models.ServiceCheckResult{}).
+		Select("COUNT(*) AS checks, "+
+			"COALESCE(SUM(CASE WHEN status <> ? THEN 1 ELSE 0 END), 0) AS up_checks, "+
+			"AVG(CASE WHEN status <> ? THEN response END) AS avg_response", "error", "error").
+		Where("service_id = ? AND timestamp >= ? AND timestamp < ?", serviceID, start, end).
+		Where("state_type <> ?", "soft").
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	period := &UptimePeriod{
+		Checks:      row.Checks,
+		UpChecks:    row.UpChecks,
+		AvgResponse: row.AvgResponse,
+	}
+	if row.Checks > 0 {
+		availability := float64(row.UpChecks) / float64(row.Checks) * 100
+		period.Availability = &availability
+	}
+	return period, nil
+}
@@ -0,0 +1,207 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// zpool status 中各部分的标题行，如 "  pool: tank"、" state: ONLINE"；后续内容以制表符缩进
+	zpoolStatusKey = regexp.MustCompile(`^ *(pool|state|status|action|see|scan|remove|config|errors): ?(.*)$`)
+	// 正在进行的scrub、resilver的进度，如 "0B repaired, 31.25% done, 00:41:36 to go"
+	zpoolScanDone = regexp.MustCompile(`([\d.]+)% done`)
+)
+
+// CheckZFS 读取各存储池的容量和状态并保存；未安装 zpool 或没有存储池时返回空
+func CheckZFS() ([]models.ZFSPool, error) {
+	command := config.AppConfig.ZFS.Command
+	if _, err := exec.LookPath(command); err != nil {
+		return nil, nil
+	}
+
+	output, err := zpool(command, "list", "-Hp", "-o", "name,size,alloc,free,frag,cap,health")
+	if err != nil {
+		return nil, err
+	}
+	pools := parseZpoolList(output)
+	if len(pools) == 0 {
+		return nil, nil
+	}
+
+	output, err = zpool(command, "status")
+	if err != nil {
+		return nil, err
+	}
+	statuses := parseZpoolStatus(output)
+
+	now := time.Now()
+	for i := range pools {
+		if status, ok := statuses[pools[i].Name]; ok {
+			pools[i].Scan = status.Scan
+			pools[i].ScanProgress = status.ScanProgress
+			pools[i].LastScrub = status.LastScrub
+			pools[i].Errors = status.Errors
+		}
+		pools[i].Timestamp = now
+	}
+	if err := database.DB.Create(&pools).Error; err != nil {
+		return nil, err
+	}
+	return pools, nil
+}
+
+// CheckZFSAlerts 存储池不是ONLINE时触发 zfs_health:<存储池> 告警，DEGRADED、FAULTED、UNAVAIL、SUSPENDED为error，
+// 其他（如OFFLINE、REMOVED）为warning；已用容量达到 zfs.alert_capacity 时触发 zfs_capacity:<存储池> 告警。恢复后自动解除
+func CheckZFSAlerts(pools []models.ZFSPool) {
+	cfg := config.AppConfig.ZFS
+	current := make(map[string]bool)
+	for _, pool := range pools {
+		if cfg.AlertHealth && pool.Health != "ONLINE" {
+			level := "warning"
+			switch pool.Health {
+			case "DEGRADED", "FAULTED", "UNAVAIL", "SUSPENDED":
+				level = "error"
+			}
+			current["zfs_health:"+pool.Name] = true
+			raiseAlert("zfs_health:"+pool.Name, level, "system",
+				i18n.Sprintf("ZFS存储池 %s 状态为 %s", pool.Name, pool.Health), 0, 0)
+		}
+		if cfg.AlertCapacity > 0 && pool.Capacity >= float64(cfg.AlertCapacity) {
+			current["zfs_capacity:"+pool.Name] = true
+			raiseAlert("zfs_capacity:"+pool.Name, "warning", "system",
+				i18n.Sprintf("ZFS存储池 %s 已用 %.0f%%", pool.Name, pool.Capacity), pool.Capacity, float64(cfg.AlertCapacity))
+		}
+	}
+
+	// 包括重启前触发的告警和已被导出的存储池
+	var active []models.Alert
+	database.DB.Where("(type LIKE ? OR type LIKE ?) AND status = ?", "zfs_health:%", "zfs_capacity:%", "active").Find(&active)
+	for _, alert := range active {
+		if current[alert.Type] {
+			continue
+		}
+		kind, name, _ := strings.Cut(alert.Type, ":")
+		message := i18n.Sprintf("ZFS存储池 %s 已恢复ONLINE", name)
+		if kind == "zfs_capacity" {
+			message = i18n.Sprintf("ZFS存储池 %s 已用容量已恢复正常", name)
+		}
+		resolveAlert(alert.Type, "system", message)
+	}
+}
+
+// GetLatestZFSPools 最近一次检查时各存储池的状态，按名称排序
+func GetLatestZFSPools() ([]models.ZFSPool, error) {
+	var pools []models.ZFSPool
+	err := database.DB.Where("timestamp = (?)", database.DB.Model(&models.ZFSPool{}).Select("MAX(timestamp)")).
+		Order("name").Find(&pools).Error
+	return pools, err
+}
+
+// zpool 执行 zpool 命令（英文输出，最长30秒）
+func zpool(command string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return string(output), nil
+}
+
+// parseZpoolList 解析 zpool list -Hp 的输出，每行为制表符分隔的名称、容量、已用、可用、碎片率、已用百分比和健康状态；
+// 不支持碎片率统计的存储池碎片率为 "-"
+func parseZpoolList(output string) []models.ZFSPool {
+	var pools []models.ZFSPool
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			continue
+		}
+		pool := models.ZFSPool{Name: fields[0], Health: fields[6]}
+		pool.Size, _ = strconv.ParseUint(fields[1], 10, 64)
+		pool.Allocated, _ = strconv.ParseUint(fields[2], 10, 64)
+		pool.Free, _ = strconv.ParseUint(fields[3], 10, 64)
+		if frag, err := strconv.ParseFloat(strings.TrimSuffix(fields[4], "%"), 64); err == nil {
+			pool.Fragmentation = &frag
+		}
+		pool.Capacity, _ = strconv.ParseFloat(strings.TrimSuffix(fields[5], "%"), 64)
+		pools = append(pools, pool)
+	}
+	return pools
+}
+
+// parseZpoolStatus 解析 zpool status 的输出，按存储池返回scan、进度、最近一次scrub的时间和errors
+func parseZpoolStatus(output string) map[string]models.ZFSPool {
+	statuses := make(map[string]models.ZFSPool)
+	var pool *models.ZFSPool
+	var section string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := zpoolStatusKey.FindStringSubmatch(line); m != nil {
+			section = m[1]
+			value := strings.TrimSpace(m[2])
+			switch section {
+			case "pool":
+				if pool != nil {
+					statuses[pool.Name] = *pool
+				}
+				pool = &models.ZFSPool{Name: value}
+			case "scan":
+				if pool != nil {
+					pool.Scan = value
+					pool.LastScrub = zpoolScrubTime(value)
+				}
+			case "errors":
+				if pool != nil {
+					pool.Errors = value
+				}
+			}
+			continue
+		}
+		if section == "scan" && pool != nil {
+			if m := zpoolScanDone.FindStringSubmatch(line); m != nil {
+				pool.ScanProgress, _ = strconv.ParseFloat(m[1], 64)
+			}
+		}
+	}
+	if pool != nil {
+		statuses[pool.Name] = *pool
+	}
+	return statuses
+}
+
+// zpoolScrubTime 从已完成scrub的scan行（如 "scrub repaired 0B in 00:10:12 with 0 errors on Sun Oct 12 00:34:13 2025"）
+// 取完成时间，其他情况返回nil
+func zpoolScrubTime(scan string) *time.Time {
+	if !strings.HasPrefix(scan, "scrub repaired") {
+		return nil
+	}
+	i := strings.LastIndex(scan, " on ")
+	if i < 0 {
+		return nil
+	}
+	t, err := time.ParseInLocation("Mon Jan _2 15:04:05 2006", scan[i+len(" on "):], time.Local)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
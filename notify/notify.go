@@ -0,0 +1,250 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"server-monitor/config"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// 事件在聊天消息中的标题
+var eventTitles = map[string]string{
+	monitor.AlertFired:        "🔥 告警触发",
+	monitor.AlertResolved:     "✅ 告警恢复",
+	monitor.AlertAcknowledged: "👀 告警已确认",
+	monitor.AlertSilenced:     "🔕 告警已静默",
+	monitor.AlertEscalated:    "⬆️ 告警升级",
+	monitor.AlertDeescalated:  "⬇️ 告警降级",
+}
+
+// Init 注册告警生命周期回调，将事件推送到配置的Webhook
+func Init() {
+	// 始终注册回调，配置重新加载后新增的webhook无需重启即可生效
+	monitor.OnAlertEvent(dispatch)
+
+	if len(config.AppConfig.Notify.Webhooks) == 0 {
+		return
+	}
+	if config.AppConfig.Notify.SigningSecret == "" {
+		log.Println("Warning: notify.signing_secret is empty, alert notifications will not include action buttons")
+	}
+	log.Printf("Alert notifications enabled for %d webhook(s)", len(config.AppConfig.Notify.Webhooks))
+}
+
+// DryRun 校验Webhook配置并为示例告警生成消息体，启用摘要报告时试渲染报告模板，不实际发送
+func DryRun() error {
+	for _, webhook := range config.AppConfig.Notify.Webhooks {
+		if err := ValidateWebhook(webhook); err != nil {
+			return err
+		}
+	}
+	if config.AppConfig.Reports.Enabled {
+		return validateReportTemplate()
+	}
+	return nil
+}
+
+// ValidateWebhook 校验单个Webhook配置并为示例告警生成消息体，不实际发送
+func ValidateWebhook(webhook config.WebhookConfig) error {
+	sample := models.Alert{
+		Type:      "selftest",
+		Level:     "info",
+		Message:   i18n.Sprintf("自检示例告警"),
+		Status:    "active",
+		Timestamp: time.Now(),
+	}
+
+	if _, err := url.ParseRequestURI(webhook.URL); err != nil {
+		return fmt.Errorf("webhook %s: invalid url: %v", webhook.Name, err)
+	}
+	if webhook.Format == "telegram" && webhook.ChatID == "" {
+		return fmt.Errorf("webhook %s: chat_id is required for telegram", webhook.Name)
+	}
+	if _, err := buildPayload(webhook, monitor.AlertFired, sample, nil); err != nil {
+		return fmt.Errorf("webhook %s: %v", webhook.Name, err)
+	}
+	return nil
+}
+
+// dispatch 将告警事件发送到所有订阅了该事件的Webhook
+func dispatch(event string, alert models.Alert) {
+	if len(config.AppConfig.Notify.Webhooks) == 0 {
+		return
+	}
+
+	// 静默期内的触发和恢复不再通知，确认/静默操作本身仍然通知
+	if (event == monitor.AlertFired || event == monitor.AlertResolved) && monitor.IsAlertSilenced(alert.Type) {
+		return
+	}
+
+	// 冷却期内的重复事件合并到窗口结束时的一条通知中
+	if !throttle(event, alert) {
+		return
+	}
+
+	for _, webhook := range config.AppConfig.Notify.Webhooks {
+		if !subscribed(webhook, event) {
+			continue
+		}
+		deliver(webhook, event, alert, nil)
+	}
+}
+
+// deliver 生成消息体并发送到Webhook，group不为空时为冷却窗口结束时的合并通知
+func deliver(webhook config.WebhookConfig, event string, alert models.Alert, group *groupSummary) {
+	payload, err := buildPayload(webhook, event, alert, group)
+	if err != nil {
+		log.Printf("Error building payload for webhook %s: %v", webhook.Name, err)
+		return
+	}
+	if err := send(webhook.URL, payload); err != nil {
+		log.Printf("Error sending alert %d %s to webhook %s: %v", alert.ID, event, webhook.Name, err)
+	}
+}
+
+// subscribed 判断Webhook是否订阅了该事件
+func subscribed(webhook config.WebhookConfig, event string) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+	for _, e := range webhook.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// send 以JSON格式POST到Webhook地址
+func send(endpoint string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Post(endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		// 错误信息中的Webhook地址可能包含令牌，写日志前脱敏
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = config.RedactURL(urlErr.URL)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// action 告警操作按钮
+type action struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// alertActions 活跃告警可执行的操作，未配置签名密钥时不生成
+func alertActions(event string, alert models.Alert) []action {
+	if config.AppConfig.Notify.SigningSecret == "" || alert.Status != "active" {
+		return nil
+	}
+
+	actions := []action{}
+	if event != monitor.AlertAcknowledged && alert.AcknowledgedAt == nil {
+		actions = append(actions, action{Name: "ack", Label: i18n.Sprintf("确认"), URL: ActionURL(alert.ID, "ack")})
+	}
+	actions = append(actions, action{Name: "resolve", Label: i18n.Sprintf("解决"), URL: ActionURL(alert.ID, "resolve")})
+	if event != monitor.AlertSilenced {
+		label := i18n.Sprintf("静默%v", time.Duration(config.AppConfig.Notify.SilenceDuration)*time.Second)
+		actions = append(actions, action{Name: "silence", Label: label, URL: ActionURL(alert.ID, "silence")})
+	}
+	return actions
+}
+
+// alertText 告警的文字描述
+func alertText(event string, alert models.Alert) string {
+	text := i18n.Sprintf("%s [%s] %s\n%s\n当前值: %.2f  阈值: %.2f  时间: %s",
+		i18n.Sprintf(eventTitles[event]), alert.Level, alert.Type, alert.Message,
+		alert.Value, alert.Threshold, alert.Timestamp.In(config.AppConfig.I18n.Location()).Format("2006-01-02 15:04:05"))
+	if alert.Context != "" && event != monitor.AlertResolved {
+		text += "\n" + alert.Context
+	}
+	return text
+}
+
+// buildPayload 按Webhook格式生成消息体，合并通知在文字末尾附加合并的事件次数
+func buildPayload(webhook config.WebhookConfig, event string, alert models.Alert, group *groupSummary) (interface{}, error) {
+	actions := alertActions(event, alert)
+	text := alertText(event, alert)
+	if group != nil {
+		text += "\n" + group.text()
+	}
+
+	switch webhook.Format {
+	case "", "generic":
+		payload := map[string]interface{}{
+			"event":     event,
+			"alert":     alert,
+			"text":      text,
+			"actions":   actions,
+			"timestamp": time.Now(),
+		}
+		if group != nil {
+			payload["group"] = group
+		}
+		return payload, nil
+
+	case "slack":
+		blocks := []map[string]interface{}{
+			{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": text},
+			},
+		}
+		if len(actions) > 0 {
+			elements := make([]map[string]interface{}, 0, len(actions))
+			for _, a := range actions {
+				elements = append(elements, map[string]interface{}{
+					"type":      "button",
+					"text":      map[string]string{"type": "plain_text", "text": a.Label},
+					"url":       a.URL,
+					"action_id": a.Name,
+				})
+			}
+			blocks = append(blocks, map[string]interface{}{"type": "actions", "elements": elements})
+		}
+		return map[string]interface{}{"text": text, "blocks": blocks}, nil
+
+	case "telegram":
+		payload := map[string]interface{}{
+			"chat_id": webhook.ChatID,
+			"text":    text,
+		}
+		if len(actions) > 0 {
+			buttons := make([]map[string]string, 0, len(actions))
+			for _, a := range actions {
+				buttons = append(buttons, map[string]string{"text": a.Label, "url": a.URL})
+			}
+			payload["reply_markup"] = map[string]interface{}{
+				"inline_keyboard": [][]map[string]string{buttons},
+			}
+		}
+		return payload, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported webhook format: %s", webhook.Format)
+	}
+}
@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"server-monitor/config"
+	"server-monitor/monitor"
+	"server-monitor/report"
+	"text/template"
+	"time"
+)
+
+// ReportEvent 摘要报告在Webhook订阅中的事件名
+const ReportEvent = "report"
+
+// defaultReportTemplate 内置的摘要报告模板
+const defaultReportTemplate = `📊 {{.Hostname}} {{if eq .Period "weekly"}}周报{{else}}日报{{end}}
+{{date .Start}} ~ {{date .End}}
+
+CPU: 平均 {{printf "%.1f" .CPU.Avg}}%  峰值 {{printf "%.1f" .CPU.Peak}}%
+内存: 平均 {{printf "%.1f" .Memory.Avg}}%  峰值 {{printf "%.1f" .Memory.Peak}}%
+{{- if .Disks}}
+
+磁盘增长:
+{{- range .Disks}}
+  {{.Path}}: {{signed .Growth}}GB（当前 {{.End}}GB，{{printf "%.1f" .Usage}}%）
+{{- end}}
+{{- end}}
+{{- if .Traffic}}
+
+流量:
+{{- range .Traffic}}
+  {{.Interface}}: ↑{{bytes .Upload}} ↓{{bytes .Download}}
+{{- end}}
+{{- end}}
+
+告警: {{.Alerts}} 次
+{{- range .AlertsBy}} {{.Key}}:{{.Count}}{{end}}
+{{- if .TopAlerts}}
+  最频繁: {{range $i, $a := .TopAlerts}}{{if $i}}、{{end}}{{$a.Key}}({{$a.Count}}){{end}}
+{{- end}}
+{{- if .Services}}
+
+服务可用率:
+{{- range .Services}}
+  {{.Name}}: {{percent .Availability}}{{if .AvgResponse}}  平均响应 {{printf "%.0f" (deref .AvgResponse)}}ms{{end}}
+{{- end}}
+{{- end}}
+`
+
+// reportTemplate 解析 reports.template 指定的模板文件，未配置时使用内置模板
+func reportTemplate() (*template.Template, error) {
+	text := defaultReportTemplate
+	if path := config.AppConfig.Reports.Template; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取报告模板失败: %v", err)
+		}
+		text = string(data)
+	}
+	return template.New("report").Funcs(report.Funcs).Parse(text)
+}
+
+// RenderReport 用报告模板渲染摘要报告
+func RenderReport(report *monitor.Report) (string, error) {
+	tmpl, err := reportTemplate()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, report); err != nil {
+		return "", fmt.Errorf("渲染报告模板失败: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// SendReport 渲染摘要报告并发送到所有订阅了 report 事件的Webhook，返回发送成功的数量
+func SendReport(report *monitor.Report) (int, error) {
+	text, err := RenderReport(report)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var lastErr error
+	for _, webhook := range config.AppConfig.Notify.Webhooks {
+		if !subscribed(webhook, ReportEvent) {
+			continue
+		}
+		if err := send(webhook.URL, reportPayload(webhook, report, text)); err != nil {
+			log.Printf("Error sending report to webhook %s: %v", webhook.Name, err)
+			lastErr = err
+			continue
+		}
+		sent++
+	}
+	if sent == 0 && lastErr != nil {
+		return 0, lastErr
+	}
+	return sent, nil
+}
+
+// reportPayload 按Webhook格式生成报告消息体
+func reportPayload(webhook config.WebhookConfig, report *monitor.Report, text string) interface{} {
+	switch webhook.Format {
+	case "slack":
+		return map[string]interface{}{"text": text}
+	case "telegram":
+		return map[string]interface{}{"chat_id": webhook.ChatID, "text": text}
+	default:
+		return map[string]interface{}{
+			"event":     ReportEvent,
+			"report":    report,
+			"text":      text,
+			"timestamp": time.Now(),
+		}
+	}
+}
+
+// validateReportTemplate 用示例报告试渲染模板，启动自检时发现模板错误
+func validateReportTemplate() error {
+	sample := &monitor.Report{Period: config.AppConfig.Reports.Period, Start: time.Now().Add(-24 * time.Hour), End: time.Now()}
+	if _, err := RenderReport(sample); err != nil {
+		return fmt.Errorf("reports.template: %v", err)
+	}
+	return nil
+}
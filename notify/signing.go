@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"server-monitor/config"
+	"server-monitor/i18n"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSignature 回调链接签名无效或已过期
+var ErrInvalidSignature = i18n.Errorf("链接无效或已过期")
+
+// ActionURL 生成带签名的告警操作回调链接，聊天机器人按钮直接打开该链接即可完成操作
+func ActionURL(alertID uint, action string) string {
+	expires := time.Now().Add(time.Duration(config.AppConfig.Notify.ActionTTL) * time.Second).Unix()
+	return fmt.Sprintf("%s/api/v1/alerts/%d/actions/%s?expires=%d&sig=%s",
+		strings.TrimRight(config.AppConfig.Notify.PublicURL, "/"), alertID, action, expires, sign(alertID, action, expires))
+}
+
+// VerifyAction 校验回调链接的签名和有效期
+func VerifyAction(alertID uint, action string, expires int64, signature string) error {
+	if config.AppConfig.Notify.SigningSecret == "" || time.Now().Unix() > expires {
+		return ErrInvalidSignature
+	}
+
+	expected := sign(alertID, action, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// sign 计算 告警ID:操作:过期时间 的HMAC-SHA256签名
+func sign(alertID uint, action string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(config.AppConfig.Notify.SigningSecret))
+	fmt.Fprintf(mac, "%d:%s:%d", alertID, action, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
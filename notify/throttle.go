@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"server-monitor/config"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 受冷却期限制的事件：由指标自动触发，指标抖动时会反复出现；确认和静默是人工操作，始终立即通知
+var throttledEvents = map[string]bool{
+	monitor.AlertFired:       true,
+	monitor.AlertResolved:    true,
+	monitor.AlertEscalated:   true,
+	monitor.AlertDeescalated: true,
+}
+
+// 合并通知中各事件的描述顺序
+var groupEventOrder = []string{monitor.AlertFired, monitor.AlertResolved, monitor.AlertEscalated, monitor.AlertDeescalated}
+
+// 合并通知中事件的简称
+var groupEventNames = map[string]string{
+	monitor.AlertFired:       "触发",
+	monitor.AlertResolved:    "恢复",
+	monitor.AlertEscalated:   "升级",
+	monitor.AlertDeescalated: "降级",
+}
+
+// alertGroup 一个告警类型的冷却窗口，窗口内的重复事件不单独通知，窗口结束时合并为一条
+type alertGroup struct {
+	since  time.Time      // 窗口开始时间
+	counts map[string]int // 窗口内被合并的事件次数
+	event  string         // 最后一次被合并的事件
+	alert  models.Alert   // 最后一次被合并事件时的告警
+}
+
+// groupSummary 合并通知的统计，随通用格式的消息体发送
+type groupSummary struct {
+	Since  time.Time      `json:"since"`
+	Counts map[string]int `json:"counts"`
+}
+
+var (
+	groupsMu sync.Mutex
+	groups   = make(map[string]*alertGroup)
+)
+
+// cooldownFor 告警类型的通知冷却时间，notify.cooldowns 中的设置优先于 notify.cooldown
+func cooldownFor(alertType string) time.Duration {
+	seconds := config.AppConfig.Notify.Cooldown
+	if n, ok := config.AppConfig.Notify.Cooldowns[alertType]; ok {
+		seconds = n
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// throttle 判断事件是否应立即通知：冷却窗口外的事件立即通知并开启新窗口，窗口内的事件记入窗口，等窗口结束时合并通知
+func throttle(event string, alert models.Alert) bool {
+	if !throttledEvents[event] {
+		return true
+	}
+	cooldown := cooldownFor(alert.Type)
+	if cooldown <= 0 {
+		return true
+	}
+
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+
+	if g, ok := groups[alert.Type]; ok {
+		g.counts[event]++
+		g.event = event
+		g.alert = alert
+		return false
+	}
+
+	groups[alert.Type] = &alertGroup{since: time.Now(), counts: make(map[string]int)}
+	time.AfterFunc(cooldown, func() { flushGroup(alert.Type) })
+	return true
+}
+
+// flushGroup 冷却窗口结束：窗口内有被合并的事件时发送一条合并通知，并开启下一个窗口，抖动持续时每个窗口最多一条通知
+func flushGroup(alertType string) {
+	groupsMu.Lock()
+	g := groups[alertType]
+	if g == nil || len(g.counts) == 0 {
+		delete(groups, alertType)
+		groupsMu.Unlock()
+		return
+	}
+	groups[alertType] = &alertGroup{since: time.Now(), counts: make(map[string]int)}
+	groupsMu.Unlock()
+
+	if cooldown := cooldownFor(alertType); cooldown > 0 {
+		time.AfterFunc(cooldown, func() { flushGroup(alertType) })
+	} else {
+		groupsMu.Lock()
+		delete(groups, alertType)
+		groupsMu.Unlock()
+	}
+
+	// 窗口内告警被静默时不再发送合并通知
+	if monitor.IsAlertSilenced(alertType) {
+		return
+	}
+	summary := &groupSummary{Since: g.since, Counts: g.counts}
+	for _, webhook := range config.AppConfig.Notify.Webhooks {
+		if !subscribedAny(webhook, g.counts) {
+			continue
+		}
+		deliver(webhook, g.event, g.alert, summary)
+	}
+}
+
+// subscribedAny 判断Webhook是否订阅了合并通知中的任一事件
+func subscribedAny(webhook config.WebhookConfig, counts map[string]int) bool {
+	for event := range counts {
+		if subscribed(webhook, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// text 合并通知附加的说明
+func (s *groupSummary) text() string {
+	parts := []string{}
+	for _, event := range groupEventOrder {
+		if n := s.Counts[event]; n > 0 {
+			parts = append(parts, i18n.Sprintf("%s %d 次", i18n.Sprintf(groupEventNames[event]), n))
+		}
+	}
+	return i18n.Sprintf("🔁 %s 起的冷却期内合并了 %s，以上为最新状态",
+		s.Since.In(config.AppConfig.I18n.Location()).Format("15:04:05"), strings.Join(parts, i18n.Sprintf("、")))
+}
@@ -0,0 +1,88 @@
+package report
+
+import (
+	"math"
+	"server-monitor/monitor"
+	"time"
+)
+
+// Chart 折线图：共享时间轴的多条数据序列
+type Chart struct {
+	Title  string
+	Max    float64 // 纵轴上限
+	Times  []time.Time
+	Series []Series
+}
+
+// Series 折线图中的一条数据序列
+type Series struct {
+	Name   string
+	Color  [3]uint8 // RGB
+	Values []float64
+}
+
+// buildCharts 由趋势数据生成CPU/内存和网络速率两张图
+func buildCharts(points []monitor.ReportPoint) []Chart {
+	usage := Chart{
+		Title: "CPU与内存使用率（%）",
+		Max:   100,
+		Series: []Series{
+			{Name: "CPU", Color: [3]uint8{59, 130, 246}},
+			{Name: "内存", Color: [3]uint8{16, 185, 129}},
+		},
+	}
+	network := Chart{
+		Title: "网络速率（MB/s）",
+		Series: []Series{
+			{Name: "上传", Color: [3]uint8{245, 158, 11}},
+			{Name: "下载", Color: [3]uint8{139, 92, 246}},
+		},
+	}
+
+	for _, p := range points {
+		usage.Times = append(usage.Times, p.Time)
+		usage.Series[0].Values = append(usage.Series[0].Values, p.CPU)
+		usage.Series[1].Values = append(usage.Series[1].Values, p.Memory)
+		network.Times = append(network.Times, p.Time)
+		network.Series[0].Values = append(network.Series[0].Values, p.Upload)
+		network.Series[1].Values = append(network.Series[1].Values, p.Download)
+		network.Max = math.Max(network.Max, math.Max(p.Upload, p.Download))
+	}
+	network.Max = niceCeil(network.Max)
+	return []Chart{usage, network}
+}
+
+// niceCeil 将纵轴上限取整到 1、2、5 乘以10的幂，至少为1
+func niceCeil(v float64) float64 {
+	if v <= 1 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, step := range []float64{1, 2, 5, 10} {
+		if v <= step*magnitude {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// Plot 将第i条序列映射到宽w、高h的绘图区，原点在左上角
+func (c Chart) Plot(i int, w, h float64) [][2]float64 {
+	values := c.Series[i].Values
+	if len(values) == 0 {
+		return nil
+	}
+	start, end := c.Times[0], c.Times[len(c.Times)-1]
+	span := end.Sub(start).Seconds()
+
+	points := make([][2]float64, len(values))
+	for j, v := range values {
+		x := w / 2
+		if span > 0 {
+			x = c.Times[j].Sub(start).Seconds() / span * w
+		}
+		y := h - math.Min(v, c.Max)/c.Max*h
+		points[j] = [2]float64{x, y}
+	}
+	return points
+}
@@ -0,0 +1,41 @@
+package report
+
+import (
+	"fmt"
+	"server-monitor/config"
+	"time"
+)
+
+// Funcs 报告模板（摘要报告文本、HTML报告）中可用的格式化函数
+var Funcs = map[string]interface{}{
+	"date":    FormatDate,
+	"bytes":   FormatBytes,
+	"percent": FormatPercent,
+	"signed":  func(n int64) string { return fmt.Sprintf("%+d", n) },
+	"deref":   func(p *float64) float64 { return *p },
+}
+
+// FormatDate 报告中的时间格式，按显示时区显示
+func FormatDate(t time.Time) string {
+	return t.In(config.AppConfig.I18n.Location()).Format("2006-01-02 15:04")
+}
+
+// FormatBytes 字节数格式化为 B、KB、MB、GB、TB
+func FormatBytes(n uint64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(n)
+	i := 0
+	for value >= 1024 && i < len(units)-1 {
+		value /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f%s", value, units[i])
+}
+
+// FormatPercent 可用率格式化，没有检查记录时显示"无数据"
+func FormatPercent(p *float64) string {
+	if p == nil {
+		return "无数据"
+	}
+	return fmt.Sprintf("%.2f%%", *p)
+}
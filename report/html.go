@@ -0,0 +1,129 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// 趋势图在HTML中的尺寸（绘图区，不含坐标轴标签）
+const (
+	svgWidth  = 640
+	svgHeight = 180
+)
+
+// 趋势图横向网格线的位置（占纵轴上限的比例）
+var gridFractions = []float64{0, 0.25, 0.5, 0.75, 1}
+
+// htmlTemplate 独立的HTML报告：样式内联、趋势图为内嵌SVG，不依赖任何外部资源，可直接作为附件分享
+var htmlTemplate = template.Must(template.New("report").Funcs(Funcs).Funcs(template.FuncMap{
+	"polyline": func(c Chart, i int) string {
+		var b strings.Builder
+		for _, p := range c.Plot(i, svgWidth, svgHeight) {
+			fmt.Fprintf(&b, "%.1f,%.1f ", p[0], p[1])
+		}
+		return b.String()
+	},
+	"rgb":       func(c [3]uint8) template.CSS { return template.CSS(fmt.Sprintf("rgb(%d,%d,%d)", c[0], c[1], c[2])) },
+	"gridLines": func() []float64 { return gridFractions },
+	"gridY":     func(fraction float64) float64 { return svgHeight * (1 - fraction) },
+	"mul":       func(a, b float64) float64 { return a * b },
+	"last":      func(times []time.Time) time.Time { return times[len(times)-1] },
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Report.Hostname}} 监控报告 {{date .Report.Start}} ~ {{date .Report.End}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif; color: #1f2937; max-width: 760px; margin: 24px auto; padding: 0 16px; }
+h1 { font-size: 22px; margin-bottom: 4px; }
+h2 { font-size: 16px; margin: 28px 0 8px; border-bottom: 1px solid #e5e7eb; padding-bottom: 4px; }
+.meta { color: #6b7280; font-size: 13px; }
+.cards { display: flex; gap: 12px; flex-wrap: wrap; }
+.card { flex: 1; min-width: 150px; border: 1px solid #e5e7eb; border-radius: 6px; padding: 10px 12px; }
+.card .label { color: #6b7280; font-size: 12px; }
+.card .value { font-size: 20px; font-weight: 600; }
+table { width: 100%; border-collapse: collapse; font-size: 13px; }
+th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid #f3f4f6; }
+th { color: #6b7280; font-weight: normal; }
+.legend span { display: inline-block; margin-right: 12px; font-size: 12px; }
+.legend i { display: inline-block; width: 10px; height: 10px; margin-right: 4px; vertical-align: middle; }
+.empty { color: #9ca3af; font-size: 13px; }
+</style>
+</head>
+<body>
+<h1>{{.Report.Hostname}} 监控报告</h1>
+<div class="meta">{{date .Report.Start}} ~ {{date .Report.End}}（{{.Report.Period}}）· 生成于 {{date .Generated}}{{if eq .Report.Source "daily"}} · 超出原始数据保留期，指标来自每日汇总{{end}}</div>
+
+<h2>概览</h2>
+<div class="cards">
+<div class="card"><div class="label">CPU 平均 / 峰值</div><div class="value">{{printf "%.1f" .Report.CPU.Avg}}% / {{printf "%.1f" .Report.CPU.Peak}}%</div></div>
+<div class="card"><div class="label">内存 平均 / 峰值</div><div class="value">{{printf "%.1f" .Report.Memory.Avg}}% / {{printf "%.1f" .Report.Memory.Peak}}%</div></div>
+<div class="card"><div class="label">告警</div><div class="value">{{.Report.Alerts}} 次</div></div>
+</div>
+
+{{range .Charts}}
+<h2>{{.Title}}</h2>
+{{if .Times}}
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="-40 -10 700 220" width="100%">
+{{- $c := .}}
+{{- range $f := gridLines}}
+<line x1="0" x2="640" y1="{{gridY $f}}" y2="{{gridY $f}}" stroke="#e5e7eb" stroke-width="1"/>
+<text x="-6" y="{{gridY $f}}" font-size="10" fill="#6b7280" text-anchor="end" dominant-baseline="middle">{{printf "%g" (mul $c.Max $f)}}</text>
+{{- end}}
+{{- range $i, $s := .Series}}
+<polyline fill="none" stroke="{{rgb $s.Color}}" stroke-width="1.5" points="{{polyline $c $i}}"/>
+{{- end}}
+<text x="0" y="198" font-size="10" fill="#6b7280">{{date (index .Times 0)}}</text>
+<text x="640" y="198" font-size="10" fill="#6b7280" text-anchor="end">{{date (last .Times)}}</text>
+</svg>
+<div class="legend">{{range .Series}}<span><i style="background:{{rgb .Color}}"></i>{{.Name}}</span>{{end}}</div>
+{{else}}
+<div class="empty">该时间范围内没有数据</div>
+{{end}}
+{{end}}
+
+<h2>磁盘增长</h2>
+{{if .Report.Disks}}
+<table><tr><th>挂载点</th><th>期初已用</th><th>期末已用</th><th>增长</th><th>使用率</th></tr>
+{{range .Report.Disks}}<tr><td>{{.Path}}</td><td>{{.Start}}GB</td><td>{{.End}}GB</td><td>{{signed .Growth}}GB</td><td>{{printf "%.1f" .Usage}}%</td></tr>
+{{end}}</table>
+{{else}}<div class="empty">没有磁盘数据</div>{{end}}
+
+<h2>网络流量</h2>
+{{if .Report.Traffic}}
+<table><tr><th>网卡</th><th>上传</th><th>下载</th></tr>
+{{range .Report.Traffic}}<tr><td>{{.Interface}}</td><td>{{bytes .Upload}}</td><td>{{bytes .Download}}</td></tr>
+{{end}}</table>
+{{else}}<div class="empty">没有流量数据</div>{{end}}
+
+<h2>告警</h2>
+{{if .Report.Alerts}}
+<table><tr><th>级别</th><th>次数</th></tr>
+{{range .Report.AlertsBy}}<tr><td>{{.Key}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+<table><tr><th>最频繁的告警类型</th><th>次数</th></tr>
+{{range .Report.TopAlerts}}<tr><td>{{.Key}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+{{else}}<div class="empty">没有触发告警</div>{{end}}
+
+<h2>服务可用率</h2>
+{{if .Report.Services}}
+<table><tr><th>服务</th><th>检查次数</th><th>可用率</th><th>平均响应</th></tr>
+{{range .Report.Services}}<tr><td>{{.Name}}</td><td>{{.Checks}}</td><td>{{percent .Availability}}</td><td>{{if .AvgResponse}}{{printf "%.0f" (deref .AvgResponse)}}ms{{else}}-{{end}}</td></tr>
+{{end}}</table>
+{{else}}<div class="empty">没有服务</div>{{end}}
+</body>
+</html>
+`))
+
+// RenderHTML 生成独立的HTML报告
+func RenderHTML(doc *Document) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,318 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+)
+
+// A4纸张尺寸和页边距（pt）
+const (
+	pageWidth   = 595.28
+	pageHeight  = 841.89
+	pageMargin  = 50.0
+	chartHeight = 140.0
+)
+
+// PDF中使用的颜色
+var (
+	colorText  = [3]uint8{31, 41, 55}
+	colorMuted = [3]uint8{107, 114, 128}
+	colorGrid  = [3]uint8{229, 231, 235}
+)
+
+// pdfWriter 简单的PDF生成器：自上而下排版文字、表格和折线图，内容超出一页时自动换页。
+// 文字使用PDF阅读器内置的中文字体STSong-Light（Adobe-GB1，不嵌入字体文件），生成的文件很小且无外部依赖
+type pdfWriter struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64 // 当前排版位置（距页面底部）
+}
+
+// RenderPDF 生成PDF报告，内容与HTML报告一致
+func RenderPDF(doc *Document) ([]byte, error) {
+	w := &pdfWriter{}
+	w.newPage()
+
+	r := doc.Report
+	w.text(20, colorText, fmt.Sprintf("%s 监控报告", r.Hostname))
+	meta := fmt.Sprintf("%s ~ %s（%s） · 生成于 %s", FormatDate(r.Start), FormatDate(r.End), r.Period, FormatDate(doc.Generated))
+	w.text(10, colorMuted, meta)
+	if r.Source == "daily" {
+		w.text(10, colorMuted, "超出原始数据保留期，指标来自每日汇总")
+	}
+
+	w.heading("概览")
+	w.table([]string{"指标", "平均", "峰值"}, [][]string{
+		{"CPU", fmt.Sprintf("%.1f%%", r.CPU.Avg), fmt.Sprintf("%.1f%%", r.CPU.Peak)},
+		{"内存", fmt.Sprintf("%.1f%%", r.Memory.Avg), fmt.Sprintf("%.1f%%", r.Memory.Peak)},
+	})
+	w.text(11, colorText, fmt.Sprintf("告警: %d 次", r.Alerts))
+
+	for _, chart := range doc.Charts {
+		w.heading(chart.Title)
+		if len(chart.Times) == 0 {
+			w.text(10, colorMuted, "该时间范围内没有数据")
+			continue
+		}
+		w.chart(chart)
+	}
+
+	w.heading("磁盘增长")
+	if len(r.Disks) == 0 {
+		w.text(10, colorMuted, "没有磁盘数据")
+	} else {
+		rows := make([][]string, 0, len(r.Disks))
+		for _, d := range r.Disks {
+			rows = append(rows, []string{d.Path, fmt.Sprintf("%dGB", d.Start), fmt.Sprintf("%dGB", d.End),
+				fmt.Sprintf("%+dGB", d.Growth), fmt.Sprintf("%.1f%%", d.Usage)})
+		}
+		w.table([]string{"挂载点", "期初已用", "期末已用", "增长", "使用率"}, rows)
+	}
+
+	w.heading("网络流量")
+	if len(r.Traffic) == 0 {
+		w.text(10, colorMuted, "没有流量数据")
+	} else {
+		rows := make([][]string, 0, len(r.Traffic))
+		for _, t := range r.Traffic {
+			rows = append(rows, []string{t.Interface, FormatBytes(t.Upload), FormatBytes(t.Download)})
+		}
+		w.table([]string{"网卡", "上传", "下载"}, rows)
+	}
+
+	w.heading("告警")
+	if r.Alerts == 0 {
+		w.text(10, colorMuted, "没有触发告警")
+	} else {
+		rows := make([][]string, 0, len(r.AlertsBy)+len(r.TopAlerts))
+		for _, a := range r.AlertsBy {
+			rows = append(rows, []string{"级别 " + a.Key, fmt.Sprint(a.Count)})
+		}
+		for _, a := range r.TopAlerts {
+			rows = append(rows, []string{"类型 " + a.Key, fmt.Sprint(a.Count)})
+		}
+		w.table([]string{"分类", "次数"}, rows)
+	}
+
+	w.heading("服务可用率")
+	if len(r.Services) == 0 {
+		w.text(10, colorMuted, "没有服务")
+	} else {
+		rows := make([][]string, 0, len(r.Services))
+		for _, s := range r.Services {
+			response := "-"
+			if s.AvgResponse != nil {
+				response = fmt.Sprintf("%.0fms", *s.AvgResponse)
+			}
+			rows = append(rows, []string{s.Name, fmt.Sprint(s.Checks), FormatPercent(s.Availability), response})
+		}
+		w.table([]string{"服务", "检查次数", "可用率", "平均响应"}, rows)
+	}
+
+	return w.bytes(fmt.Sprintf("%s 监控报告", r.Hostname)), nil
+}
+
+// newPage 开始新的一页
+func (w *pdfWriter) newPage() {
+	w.page = &bytes.Buffer{}
+	w.pages = append(w.pages, w.page)
+	w.y = pageHeight - pageMargin
+}
+
+// ensure 当前页剩余空间不足height时换页
+func (w *pdfWriter) ensure(height float64) {
+	if w.y-height < pageMargin {
+		w.newPage()
+	}
+}
+
+// text 在当前位置输出一行文字
+func (w *pdfWriter) text(size float64, color [3]uint8, s string) {
+	w.ensure(size * 1.6)
+	w.y -= size * 1.4
+	w.textAt(pageMargin, w.y, size, color, s)
+	w.y -= size * 0.2
+}
+
+// heading 输出章节标题
+func (w *pdfWriter) heading(s string) {
+	w.ensure(40)
+	w.y -= 14
+	w.text(13, colorText, s)
+	w.strokeColor(colorGrid)
+	fmt.Fprintf(w.page, "0.5 w %.2f %.2f m %.2f %.2f l S\n", pageMargin, w.y, pageWidth-pageMargin, w.y)
+	w.y -= 4
+}
+
+// table 输出表格，各列等宽
+func (w *pdfWriter) table(headers []string, rows [][]string) {
+	width := (pageWidth - 2*pageMargin) / float64(len(headers))
+	row := func(cells []string, color [3]uint8) {
+		w.ensure(18)
+		w.y -= 14
+		for i, cell := range cells {
+			w.textAt(pageMargin+float64(i)*width, w.y, 10, color, truncate(cell, width-6, 10))
+		}
+		w.y -= 4
+		w.strokeColor(colorGrid)
+		fmt.Fprintf(w.page, "0.3 w %.2f %.2f m %.2f %.2f l S\n", pageMargin, w.y, pageWidth-pageMargin, w.y)
+	}
+
+	row(headers, colorMuted)
+	for _, cells := range rows {
+		row(cells, colorText)
+	}
+}
+
+// chart 输出折线图：网格线、纵轴刻度、数据序列、首尾时间和图例
+func (w *pdfWriter) chart(c Chart) {
+	const axis = 30.0 // 纵轴刻度标签的宽度
+	w.ensure(chartHeight + 40)
+	w.y -= 8
+	left, top := pageMargin+axis, w.y
+	width := pageWidth - 2*pageMargin - axis
+
+	for _, f := range gridFractions {
+		y := top - chartHeight*(1-f)
+		w.strokeColor(colorGrid)
+		fmt.Fprintf(w.page, "0.5 w %.2f %.2f m %.2f %.2f l S\n", left, y, left+width, y)
+		label := fmt.Sprintf("%g", c.Max*f)
+		w.textAt(left-6-textWidth(label, 8), y-3, 8, colorMuted, label)
+	}
+
+	for i, s := range c.Series {
+		points := c.Plot(i, width, chartHeight)
+		if len(points) == 0 {
+			continue
+		}
+		w.strokeColor(s.Color)
+		fmt.Fprintf(w.page, "1.2 w %.2f %.2f m", left+points[0][0], top-points[0][1])
+		for _, p := range points[1:] {
+			fmt.Fprintf(w.page, " %.2f %.2f l", left+p[0], top-p[1])
+		}
+		w.page.WriteString(" S\n")
+	}
+
+	w.y = top - chartHeight - 12
+	first, last := FormatDate(c.Times[0]), FormatDate(c.Times[len(c.Times)-1])
+	w.textAt(left, w.y, 8, colorMuted, first)
+	w.textAt(left+width-textWidth(last, 8), w.y, 8, colorMuted, last)
+
+	w.y -= 14
+	x := left
+	for _, s := range c.Series {
+		fmt.Fprintf(w.page, "%s %.2f %.2f 8 8 re f\n", rgb(s.Color, "rg"), x, w.y)
+		w.textAt(x+11, w.y, 9, colorText, s.Name)
+		x += 16 + textWidth(s.Name, 9) + 12
+	}
+	w.y -= 6
+}
+
+// textAt 在指定位置输出文字，字符串按UCS-2编码为十六进制
+func (w *pdfWriter) textAt(x, y, size float64, color [3]uint8, s string) {
+	fmt.Fprintf(w.page, "BT %s /F1 %.1f Tf %.2f %.2f Td <%s> Tj ET\n", rgb(color, "rg"), size, x, y, ucs2Hex(s))
+}
+
+// strokeColor 设置线条颜色
+func (w *pdfWriter) strokeColor(color [3]uint8) {
+	w.page.WriteString(rgb(color, "RG") + "\n")
+}
+
+// bytes 组装PDF文件：目录、页面树、字体、各页面及其内容流，最后写入交叉引用表
+func (w *pdfWriter) bytes(title string) []byte {
+	var objects []string
+	add := func(body string) int {
+		objects = append(objects, body)
+		return len(objects)
+	}
+
+	catalog := add("") // 目录和页面树在页面对象生成后填写
+	pagesID := add("")
+	descriptor := add("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	cidFont := add(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> /FontDescriptor %d 0 R "+
+		"/DW 1000 /W [1 95 500] >>", descriptor))
+	font := add(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H "+
+		"/DescendantFonts [%d 0 R] >>", cidFont))
+	info := add(fmt.Sprintf("<< /Title <%s> /Producer (server-monitor) >>", utf16Hex(title)))
+
+	kids := ""
+	for _, page := range w.pages {
+		content := add(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+		pageID := add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>", pagesID, pageWidth, pageHeight, font, content))
+		kids += fmt.Sprintf("%d 0 R ", pageID)
+	}
+	objects[catalog-1] = fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID)
+	objects[pagesID-1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(w.pages))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(objects)+1, catalog, info, xref)
+	return out.Bytes()
+}
+
+// rgb 颜色设置操作符，op为rg（填充）或RG（线条）
+func rgb(c [3]uint8, op string) string {
+	return fmt.Sprintf("%.3f %.3f %.3f %s", float64(c[0])/255, float64(c[1])/255, float64(c[2])/255, op)
+}
+
+// ucs2Hex 文字编码为UCS-2十六进制，基本多文种平面以外的字符（如表情符号）替换为问号
+func ucs2Hex(s string) string {
+	var b bytes.Buffer
+	for _, r := range s {
+		if r > 0xFFFF {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+// utf16Hex 文档信息中的文字编码为带BOM的UTF-16BE十六进制
+func utf16Hex(s string) string {
+	var b bytes.Buffer
+	b.WriteString("FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	return b.String()
+}
+
+// textWidth 估算文字宽度：ASCII字符半角，其余全角
+func textWidth(s string, size float64) float64 {
+	width := 0.0
+	for _, r := range s {
+		if r < 0x80 {
+			width += 0.5
+		} else {
+			width++
+		}
+	}
+	return width * size
+}
+
+// truncate 文字超出宽度时截断并以省略号结尾
+func truncate(s string, width, size float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"…", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,58 @@
+package report
+
+import (
+	"server-monitor/i18n"
+	"server-monitor/monitor"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 报告时间范围的上限
+const maxRange = 366 * 24 * time.Hour
+
+// 原始数据趋势图的数据点数
+const chartPoints = 120
+
+// Document 导出报告的内容：统计数据和趋势图数据
+type Document struct {
+	Report    *monitor.Report
+	Charts    []Chart
+	Generated time.Time
+}
+
+// ParseRange 解析报告时间范围，支持 h（小时）、d（天）、w（周）后缀，如 24h、7d、30d
+func ParseRange(value string) (time.Duration, error) {
+	units := map[string]time.Duration{"h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	if len(value) < 2 {
+		return 0, i18n.Errorf("无效的时间范围: %q，格式如 24h、7d、30d", value)
+	}
+	unit, ok := units[strings.ToLower(value[len(value)-1:])]
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if !ok || err != nil || n <= 0 {
+		return 0, i18n.Errorf("无效的时间范围: %q，格式如 24h、7d、30d", value)
+	}
+	if d := time.Duration(n) * unit; d <= maxRange {
+		return d, nil
+	}
+	return 0, i18n.Errorf("时间范围不能超过 %d 天", int(maxRange.Hours()/24))
+}
+
+// Generate 生成截止到当前、覆盖最近duration的报告，label为时间范围的描述，如 30d
+func Generate(label string, duration time.Duration) (*Document, error) {
+	end := time.Now()
+	report, err := monitor.BuildReportRange(label, end.Add(-duration), end)
+	if err != nil {
+		return nil, err
+	}
+	points, err := monitor.ReportPoints(report, chartPoints)
+	if err != nil {
+		return nil, i18n.Errorf("查询趋势数据失败: %v", err)
+	}
+
+	return &Document{
+		Report:    report,
+		Charts:    buildCharts(points),
+		Generated: end,
+	}, nil
+}
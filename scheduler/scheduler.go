@@ -1,256 +1,1182 @@
-package scheduler
-
-import (
-	"fmt"
-	"log"
-	"server-monitor/config"
-	"server-monitor/database"
-	"server-monitor/monitor"
-	"server-monitor/websocket"
-	"time"
-	"server-monitor/models"
-
-	"github.com/robfig/cron/v3"
-)
-
-type Scheduler struct {
-	cron     *cron.Cron
-	hub      *websocket.Hub
-	sysMon   *monitor.SystemMonitor
-	svcMon   *monitor.ServiceMonitor
-}
-
-// NewScheduler 创建新的调度器
-func NewScheduler(hub *websocket.Hub) *Scheduler {
-	return &Scheduler{
-		cron:   cron.New(cron.WithSeconds()),
-		hub:    hub,
-		sysMon: monitor.NewSystemMonitor(),
-		svcMon: monitor.NewServiceMonitor(),
-	}
-}
-
-// Start 启动调度器
-func (s *Scheduler) Start() {
-	log.Println("Starting scheduler...")
-
-	// 启动WebSocket指标广播器
-	s.hub.StartMetricsBroadcaster()
-
-	// 添加定时任务
-	s.addSystemMetricsJob()
-	s.addServiceCheckJob()
-	s.addDataCleanupJob()
-	s.addDiskUsageJob()
-	s.addNetworkTrafficJob()
-	s.addSystemLogPushJob()
-
-	// 启动cron调度器
-	s.cron.Start()
-
-	log.Println("Scheduler started successfully")
-}
-
-// Stop 停止调度器
-func (s *Scheduler) Stop() {
-	log.Println("Stopping scheduler...")
-	ctx := s.cron.Stop()
-	<-ctx.Done()
-	log.Println("Scheduler stopped")
-}
-
-// addSystemMetricsJob 添加系统指标收集任务
-func (s *Scheduler) addSystemMetricsJob() {
-	interval := config.AppConfig.Monitor.Interval
-	schedule := fmt.Sprintf("*/%d * * * * *", interval)
-	
-	_, err := s.cron.AddFunc(schedule, func() {
-		s.collectSystemMetrics()
-	})
-	
-	if err != nil {
-		log.Printf("Error adding system metrics job: %v", err)
-	} else {
-		log.Printf("System metrics job scheduled every %d seconds", interval)
-	}
-}
-
-// addServiceCheckJob 添加服务检查任务
-func (s *Scheduler) addServiceCheckJob() {
-	// 每30秒检查一次服务状态
-	_, err := s.cron.AddFunc("*/30 * * * * *", func() {
-		s.checkServices()
-	})
-	
-	if err != nil {
-		log.Printf("Error adding service check job: %v", err)
-	} else {
-		log.Println("Service check job scheduled every 30 seconds")
-	}
-}
-
-// addDataCleanupJob 添加数据清理任务
-func (s *Scheduler) addDataCleanupJob() {
-	// 每天凌晨2点清理旧数据
-	_, err := s.cron.AddFunc("0 0 2 * * *", func() {
-		s.cleanupOldData()
-	})
-	
-	if err != nil {
-		log.Printf("Error adding data cleanup job: %v", err)
-	} else {
-		log.Println("Data cleanup job scheduled daily at 2:00 AM")
-	}
-}
-
-// addDiskUsageJob 添加磁盘使用情况收集任务
-func (s *Scheduler) addDiskUsageJob() {
-	// 每5分钟收集一次磁盘使用情况
-	_, err := s.cron.AddFunc("0 */5 * * * *", func() {
-		s.collectDiskUsage()
-	})
-	
-	if err != nil {
-		log.Printf("Error adding disk usage job: %v", err)
-	} else {
-		log.Println("Disk usage job scheduled every 5 minutes")
-	}
-}
-
-// addNetworkTrafficJob 添加网络流量收集任务
-func (s *Scheduler) addNetworkTrafficJob() {
-	// 每30秒收集一次网络流量
-	_, err := s.cron.AddFunc("*/30 * * * * *", func() {
-		s.collectNetworkTraffic()
-	})
-	
-	if err != nil {
-		log.Printf("Error adding network traffic job: %v", err)
-	} else {
-		log.Println("Network traffic job scheduled every 30 seconds")
-	}
-}
-
-// addSystemLogPushJob 添加系统日志推送任务
-func (s *Scheduler) addSystemLogPushJob() {
-	_, err := s.cron.AddFunc("*/10 * * * * *", func() {
-		var logs []models.SystemLog
-		database.DB.Order("timestamp desc").Limit(5).Find(&logs)
-		s.hub.BroadcastSystemLog(logs)
-	})
-	if err != nil {
-		log.Printf("Error adding system log push job: %v", err)
-	} else {
-		log.Println("System log push job scheduled every 10 seconds")
-	}
-}
-
-// collectSystemMetrics 收集系统指标
-func (s *Scheduler) collectSystemMetrics() {
-	metrics, err := s.sysMon.CollectSystemMetrics()
-	if err != nil {
-		log.Printf("Error collecting system metrics: %v", err)
-		return
-	}
-
-	// 保存到数据库
-	err = s.sysMon.SaveMetrics(metrics)
-	if err != nil {
-		log.Printf("Error saving system metrics: %v", err)
-		return
-	}
-
-	// 检查告警
-	err = s.sysMon.CheckAlerts(metrics)
-	if err != nil {
-		log.Printf("Error checking alerts: %v", err)
-	}
-
-	// 广播到WebSocket客户端
-	s.hub.BroadcastSystemMetrics(metrics)
-
-	log.Printf("System metrics collected: CPU=%.2f%%, Memory=%.2f%%, Disk=%.2f%%, Upload=%.2fMB/s, Download=%.2fMB/s",
-		metrics.CPU, metrics.Memory, metrics.Disk, metrics.Upload, metrics.Download)
-}
-
-// checkServices 检查服务状态
-func (s *Scheduler) checkServices() {
-	err := s.svcMon.CheckAllServices()
-	if err != nil {
-		log.Printf("Error checking services: %v", err)
-		return
-	}
-
-	// 获取服务状态并广播
-	services, err := s.svcMon.GetServiceStatus()
-	if err != nil {
-		log.Printf("Error getting service status: %v", err)
-		return
-	}
-
-	s.hub.BroadcastServiceStatus(services)
-
-	log.Printf("Service status checked: %d services", len(services))
-}
-
-// cleanupOldData 清理旧数据
-func (s *Scheduler) cleanupOldData() {
-	log.Println("Starting data cleanup...")
-	
-	start := time.Now()
-	database.CleanupOldData()
-	
-	duration := time.Since(start)
-	log.Printf("Data cleanup completed in %v", duration)
-}
-
-// collectDiskUsage 收集磁盘使用情况
-func (s *Scheduler) collectDiskUsage() {
-	diskUsages, err := s.sysMon.CollectDiskUsage()
-	if err != nil {
-		log.Printf("Error collecting disk usage: %v", err)
-		return
-	}
-
-	// 保存到数据库
-	err = s.sysMon.SaveDiskUsage(diskUsages)
-	if err != nil {
-		log.Printf("Error saving disk usage: %v", err)
-		return
-	}
-
-	log.Printf("Disk usage collected: %d partitions", len(diskUsages))
-}
-
-// collectNetworkTraffic 收集网络流量
-func (s *Scheduler) collectNetworkTraffic() {
-	traffic, err := s.sysMon.CollectNetworkTraffic()
-	if err != nil {
-		log.Printf("Error collecting network traffic: %v", err)
-		return
-	}
-
-	// 保存到数据库
-	err = s.sysMon.SaveNetworkTraffic(traffic)
-	if err != nil {
-		log.Printf("Error saving network traffic: %v", err)
-		return
-	}
-
-	log.Printf("Network traffic collected: %d interfaces", len(traffic))
-}
-
-// GetJobStatus 获取任务状态
-func (s *Scheduler) GetJobStatus() []cron.Entry {
-	return s.cron.Entries()
-}
-
-// AddCustomJob 添加自定义任务
-func (s *Scheduler) AddCustomJob(schedule string, job func()) (cron.EntryID, error) {
-	return s.cron.AddFunc(schedule, job)
-}
-
-// RemoveJob 移除任务
-func (s *Scheduler) RemoveJob(id cron.EntryID) {
-	s.cron.Remove(id)
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"runtime"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"server-monitor/notify"
+	"server-monitor/websocket"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+type Scheduler struct {
+	cron      *cron.Cron
+	hub       *websocket.Hub
+	sysMon    *monitor.SystemMonitor
+	svcMon    *monitor.ServiceMonitor
+	repMon    *monitor.ReputationMonitor
+	certMon   *monitor.CertificateMonitor
+	noDataMon *monitor.NoDataMonitor
+	addrMon   *monitor.AddressMonitor
+	portMon   *monitor.PortMonitor
+	sshMon    *monitor.SSHAuthMonitor
+
+	mu       sync.Mutex
+	jobs     map[string]cron.Job     // 已注册的任务（含防重叠包装），用于调度中断后的补偿执行
+	entries  map[string]cron.EntryID // 已注册任务的cron条目，配置重新加载时移除
+	lastTick time.Time               // 中断检测任务上次执行的墙上时间
+}
+
+// JobStatus 任务最近一次执行的情况
+type JobStatus struct {
+	LastRun  time.Time `json:"last_run"`
+	Duration int64     `json:"duration"` // 耗时（毫秒）
+	Success  bool      `json:"success"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// 调度器运行状态，保存在内存中供健康检查读取，不依赖数据库
+var (
+	statusMu sync.RWMutex
+	running  bool
+	lastRuns = make(map[string]JobStatus)
+)
+
+// Status 获取调度器是否在运行及各任务最近一次执行的情况
+func Status() (bool, map[string]JobStatus) {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+
+	jobs := make(map[string]JobStatus, len(lastRuns))
+	for name, status := range lastRuns {
+		jobs[name] = status
+	}
+	return running, jobs
+}
+
+// NewScheduler 创建新的调度器
+func NewScheduler(hub *websocket.Hub) *Scheduler {
+	return &Scheduler{
+		cron:      cron.New(cron.WithSeconds()),
+		hub:       hub,
+		sysMon:    monitor.NewSystemMonitor(),
+		svcMon:    monitor.NewServiceMonitor(),
+		repMon:    monitor.NewReputationMonitor(),
+		certMon:   monitor.NewCertificateMonitor(),
+		noDataMon: monitor.NewNoDataMonitor(),
+		addrMon:   monitor.NewAddressMonitor(),
+		portMon:   monitor.NewPortMonitor(),
+		sshMon:    monitor.NewSSHAuthMonitor(),
+		jobs:      make(map[string]cron.Job),
+		entries:   make(map[string]cron.EntryID),
+	}
+}
+
+// Start 启动调度器
+func (s *Scheduler) Start() {
+	log.Println("Starting scheduler...")
+
+	// 采集任务在写入新数据后直接推送到Hub；告警在状态变化时推送
+	monitor.OnAlertEvent(func(event string, alert models.Alert) {
+		s.hub.BroadcastAlert(&alert)
+	})
+	// 系统日志在写入时推送
+	models.OnSystemLogCreated(s.hub.BroadcastSystemLog)
+
+	// 添加定时任务
+	s.addJobs()
+	s.addGapDetectionJob()
+
+	// 配置重新加载后按新的间隔重新注册任务
+	config.OnReload(s.Reload)
+
+	// 启动cron调度器
+	s.cron.Start()
+	statusMu.Lock()
+	running = true
+	statusMu.Unlock()
+
+	log.Println("Scheduler started successfully")
+}
+
+// addJobs 按当前配置注册所有监控任务
+func (s *Scheduler) addJobs() {
+	s.addSystemMetricsJob()
+	s.addServiceCheckJob()
+	s.addProcessWatchJob()
+	s.addDataCleanupJob()
+	s.addDiskUsageJob()
+	s.addNetworkTrafficJob()
+	s.addIPReputationJob()
+	s.addCertificateJob()
+	s.addNoDataJob()
+	s.addComparisonJob()
+	s.addAPIRuleJob()
+	s.addAddressJob()
+	s.addPortJob()
+	s.addSSHAuthJob()
+	s.addFail2banJob()
+	s.addUpdatesJob()
+	s.addClockJob()
+	s.addSoCJob()
+	s.addUPSJob()
+	s.addIPMIJob()
+	s.addRAIDJob()
+	s.addZFSJob()
+	s.addForecastJob()
+	s.addAnomalyJob()
+	s.addIntegrityJob()
+	s.addRollupJob()
+	s.addActionJob()
+	s.addReportJob()
+	s.addJournalJob()
+}
+
+// Reload 移除已注册的监控任务并按新配置重新注册，正在执行的任务不受影响
+func (s *Scheduler) Reload() {
+	s.mu.Lock()
+	for _, id := range s.entries {
+		s.cron.Remove(id)
+	}
+	s.jobs = make(map[string]cron.Job)
+	s.entries = make(map[string]cron.EntryID)
+	s.mu.Unlock()
+
+	s.addJobs()
+	log.Println("Scheduler jobs reloaded")
+}
+
+// Stop 停止调度器
+func (s *Scheduler) Stop() {
+	log.Println("Stopping scheduler...")
+	ctx := s.cron.Stop()
+	<-ctx.Done()
+	statusMu.Lock()
+	running = false
+	statusMu.Unlock()
+	log.Println("Scheduler stopped")
+}
+
+// Resume 重新启动Stop停止的调度器，已注册的任务不变；用于升级时新进程未能接管的情况
+func (s *Scheduler) Resume() {
+	s.cron.Start()
+	statusMu.Lock()
+	running = true
+	statusMu.Unlock()
+	log.Println("Scheduler resumed")
+}
+
+// addJob 注册定时任务并记录，以便调度中断后按补偿策略重新执行；每次执行都会记录到JobRun。
+// 上一次执行尚未结束时跳过本次（如慢磁盘上的磁盘采集），执行前按配置随机延迟以错开负载
+func (s *Scheduler) addJob(name, schedule string, job func() error) error {
+	logger := cron.VerbosePrintfLogger(log.New(log.Writer(), "job "+name+": ", log.LstdFlags))
+	wrapped := cron.NewChain(cron.SkipIfStillRunning(logger)).Then(cron.FuncJob(func() {
+		sleepJitter()
+		s.runJob(name, job)
+	}))
+
+	id, err := s.cron.AddJob(localSchedule(schedule), wrapped)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[name] = wrapped
+	s.entries[name] = id
+	return nil
+}
+
+// localSchedule cron表达式按显示时区（i18n.timezone）计算，如每天凌晨2点的清理；@every 和已指定 CRON_TZ 的计划不变
+func localSchedule(schedule string) string {
+	if strings.HasPrefix(schedule, "@every") || strings.HasPrefix(schedule, "CRON_TZ=") || strings.HasPrefix(schedule, "TZ=") {
+		return schedule
+	}
+	return "CRON_TZ=" + config.AppConfig.I18n.Location().String() + " " + schedule
+}
+
+// sleepJitter 执行前随机延迟，避免多个任务在同一时刻触发造成周期性负载尖峰
+func sleepJitter() {
+	jitter := config.AppConfig.Monitor.JobJitter
+	if jitter <= 0 {
+		return
+	}
+	time.Sleep(time.Duration(rand.Int63n(int64(jitter))) * time.Millisecond)
+}
+
+// runJob 执行任务并记录开始时间、耗时和执行结果
+func (s *Scheduler) runJob(name string, job func() error) {
+	startedAt := time.Now()
+	err := job()
+
+	run := models.JobRun{
+		Job:       name,
+		StartedAt: startedAt,
+		Duration:  time.Since(startedAt).Milliseconds(),
+		Success:   err == nil,
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	statusMu.Lock()
+	lastRuns[name] = JobStatus{LastRun: startedAt, Duration: run.Duration, Success: run.Success, Error: run.Error}
+	statusMu.Unlock()
+
+	if dbErr := database.DB.Create(&run).Error; dbErr != nil {
+		log.Printf("Error recording run of job %s: %v", name, dbErr)
+	}
+}
+
+// everySeconds 生成按固定秒数间隔执行的调度表达式
+func everySeconds(seconds int) string {
+	return fmt.Sprintf("@every %ds", seconds)
+}
+
+// addSystemMetricsJob 添加系统指标收集任务
+func (s *Scheduler) addSystemMetricsJob() {
+	interval := config.AppConfig.Monitor.Interval
+	
+	err := s.addJob("system_metrics", everySeconds(interval), func() error {
+		return s.collectSystemMetrics()
+	})
+	
+	if err != nil {
+		log.Printf("Error adding system metrics job: %v", err)
+	} else {
+		log.Printf("System metrics job scheduled every %d seconds", interval)
+	}
+}
+
+// addServiceCheckJob 添加服务检查任务
+func (s *Scheduler) addServiceCheckJob() {
+	interval := config.AppConfig.Monitor.ServiceInterval
+	err := s.addJob("service_check", everySeconds(interval), func() error {
+		return s.checkServices()
+	})
+	
+	if err != nil {
+		log.Printf("Error adding service check job: %v", err)
+	} else {
+		log.Printf("Service check job scheduled every %d seconds", interval)
+	}
+}
+
+// addProcessWatchJob 添加进程监视任务，与服务检查使用相同的间隔
+func (s *Scheduler) addProcessWatchJob() {
+	interval := config.AppConfig.Monitor.ServiceInterval
+	err := s.addJob("process_watch", everySeconds(interval), func() error {
+		err := monitor.CheckProcessWatches()
+		if err != nil {
+			log.Printf("Error checking process watches: %v", err)
+		}
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Error adding process watch job: %v", err)
+	} else {
+		log.Printf("Process watch job scheduled every %d seconds", interval)
+	}
+}
+
+// addDataCleanupJob 添加数据清理任务
+func (s *Scheduler) addDataCleanupJob() {
+	// 默认每天凌晨2点清理旧数据；低资源占用模式下只保留最近的数据，每分钟清理一次
+	schedule := config.AppConfig.Monitor.CleanupSchedule
+	if config.AppConfig.Minimal.Enabled {
+		schedule = everySeconds(60)
+	}
+	err := s.addJob("data_cleanup", schedule, func() error {
+		return s.cleanupOldData()
+	})
+	
+	if err != nil {
+		log.Printf("Error adding data cleanup job: %v", err)
+	} else {
+		log.Printf("Data cleanup job scheduled at %q", schedule)
+	}
+}
+
+// addDiskUsageJob 添加磁盘使用情况收集任务
+func (s *Scheduler) addDiskUsageJob() {
+	interval := config.AppConfig.Monitor.DiskInterval
+	err := s.addJob("disk_usage", everySeconds(interval), func() error {
+		return s.collectDiskUsage()
+	})
+	
+	if err != nil {
+		log.Printf("Error adding disk usage job: %v", err)
+	} else {
+		log.Printf("Disk usage job scheduled every %d seconds", interval)
+	}
+}
+
+// addNetworkTrafficJob 添加网络流量收集任务
+func (s *Scheduler) addNetworkTrafficJob() {
+	interval := config.AppConfig.Monitor.NetworkInterval
+	err := s.addJob("network_traffic", everySeconds(interval), func() error {
+		return s.collectNetworkTraffic()
+	})
+	
+	if err != nil {
+		log.Printf("Error adding network traffic job: %v", err)
+	} else {
+		log.Printf("Network traffic job scheduled every %d seconds", interval)
+	}
+}
+
+// addIPReputationJob 添加公网IP黑名单检查任务
+func (s *Scheduler) addIPReputationJob() {
+	if !config.AppConfig.Reputation.Enabled {
+		return
+	}
+
+	interval := config.AppConfig.Reputation.Interval
+	err := s.addJob("ip_reputation", everySeconds(interval), func() error {
+		return s.checkIPReputation()
+	})
+
+	if err != nil {
+		log.Printf("Error adding IP reputation job: %v", err)
+	} else {
+		log.Printf("IP reputation job scheduled every %d seconds", interval)
+	}
+}
+
+// addCertificateJob 添加证书发现与到期检查任务
+func (s *Scheduler) addCertificateJob() {
+	if !config.AppConfig.Certificates.Enabled {
+		return
+	}
+
+	interval := config.AppConfig.Certificates.Interval
+	err := s.addJob("certificates", everySeconds(interval), func() error {
+		return s.checkCertificates()
+	})
+
+	if err != nil {
+		log.Printf("Error adding certificate job: %v", err)
+	} else {
+		log.Printf("Certificate job scheduled every %d seconds", interval)
+	}
+
+	// 启动时立即执行一次发现，避免等待一个完整周期
+	s.mu.Lock()
+	job, ok := s.jobs["certificates"]
+	s.mu.Unlock()
+	if ok {
+		go job.Run()
+	}
+}
+
+// addAddressJob 添加IP地址变化检测任务
+func (s *Scheduler) addAddressJob() {
+	if !config.AppConfig.Addresses.Enabled {
+		return
+	}
+
+	interval := config.AppConfig.Addresses.Interval
+	err := s.addJob("addresses", everySeconds(interval), func() error {
+		return s.checkAddresses()
+	})
+
+	if err != nil {
+		log.Printf("Error adding address job: %v", err)
+	} else {
+		log.Printf("Address job scheduled every %d seconds", interval)
+	}
+}
+
+// addPortJob 添加监听端口变化检测任务
+func (s *Scheduler) addPortJob() {
+	if !config.AppConfig.Ports.Enabled {
+		return
+	}
+
+	interval := config.AppConfig.Ports.Interval
+	err := s.addJob("ports", everySeconds(interval), func() error {
+		return s.checkPorts()
+	})
+
+	if err != nil {
+		log.Printf("Error adding port job: %v", err)
+	} else {
+		log.Printf("Port job scheduled every %d seconds", interval)
+	}
+}
+
+// addSSHAuthJob 添加SSH登录监控任务
+func (s *Scheduler) addSSHAuthJob() {
+	sshAuth := config.AppConfig.SSHAuth
+	if !sshAuth.Enabled {
+		return
+	}
+
+	err := s.addJob("ssh_auth", everySeconds(sshAuth.Interval), func() error {
+		logins, err := s.sshMon.Check()
+		if err != nil {
+			log.Printf("Error checking SSH logins: %v", err)
+			return err
+		}
+		if logins > 0 {
+			log.Printf("SSH login attempts recorded: %d", logins)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error adding SSH auth job: %v", err)
+	} else {
+		log.Printf("SSH auth job scheduled every %d seconds", sshAuth.Interval)
+	}
+}
+
+// addFail2banJob 添加fail2ban封禁状态同步任务
+func (s *Scheduler) addFail2banJob() {
+	fail2ban := config.AppConfig.Fail2ban
+	if !fail2ban.Enabled {
+		return
+	}
+
+	err := s.addJob("fail2ban", everySeconds(fail2ban.Interval), func() error {
+		banned, err := monitor.SyncFail2ban()
+		if err != nil {
+			log.Printf("Error syncing fail2ban status: %v", err)
+			return err
+		}
+		if banned > 0 {
+			log.Printf("New fail2ban bans recorded: %d", banned)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error adding fail2ban job: %v", err)
+	} else {
+		log.Printf("Fail2ban job scheduled every %d seconds", fail2ban.Interval)
+	}
+}
+
+// addUpdatesJob 添加软件包更新检查任务；上次检查距今超过一个间隔时启动后立即检查一次，避免重启后长时间没有结果
+func (s *Scheduler) addUpdatesJob() {
+	updates := config.AppConfig.Updates
+	if !updates.Enabled {
+		return
+	}
+
+	err := s.addJob("updates", everySeconds(updates.Interval), func() error {
+		check, err := monitor.CheckUpdates()
+		if err != nil {
+			log.Printf("Error checking package updates: %v", err)
+			return err
+		}
+		monitor.CheckUpdateAlerts(check)
+		log.Printf("Package updates: %d pending, %d security, reboot required: %v", check.Pending, check.Security, check.RebootRequired)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error adding updates job: %v", err)
+		return
+	}
+	log.Printf("Updates job scheduled every %d seconds", updates.Interval)
+
+	last, err := monitor.GetLatestUpdateCheck()
+	if err != nil || (last != nil && time.Since(last.Timestamp) < time.Duration(updates.Interval)*time.Second) {
+		return
+	}
+	s.mu.Lock()
+	job, ok := s.jobs["updates"]
+	s.mu.Unlock()
+	if ok {
+		go job.Run()
+	}
+}
+
+// addClockJob 添加时钟偏差检测任务
+func (s *Scheduler) addClockJob() {
+	clock := config.AppConfig.Clock
+	if !clock.Enabled {
+		return
+	}
+
+	err := s.addJob("clock", everySeconds(clock.Interval), func() error {
+		check, err := monitor.CheckClock()
+		if err != nil {
+			log.Printf("Error checking clock drift: %v", err)
+			return err
+		}
+		monitor.CheckClockAlerts(check)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error adding clock job: %v", err)
+		return
+	}
+	log.Printf("Clock job scheduled every %d seconds", clock.Interval)
+
+	// 启动时立即检查一次，避免等待一个完整周期
+	s.mu.Lock()
+	job, ok := s.jobs["clock"]
+	s.mu.Unlock()
+	if ok {
+		go job.Run()
+	}
+}
+
+// addSoCJob 添加SoC温度和树莓派欠压、降频检测任务
+func (s *Scheduler) addSoCJob() {
+	soc := config.AppConfig.SoC
+	if !soc.Enabled || runtime.GOOS != "linux" {
+		return
+	}
+
+	err := s.addJob("soc", everySeconds(soc.Interval), func() error {
+		readings := monitor.CollectSoC()
+		if len(readings) == 0 {
+			return nil
+		}
+		if err := monitor.SaveSensorReadings(readings); err != nil {
+			log.Printf("Error saving SoC sensor readings: %v", err)
+			return err
+		}
+		monitor.CheckSoCAlerts(readings)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error adding SoC job: %v", err)
+		return
+	}
+	log.Printf("SoC job scheduled every %d seconds", soc.Interval)
+}
+
+// addUPSJob 添加UPS状态采集任务
+func (s *Scheduler) addUPSJob() {
+	ups := config.AppConfig.UPS
+	if !ups.Enabled {
+		return
+	}
+
+	err := s.addJob("ups", everySeconds(ups.Interval), func() error {
+		readings, err := monitor.CollectUPS()
+		if err != nil {
+			log.Printf("Error collecting UPS status: %v", err)
+			return err
+		}
+		if err := monitor.SaveSensorReadings(readings); err != nil {
+			log.Printf("Error saving UPS readings: %v", err)
+			return err
+		}
+		monitor.CheckUPSAlerts(readings)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error adding UPS job: %v", err)
+		return
+	}
+	log.Printf("UPS job scheduled every %d seconds", ups.Interval)
+}
+
+// addIPMIJob 添加IPMI/BMC传感器采集任务
+func (s *Scheduler) addIPMIJob() {
+	ipmi := config.AppConfig.IPMI
+	if !ipmi.Enabled {
+		return
+	}
+
+	err := s.addJob("ipmi", everySeconds(ipmi.Interval), func() error {
+		readings, faults, err := monitor.CollectIPMI()
+		if err != nil {
+			log.Printf("Error collecting IPMI sensors: %v", err)
+			return err
+		}
+		if err := monitor.SaveSensorReadings(readings); err != nil {
+			log.Printf("Error saving IPMI sensor readings: %v", err)
+			return err
+		}
+		monitor.CheckIPMIAlerts(faults)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error adding IPMI job: %v", err)
+		return
+	}
+	log.Printf("IPMI job scheduled every %d seconds", ipmi.Interval)
+}
+
+// addRAIDJob 添加mdadm软RAID健康检测任务
+func (s *Scheduler) addRAIDJob() {
+	raid := config.AppConfig.RAID
+	if !raid.Enabled || runtime.GOOS != "linux" {
+		return
+	}
+
+	err := s.addJob("raid", everySeconds(raid.Interval), func() error {
+		arrays, err := monitor.CheckRAID()
+		if err != nil {
+			log.Printf("Error checking RAID arrays: %v", err)
+			return err
+		}
+		// 没有阵列时同样检查，阵列被移除后解除其告警
+		monitor.CheckRAIDAlerts(arrays)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error adding RAID job: %v", err)
+		return
+	}
+	log.Printf("RAID job scheduled every %d seconds", raid.Interval)
+}
+
+// addZFSJob 添加ZFS存储池检测任务
+func (s *Scheduler) addZFSJob() {
+	zfs := config.AppConfig.ZFS
+	if !zfs.Enabled {
+		return
+	}
+
+	err := s.addJob("zfs", everySeconds(zfs.Interval), func() error {
+		pools, err := monitor.CheckZFS()
+		if err != nil {
+			log.Printf("Error checking ZFS pools: %v", err)
+			return err
+		}
+		// 没有存储池时同样检查，存储池被导出后解除其告警
+		monitor.CheckZFSAlerts(pools)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error adding ZFS job: %v", err)
+		return
+	}
+	log.Printf("ZFS job scheduled every %d seconds", zfs.Interval)
+}
+
+// addForecastJob 添加磁盘写满预测任务
+func (s *Scheduler) addForecastJob() {
+	forecast := config.AppConfig.Forecast
+	if !forecast.Enabled {
+		return
+	}
+
+	err := s.addJob("forecast", everySeconds(forecast.Interval), func() error {
+		forecasts, err := monitor.ForecastDisks("")
+		if err != nil {
+			log.Printf("Error forecasting disk usage: %v", err)
+			return err
+		}
+		monitor.CheckDiskForecastAlerts(forecasts)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error adding forecast job: %v", err)
+		return
+	}
+	log.Printf("Forecast job scheduled every %d seconds", forecast.Interval)
+}
+
+// addAnomalyJob 添加指标异常检测任务，无法识别的指标在启动时记录日志并跳过
+func (s *Scheduler) addAnomalyJob() {
+	anomaly := config.AppConfig.Anomaly
+	if !anomaly.Enabled {
+		return
+	}
+
+	metrics, errs := monitor.ParseAnomalyMetrics(anomaly.Metrics)
+	for _, err := range errs {
+		log.Printf("Skipping anomaly metric %v", err)
+	}
+	if len(metrics) == 0 {
+		return
+	}
+
+	err := s.addJob("anomaly", everySeconds(anomaly.Interval), func() error {
+		if err := monitor.CheckAnomalies(metrics); err != nil {
+			log.Printf("Error checking metric anomalies: %v", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error adding anomaly job: %v", err)
+		return
+	}
+	log.Printf("Anomaly job scheduled every %d seconds for %d metrics", anomaly.Interval, len(metrics))
+}
+
+// addIntegrityJob 添加数据库完整性检查任务
+func (s *Scheduler) addIntegrityJob() {
+	// 低资源占用模式使用内存数据库，无需检查
+	interval := config.AppConfig.Database.IntegrityInterval
+	if interval <= 0 || config.AppConfig.Minimal.Enabled {
+		return
+	}
+
+	err := s.addJob("integrity_check", everySeconds(interval), func() error {
+		return monitor.CheckDatabaseIntegrity()
+	})
+
+	if err != nil {
+		log.Printf("Error adding integrity check job: %v", err)
+	} else {
+		log.Printf("Integrity check job scheduled every %d seconds", interval)
+	}
+}
+
+// addRollupJob 添加每日汇总任务
+func (s *Scheduler) addRollupJob() {
+	if !config.AppConfig.Rollup.Enabled {
+		return
+	}
+
+	interval := config.AppConfig.Rollup.Interval
+	err := s.addJob("daily_rollup", everySeconds(interval), func() error {
+		written, err := monitor.RollupDaily()
+		if err != nil {
+			log.Printf("Error rolling up daily summaries: %v", err)
+			return err
+		}
+		log.Printf("Daily summaries updated: %d rows", written)
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error adding daily rollup job: %v", err)
+	} else {
+		log.Printf("Daily rollup job scheduled every %d seconds", interval)
+	}
+}
+
+// addActionJob 添加维护操作调度任务：每30秒检查一次已到计划时间的操作，操作本身按各自的cron表达式执行
+func (s *Scheduler) addActionJob() {
+	if !config.AppConfig.Actions.Enabled {
+		return
+	}
+
+	err := s.addJob("actions", everySeconds(30), monitor.RunDueActions)
+	if err != nil {
+		log.Printf("Error adding action job: %v", err)
+	} else {
+		log.Printf("Action job scheduled every %d seconds", 30)
+	}
+}
+
+// addReportJob 添加摘要报告任务：每天（或每周的指定一天）在指定整点发送报告
+func (s *Scheduler) addReportJob() {
+	reports := config.AppConfig.Reports
+	if !reports.Enabled {
+		return
+	}
+
+	weekday := "*"
+	if reports.Period == "weekly" {
+		weekday = fmt.Sprint(reports.Weekday)
+	}
+	schedule := fmt.Sprintf("0 0 %d * * %s", reports.Hour, weekday)
+	err := s.addJob("report", schedule, func() error {
+		report, err := monitor.BuildReport(reports.Period, time.Now())
+		if err != nil {
+			log.Printf("Error building %s report: %v", reports.Period, err)
+			return err
+		}
+		sent, err := notify.SendReport(report)
+		if err != nil {
+			log.Printf("Error sending %s report: %v", reports.Period, err)
+			return err
+		}
+		log.Printf("%s report sent to %d webhook(s)", reports.Period, sent)
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error adding report job: %v", err)
+	} else {
+		log.Printf("Report job scheduled at %q", schedule)
+	}
+}
+
+// addJournalJob 添加systemd journal采集任务，把系统事件写入系统日志
+func (s *Scheduler) addJournalJob() {
+	journal := config.AppConfig.Journal
+	if !journal.Enabled {
+		return
+	}
+
+	err := s.addJob("journal", everySeconds(journal.Interval), func() error {
+		written, err := monitor.CollectJournal()
+		if err != nil {
+			log.Printf("Error collecting journal: %v", err)
+			return err
+		}
+		if written > 0 {
+			log.Printf("Journal entries collected: %d", written)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error adding journal job: %v", err)
+	} else {
+		log.Printf("Journal job scheduled every %d seconds", journal.Interval)
+	}
+}
+
+// addComparisonJob 添加同比告警检查任务
+func (s *Scheduler) addComparisonJob() {
+	// 定期将最近的指标与昨天/上周同期对比
+	interval := config.AppConfig.Monitor.ComparisonInterval
+	err := s.addJob("comparison_check", everySeconds(interval), func() error {
+		err := monitor.CheckComparisonRules()
+		if err != nil {
+			log.Printf("Error checking comparison rules: %v", err)
+		}
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Error adding comparison check job: %v", err)
+	} else {
+		log.Printf("Comparison check job scheduled every %d seconds", interval)
+	}
+}
+
+// addAPIRuleJob 添加API延迟、错误率告警检查任务
+func (s *Scheduler) addAPIRuleJob() {
+	interval := config.AppConfig.Monitor.APIRuleInterval
+	err := s.addJob("api_rule_check", everySeconds(interval), func() error {
+		err := monitor.CheckAPIRules()
+		if err != nil {
+			log.Printf("Error checking API rules: %v", err)
+		}
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Error adding API rule check job: %v", err)
+	} else {
+		log.Printf("API rule check job scheduled every %d seconds", interval)
+	}
+}
+
+// addNoDataJob 添加无数据告警检查任务
+func (s *Scheduler) addNoDataJob() {
+	// 定期检查各数据源是否按时上报，以及远程主机是否按时推送
+	interval := config.AppConfig.Monitor.NoDataInterval
+	err := s.addJob("no_data_check", everySeconds(interval), func() error {
+		err := s.noDataMon.CheckRules()
+		if err != nil {
+			log.Printf("Error checking no-data rules: %v", err)
+		}
+		if serverErr := s.noDataMon.CheckServers(); serverErr != nil {
+			log.Printf("Error checking server heartbeats: %v", serverErr)
+			if err == nil {
+				err = serverErr
+			}
+		}
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Error adding no-data check job: %v", err)
+	} else {
+		log.Printf("No-data check job scheduled every %d seconds", interval)
+	}
+}
+
+// addGapDetectionJob 添加调度中断检测任务
+func (s *Scheduler) addGapDetectionJob() {
+	_, err := s.cron.AddFunc("@every 5s", func() {
+		s.detectGap()
+	})
+
+	if err != nil {
+		log.Printf("Error adding gap detection job: %v", err)
+	} else {
+		log.Printf("Gap detection job scheduled, catch-up policy: %s", config.AppConfig.Monitor.CatchUpPolicy)
+	}
+}
+
+// detectGap 检测进程挂起（系统休眠、虚拟机暂停）导致的调度中断
+func (s *Scheduler) detectGap() {
+	// 去掉单调时钟读数按墙上时间比较，挂起期间单调时钟不走，只有墙上时间会跳变
+	now := time.Now().Round(0)
+	last := s.lastTick
+	s.lastTick = now
+
+	if last.IsZero() {
+		return
+	}
+
+	gap := now.Sub(last)
+	threshold := time.Duration(config.AppConfig.Monitor.GapThreshold) * time.Second
+	if gap <= threshold {
+		return
+	}
+
+	message := i18n.Sprintf("检测到调度中断 %v（进程挂起或系统休眠），期间监控数据缺失", gap.Round(time.Second))
+	log.Printf("Scheduler gap detected: %v (from %s to %s)", gap.Round(time.Second),
+		last.Format(time.RFC3339), now.Format(time.RFC3339))
+
+	// 记录注释，用于解释图表中的数据空洞
+	annotation := models.Annotation{
+		Type:      "gap",
+		Message:   message,
+		StartTime: last,
+		EndTime:   now,
+		Timestamp: now,
+	}
+	database.DB.Create(&annotation)
+
+	systemLog := models.SystemLog{
+		Level:     "warning",
+		Category:  "system",
+		Message:   message,
+		Timestamp: now,
+	}
+	database.DB.Create(&systemLog)
+
+	if config.AppConfig.Monitor.CatchUpPolicy == "run_once" {
+		s.runMissedJobs(last, now)
+	}
+}
+
+// runMissedJobs 补偿执行中断期间 (last, now] 内本应触发的任务，每个任务只执行一次；
+// 各任务并发执行，经过与定时触发相同的防重叠包装，正在执行的任务不会重复执行
+func (s *Scheduler) runMissedJobs(last, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, job := range s.jobs {
+		entry := s.cron.Entry(s.entries[name])
+		if entry.Schedule == nil || entry.Schedule.Next(last).After(now) {
+			continue
+		}
+		log.Printf("Catch-up run: %s", name)
+		go job.Run()
+	}
+}
+
+// collectSystemMetrics 收集系统指标
+func (s *Scheduler) collectSystemMetrics() error {
+	metrics, err := s.sysMon.CollectSystemMetrics()
+	if err != nil {
+		log.Printf("Error collecting system metrics: %v", err)
+		return err
+	}
+
+	// 保存到数据库
+	err = s.sysMon.SaveMetrics(metrics)
+	if err != nil {
+		log.Printf("Error saving system metrics: %v", err)
+		return err
+	}
+
+	// 检查告警
+	err = s.sysMon.CheckAlerts(metrics)
+	if err != nil {
+		log.Printf("Error checking alerts: %v", err)
+	}
+
+	// 广播到WebSocket客户端
+	s.hub.BroadcastSystemMetrics(metrics)
+
+	log.Printf("System metrics collected: CPU=%.2f%%, Memory=%.2f%%, Disk=%.2f%%, Upload=%.2fMB/s, Download=%.2fMB/s",
+		metrics.CPU, metrics.Memory, metrics.Disk, metrics.Upload, metrics.Download)
+	return nil
+}
+
+// checkServices 检查服务状态
+func (s *Scheduler) checkServices() error {
+	err := s.svcMon.CheckAllServices()
+	if err != nil {
+		log.Printf("Error checking services: %v", err)
+		return err
+	}
+
+	// 获取服务状态并广播
+	services, err := s.svcMon.GetServiceStatus()
+	if err != nil {
+		log.Printf("Error getting service status: %v", err)
+		return err
+	}
+
+	s.hub.BroadcastServiceStatus(services)
+
+	log.Printf("Service status checked: %d services", len(services))
+	return nil
+}
+
+// cleanupOldData 清理旧数据
+func (s *Scheduler) cleanupOldData() error {
+	log.Println("Starting data cleanup...")
+	
+	stats := database.CleanupOldData()
+
+	var removed int64
+	for _, rows := range stats.Removed {
+		removed += rows
+	}
+	log.Printf("Data cleanup completed in %s, %d rows removed", stats.Duration, removed)
+
+	if len(stats.Errors) > 0 {
+		return errors.New(strings.Join(stats.Errors, "; "))
+	}
+	return nil
+}
+
+// collectDiskUsage 收集磁盘使用情况
+func (s *Scheduler) collectDiskUsage() error {
+	diskUsages, err := s.sysMon.CollectDiskUsage()
+	if err != nil {
+		log.Printf("Error collecting disk usage: %v", err)
+		return err
+	}
+
+	// 保存到数据库
+	err = s.sysMon.SaveDiskUsage(diskUsages)
+	if err != nil {
+		log.Printf("Error saving disk usage: %v", err)
+		return err
+	}
+
+	log.Printf("Disk usage collected: %d partitions", len(diskUsages))
+	return nil
+}
+
+// collectNetworkTraffic 收集网络流量
+func (s *Scheduler) collectNetworkTraffic() error {
+	traffic, err := s.sysMon.CollectNetworkTraffic()
+	if err != nil {
+		log.Printf("Error collecting network traffic: %v", err)
+		return err
+	}
+
+	// 保存到数据库
+	err = s.sysMon.SaveNetworkTraffic(traffic)
+	if err != nil {
+		log.Printf("Error saving network traffic: %v", err)
+		return err
+	}
+
+	log.Printf("Network traffic collected: %d interfaces", len(traffic))
+	return nil
+}
+
+// checkIPReputation 检查公网IP是否被列入黑名单
+func (s *Scheduler) checkIPReputation() error {
+	ip, err := s.repMon.DetectPublicIP()
+	if err != nil {
+		log.Printf("Error detecting public IP: %v", err)
+		return err
+	}
+
+	results := s.repMon.CheckBlocklists(ip)
+
+	// 保存到数据库
+	err = s.repMon.SaveResults(results)
+	if err != nil {
+		log.Printf("Error saving IP reputation results: %v", err)
+		return err
+	}
+
+	s.repMon.CheckAlerts(ip, results)
+
+	log.Printf("IP reputation checked: %s against %d blocklists", ip, len(results))
+	return nil
+}
+
+// checkCertificates 自动发现证书并检查有效期
+func (s *Scheduler) checkCertificates() error {
+	discovered := s.certMon.Discover()
+
+	certs, err := s.certMon.CheckAll()
+	if err != nil {
+		log.Printf("Error checking certificates: %v", err)
+		return err
+	}
+
+	s.certMon.CheckAlerts(certs)
+
+	log.Printf("Certificates checked: %d total, %d newly discovered", len(certs), discovered)
+	return nil
+}
+
+// checkAddresses 检测网卡地址和公网IP变化
+func (s *Scheduler) checkAddresses() error {
+	current, err := s.addrMon.CollectAddresses()
+	if err != nil {
+		log.Printf("Error collecting addresses: %v", err)
+		return err
+	}
+
+	changes, err := s.addrMon.DetectChanges(current)
+	if err != nil {
+		log.Printf("Error detecting address changes: %v", err)
+		return err
+	}
+
+	// 保存到数据库
+	err = s.addrMon.SaveChanges(changes)
+	if err != nil {
+		log.Printf("Error saving address changes: %v", err)
+		return err
+	}
+
+	// 先查询新公网IP的地理位置，告警消息中附带位置
+	monitor.LocatePublicIPs(current)
+	s.addrMon.CheckAlerts(current, changes)
+
+	if len(changes) > 0 {
+		log.Printf("Address changes detected on %d interface(s)", len(changes))
+	}
+	return nil
+}
+
+// checkPorts 检测监听端口变化
+func (s *Scheduler) checkPorts() error {
+	current, err := s.portMon.CollectPorts()
+	if err != nil {
+		log.Printf("Error collecting listening ports: %v", err)
+		return err
+	}
+
+	changes, err := s.portMon.DetectChanges(current)
+	if err != nil {
+		log.Printf("Error detecting port changes: %v", err)
+		return err
+	}
+
+	if err := s.portMon.SaveChanges(changes); err != nil {
+		log.Printf("Error saving port changes: %v", err)
+		return err
+	}
+	s.portMon.CheckAlerts(current, changes)
+
+	if len(changes) > 0 {
+		log.Printf("Listening port changes detected: %d", len(changes))
+	}
+	return nil
+}
+
+// GetJobStatus 获取任务状态
+func (s *Scheduler) GetJobStatus() []cron.Entry {
+	return s.cron.Entries()
+}
+
+// AddCustomJob 添加自定义任务
+func (s *Scheduler) AddCustomJob(schedule string, job func()) (cron.EntryID, error) {
+	return s.cron.AddFunc(schedule, job)
+}
+
+// RemoveJob 移除任务
+func (s *Scheduler) RemoveJob(id cron.EntryID) {
+	s.cron.Remove(id)
 } 
\ No newline at end of file
@@ -0,0 +1,169 @@
+package selftest
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/models"
+	"server-monitor/monitor"
+	"server-monitor/notify"
+	"server-monitor/upgrade"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CheckResult 单项自检结果
+type CheckResult struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Report 自检报告
+type Report struct {
+	StartedAt time.Time     `json:"started_at"`
+	Duration  string        `json:"duration"`
+	Passed    bool          `json:"passed"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// check 自检项
+type check struct {
+	name string
+	run  func() error
+}
+
+var (
+	lastReport   *Report
+	lastReportMu sync.RWMutex
+)
+
+// Last 获取最近一次自检报告，尚未执行过时返回nil
+func Last() *Report {
+	lastReportMu.RLock()
+	defer lastReportMu.RUnlock()
+	return lastReport
+}
+
+// Run 执行启动自检：数据库读写、采集器试运行、通知配置演练、端口绑定检查，并在日志中输出汇总
+func Run() *Report {
+	checks := []check{
+		{"database", checkDatabase},
+		{"collectors", checkCollectors},
+		{"notifier", notify.DryRun},
+		{"port", checkPort},
+	}
+
+	report := &Report{
+		StartedAt: time.Now(),
+		Passed:    true,
+	}
+	for _, c := range checks {
+		start := time.Now()
+		err := c.run()
+
+		result := CheckResult{
+			Name:     c.name,
+			Passed:   err == nil,
+			Duration: time.Since(start).Round(time.Microsecond).String(),
+		}
+		if err != nil {
+			result.Error = err.Error()
+			report.Passed = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	report.Duration = time.Since(report.StartedAt).Round(time.Millisecond).String()
+
+	logReport(report)
+
+	lastReportMu.Lock()
+	lastReport = report
+	lastReportMu.Unlock()
+
+	return report
+}
+
+// logReport 在日志中输出自检汇总
+func logReport(report *Report) {
+	passed := 0
+	for _, result := range report.Checks {
+		if result.Passed {
+			passed++
+			log.Printf("Self-test [OK]   %s (%s)", result.Name, result.Duration)
+		} else {
+			log.Printf("Self-test [FAIL] %s (%s): %s", result.Name, result.Duration, result.Error)
+		}
+	}
+
+	status := "passed"
+	if !report.Passed {
+		status = "FAILED"
+	}
+	log.Printf("Self-test %s: %d/%d checks passed in %s", status, passed, len(report.Checks), report.Duration)
+}
+
+// checkDatabase 在事务中写入并读回一条记录，最后回滚，不留下自检数据
+func checkDatabase() error {
+	errRollback := fmt.Errorf("rollback")
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		entry := models.SystemLog{
+			Level:     "info",
+			Category:  "selftest",
+			Message:   "self-test",
+			Timestamp: time.Now(),
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return fmt.Errorf("write: %v", err)
+		}
+
+		var readBack models.SystemLog
+		if err := tx.First(&readBack, entry.ID).Error; err != nil {
+			return fmt.Errorf("read: %v", err)
+		}
+		if readBack.Message != entry.Message {
+			return fmt.Errorf("read back mismatch")
+		}
+		return errRollback
+	})
+	if err == errRollback {
+		return nil
+	}
+	return err
+}
+
+// checkCollectors 试运行系统指标、磁盘和网络采集，不保存结果
+func checkCollectors() error {
+	sysMon := monitor.NewSystemMonitor()
+	if _, err := sysMon.CollectSystemMetrics(); err != nil {
+		return fmt.Errorf("system metrics: %v", err)
+	}
+	if _, err := sysMon.CollectDiskUsage(); err != nil {
+		return fmt.Errorf("disk usage: %v", err)
+	}
+	if _, err := sysMon.CollectNetworkTraffic(); err != nil {
+		return fmt.Errorf("network traffic: %v", err)
+	}
+	return nil
+}
+
+// checkPort 检查HTTP监听端口是否可绑定
+func checkPort() error {
+	// 升级启动或套接字激活时监听套接字继承自旧进程或systemd，端口本就处于占用状态
+	if upgrade.Inherited() || upgrade.SocketActivated() {
+		return nil
+	}
+
+	addr := net.JoinHostPort(config.AppConfig.Server.Host, config.AppConfig.Server.Port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return listener.Close()
+}
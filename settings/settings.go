@@ -0,0 +1,238 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"server-monitor/config"
+	"server-monitor/database"
+	"server-monitor/i18n"
+	"server-monitor/models"
+	"server-monitor/notify"
+	"sort"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidSetting 设置项不存在或值无效
+var ErrInvalidSetting = i18n.Errorf("无效的设置")
+
+type valueKind int
+
+const (
+	kindInt valueKind = iota
+	kindString
+	kindWebhooks
+)
+
+// editable 可在运行时修改的配置项；服务器地址、数据库等需要重启才能生效的配置不在其中
+var editable = map[string]valueKind{
+	"monitor.alert_cpu":          kindInt,
+	"monitor.alert_memory":       kindInt,
+	"monitor.alert_memory_basis": kindString,
+	"monitor.alert_disk":         kindInt,
+	"monitor.history_hours":      kindInt,
+	"monitor.job_run_hours":      kindInt,
+	"monitor.service_check_days": kindInt,
+	"monitor.interval":           kindInt,
+	"monitor.service_interval":   kindInt,
+	"monitor.disk_interval":      kindInt,
+	"monitor.network_interval":   kindInt,
+	"monitor.no_data_interval":   kindInt,
+	"monitor.cleanup_schedule":   kindString,
+	"notify.public_url":          kindString,
+	"notify.action_ttl":          kindInt,
+	"notify.silence_duration":    kindInt,
+	"notify.cooldown":            kindInt,
+	"notify.webhooks":            kindWebhooks,
+	"ingest.offline_after":       kindInt,
+	"i18n.locale":                kindString,
+	"i18n.timezone":              kindString,
+}
+
+// Item 设置项及其当前生效的值
+type Item struct {
+	Key        string      `json:"key"`
+	Value      interface{} `json:"value"`
+	Overridden bool        `json:"overridden"` // 是否被数据库中的设置覆盖，否则来自配置文件或默认值
+}
+
+// Load 启动时读取数据库中保存的设置，覆盖配置文件中的同名配置项
+func Load() error {
+	var rows []models.Setting
+	if err := database.DB.Find(&rows).Error; err != nil {
+		return err
+	}
+
+	values := make(map[string]interface{}, len(rows))
+	for _, row := range rows {
+		kind, ok := editable[row.Key]
+		if !ok {
+			log.Printf("Ignoring unknown setting %s", row.Key)
+			continue
+		}
+		value, err := parse(kind, json.RawMessage(row.Value))
+		if err != nil {
+			log.Printf("Ignoring invalid setting %s: %v", row.Key, err)
+			continue
+		}
+		values[row.Key] = value
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	if err := config.ApplyOverrides(values); err != nil {
+		return err
+	}
+
+	log.Printf("Applied %d setting(s) from database", len(values))
+	return nil
+}
+
+// Current 获取所有可修改设置项当前生效的值
+func Current() []Item {
+	keys := make([]string, 0, len(editable))
+	for key := range editable {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := make([]Item, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, Item{
+			Key:        key,
+			Value:      config.Redact(key, config.Get(key)),
+			Overridden: config.IsOverridden(key),
+		})
+	}
+	return items
+}
+
+// Update 校验并保存设置后立即生效，值为null时删除设置，恢复配置文件中的值
+func Update(changes map[string]json.RawMessage) error {
+	values := make(map[string]interface{}, len(changes))
+	for key, raw := range changes {
+		kind, ok := editable[key]
+		if !ok {
+			return i18n.Errorf("%v: 不支持修改 %s", ErrInvalidSetting, key)
+		}
+		if string(raw) == "null" {
+			values[key] = nil
+			continue
+		}
+
+		value, err := parse(kind, raw)
+		if err != nil {
+			return i18n.Errorf("%v: %s: %v", ErrInvalidSetting, key, err)
+		}
+		values[key] = value
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for key, value := range values {
+			if value == nil {
+				if err := tx.Where("key = ?", key).Delete(&models.Setting{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			if err := tx.Save(&models.Setting{Key: key, Value: string(encoded)}).Error; err != nil {
+				return err
+			}
+		}
+
+		// 校验失败时回滚，配置保持不变
+		if err := config.ApplyOverrides(values); err != nil {
+			return i18n.Errorf("%v: %v", ErrInvalidSetting, err)
+		}
+		return nil
+	})
+}
+
+// parse 按配置项类型解析JSON值
+func parse(kind valueKind, raw json.RawMessage) (interface{}, error) {
+	switch kind {
+	case kindInt:
+		var number float64
+		if err := json.Unmarshal(raw, &number); err != nil || number != math.Trunc(number) {
+			return nil, i18n.Errorf("必须是整数")
+		}
+		return int(number), nil
+	case kindString:
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, i18n.Errorf("必须是字符串")
+		}
+		return text, nil
+	case kindWebhooks:
+		var list []interface{}
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, i18n.Errorf("必须是Webhook数组")
+		}
+
+		if err := restoreRedactedURLs(list); err != nil {
+			return nil, err
+		}
+
+		// 按配置文件相同的规则解码，再逐个校验
+		decoder := viper.New()
+		decoder.Set("webhooks", list)
+		var webhooks []config.WebhookConfig
+		if err := decoder.UnmarshalKey("webhooks", &webhooks); err != nil {
+			return nil, err
+		}
+		for _, webhook := range webhooks {
+			plainURL, err := config.Decrypt(webhook.URL)
+			if err != nil {
+				return nil, fmt.Errorf("webhook %s: %v", webhook.Name, err)
+			}
+			webhook.URL = plainURL
+			if err := notify.ValidateWebhook(webhook); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	}
+	return nil, i18n.Errorf("未知的设置类型")
+}
+
+// restoreRedactedURLs 页面提交的Webhook地址仍为脱敏值时，按名称找回当前配置中的原始地址
+func restoreRedactedURLs(list []interface{}) error {
+	current := make(map[string]string)
+	if existing, ok := config.Get("notify.webhooks").([]interface{}); ok {
+		for _, item := range existing {
+			if webhook, ok := item.(map[string]interface{}); ok {
+				name, _ := webhook["name"].(string)
+				rawURL, _ := webhook["url"].(string)
+				current[name] = rawURL
+			}
+		}
+	}
+
+	for _, item := range list {
+		webhook, ok := item.(map[string]interface{})
+		if !ok {
+			return i18n.Errorf("必须是Webhook数组")
+		}
+		name, _ := webhook["name"].(string)
+		rawURL, _ := webhook["url"].(string)
+		if !config.IsRedacted(rawURL) {
+			continue
+		}
+
+		original, ok := current[name]
+		if !ok || config.RedactURL(original) != rawURL {
+			return i18n.Errorf("webhook %s 的地址已脱敏，请填写完整地址", name)
+		}
+		webhook["url"] = original
+	}
+	return nil
+}
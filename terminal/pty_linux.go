@@ -0,0 +1,121 @@
+//go:build linux
+
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// ptySession 本机伪终端中运行的shell
+type ptySession struct {
+	ptmx *os.File
+	cmd  *exec.Cmd
+}
+
+// openPTY 打开伪终端并在其中启动shell，shell以监控程序的用户身份运行
+func openPTY(shell string, cols, rows uint16) (Session, error) {
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("打开伪终端失败: %v", err)
+	}
+
+	// 通过 SyscallConn 操作文件描述符，避免 Fd() 将其切换为阻塞模式，关闭时读取才能立即返回
+	var ptn int
+	err = control(ptmx, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return err
+		}
+		n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+		if err != nil {
+			return err
+		}
+		ptn = n
+		return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: rows, Col: cols})
+	})
+	if err != nil {
+		ptmx.Close()
+		return nil, fmt.Errorf("初始化伪终端失败: %v", err)
+	}
+
+	tty, err := os.OpenFile("/dev/pts/"+strconv.Itoa(ptn), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		ptmx.Close()
+		return nil, fmt.Errorf("打开伪终端失败: %v", err)
+	}
+	defer tty.Close()
+
+	cmd := exec.Command(shell)
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	// 新建会话并以伪终端为控制终端，shell的作业控制和 Ctrl+C 才能正常工作
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	if err := cmd.Start(); err != nil {
+		ptmx.Close()
+		return nil, fmt.Errorf("启动 %s 失败: %v", shell, err)
+	}
+
+	return &ptySession{ptmx: ptmx, cmd: cmd}, nil
+}
+
+// control 在伪终端主设备的文件描述符上执行 ioctl
+func control(f *os.File, fn func(fd int) error) error {
+	raw, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	if err := raw.Control(func(fd uintptr) { fnErr = fn(int(fd)) }); err != nil {
+		return err
+	}
+	return fnErr
+}
+
+func (p *ptySession) Read(b []byte) (int, error) {
+	n, err := p.ptmx.Read(b)
+	// shell退出后从设备读取返回EIO，视为正常结束
+	if errors.Is(err, syscall.EIO) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (p *ptySession) Write(b []byte) (int, error) {
+	return p.ptmx.Write(b)
+}
+
+func (p *ptySession) Resize(cols, rows uint16) error {
+	return control(p.ptmx, func(fd int) error {
+		return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: rows, Col: cols})
+	})
+}
+
+// SecretInput 在主设备上读取的是从设备（shell一侧）的终端属性。shell的行编辑（readline）同样关闭回显，
+// 但使用非规范模式；getpass 等读取密码时保留规范模式只关闭回显。读取失败时按密码输入处理
+func (p *ptySession) SecretInput() bool {
+	secret := true
+	control(p.ptmx, func(fd int) error {
+		termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+		if err != nil {
+			return err
+		}
+		secret = termios.Lflag&unix.ICANON != 0 && termios.Lflag&unix.ECHO == 0
+		return nil
+	})
+	return secret
+}
+
+// Close 关闭伪终端并挂断shell所在的进程组，与断开SSH连接时的行为一致
+func (p *ptySession) Close() error {
+	err := p.ptmx.Close()
+	syscall.Kill(-p.cmd.Process.Pid, syscall.SIGHUP)
+	go p.cmd.Wait()
+	return err
+}
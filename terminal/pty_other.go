@@ -0,0 +1,10 @@
+//go:build !linux
+
+package terminal
+
+import "errors"
+
+// openPTY 其他平台不支持本机终端，配置校验时已拒绝 pty 模式
+func openPTY(shell string, cols, rows uint16) (Session, error) {
+	return nil, errors.New("当前平台不支持本机终端，请使用 ssh 模式")
+}
@@ -0,0 +1,111 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"server-monitor/config"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSH连接超时
+const sshDialTimeout = 10 * time.Second
+
+// sshSession 通过SSH打开的远程终端
+type sshSession struct {
+	client  *ssh.Client
+	session *ssh.Session
+	stdin   io.WriteCloser
+	output  *io.PipeReader // 合并后的标准输出和标准错误
+}
+
+// openSSH 连接 terminal.ssh.address 并打开交互式shell
+func openSSH(cfg config.TerminalSSHConfig, cols, rows uint16) (Session, error) {
+	var auth []ssh.AuthMethod
+	if cfg.PrivateKey != "" {
+		key, err := os.ReadFile(cfg.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("读取SSH私钥失败: %v", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("解析SSH私钥失败: %v", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if cfg.Password != "" {
+		auth = append(auth, ssh.Password(cfg.Password))
+	}
+
+	// 未配置 known_hosts 时不校验主机密钥，配置校验时已警告
+	hostKey := ssh.InsecureIgnoreHostKey()
+	if cfg.KnownHosts != "" {
+		callback, err := knownhosts.New(cfg.KnownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("读取 known_hosts 失败: %v", err)
+		}
+		hostKey = callback
+	}
+
+	client, err := ssh.Dial("tcp", cfg.Address, &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            auth,
+		HostKeyCallback: hostKey,
+		Timeout:         sshDialTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SSH连接 %s 失败: %v", cfg.Address, err)
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("创建SSH会话失败: %v", err)
+	}
+
+	modes := ssh.TerminalModes{ssh.ECHO: 1, ssh.TTY_OP_ISPEED: 14400, ssh.TTY_OP_OSPEED: 14400}
+	if err := session.RequestPty("xterm-256color", int(rows), int(cols), modes); err != nil {
+		session.Close()
+		client.Close()
+		return nil, fmt.Errorf("申请SSH终端失败: %v", err)
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		client.Close()
+		return nil, err
+	}
+	output, writer := io.Pipe()
+	session.Stdout, session.Stderr = writer, writer
+	if err := session.Shell(); err != nil {
+		session.Close()
+		client.Close()
+		return nil, fmt.Errorf("启动远程shell失败: %v", err)
+	}
+	// shell退出后结束输出，读取方收到 io.EOF
+	go func() {
+		session.Wait()
+		writer.Close()
+	}()
+
+	return &sshSession{client: client, session: session, stdin: stdin, output: output}, nil
+}
+
+func (s *sshSession) Read(p []byte) (int, error) {
+	return s.output.Read(p)
+}
+
+func (s *sshSession) Write(p []byte) (int, error) {
+	return s.stdin.Write(p)
+}
+
+func (s *sshSession) Resize(cols, rows uint16) error {
+	return s.session.WindowChange(int(rows), int(cols))
+}
+
+func (s *sshSession) Close() error {
+	s.session.Close()
+	return s.client.Close()
+}
@@ -0,0 +1,35 @@
+package terminal
+
+import (
+	"io"
+	"server-monitor/config"
+)
+
+// Session 终端会话：Read 读取终端输出，Write 写入键盘输入
+type Session interface {
+	io.ReadWriteCloser
+	// Resize 调整终端窗口大小
+	Resize(cols, rows uint16) error
+}
+
+// SecretInput 能判断程序是否正在读取不回显的输入的会话，目前只有本机PTY支持，SSH会话无法得知远端的终端属性
+type SecretInput interface {
+	// SecretInput 终端处于关闭回显的行输入模式时返回true，如sudo、ssh、passwd的密码提示
+	SecretInput() bool
+}
+
+// Open 按 terminal.mode 打开本机PTY或SSH会话，cols、rows 为初始窗口大小
+func Open(cfg config.TerminalConfig, cols, rows uint16) (Session, error) {
+	if cfg.Mode == "ssh" {
+		return openSSH(cfg.SSH, cols, rows)
+	}
+	return openPTY(cfg.Shell, cols, rows)
+}
+
+// Target 会话连接的目标，记录在审计日志中，如 pty /bin/bash、ssh root@10.0.0.5:22
+func Target(cfg config.TerminalConfig) string {
+	if cfg.Mode == "ssh" {
+		return "ssh " + cfg.SSH.User + "@" + cfg.SSH.Address
+	}
+	return "pty " + cfg.Shell
+}
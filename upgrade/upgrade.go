@@ -0,0 +1,76 @@
+package upgrade
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"server-monitor/config"
+	"strconv"
+	"sync/atomic"
+)
+
+// listenFDEnv 旧进程通过该环境变量告知新进程继承的监听套接字
+const listenFDEnv = "SERVER_MONITOR_LISTEN_FD"
+
+// inherited 当前进程是否由旧进程升级启动，监听套接字继承自旧进程
+var inherited = os.Getenv(listenFDEnv) != ""
+
+// handingOff 已启动新进程并等待其接管，此时收到的SIGTERM来自新进程
+var handingOff atomic.Bool
+
+// activated 当前进程是否由systemd套接字激活启动（LISTEN_PID为当前进程且传入了套接字）
+var activated = os.Getenv("LISTEN_PID") == strconv.Itoa(os.Getpid()) && os.Getenv("LISTEN_FDS") != "" && os.Getenv("LISTEN_FDS") != "0"
+
+// systemd传入的第一个套接字的文件描述符
+const systemdFirstFD = 3
+
+// Inherited 判断当前进程是否由旧进程升级启动
+func Inherited() bool {
+	return inherited
+}
+
+// HandingOff 判断当前进程是否正在向新进程移交，退出时已连接的客户端由新进程继续服务
+func HandingOff() bool {
+	return handingOff.Load()
+}
+
+// SocketActivated 判断当前进程是否由systemd套接字激活启动，监听端口由systemd持有
+func SocketActivated() bool {
+	return activated
+}
+
+// Listen 创建HTTP监听套接字；由旧进程升级启动时直接复用继承的套接字，升级期间新连接不会被拒绝；
+// 由systemd套接字激活启动时使用systemd传入的套接字，忽略配置的监听地址
+func Listen(addr string) (net.Listener, error) {
+	if !inherited && activated {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+
+		file := os.NewFile(systemdFirstFD, "systemd-socket")
+		defer file.Close()
+		return net.FileListener(file)
+	}
+	if !inherited {
+		return net.Listen("tcp", addr)
+	}
+
+	fd, err := strconv.Atoi(os.Getenv(listenFDEnv))
+	if err != nil {
+		return nil, fmt.Errorf("无效的 %s: %v", listenFDEnv, err)
+	}
+	os.Unsetenv(listenFDEnv)
+
+	file := os.NewFile(uintptr(fd), "listener")
+	defer file.Close()
+	return net.FileListener(file)
+}
+
+// writePIDFile 写入当前进程PID，升级后由新进程覆盖，便于systemd等通过PID文件跟踪主进程
+func writePIDFile() error {
+	path := config.AppConfig.Server.PIDFile
+	if path == "" {
+		return nil
+	}
+	return os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644)
+}
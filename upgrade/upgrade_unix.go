@@ -0,0 +1,73 @@
+//go:build !windows
+
+package upgrade
+
+import (
+	"errors"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// Notify 注册升级信号（SIGUSR2）
+func Notify(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGUSR2)
+}
+
+// Start 以相同参数启动磁盘上的新版本程序并移交监听套接字；新进程就绪后会通知当前进程退出，
+// 新进程启动失败时当前进程继续运行，新进程在接管前退出时调用onFail
+func Start(ln net.Listener, onFail func()) error {
+	tcpListener, ok := ln.(*net.TCPListener)
+	if !ok {
+		return errors.New("只支持移交TCP监听套接字")
+	}
+	file, err := tcpListener.File()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// ExtraFiles中的第一个文件在新进程中的描述符为3
+	cmd.ExtraFiles = []*os.File{file}
+	cmd.Env = append(os.Environ(), listenFDEnv+"=3")
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	log.Printf("Started new process %d, waiting for it to take over", cmd.Process.Pid)
+	handingOff.Store(true)
+	go func() {
+		err := cmd.Wait()
+		handingOff.Store(false)
+		log.Printf("Upgrade failed, new process exited before taking over: %v", err)
+		onFail()
+	}()
+	return nil
+}
+
+// Ready 启动完成后写入PID文件；由旧进程升级启动时通知旧进程退出
+func Ready() {
+	if err := writePIDFile(); err != nil {
+		log.Printf("Error writing pid file: %v", err)
+	}
+	if !inherited {
+		return
+	}
+
+	if err := syscall.Kill(os.Getppid(), syscall.SIGTERM); err != nil {
+		log.Printf("Error notifying old process: %v", err)
+		return
+	}
+	log.Printf("Took over from old process %d", os.Getppid())
+}
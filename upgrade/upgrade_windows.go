@@ -0,0 +1,25 @@
+//go:build windows
+
+package upgrade
+
+import (
+	"errors"
+	"log"
+	"net"
+	"os"
+)
+
+// Notify Windows不支持升级信号，不注册任何信号
+func Notify(c chan<- os.Signal) {}
+
+// Start Windows不支持移交监听套接字
+func Start(ln net.Listener, onFail func()) error {
+	return errors.New("当前平台不支持不停机升级")
+}
+
+// Ready 启动完成后写入PID文件
+func Ready() {
+	if err := writePIDFile(); err != nil {
+		log.Printf("Error writing pid file: %v", err)
+	}
+}
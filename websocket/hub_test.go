@@ -0,0 +1,227 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"server-monitor/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestClient 创建不带连接的客户端并注册到Hub，发送队列长度为 queue
+func newTestClient(t *testing.T, hub *Hub, id string, queue int) *Client {
+	t.Helper()
+	client := &Client{
+		ID:          id,
+		Send:        make(chan []byte, queue),
+		Hub:         hub,
+		RemoteAddr:  "127.0.0.1",
+		ConnectedAt: time.Now(),
+		transport:   "sse",
+		intervalCh:  make(chan time.Duration, 1),
+		pending:     make(map[string][]byte),
+		done:        make(chan struct{}),
+	}
+	if _, ok := hub.reserve(client.RemoteAddr); !ok {
+		t.Fatalf("reserve %s failed", id)
+	}
+	hub.Register <- client
+	return client
+}
+
+// syncHub 等待Run协程处理完之前的所有事件：通道无缓冲，发送成功说明Run已处理完上一个事件并回到select；
+// 重复注销已移除的探测客户端不做任何操作，只用于等待前一次注销完成
+func syncHub(t *testing.T, hub *Hub) {
+	t.Helper()
+	probe := newTestClient(t, hub, "probe", 1)
+	hub.Unregister <- probe
+	hub.Unregister <- probe
+}
+
+// drain 持续读取客户端的发送队列直到关闭，返回收到的消息
+func drain(client *Client) <-chan [][]byte {
+	result := make(chan [][]byte, 1)
+	go func() {
+		var messages [][]byte
+		for message := range client.Send {
+			messages = append(messages, message)
+		}
+		result <- messages
+	}()
+	return result
+}
+
+// alertIDs 解析广播的告警消息中的告警ID
+func alertIDs(t *testing.T, messages [][]byte) []uint {
+	t.Helper()
+	var ids []uint
+	for _, message := range messages {
+		var frame struct {
+			Type string       `json:"type"`
+			Data models.Alert `json:"data"`
+		}
+		if err := json.Unmarshal(message, &frame); err != nil {
+			t.Fatalf("invalid message %s: %v", message, err)
+		}
+		if frame.Type == "alert" {
+			ids = append(ids, frame.Data.ID)
+		}
+	}
+	return ids
+}
+
+func TestHubConcurrentRegisterUnregisterBroadcast(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	const clients = 20
+	const messages = 50
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var kept []*Client
+	var keptMessages []<-chan [][]byte
+
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client := newTestClient(t, hub, fmt.Sprintf("client-%d", i), 1024)
+			received := drain(client)
+			if i%2 == 0 {
+				hub.Unregister <- client
+				<-received
+				return
+			}
+			mu.Lock()
+			kept = append(kept, client)
+			keptMessages = append(keptMessages, received)
+			mu.Unlock()
+		}(i)
+	}
+	for i := 1; i <= messages; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			hub.BroadcastAlert(&models.Alert{ID: uint(i), Type: "test"})
+		}(i)
+	}
+	// 并发读取客户端信息
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			hub.ClientInfos()
+		}
+	}()
+	wg.Wait()
+
+	syncHub(t, hub)
+	if got := hub.ClientCount(); got != clients/2 {
+		t.Fatalf("ClientCount = %d, want %d", got, clients/2)
+	}
+
+	for _, client := range kept {
+		hub.Unregister <- client
+	}
+	for i, received := range keptMessages {
+		// 每个客户端注册后收到的告警序号严格递增，没有重复或乱序
+		var last uint64
+		for _, message := range <-received {
+			var frame struct {
+				Seq uint64 `json:"seq"`
+			}
+			if err := json.Unmarshal(message, &frame); err != nil {
+				t.Fatalf("invalid message %s: %v", message, err)
+			}
+			if frame.Seq <= last {
+				t.Errorf("client %s received seq %d after %d", kept[i].ID, frame.Seq, last)
+			}
+			last = frame.Seq
+		}
+	}
+
+	syncHub(t, hub)
+	if got := hub.ClientCount(); got != 0 {
+		t.Fatalf("ClientCount after unregister = %d, want 0", got)
+	}
+	if hub.connections != 0 || len(hub.perIP) != 0 {
+		t.Errorf("connections = %d, perIP = %v, want released", hub.connections, hub.perIP)
+	}
+}
+
+func TestHubEvictsSlowClient(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	slow := newTestClient(t, hub, "slow", 2)
+	fast := newTestClient(t, hub, "fast", 64)
+	received := drain(fast)
+
+	for i := 1; i <= 5; i++ {
+		hub.BroadcastAlert(&models.Alert{ID: uint(i), Type: "test"})
+	}
+	syncHub(t, hub)
+
+	if got := hub.ClientCount(); got != 1 {
+		t.Fatalf("ClientCount = %d, want 1 (slow client evicted)", got)
+	}
+	// 被移除的客户端的发送队列已关闭，只保留溢出前排队的消息
+	var queued [][]byte
+	for message := range slow.Send {
+		queued = append(queued, message)
+	}
+	if ids := alertIDs(t, queued); len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("slow client queued alerts %v, want [1 2]", ids)
+	}
+	if slow.trySend([]byte("late")) {
+		t.Error("trySend succeeded on evicted client")
+	}
+
+	hub.Unregister <- fast
+	ids := alertIDs(t, <-received)
+	if fmt.Sprint(ids) != "[1 2 3 4 5]" {
+		t.Errorf("fast client received alerts %v, want [1 2 3 4 5]", ids)
+	}
+}
+
+func TestHubCoalescesOnlySnapshotTopics(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	client := newTestClient(t, hub, "limited", 64)
+	client.interval = time.Minute
+
+	for i := 1; i <= 3; i++ {
+		hub.BroadcastSystemMetrics(&models.SystemMetrics{ID: uint(i), CPU: float64(i)})
+		hub.BroadcastAlert(&models.Alert{ID: uint(i), Type: "test"})
+	}
+	syncHub(t, hub)
+
+	// 告警立即按顺序进入发送队列，指标只保留最新一条等待合并推送
+	if got := len(client.Send); got != 3 {
+		t.Fatalf("queued %d messages, want 3 alerts", got)
+	}
+	var queued [][]byte
+	for i := 0; i < 3; i++ {
+		queued = append(queued, <-client.Send)
+	}
+	if ids := alertIDs(t, queued); fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("alerts = %v, want [1 2 3]", ids)
+	}
+
+	pending := client.takePending()
+	if len(pending) != 1 {
+		t.Fatalf("pending %d messages, want 1", len(pending))
+	}
+	var frame struct {
+		Data models.SystemMetrics `json:"data"`
+	}
+	if err := json.Unmarshal(pending[0], &frame); err != nil || frame.Data.ID != 3 {
+		t.Errorf("pending metrics = %s, want latest (id 3)", pending[0])
+	}
+	if got := client.dropped.Load(); got != 2 {
+		t.Errorf("dropped = %d, want 2 coalesced metrics", got)
+	}
+}
@@ -0,0 +1,164 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"server-monitor/config"
+	"time"
+)
+
+// replayEntry 已广播的消息及其序号
+type replayEntry struct {
+	seq  uint64
+	data []byte
+}
+
+// topicLog 单个主题的当前序号和最近广播的消息，用于客户端请求补发
+type topicLog struct {
+	serverID uint   // 主题所属主机的ID，本机为0
+	msgType  string // 消息类型
+	seq      uint64
+	entries  []replayEntry // 按序号递增，最多保留 websocket.replay_size 条
+
+	hash   uint64    // 上次广播的内容的哈希
+	sentAt time.Time // 上次广播的时间
+}
+
+// append 记录新广播的消息，超出缓存条数时丢弃最早的消息
+func (t *topicLog) append(seq uint64, data []byte, size int) {
+	t.seq = seq
+	if size <= 0 {
+		t.entries = nil
+		return
+	}
+	t.entries = append(t.entries, replayEntry{seq: seq, data: data})
+	if over := len(t.entries) - size; over > 0 {
+		t.entries = append(t.entries[:0], t.entries[over:]...)
+	}
+}
+
+// topicKey 主题在 Hub.topics 中的键：每个主机的同名主题单独编号，本机的主题键即消息类型
+func topicKey(serverID uint, msgType string) string {
+	if serverID == 0 {
+		return msgType
+	}
+	return fmt.Sprintf("%s@%d", msgType, serverID)
+}
+
+// topic 主机的主题，不存在时创建；调用方需持有 seqMu
+func (h *Hub) topic(serverID uint, msgType string) *topicLog {
+	key := topicKey(serverID, msgType)
+	topic, ok := h.topics[key]
+	if !ok {
+		topic = &topicLog{serverID: serverID, msgType: msgType}
+		h.topics[key] = topic
+	}
+	return topic
+}
+
+// unchanged 内容与主题上次广播的相同，且距上次广播不足 websocket.unchanged_interval 秒时返回true，不必再次推送；
+// 否则记录本次内容。调用方需持有 seqMu
+func (h *Hub) unchanged(serverID uint, msgType string, data []byte) bool {
+	hash := fnv.New64a()
+	hash.Write(data)
+	sum := hash.Sum64()
+
+	topic := h.topic(serverID, msgType)
+	now := time.Now()
+	if topic.seq > 0 && topic.hash == sum {
+		interval := config.AppConfig.WebSocket.UnchangedInterval
+		if interval == 0 || now.Sub(topic.sentAt) < time.Duration(interval)*time.Second {
+			return true
+		}
+	}
+	topic.hash, topic.sentAt = sum, now
+	return false
+}
+
+// nextSequence 为主机的主题分配下一个序号，并返回带序号的消息帧；调用方需持有 seqMu
+func (h *Hub) nextSequence(serverID uint, msgType string, payload interface{}) (*Message, error) {
+	topic := h.topic(serverID, msgType)
+
+	seq := topic.seq + 1
+	message, err := json.Marshal(map[string]interface{}{
+		"type": msgType,
+		"seq":  seq,
+		"data": payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	topic.append(seq, message, config.AppConfig.WebSocket.ReplaySize)
+	return &Message{Type: msgType, ServerID: serverID, Data: message}, nil
+}
+
+// Sequences 主机各主题当前的序号，随初始快照下发，客户端以此作为检测丢失消息的起点
+func (h *Hub) Sequences(serverID uint) map[string]uint64 {
+	h.seqMu.Lock()
+	defer h.seqMu.Unlock()
+
+	sequences := make(map[string]uint64)
+	for _, topic := range h.topics {
+		if topic.serverID == serverID {
+			sequences[topic.msgType] = topic.seq
+		}
+	}
+	return sequences
+}
+
+// replay 返回主机的主题中序号大于since的缓存消息；缓存已不包含的序号作为无法补发的范围 [missedFrom, missedTo] 返回，
+// 没有遗漏时 missedTo 为0
+func (h *Hub) replay(serverID uint, msgType string, since uint64) (messages []json.RawMessage, missedFrom, missedTo uint64) {
+	h.seqMu.Lock()
+	defer h.seqMu.Unlock()
+
+	topic, ok := h.topics[topicKey(serverID, msgType)]
+	if !ok || since >= topic.seq {
+		return nil, 0, 0
+	}
+
+	oldest := topic.seq + 1
+	if len(topic.entries) > 0 {
+		oldest = topic.entries[0].seq
+	}
+	if oldest > since+1 {
+		missedFrom, missedTo = since+1, oldest-1
+	}
+	for _, entry := range topic.entries {
+		if entry.seq > since {
+			messages = append(messages, entry.data)
+		}
+	}
+	return messages, missedFrom, missedTo
+}
+
+// resend 响应客户端的补发请求：先说明缓存中已没有的序号范围（客户端应通过REST接口重新加载），
+// 再把缓存中序号大于since的消息合并为一个resend帧发送
+func (c *Client) resend(msgType string, since uint64) {
+	messages, missedFrom, missedTo := c.Hub.replay(c.serverID, msgType, since)
+
+	if missedTo > 0 {
+		gap := map[string]interface{}{
+			"type":  "gap",
+			"topic": msgType,
+			"from":  missedFrom,
+			"to":    missedTo,
+		}
+		if data, err := json.Marshal(gap); err == nil {
+			c.trySend(data)
+		}
+	}
+
+	response := map[string]interface{}{
+		"type":  "resend",
+		"topic": msgType,
+		"data":  messages,
+	}
+	if messages == nil {
+		response["data"] = []json.RawMessage{}
+	}
+	if data, err := json.Marshal(response); err == nil {
+		c.trySend(data)
+	}
+}
@@ -0,0 +1,140 @@
+package websocket
+
+import (
+	"net/http"
+	"server-monitor/config"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BroadcastTypes Hub广播的消息类型，SSE连接可按类型订阅
+var BroadcastTypes = []string{"system_metrics", "service_status", "alert", "system_log"}
+
+// SSE连接的保活间隔，防止代理因长时间没有数据而断开连接
+const sseKeepalive = 30 * time.Second
+
+// StreamOptions SSE连接的订阅选项，与WebSocket连接的 ?host=、订阅和更新间隔对应
+type StreamOptions struct {
+	Host     string        // 订阅的主机标识，为空表示本机
+	ServerID uint          // 订阅的主机的ID，本机为0
+	Types    []string      // 只推送这些类型的广播，为空时全部推送
+	Interval time.Duration // 更新间隔，0表示实时推送
+}
+
+// ServeEvents 以Server-Sent Events推送与WebSocket相同的消息（初始快照和广播），供无法使用WebSocket的代理后的客户端
+// 或curl等简单客户端使用；连接计数、限速合并和管理接口与WebSocket客户端共用。阻塞到客户端断开、被管理员断开或服务关闭，
+// 服务正在关闭或连接数超出限制时不建立连接，返回应答的HTTP状态码
+func (h *Hub) ServeEvents(c *gin.Context, opts StreamOptions) (int, bool) {
+	if h.shuttingDown.Load() {
+		return http.StatusServiceUnavailable, false
+	}
+	remoteAddr := c.ClientIP()
+	if status, ok := h.reserve(remoteAddr); !ok {
+		return status, false
+	}
+
+	client := &Client{
+		ID:         generateClientID(),
+		Send:       make(chan []byte, sendQueueSize()),
+		Hub:        h,
+		intervalCh: make(chan time.Duration, 1),
+		pending:    make(map[string][]byte),
+		done:       make(chan struct{}),
+
+		RemoteAddr:    remoteAddr,
+		ConnectedAt:   time.Now(),
+		Host:          opts.Host,
+		serverID:      opts.ServerID,
+		transport:     "sse",
+		subscriptions: opts.Types,
+	}
+	if len(opts.Types) > 0 {
+		client.types = make(map[string]bool)
+		for _, t := range opts.Types {
+			client.types[t] = true
+		}
+	}
+	if opts.Interval > 0 || config.AppConfig.WebSocket.MinInterval > 0 {
+		client.SetInterval(opts.Interval)
+	}
+
+	h.Register <- client
+	client.sendSnapshot()
+	client.streamEvents(c)
+	return http.StatusOK, true
+}
+
+// streamEvents 逐条写出发送队列中的消息，每条消息为一个SSE事件，data为与WebSocket帧相同的JSON
+func (c *Client) streamEvents(ctx *gin.Context) {
+	keepalive := time.NewTicker(sseKeepalive)
+	// 合并推送定时器，客户端未设置更新间隔时保持停止
+	flushTicker := time.NewTicker(time.Hour)
+	flushTicker.Stop()
+	defer func() {
+		keepalive.Stop()
+		flushTicker.Stop()
+		close(c.done)
+	}()
+
+	w := ctx.Writer
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// 禁止nginx缓冲事件流
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	// 断开后EventSource等待3秒重连，重连后重新收到初始快照
+	w.WriteString("retry: 3000\n\n")
+	w.Flush()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Request.Context().Done():
+			c.Hub.Unregister <- c
+			return
+		case interval := <-c.intervalCh:
+			if interval > 0 {
+				flushTicker.Reset(interval)
+			} else {
+				flushTicker.Stop()
+			}
+		case <-flushTicker.C:
+			err = c.writeEvents(w, c.takePending())
+		case message, ok := <-c.Send:
+			if !ok {
+				return
+			}
+			err = c.writeEvents(w, [][]byte{message})
+		case <-keepalive.C:
+			_, err = w.WriteString(": keepalive\n\n")
+			w.Flush()
+		}
+		if err != nil {
+			c.Hub.Unregister <- c
+			return
+		}
+	}
+}
+
+// writeEvents 写出SSE事件并立即刷新
+func (c *Client) writeEvents(w gin.ResponseWriter, messages [][]byte) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	for _, message := range messages {
+		if _, err := w.WriteString("data: " + string(message) + "\n\n"); err != nil {
+			return err
+		}
+		c.sent.Add(1)
+	}
+	w.Flush()
+	return nil
+}
+
+// ShuttingDown 服务是否正在关闭，关闭过程中拒绝新连接
+func (h *Hub) ShuttingDown() bool {
+	return h.shuttingDown.Load()
+}
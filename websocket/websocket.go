@@ -1,12 +1,17 @@
 package websocket
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"log"
 	"net/http"
+	"server-monitor/config"
 	"server-monitor/database"
 	"server-monitor/models"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +24,13 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// Message 广播消息，Type用于按主题合并同类更新
+type Message struct {
+	Type     string
+	ServerID uint // 消息所属主机的ID，本机为0，只投递给订阅了该主机的客户端
+	Data     []byte
+}
+
 // Client WebSocket客户端
 type Client struct {
 	ID       string
@@ -26,24 +38,87 @@ type Client struct {
 	Send     chan []byte
 	Hub      *Hub
 	mu       sync.Mutex
+
+	RemoteAddr  string    // 客户端地址
+	ConnectedAt time.Time // 连接建立时间
+	Host        string    // 订阅的主机标识（连接时的 ?host= 参数），为空表示本机
+	serverID    uint      // 订阅的主机的ID，本机为0
+	transport   string    // 连接方式：websocket 或 sse
+	types       map[string]bool // 只推送这些类型的广播，为空时全部推送
+
+	interval      time.Duration      // 客户端请求的更新间隔，0表示实时推送
+	intervalCh    chan time.Duration // 通知writePump调整合并推送间隔
+	subscriptions []string           // 客户端订阅的数据类型
+	pending       map[string][]byte  // 等待合并推送的快照类消息（每个主题只保留最新一条）
+	pendingOrder  []string           // 待推送主题的到达顺序
+	closed        bool               // 发送队列是否已关闭
+	closeFrame    []byte             // 发送队列关闭后writePump发出的关闭帧，为空时发送不带状态码的关闭帧
+	done          chan struct{}      // writePump退出时关闭
+
+	sent    atomic.Int64 // 已发送的消息数
+	dropped atomic.Int64 // 因发送队列溢出或合并推送被丢弃的消息数
 }
 
 // Hub WebSocket中心
 type Hub struct {
 	Clients    map[*Client]bool
-	Broadcast  chan []byte
+	Broadcast  chan *Message
 	Register   chan *Client
 	Unregister chan *Client
 	mu         sync.RWMutex
+
+	limitMu     sync.Mutex     // 保护连接计数
+	connections int            // 当前连接数（含正在握手的连接）
+	perIP       map[string]int // 每个IP的连接数
+
+	shuttingDown atomic.Bool // 关闭过程中拒绝新连接
+
+	seqMu  sync.Mutex           // 保证序号分配与投递顺序一致
+	topics map[string]*topicLog // 各主题的序号和最近广播的消息
 }
 
 // NewHub 创建新的Hub
 func NewHub() *Hub {
 	return &Hub{
 		Clients:    make(map[*Client]bool),
-		Broadcast:  make(chan []byte),
+		Broadcast:  make(chan *Message),
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
+		perIP:      make(map[string]int),
+		topics:     make(map[string]*topicLog),
+	}
+}
+
+// reserve 为新连接占用配额，超出总连接数或单IP连接数限制时返回对应的HTTP状态码
+func (h *Hub) reserve(ip string) (int, bool) {
+	h.limitMu.Lock()
+	defer h.limitMu.Unlock()
+
+	maxConnections := config.AppConfig.WebSocket.MaxConnections
+	if maxConnections > 0 && h.connections >= maxConnections {
+		return http.StatusServiceUnavailable, false
+	}
+
+	maxPerIP := config.AppConfig.WebSocket.MaxPerIP
+	if maxPerIP > 0 && h.perIP[ip] >= maxPerIP {
+		return http.StatusTooManyRequests, false
+	}
+
+	h.connections++
+	h.perIP[ip]++
+	return http.StatusOK, true
+}
+
+// release 释放连接占用的配额
+func (h *Hub) release(ip string) {
+	h.limitMu.Lock()
+	defer h.limitMu.Unlock()
+
+	h.connections--
+	if h.perIP[ip] <= 1 {
+		delete(h.perIP, ip)
+	} else {
+		h.perIP[ip]--
 	}
 }
 
@@ -58,27 +133,325 @@ func (h *Hub) Run() {
 			log.Printf("Client %s connected", client.ID)
 
 		case client := <-h.Unregister:
-			h.mu.Lock()
-			if _, ok := h.Clients[client]; ok {
-				delete(h.Clients, client)
-				close(client.Send)
+			if h.removeClient(client) {
+				log.Printf("Client %s disconnected", client.ID)
 			}
-			h.mu.Unlock()
-			log.Printf("Client %s disconnected", client.ID)
 
 		case message := <-h.Broadcast:
+			// 读锁下只做投递，不修改客户端集合；发送队列溢出的慢客户端先记录下来
+			var slowClients []*Client
 			h.mu.RLock()
 			for client := range h.Clients {
-				select {
-				case client.Send <- message:
-				default:
-					close(client.Send)
-					delete(h.Clients, client)
+				// 每个客户端只接收所订阅主机的消息
+				if client.serverID != message.ServerID || !client.accepts(message.Type) {
+					continue
+				}
+				// 限速客户端的快照类消息先合并，由writePump按其间隔推送
+				if client.enqueue(message) {
+					continue
+				}
+				if !client.offer(message.Data) {
+					slowClients = append(slowClients, client)
 				}
 			}
 			h.mu.RUnlock()
+
+			// 释放读锁后走与Unregister相同的注销流程
+			for _, client := range slowClients {
+				if h.removeClient(client) {
+					log.Printf("Client %s removed: send queue overflow", client.ID)
+				}
+			}
+		}
+	}
+}
+
+// removeClient 从Hub中注销客户端并关闭其发送队列，返回客户端是否仍在Hub中。
+// 客户端集合只在Run协程中修改，这里在写锁下删除，保证发送队列只被关闭一次
+func (h *Hub) removeClient(client *Client) bool {
+	h.mu.Lock()
+	_, ok := h.Clients[client]
+	if ok {
+		delete(h.Clients, client)
+	}
+	h.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	client.closeSend()
+	h.release(client.RemoteAddr)
+	return true
+}
+
+// ClientInfo 客户端连接信息，用于管理接口展示
+type ClientInfo struct {
+	ID            string    `json:"id"`
+	RemoteAddr    string    `json:"remote_addr"`
+	ConnectedAt   time.Time `json:"connected_at"`
+	Host          string    `json:"host"` // 订阅的主机标识，为空表示本机
+	Transport     string    `json:"transport"` // 连接方式：websocket 或 sse
+	Subscriptions []string  `json:"subscriptions"`
+	Interval      float64   `json:"interval"`  // 更新间隔（秒），0表示实时推送
+	Queued        int       `json:"queued"`    // 排队等待发送的消息数
+	Sent          int64     `json:"sent"`      // 已发送的消息数
+	Dropped       int64     `json:"dropped"`   // 被丢弃的消息数
+	SendRate      float64   `json:"send_rate"` // 连接以来的平均发送速率（条/秒）
+}
+
+// ClientCount 获取当前已连接的客户端数量
+func (h *Hub) ClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.Clients)
+}
+
+// ClientInfos 获取当前所有已连接客户端的信息
+func (h *Hub) ClientInfos() []ClientInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	infos := make([]ClientInfo, 0, len(h.Clients))
+	for client := range h.Clients {
+		sent := client.sent.Load()
+		sendRate := 0.0
+		if elapsed := time.Since(client.ConnectedAt).Seconds(); elapsed > 0 {
+			sendRate = float64(sent) / elapsed
+		}
+
+		client.mu.Lock()
+		infos = append(infos, ClientInfo{
+			ID:            client.ID,
+			RemoteAddr:    client.RemoteAddr,
+			ConnectedAt:   client.ConnectedAt,
+			Host:          client.Host,
+			Transport:     client.transport,
+			Subscriptions: append([]string{}, client.subscriptions...),
+			Interval:      client.interval.Seconds(),
+			Queued:        len(client.Send) + len(client.pending),
+			Sent:          sent,
+			Dropped:       client.dropped.Load(),
+			SendRate:      sendRate,
+		})
+		client.mu.Unlock()
+	}
+	return infos
+}
+
+// Disconnect 断开指定ID的客户端，返回是否找到该客户端
+func (h *Hub) Disconnect(id string) bool {
+	h.mu.RLock()
+	var target *Client
+	for client := range h.Clients {
+		if client.ID == id {
+			target = client
+			break
+		}
+	}
+	h.mu.RUnlock()
+
+	if target == nil {
+		return false
+	}
+	// SSE连接没有关闭帧，注销后发送队列关闭，输出协程随之结束
+	if target.Socket == nil {
+		return target.Hub.removeClient(target)
+	}
+
+	// 发送关闭帧后关闭连接，readPump读取失败后会自动注销客户端
+	target.Socket.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "disconnected by administrator"),
+		time.Now().Add(time.Second))
+	target.Socket.Close()
+	return true
+}
+
+// Shutdown 进程退出前通知所有客户端重连：拒绝新连接，向每个客户端发送 server_restarting 消息，
+// 由writePump发完已排队的消息后以"服务重启"关闭帧断开；ctx到期时强制关闭尚未断开的连接
+func (h *Hub) Shutdown(ctx context.Context) {
+	h.shuttingDown.Store(true)
+
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.Clients))
+	for client := range h.Clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	notice, _ := json.Marshal(map[string]interface{}{
+		"type":      "server_restarting",
+		"timestamp": time.Now().Unix(),
+	})
+	closeFrame := websocket.FormatCloseMessage(websocket.CloseServiceRestart, "server restarting")
+	for _, client := range clients {
+		client.trySend(notice)
+		client.mu.Lock()
+		client.closeFrame = closeFrame
+		client.mu.Unlock()
+		h.removeClient(client)
+	}
+
+	for _, client := range clients {
+		select {
+		case <-client.done:
+		case <-ctx.Done():
+			if client.Socket != nil {
+				client.Socket.Close()
+			}
+		}
+	}
+	log.Printf("WebSocket hub shut down, %d clients notified", len(clients))
+}
+
+// Drain 升级移交时代替Shutdown：拒绝新连接（由新进程接受），已连接的客户端不通知也不断开，
+// 等待它们自行断开；ctx到期时不再等待，剩余的连接随进程退出断开
+func (h *Hub) Drain(ctx context.Context) {
+	h.shuttingDown.Store(true)
+
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.Clients))
+	for client := range h.Clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		select {
+		case <-client.done:
+		case <-ctx.Done():
+			log.Printf("WebSocket hub drain timed out, %d clients still connected", h.ClientCount())
+			return
+		}
+	}
+	log.Printf("WebSocket hub drained, %d clients disconnected", len(clients))
+}
+
+// closeSend 关闭发送队列，之后通过trySend发送的消息会被丢弃
+func (c *Client) closeSend() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	close(c.Send)
+}
+
+// trySend 在Run协程之外向客户端发送消息（如ping响应、初始快照），发送队列已关闭或已满时丢弃
+func (c *Client) trySend(message []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+
+	select {
+	case c.Send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// offer 按溢出策略将消息放入发送队列，返回false表示应断开该客户端
+func (c *Client) offer(message []byte) bool {
+	select {
+	case c.Send <- message:
+		return true
+	default:
+	}
+
+	switch config.AppConfig.WebSocket.OverflowPolicy {
+	case "drop_newest":
+		c.dropped.Add(1)
+		return true
+	case "drop_oldest":
+		select {
+		case <-c.Send:
+			c.dropped.Add(1)
+		default:
+		}
+		select {
+		case c.Send <- message:
+		default:
 		}
+		return true
+	default:
+		return false
+	}
+}
+
+// snapshotTopics 内容为完整快照的主题，只有最新一条有意义，限速客户端可以合并；
+// 告警、日志等事件类主题的每条消息都需要送达，不参与合并
+var snapshotTopics = map[string]bool{
+	"system_metrics":  true,
+	"service_status":  true,
+	"disk_usage":      true,
+	"network_traffic": true,
+}
+
+// enqueue 客户端设置了更新间隔时，将快照类消息放入待推送队列（同主题只保留最新一条）；
+// 返回false时由调用方立即放入发送队列，事件类消息因此不受更新间隔影响、按到达顺序推送
+func (c *Client) enqueue(message *Message) bool {
+	if !snapshotTopics[message.Type] {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.interval <= 0 {
+		return false
+	}
+
+	if _, exists := c.pending[message.Type]; exists {
+		// 同主题的旧消息被新消息覆盖
+		c.dropped.Add(1)
+	} else {
+		c.pendingOrder = append(c.pendingOrder, message.Type)
+	}
+	c.pending[message.Type] = message.Data
+	return true
+}
+
+// accepts 客户端是否接收该类型的广播
+func (c *Client) accepts(msgType string) bool {
+	return len(c.types) == 0 || c.types[msgType]
+}
+
+// takePending 取出所有待推送消息
+func (c *Client) takePending() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	messages := make([][]byte, 0, len(c.pendingOrder))
+	for _, msgType := range c.pendingOrder {
+		messages = append(messages, c.pending[msgType])
+	}
+	c.pending = make(map[string][]byte)
+	c.pendingOrder = nil
+	return messages
+}
+
+// SetInterval 设置客户端的更新间隔，0表示实时推送。
+// 请求的间隔小于配置的最小间隔时按最小间隔推送，返回实际生效的间隔
+func (c *Client) SetInterval(interval time.Duration) time.Duration {
+	if interval < 0 {
+		interval = 0
+	}
+	if minInterval := time.Duration(config.AppConfig.WebSocket.MinInterval * float64(time.Second)); interval < minInterval {
+		interval = minInterval
+	}
+
+	c.mu.Lock()
+	c.interval = interval
+	c.mu.Unlock()
+
+	select {
+	case c.intervalCh <- interval:
+	default:
 	}
+	return interval
 }
 
 // readPump 读取客户端消息
@@ -95,6 +468,11 @@ func (c *Client) readPump() {
 		return nil
 	})
 
+	// 按秒统计客户端发来的消息数，防止异常客户端刷消息
+	maxRate := config.AppConfig.WebSocket.MaxMessageRate
+	windowStart := time.Now()
+	windowCount := 0
+
 	for {
 		_, message, err := c.Socket.ReadMessage()
 		if err != nil {
@@ -104,6 +482,21 @@ func (c *Client) readPump() {
 			break
 		}
 
+		if maxRate > 0 {
+			if time.Since(windowStart) >= time.Second {
+				windowStart = time.Now()
+				windowCount = 0
+			}
+			windowCount++
+			if windowCount > maxRate {
+				log.Printf("Client %s exceeded message rate limit (%d/s), disconnecting", c.ID, maxRate)
+				c.Socket.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "message rate limit exceeded"),
+					time.Now().Add(time.Second))
+				break
+			}
+		}
+
 		// 处理客户端消息
 		c.handleMessage(message)
 	}
@@ -112,27 +505,48 @@ func (c *Client) readPump() {
 // writePump 向客户端发送消息
 func (c *Client) writePump() {
 	ticker := time.NewTicker(54 * time.Second)
+	// 合并推送定时器，客户端未设置更新间隔时保持停止
+	flushTicker := time.NewTicker(time.Hour)
+	flushTicker.Stop()
 	defer func() {
 		ticker.Stop()
+		flushTicker.Stop()
 		c.Socket.Close()
+		close(c.done)
 	}()
 
 	for {
 		select {
+		case interval := <-c.intervalCh:
+			if interval > 0 {
+				flushTicker.Reset(interval)
+			} else {
+				flushTicker.Stop()
+			}
+		case <-flushTicker.C:
+			c.Socket.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := c.writeMessages(c.takePending()); err != nil {
+				return
+			}
 		case message, ok := <-c.Send:
 			c.Socket.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if !ok {
-				c.Socket.WriteMessage(websocket.CloseMessage, []byte{})
+				c.mu.Lock()
+				closeFrame := c.closeFrame
+				c.mu.Unlock()
+				c.Socket.WriteMessage(websocket.CloseMessage, closeFrame)
 				return
 			}
 
-			w, err := c.Socket.NextWriter(websocket.TextMessage)
-			if err != nil {
-				return
+			messages := [][]byte{message}
+			// 把发送队列中已积压的消息一并取出，合并为一帧发送
+			if config.AppConfig.WebSocket.Batch {
+				for i, n := 0, len(c.Send); i < n; i++ {
+					messages = append(messages, <-c.Send)
+				}
 			}
-			w.Write(message)
 
-			if err := w.Close(); err != nil {
+			if err := c.writeMessages(messages); err != nil {
 				return
 			}
 		case <-ticker.C:
@@ -144,6 +558,40 @@ func (c *Client) writePump() {
 	}
 }
 
+// writeMessages 写出消息，启用批量发送时多条消息合并为一个batch帧
+func (c *Client) writeMessages(messages [][]byte) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	if len(messages) == 1 || !config.AppConfig.WebSocket.Batch {
+		for _, message := range messages {
+			if err := c.Socket.WriteMessage(websocket.TextMessage, message); err != nil {
+				return err
+			}
+			c.sent.Add(1)
+		}
+		return nil
+	}
+
+	batch := make([]json.RawMessage, len(messages))
+	for i, message := range messages {
+		batch[i] = message
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"type": "batch",
+		"data": batch,
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.Socket.WriteMessage(websocket.TextMessage, data); err != nil {
+		return err
+	}
+	c.sent.Add(int64(len(messages)))
+	return nil
+}
+
 // handleMessage 处理客户端消息
 func (c *Client) handleMessage(message []byte) {
 	var msg map[string]interface{}
@@ -157,8 +605,36 @@ func (c *Client) handleMessage(message []byte) {
 	case "subscribe":
 		// 客户端订阅特定类型的数据
 		if dataType, ok := msg["data_type"].(string); ok {
+			c.mu.Lock()
+			c.subscriptions = append(c.subscriptions, dataType)
+			c.mu.Unlock()
 			log.Printf("Client %s subscribed to %s", c.ID, dataType)
 		}
+		// 客户端可指定更新间隔（秒），如活跃仪表板1秒、大屏展示30秒；
+		// 也可指定最大更新频率（条/秒），如移动端 max_rate: 0.2 即每5秒一次
+		requested, hasInterval := msg["interval"].(float64)
+		if maxRate, ok := msg["max_rate"].(float64); ok && maxRate > 0 {
+			requested, hasInterval = 1/maxRate, true
+		}
+		if hasInterval {
+			interval := c.SetInterval(time.Duration(requested * float64(time.Second)))
+			log.Printf("Client %s update interval set to %.1fs", c.ID, interval.Seconds())
+
+			// 回复协商结果，客户端请求的间隔可能被服务端最小间隔限制
+			response := map[string]interface{}{
+				"type":     "subscribed",
+				"interval": interval.Seconds(),
+			}
+			if data, err := json.Marshal(response); err == nil {
+				c.trySend(data)
+			}
+		}
+	case "resend":
+		// 客户端发现某主题的序号不连续时，请求补发序号大于 since 的消息
+		if topic, ok := msg["topic"].(string); ok {
+			since, _ := msg["since"].(float64)
+			c.resend(topic, uint64(since))
+		}
 	case "ping":
 		// 响应ping消息
 		response := map[string]interface{}{
@@ -166,114 +642,191 @@ func (c *Client) handleMessage(message []byte) {
 			"timestamp": time.Now().Unix(),
 		}
 		if data, err := json.Marshal(response); err == nil {
-			c.Send <- data
+			c.trySend(data)
 		}
 	}
 }
 
 // ServeWebSocket WebSocket处理器
 func ServeWebSocket(hub *Hub) gin.HandlerFunc {
+	wsUpgrader := upgrader
+	wsUpgrader.EnableCompression = config.AppConfig.WebSocket.Compression
+
 	return func(c *gin.Context) {
-		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		remoteAddr := c.ClientIP()
+		if hub.shuttingDown.Load() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"code":    http.StatusServiceUnavailable,
+				"message": "服务正在重启",
+				"data":    nil,
+			})
+			return
+		}
+		// 连接时通过 ?host= 选择远程主机，未指定时推送本机的数据
+		host := c.Query("host")
+		var server models.Server
+		if host != "" {
+			if err := database.DB.Where("name = ?", host).First(&server).Error; err != nil {
+				c.JSON(http.StatusNotFound, gin.H{
+					"code":    http.StatusNotFound,
+					"message": "主机不存在",
+					"data":    nil,
+				})
+				return
+			}
+		}
+		if status, ok := hub.reserve(remoteAddr); !ok {
+			log.Printf("WebSocket connection from %s rejected: connection limit reached", remoteAddr)
+			c.JSON(status, gin.H{
+				"code":    status,
+				"message": "连接数已达上限",
+				"data":    nil,
+			})
+			return
+		}
+
+		conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
 		if err != nil {
+			hub.release(remoteAddr)
 			log.Printf("WebSocket upgrade error: %v", err)
 			return
 		}
 
+		// 客户端协商了压缩扩展时才会生效
+		if wsUpgrader.EnableCompression {
+			conn.EnableWriteCompression(true)
+			if err := conn.SetCompressionLevel(config.AppConfig.WebSocket.CompressionLevel); err != nil {
+				log.Printf("Invalid WebSocket compression level: %v", err)
+			}
+		}
+
 		client := &Client{
-			ID:     generateClientID(),
-			Socket: conn,
-			Send:   make(chan []byte, 256),
-			Hub:    hub,
+			ID:         generateClientID(),
+			Socket:     conn,
+			Send:       make(chan []byte, sendQueueSize()),
+			Hub:        hub,
+			intervalCh: make(chan time.Duration, 1),
+			pending:    make(map[string][]byte),
+			done:       make(chan struct{}),
+
+			RemoteAddr:  remoteAddr,
+			ConnectedAt: time.Now(),
+			Host:        host,
+			serverID:    server.ID,
+			transport:   "websocket",
+		}
+
+		// 配置了最小更新间隔时，新连接默认按最小间隔合并推送
+		if config.AppConfig.WebSocket.MinInterval > 0 {
+			client.SetInterval(0)
 		}
 
 		client.Hub.Register <- client
 
+		// 立即推送初始快照，仪表板无需等待下一次广播
+		client.sendSnapshot()
+
 		// 启动读写协程
 		go client.writePump()
 		go client.readPump()
 	}
 }
 
-// generateClientID 生成客户端ID
-func generateClientID() string {
-	return time.Now().Format("20060102150405") + "-" + randomString(8)
-}
+// sendSnapshot 推送所订阅主机的初始快照：当前指标、服务状态、活跃告警、最近日志和最近的图表数据点
+func (c *Client) sendSnapshot() {
+	host := database.HostScope(c.Host)
+
+	var currentMetric models.SystemMetrics
+	database.DB.Scopes(host).Order("timestamp desc").First(&currentMetric)
+
+	var services []models.ServiceStatus
+	database.DB.Scopes(host).Find(&services)
 
-// randomString 生成随机字符串
-func randomString(n int) string {
-	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, n)
-	for i := range b {
-		b[i] = letters[time.Now().UnixNano()%int64(len(letters))]
+	var activeAlerts []models.Alert
+	database.DB.Scopes(host).Where("status = ?", "active").Order("timestamp desc").Find(&activeAlerts)
+
+	var recentLogs []models.SystemLog
+	database.DB.Scopes(host).Order("timestamp desc").Limit(5).Find(&recentLogs)
+
+	var history []models.SystemMetrics
+	database.DB.Scopes(host).Order("timestamp desc").Limit(config.AppConfig.WebSocket.SnapshotPoints).Find(&history)
+	// 图表按时间正序绘制
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
 	}
-	return string(b)
-}
 
-// BroadcastSystemMetrics 广播系统指标
-func (h *Hub) BroadcastSystemMetrics(metrics *models.SystemMetrics) {
 	data := map[string]interface{}{
-		"type": "system_metrics",
-		"data": metrics,
+		"type": "snapshot",
+		"data": map[string]interface{}{
+			"current_metrics": currentMetric,
+			"services":        services,
+			"active_alerts":   activeAlerts,
+			"recent_logs":     recentLogs,
+			"history":         history,
+		},
+		"sequences": c.Hub.Sequences(c.serverID),
 	}
 
-	if message, err := json.Marshal(data); err == nil {
-		h.Broadcast <- message
+	message, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshaling snapshot for client %s: %v", c.ID, err)
+		return
 	}
+
+	c.trySend(message)
 }
 
-// BroadcastServiceStatus 广播服务状态
-func (h *Hub) BroadcastServiceStatus(services []models.ServiceStatus) {
-	data := map[string]interface{}{
-		"type": "service_status",
-		"data": services,
+// sendQueueSize 每个客户端发送队列的长度
+func sendQueueSize() int {
+	if size := config.AppConfig.WebSocket.SendQueueSize; size > 0 {
+		return size
 	}
+	return 256
+}
 
-	if message, err := json.Marshal(data); err == nil {
-		h.Broadcast <- message
-	}
+// generateClientID 生成客户端ID：时间前缀加8字节随机数的十六进制
+func generateClientID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return time.Now().Format("20060102150405") + "-" + hex.EncodeToString(buf)
 }
 
-// BroadcastAlert 广播告警
-func (h *Hub) BroadcastAlert(alert *models.Alert) {
-	data := map[string]interface{}{
-		"type": "alert",
-		"data": alert,
+// broadcast 序列化并广播主机的指定类型消息，每条消息带有所属主机和主题内单调递增的序号；
+// 分配序号和投递在同一把锁下完成，客户端收到的同一主题消息序号严格递增。
+// 内容与该主题上次广播的相同时不再推送，见 websocket.unchanged_interval
+func (h *Hub) broadcast(serverID uint, msgType string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
 	}
 
-	if message, err := json.Marshal(data); err == nil {
+	h.seqMu.Lock()
+	defer h.seqMu.Unlock()
+
+	if h.unchanged(serverID, msgType, data) {
+		return
+	}
+	if message, err := h.nextSequence(serverID, msgType, json.RawMessage(data)); err == nil {
 		h.Broadcast <- message
 	}
 }
 
-// BroadcastSystemLog 广播系统日志（支持单条或多条）
-func (h *Hub) BroadcastSystemLog(logs interface{}) {
-	data := map[string]interface{}{
-		"type": "system_log",
-		"data": logs,
-	}
+// BroadcastSystemMetrics 广播系统指标，推送给订阅了指标所属主机的客户端
+func (h *Hub) BroadcastSystemMetrics(metrics *models.SystemMetrics) {
+	h.broadcast(metrics.ServerID, "system_metrics", metrics)
+}
 
-	if message, err := json.Marshal(data); err == nil {
-		h.Broadcast <- message
-	}
+// BroadcastServiceStatus 广播本机的服务状态
+func (h *Hub) BroadcastServiceStatus(services []models.ServiceStatus) {
+	h.broadcast(0, "service_status", services)
 }
 
-// StartMetricsBroadcaster 启动指标广播器
-func (h *Hub) StartMetricsBroadcaster() {
-	ticker := time.NewTicker(5 * time.Second)
-	go func() {
-		for range ticker.C {
-			// 获取最新系统指标
-			var metrics models.SystemMetrics
-			if err := database.DB.Order("timestamp desc").First(&metrics).Error; err == nil {
-				h.BroadcastSystemMetrics(&metrics)
-			}
+// BroadcastAlert 广播告警，推送给订阅了告警所属主机的客户端
+func (h *Hub) BroadcastAlert(alert *models.Alert) {
+	h.broadcast(alert.ServerID, "alert", alert)
+}
 
-			// 获取服务状态
-			var services []models.ServiceStatus
-			if err := database.DB.Find(&services).Error; err == nil {
-				h.BroadcastServiceStatus(services)
-			}
-		}
-	}()
-} 
\ No newline at end of file
+// BroadcastSystemLog 广播新写入的系统日志，推送给订阅了日志所属主机的客户端；data为只含这一条日志的数组
+func (h *Hub) BroadcastSystemLog(entry models.SystemLog) {
+	h.broadcast(entry.ServerID, "system_log", []models.SystemLog{entry})
+}